@@ -1,3 +1,19 @@
 module github.com/betallsoph/shophub
 
 go 1.24.1
+
+require (
+	ecopoint/collecting_service v0.0.0
+	golang.org/x/time v0.11.0
+	google.golang.org/grpc v1.74.2
+	google.golang.org/protobuf v1.36.7
+)
+
+require (
+	golang.org/x/net v0.40.0 // indirect
+	golang.org/x/sys v0.33.0 // indirect
+	golang.org/x/text v0.25.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20250528174236-200df99c418a // indirect
+)
+
+replace ecopoint/collecting_service => ../ecopoint_server/collecting_service
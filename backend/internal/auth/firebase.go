@@ -0,0 +1,250 @@
+// Package auth verifies Firebase ID tokens, which both Flutter apps and the web client obtain
+// from firebase_auth. Verification follows the Firebase Admin SDK rules: RS256 signed by one
+// of Google's securetoken keys, issued for this project, not expired.
+package auth
+
+import (
+    "context"
+    "crypto"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math/big"
+    "net/http"
+    "strconv"
+    "strings"
+    "sync"
+    "time"
+)
+
+// GoogleKeysURL serves the JWKS that signs Firebase ID tokens
+const GoogleKeysURL = "https://www.googleapis.com/service_accounts/v1/jwk/securetoken@system.gserviceaccount.com"
+
+var (
+    ErrNoToken      = errors.New("missing bearer token")
+    ErrInvalidToken = errors.New("invalid token")
+    ErrExpiredToken = errors.New("token expired")
+)
+
+// Roles a token may carry in its "role" custom claim; tokens without one act as customers
+const (
+    RoleCustomer  = "customer"
+    RoleCollector = "collector"
+    RoleAdmin     = "admin"
+)
+
+// User is the verified identity behind a request
+type User struct {
+    ID    string
+    Role  string
+    Email string
+    Phone string
+}
+
+// clockSkew tolerates small differences between Google's clock and ours
+const clockSkew = time.Minute
+
+type Verifier struct {
+    projectID string
+    keysURL   string
+    client    *http.Client
+    now       func() time.Time
+
+    mu        sync.Mutex
+    keys      map[string]*rsa.PublicKey
+    expires   time.Time // keys are refetched after this
+    lastFetch time.Time // unknown kids trigger at most one refetch per minute
+}
+
+type Option func(*Verifier)
+
+// WithKeysURL replaces Google's JWKS endpoint, e.g. with the Auth emulator's or a test server's
+func WithKeysURL(url string) Option { return func(v *Verifier) { v.keysURL = url } }
+
+func WithHTTPClient(c *http.Client) Option { return func(v *Verifier) { v.client = c } }
+
+func WithClock(now func() time.Time) Option { return func(v *Verifier) { v.now = now } }
+
+func NewVerifier(projectID string, opts ...Option) *Verifier {
+    v := &Verifier{
+        projectID: projectID,
+        keysURL:   GoogleKeysURL,
+        client:    &http.Client{Timeout: 5 * time.Second},
+        now:       time.Now,
+    }
+    for _, opt := range opts {
+        opt(v)
+    }
+    return v
+}
+
+// FromRequest verifies the "Authorization: Bearer" token of r
+func (v *Verifier) FromRequest(r *http.Request) (*User, error) {
+    h := r.Header.Get("Authorization")
+    token, ok := strings.CutPrefix(h, "Bearer ")
+    if !ok || token == "" {
+        return nil, ErrNoToken
+    }
+    return v.Verify(r.Context(), token)
+}
+
+type header struct {
+    Alg string `json:"alg"`
+    Kid string `json:"kid"`
+}
+
+type claims struct {
+    Iss      string `json:"iss"`
+    Aud      string `json:"aud"`
+    Sub      string `json:"sub"`
+    Exp      int64  `json:"exp"`
+    Iat      int64  `json:"iat"`
+    AuthTime int64  `json:"auth_time"`
+    Email    string `json:"email"`
+    Phone    string `json:"phone_number"`
+    Role     string `json:"role"`
+}
+
+func (v *Verifier) Verify(ctx context.Context, token string) (*User, error) {
+    parts := strings.Split(token, ".")
+    if len(parts) != 3 {
+        return nil, fmt.Errorf("%w: malformed", ErrInvalidToken)
+    }
+    var h header
+    var c claims
+    if err := decodeSegment(parts[0], &h); err != nil {
+        return nil, err
+    }
+    if err := decodeSegment(parts[1], &c); err != nil {
+        return nil, err
+    }
+    if h.Alg != "RS256" || h.Kid == "" {
+        return nil, fmt.Errorf("%w: unexpected alg %q", ErrInvalidToken, h.Alg)
+    }
+    key, err := v.key(ctx, h.Kid)
+    if err != nil {
+        return nil, err
+    }
+    sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+    if err != nil {
+        return nil, fmt.Errorf("%w: signature encoding", ErrInvalidToken)
+    }
+    digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+    if err := rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig); err != nil {
+        return nil, fmt.Errorf("%w: bad signature", ErrInvalidToken)
+    }
+
+    now := v.now()
+    switch {
+    case c.Iss != "https://securetoken.google.com/"+v.projectID:
+        return nil, fmt.Errorf("%w: issuer %q", ErrInvalidToken, c.Iss)
+    case c.Aud != v.projectID:
+        return nil, fmt.Errorf("%w: audience %q", ErrInvalidToken, c.Aud)
+    case c.Sub == "" || len(c.Sub) > 128:
+        return nil, fmt.Errorf("%w: subject", ErrInvalidToken)
+    case time.Unix(c.Iat, 0).After(now.Add(clockSkew)), time.Unix(c.AuthTime, 0).After(now.Add(clockSkew)):
+        return nil, fmt.Errorf("%w: issued in the future", ErrInvalidToken)
+    case !time.Unix(c.Exp, 0).After(now.Add(-clockSkew)):
+        return nil, ErrExpiredToken
+    }
+    u := &User{ID: c.Sub, Role: RoleCustomer, Email: c.Email, Phone: c.Phone}
+    switch c.Role {
+    case RoleCollector, RoleAdmin:
+        u.Role = c.Role
+    }
+    return u, nil
+}
+
+func decodeSegment(seg string, dst any) error {
+    data, err := base64.RawURLEncoding.DecodeString(seg)
+    if err != nil {
+        return fmt.Errorf("%w: segment encoding", ErrInvalidToken)
+    }
+    if err := json.Unmarshal(data, dst); err != nil {
+        return fmt.Errorf("%w: segment json", ErrInvalidToken)
+    }
+    return nil
+}
+
+// key returns the public key for kid, refreshing the cached set when it expired or lacks kid
+func (v *Verifier) key(ctx context.Context, kid string) (*rsa.PublicKey, error) {
+    v.mu.Lock()
+    defer v.mu.Unlock()
+    now := v.now()
+    if k, ok := v.keys[kid]; ok && now.Before(v.expires) {
+        return k, nil
+    }
+    if now.Before(v.expires) && now.Sub(v.lastFetch) < time.Minute {
+        return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+    }
+    if err := v.fetch(ctx, now); err != nil {
+        // keep serving with the old keys when Google is briefly unreachable
+        if k, ok := v.keys[kid]; ok {
+            return k, nil
+        }
+        return nil, err
+    }
+    if k, ok := v.keys[kid]; ok {
+        return k, nil
+    }
+    return nil, fmt.Errorf("%w: unknown key %q", ErrInvalidToken, kid)
+}
+
+type jwks struct {
+    Keys []struct {
+        Kid string `json:"kid"`
+        Kty string `json:"kty"`
+        N   string `json:"n"`
+        E   string `json:"e"`
+    } `json:"keys"`
+}
+
+func (v *Verifier) fetch(ctx context.Context, now time.Time) error {
+    v.lastFetch = now
+    req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.keysURL, nil)
+    if err != nil {
+        return err
+    }
+    res, err := v.client.Do(req)
+    if err != nil {
+        return fmt.Errorf("fetch signing keys: %w", err)
+    }
+    defer res.Body.Close()
+    if res.StatusCode != http.StatusOK {
+        return fmt.Errorf("fetch signing keys: %s", res.Status)
+    }
+    var set jwks
+    if err := json.NewDecoder(res.Body).Decode(&set); err != nil {
+        return fmt.Errorf("decode signing keys: %w", err)
+    }
+    keys := map[string]*rsa.PublicKey{}
+    for _, k := range set.Keys {
+        if k.Kty != "RSA" {
+            continue
+        }
+        n, err1 := base64.RawURLEncoding.DecodeString(k.N)
+        e, err2 := base64.RawURLEncoding.DecodeString(k.E)
+        if err1 != nil || err2 != nil {
+            continue
+        }
+        keys[k.Kid] = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}
+    }
+    v.keys = keys
+    v.expires = now.Add(maxAge(res.Header.Get("Cache-Control")))
+    return nil
+}
+
+// maxAge reads max-age from Cache-Control, defaulting to an hour
+func maxAge(cc string) time.Duration {
+    for _, d := range strings.Split(cc, ",") {
+        if s, ok := strings.CutPrefix(strings.TrimSpace(d), "max-age="); ok {
+            if n, err := strconv.Atoi(s); err == nil && n > 0 {
+                return time.Duration(n) * time.Second
+            }
+        }
+    }
+    return time.Hour
+}
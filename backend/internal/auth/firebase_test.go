@@ -0,0 +1,147 @@
+package auth
+
+import (
+    "context"
+    "crypto"
+    "crypto/rand"
+    "crypto/rsa"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "math/big"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+    "time"
+)
+
+type signer struct {
+    key *rsa.PrivateKey
+    kid string
+}
+
+func (s signer) sign(t *testing.T, claims map[string]any) string {
+    t.Helper()
+    enc := func(v any) string {
+        data, err := json.Marshal(v)
+        if err != nil {
+            t.Fatal(err)
+        }
+        return base64.RawURLEncoding.EncodeToString(data)
+    }
+    unsigned := enc(map[string]string{"alg": "RS256", "kid": s.kid, "typ": "JWT"}) + "." + enc(claims)
+    digest := sha256.Sum256([]byte(unsigned))
+    sig, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+    if err != nil {
+        t.Fatal(err)
+    }
+    return unsigned + "." + base64.RawURLEncoding.EncodeToString(sig)
+}
+
+func newSigner(t *testing.T, kid string) signer {
+    t.Helper()
+    key, err := rsa.GenerateKey(rand.Reader, 2048)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return signer{key: key, kid: kid}
+}
+
+// keyServer serves the public keys of signers as a JWKS and counts fetches
+func keyServer(t *testing.T, fetches *int, signers ...signer) *httptest.Server {
+    t.Helper()
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        *fetches++
+        var set jwks
+        for _, s := range signers {
+            set.Keys = append(set.Keys, struct {
+                Kid string `json:"kid"`
+                Kty string `json:"kty"`
+                N   string `json:"n"`
+                E   string `json:"e"`
+            }{s.kid, "RSA", base64.RawURLEncoding.EncodeToString(s.key.N.Bytes()), base64.RawURLEncoding.EncodeToString(big.NewInt(int64(s.key.E)).Bytes())})
+        }
+        w.Header().Set("Cache-Control", "public, max-age=3600")
+        _ = json.NewEncoder(w).Encode(set)
+    }))
+    t.Cleanup(srv.Close)
+    return srv
+}
+
+func TestVerify(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    good := newSigner(t, "k1")
+    rogue := newSigner(t, "k1")
+    fetches := 0
+    srv := keyServer(t, &fetches, good)
+    v := NewVerifier("ecopoint-v1", WithKeysURL(srv.URL), WithClock(func() time.Time { return now }))
+    valid := func() map[string]any {
+        return map[string]any{
+            "iss": "https://securetoken.google.com/ecopoint-v1", "aud": "ecopoint-v1", "sub": "uid-1",
+            "iat": now.Add(-time.Minute).Unix(), "auth_time": now.Add(-time.Hour).Unix(), "exp": now.Add(time.Hour).Unix(),
+            "phone_number": "+84901234567",
+        }
+    }
+
+    u, err := v.Verify(context.Background(), good.sign(t, valid()))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if u.ID != "uid-1" || u.Role != RoleCustomer || u.Phone != "+84901234567" {
+        t.Errorf("user = %+v", u)
+    }
+    c := valid()
+    c["role"] = "collector"
+    if u, err := v.Verify(context.Background(), good.sign(t, c)); err != nil || u.Role != RoleCollector {
+        t.Errorf("role claim: %+v %v", u, err)
+    }
+    c["role"] = "superuser"
+    if u, err := v.Verify(context.Background(), good.sign(t, c)); err != nil || u.Role != RoleCustomer {
+        t.Errorf("unknown role claim: %+v %v", u, err)
+    }
+
+    for name, tc := range map[string]struct {
+        token string
+        want  error
+    }{
+        "expired":        {good.sign(t, with(valid(), "exp", now.Add(-2*time.Minute).Unix())), ErrExpiredToken},
+        "other project":  {good.sign(t, with(valid(), "aud", "someone-else")), ErrInvalidToken},
+        "other issuer":   {good.sign(t, with(valid(), "iss", "https://evil.example")), ErrInvalidToken},
+        "no subject":     {good.sign(t, with(valid(), "sub", "")), ErrInvalidToken},
+        "future":         {good.sign(t, with(valid(), "iat", now.Add(time.Hour).Unix())), ErrInvalidToken},
+        "forged":         {rogue.sign(t, valid()), ErrInvalidToken},
+        "unknown key":    {newSigner(t, "k2").sign(t, valid()), ErrInvalidToken},
+        "not a token":    {"abc", ErrInvalidToken},
+    } {
+        if _, err := v.Verify(context.Background(), tc.token); !errors.Is(err, tc.want) {
+            t.Errorf("%s: err = %v, want %v", name, err, tc.want)
+        }
+    }
+    // unknown kids within a minute of the last fetch do not hit the key server again
+    if fetches != 1 {
+        t.Errorf("fetches = %d, want 1", fetches)
+    }
+    now = now.Add(2 * time.Minute)
+    if _, err := v.Verify(context.Background(), newSigner(t, "k3").sign(t, with(valid(), "exp", now.Add(time.Hour).Unix()))); !errors.Is(err, ErrInvalidToken) || fetches != 2 {
+        t.Errorf("unknown kid after a minute: err = %v, fetches = %d", err, fetches)
+    }
+}
+
+func TestFromRequest(t *testing.T) {
+    v := NewVerifier("ecopoint-v1")
+    r := httptest.NewRequest(http.MethodGet, "/", nil)
+    if _, err := v.FromRequest(r); !errors.Is(err, ErrNoToken) {
+        t.Errorf("err = %v", err)
+    }
+    r.Header.Set("X-User-Id", "u1")
+    r.Header.Set("X-User-Role", "admin")
+    if u, err := (TrustedHeaders{}).FromRequest(r); err != nil || u.ID != "u1" || u.Role != RoleAdmin {
+        t.Errorf("trusted headers: %+v %v", u, err)
+    }
+}
+
+func with(c map[string]any, key string, v any) map[string]any {
+    c[key] = v
+    return c
+}
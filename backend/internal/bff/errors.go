@@ -0,0 +1,50 @@
+package bff
+
+import (
+    "net/http"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/encoding/protojson"
+)
+
+// writeError renders err as google.rpc.Status JSON, the error shape of the REST gateway
+func writeError(w http.ResponseWriter, err error) {
+    st := status.Convert(err)
+    data, merr := protojson.MarshalOptions{UseProtoNames: true}.Marshal(st.Proto())
+    if merr != nil {
+        data = []byte(`{"code":13,"message":"encode error"}`)
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(httpStatus(st.Code()))
+    _, _ = w.Write(data)
+}
+
+// httpStatus maps gRPC codes like the collecting gateway does
+func httpStatus(c codes.Code) int {
+    switch c {
+    case codes.OK:
+        return http.StatusOK
+    case codes.Canceled:
+        return 499 // client closed request
+    case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+        return http.StatusBadRequest
+    case codes.DeadlineExceeded:
+        return http.StatusGatewayTimeout
+    case codes.NotFound:
+        return http.StatusNotFound
+    case codes.AlreadyExists, codes.Aborted:
+        return http.StatusConflict
+    case codes.PermissionDenied:
+        return http.StatusForbidden
+    case codes.Unauthenticated:
+        return http.StatusUnauthorized
+    case codes.ResourceExhausted:
+        return http.StatusTooManyRequests
+    case codes.Unimplemented:
+        return http.StatusNotImplemented
+    case codes.Unavailable:
+        return http.StatusServiceUnavailable
+    }
+    return http.StatusInternalServerError
+}
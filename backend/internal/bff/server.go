@@ -0,0 +1,190 @@
+// Package bff is the backend-for-frontend of the Flutter apps and the web client. It
+// authenticates each request once, rate limits per client, answers screen-shaped views
+// by fanning out to CollectingService and AccountService over gRPC, and forwards every
+// other /api/v1 route to the collecting service's REST gateway with the verified identity.
+package bff
+
+import (
+    "context"
+    "log/slog"
+    "math"
+    "net"
+    "net/http"
+    "net/http/httputil"
+    "net/url"
+    "strconv"
+    "strings"
+    "time"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+
+    "github.com/betallsoph/shophub/internal/auth"
+    "github.com/betallsoph/shophub/internal/ratelimit"
+    pb "ecopoint/collecting_service/pb"
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// Identity headers understood by the collecting service and its gateway
+const (
+    headerUserID = "X-User-Id"
+    headerRole   = "X-User-Role"
+)
+
+// Authenticator turns a request into a verified user
+type Authenticator interface {
+    FromRequest(r *http.Request) (*auth.User, error)
+}
+
+type Server struct {
+    orders   pb.CollectingServiceClient
+    accounts accountpb.AccountServiceClient
+    auth     Authenticator
+    limits   *ratelimit.Limiter // nil: unlimited
+    proxy    http.Handler       // nil: only the views are served
+    origins  map[string]bool
+    timeout  time.Duration // budget of one view, all calls included
+    log      *slog.Logger
+    mux      *http.ServeMux
+}
+
+type Option func(*Server)
+
+func WithRateLimit(l *ratelimit.Limiter) Option { return func(s *Server) { s.limits = l } }
+
+func WithLogger(l *slog.Logger) Option { return func(s *Server) { s.log = l } }
+
+func WithTimeout(d time.Duration) Option { return func(s *Server) { s.timeout = d } }
+
+// WithCORSOrigins allows browsers on these origins; "*" allows any
+func WithCORSOrigins(origins []string) Option {
+    return func(s *Server) {
+        s.origins = map[string]bool{}
+        for _, o := range origins {
+            s.origins[o] = true
+        }
+    }
+}
+
+// WithGateway forwards /api/v1/... to target/v1/... on the collecting REST gateway
+func WithGateway(target *url.URL) Option {
+    return func(s *Server) {
+        s.proxy = &httputil.ReverseProxy{Rewrite: func(pr *httputil.ProxyRequest) {
+            pr.SetURL(target)
+            pr.Out.URL.Path = strings.TrimSuffix(target.Path, "/") + strings.TrimPrefix(pr.In.URL.Path, "/api")
+            pr.Out.URL.RawPath = ""
+            pr.SetXForwarded()
+            // only the identity verified here may reach the gateway
+            pr.Out.Header.Del("Authorization")
+            pr.Out.Header.Del(headerUserID)
+            pr.Out.Header.Del(headerRole)
+            if u := userFrom(pr.In.Context()); u != nil {
+                pr.Out.Header.Set(headerUserID, u.ID)
+                pr.Out.Header.Set(headerRole, u.Role)
+            }
+        }}
+    }
+}
+
+func New(orders pb.CollectingServiceClient, accounts accountpb.AccountServiceClient, a Authenticator, opts ...Option) *Server {
+    s := &Server{orders: orders, accounts: accounts, auth: a, timeout: 3 * time.Second, log: slog.Default(), mux: http.NewServeMux()}
+    for _, opt := range opts {
+        opt(s)
+    }
+    s.mux.HandleFunc("GET /api/v1/views/orders/{order_id}", s.orderView)
+    s.mux.HandleFunc("GET /api/v1/views/me", s.meView)
+    s.mux.HandleFunc("GET /api/v1/views/collector-home", s.collectorHome)
+    if s.proxy != nil {
+        s.mux.Handle("/api/v1/", s.proxy)
+    }
+    return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if s.origins != nil && s.cors(w, r) {
+        return
+    }
+    if r.URL.Path == "/healthz" {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+    u, err := s.auth.FromRequest(r)
+    if err != nil {
+        // failed attempts are limited per IP so tokens cannot be guessed at full speed
+        if !s.allow(w, "ip:"+clientIP(r)) {
+            return
+        }
+        writeError(w, status.Error(codes.Unauthenticated, err.Error()))
+        return
+    }
+    if !s.allow(w, "uid:"+u.ID) {
+        return
+    }
+    s.mux.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey{}, u)))
+}
+
+// allow answers 429 with Retry-After when key has no tokens left
+func (s *Server) allow(w http.ResponseWriter, key string) bool {
+    if s.limits == nil {
+        return true
+    }
+    ok, wait := s.limits.Allow(key)
+    if ok {
+        return true
+    }
+    w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+    writeError(w, status.Error(codes.ResourceExhausted, "rate limit exceeded"))
+    return false
+}
+
+type userKey struct{}
+
+func userFrom(ctx context.Context) *auth.User {
+    u, _ := ctx.Value(userKey{}).(*auth.User)
+    return u
+}
+
+// outgoing is the context of the gRPC calls of one view: the request's, bounded by the view
+// budget, carrying the caller identity and address (the collecting service limits per IP)
+func (s *Server) outgoing(r *http.Request) (context.Context, context.CancelFunc) {
+    ctx, cancel := context.WithTimeout(r.Context(), s.timeout)
+    ctx = metadata.AppendToOutgoingContext(ctx, "x-forwarded-for", clientIP(r))
+    if u := userFrom(ctx); u != nil {
+        ctx = metadata.AppendToOutgoingContext(ctx, "x-user-id", u.ID, "x-user-role", u.Role)
+    }
+    return ctx, cancel
+}
+
+func clientIP(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        return r.RemoteAddr
+    }
+    return host
+}
+
+var (
+    corsMethods = "GET, POST, PUT, PATCH, DELETE"
+    corsHeaders = "Authorization, Content-Type, Traceparent, Tracestate"
+)
+
+// cors sets the CORS headers for allowed origins and answers preflights; it reports whether
+// the request has been handled
+func (s *Server) cors(w http.ResponseWriter, r *http.Request) bool {
+    origin := r.Header.Get("Origin")
+    w.Header().Add("Vary", "Origin")
+    if origin == "" || !(s.origins["*"] || s.origins[origin]) {
+        return false
+    }
+    w.Header().Set("Access-Control-Allow-Origin", origin)
+    w.Header().Set("Access-Control-Expose-Headers", "Retry-After")
+    if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+        return false
+    }
+    w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+    w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+    w.Header().Set("Access-Control-Max-Age", "600")
+    w.WriteHeader(http.StatusNoContent)
+    return true
+}
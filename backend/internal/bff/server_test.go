@@ -0,0 +1,173 @@
+package bff
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "net/url"
+    "strings"
+    "testing"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+
+    "github.com/betallsoph/shophub/internal/auth"
+    "github.com/betallsoph/shophub/internal/ratelimit"
+    pb "ecopoint/collecting_service/pb"
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// orders answers the calls of the views; the embedded nil client panics on anything else
+type orders struct {
+    pb.CollectingServiceClient
+    callers []string // x-user-id of each call
+}
+
+func (o *orders) seen(ctx context.Context) {
+    md, _ := metadata.FromOutgoingContext(ctx)
+    o.callers = append(o.callers, strings.Join(md.Get("x-user-id"), ",")+"@"+strings.Join(md.Get("x-forwarded-for"), ","))
+}
+
+func (o *orders) GetOrder(ctx context.Context, in *pb.GetOrderRequest, _ ...grpc.CallOption) (*pb.Order, error) {
+    o.seen(ctx)
+    if in.OrderId == "missing" {
+        return nil, status.Error(codes.NotFound, "order not found")
+    }
+    return &pb.Order{Id: in.OrderId, AcceptedBy: "collector-1"}, nil
+}
+
+func (o *orders) GetUserRating(ctx context.Context, in *pb.GetUserRatingRequest, _ ...grpc.CallOption) (*pb.UserRating, error) {
+    return &pb.UserRating{UserId: in.UserId, Count: 4, Average: 4.5, Tags: []*pb.TagCount{{Tag: "on_time"}, {Tag: "friendly"}, {Tag: "careful"}, {Tag: "fast"}}}, nil
+}
+
+func (o *orders) GetNotificationPreferences(ctx context.Context, in *pb.GetNotificationPreferencesRequest, _ ...grpc.CallOption) (*pb.NotificationPreferences, error) {
+    return &pb.NotificationPreferences{}, nil
+}
+
+type accounts struct {
+    accountpb.AccountServiceClient
+    down bool
+}
+
+func (a *accounts) GetUser(ctx context.Context, in *accountpb.GetUserRequest, _ ...grpc.CallOption) (*accountpb.User, error) {
+    if a.down {
+        return nil, status.Error(codes.Unavailable, "connection refused")
+    }
+    return &accountpb.User{UserId: in.UserId, DisplayName: "Anh Ba", Phone: "+84901234567"}, nil
+}
+
+func (a *accounts) ListAddresses(ctx context.Context, in *accountpb.ListAddressesRequest, _ ...grpc.CallOption) (*accountpb.ListAddressesResponse, error) {
+    if a.down {
+        return nil, status.Error(codes.Unavailable, "connection refused")
+    }
+    return &accountpb.ListAddressesResponse{}, nil
+}
+
+func get(t *testing.T, h http.Handler, path string, header map[string]string) *httptest.ResponseRecorder {
+    t.Helper()
+    r := httptest.NewRequest(http.MethodGet, path, nil)
+    for k, v := range header {
+        r.Header.Set(k, v)
+    }
+    w := httptest.NewRecorder()
+    h.ServeHTTP(w, r)
+    return w
+}
+
+func as(id, role string) map[string]string {
+    return map[string]string{"X-User-Id": id, "X-User-Role": role}
+}
+
+func TestOrderView(t *testing.T) {
+    o := &orders{}
+    s := New(o, &accounts{}, auth.TrustedHeaders{})
+    w := get(t, s, "/api/v1/views/orders/o1", as("customer-1", auth.RoleCustomer))
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d: %s", w.Code, w.Body)
+    }
+    if strings.Contains(w.Body.String(), "+84901234567") {
+        t.Errorf("collector phone leaked: %s", w.Body)
+    }
+    var v orderView
+    if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+        t.Fatal(err)
+    }
+    if v.Collector == nil || v.Collector.DisplayName != "Anh Ba" || len(v.Collector.Rating.TopTags) != topTags || len(v.Unavailable) != 0 {
+        t.Errorf("view = %s", w.Body)
+    }
+    if len(o.callers) != 1 || o.callers[0] != "customer-1@192.0.2.1" {
+        t.Errorf("gRPC calls carried %q", o.callers)
+    }
+
+    if w := get(t, s, "/api/v1/views/orders/missing", as("customer-1", auth.RoleCustomer)); w.Code != http.StatusNotFound {
+        t.Errorf("missing order: status = %d", w.Code)
+    }
+}
+
+func TestMeViewIsPartialWhenAccountsFail(t *testing.T) {
+    s := New(&orders{}, &accounts{down: true}, auth.TrustedHeaders{})
+    w := get(t, s, "/api/v1/views/me", as("customer-1", auth.RoleCustomer))
+    if w.Code != http.StatusOK {
+        t.Fatalf("status = %d: %s", w.Code, w.Body)
+    }
+    var v meView
+    if err := json.Unmarshal(w.Body.Bytes(), &v); err != nil {
+        t.Fatal(err)
+    }
+    if strings.Join(v.Unavailable, ",") != "profile,addresses" || v.Rating == nil || v.Notifications == nil {
+        t.Errorf("view = %s", w.Body)
+    }
+}
+
+func TestAuthAndLimits(t *testing.T) {
+    s := New(&orders{}, &accounts{}, auth.TrustedHeaders{}, WithRateLimit(ratelimit.New(1, 2)))
+    if w := get(t, s, "/api/v1/views/me", nil); w.Code != http.StatusUnauthorized {
+        t.Errorf("no identity: status = %d", w.Code)
+    }
+    if w := get(t, s, "/api/v1/views/collector-home", as("customer-1", auth.RoleCustomer)); w.Code != http.StatusForbidden {
+        t.Errorf("customer on collector home: status = %d", w.Code)
+    }
+    get(t, s, "/api/v1/views/me", as("customer-1", auth.RoleCustomer))
+    w := get(t, s, "/api/v1/views/me", as("customer-1", auth.RoleCustomer))
+    if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "1" {
+        t.Errorf("over the burst: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+    }
+    if w := get(t, s, "/api/v1/views/me", as("customer-2", auth.RoleCustomer)); w.Code != http.StatusOK {
+        t.Errorf("other user: status = %d", w.Code)
+    }
+    if w := get(t, s, "/healthz", nil); w.Code != http.StatusOK {
+        t.Errorf("healthz: status = %d", w.Code)
+    }
+}
+
+func TestProxySetsVerifiedIdentity(t *testing.T) {
+    var got *http.Request
+    upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        got = r
+        w.WriteHeader(http.StatusNoContent)
+    }))
+    defer upstream.Close()
+    target, _ := url.Parse(upstream.URL + "/rest")
+    // the verifier accepts any request as customer-1, whatever identity headers it carries
+    s := New(&orders{}, &accounts{}, fixed{ID: "customer-1", Role: auth.RoleCustomer}, WithGateway(target))
+    w := get(t, s, "/api/v1/orders/o1?x=1", map[string]string{"Authorization": "Bearer t", "X-User-Id": "admin", "X-User-Role": "admin"})
+    if w.Code != http.StatusNoContent || got == nil {
+        t.Fatalf("status = %d", w.Code)
+    }
+    if got.URL.Path != "/rest/v1/orders/o1" || got.URL.RawQuery != "x=1" {
+        t.Errorf("forwarded to %s", got.URL)
+    }
+    if got.Header.Get("X-User-Id") != "customer-1" || got.Header.Get("X-User-Role") != auth.RoleCustomer || got.Header.Get("Authorization") != "" {
+        t.Errorf("forwarded headers %v", got.Header)
+    }
+}
+
+type fixed auth.User
+
+func (f fixed) FromRequest(*http.Request) (*auth.User, error) {
+    u := auth.User(f)
+    return &u, nil
+}
@@ -0,0 +1,235 @@
+package bff
+
+import (
+    "encoding/json"
+    "net/http"
+    "sync"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+
+    "github.com/betallsoph/shophub/internal/auth"
+    pb "ecopoint/collecting_service/pb"
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// Views embed messages in the gateway's JSON form so clients parse one shape everywhere.
+// A section whose call failed is null and named in unavailable; only the main call of a
+// view fails the whole response.
+
+// availablePreview is how many pool orders the collector home screen shows
+const availablePreview = 10
+
+// topTags is how many rating tags a card shows
+const topTags = 3
+
+var marshal = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+
+type ratingSummary struct {
+    Average float64  `json:"average"`
+    Count   int64    `json:"count"`
+    TopTags []string `json:"top_tags"`
+}
+
+// collectorCard is what a customer sees of the collector; the phone stays behind the relay
+type collectorCard struct {
+    UserID      string         `json:"user_id"`
+    DisplayName string         `json:"display_name"`
+    AvatarURL   string         `json:"avatar_url"`
+    Rating      *ratingSummary `json:"rating"`
+}
+
+type orderView struct {
+    Order       json.RawMessage `json:"order"`
+    Collector   *collectorCard  `json:"collector"` // null until the order is accepted
+    Unavailable []string        `json:"unavailable,omitempty"`
+}
+
+type meView struct {
+    UserID        string            `json:"user_id"`
+    Role          string            `json:"role"`
+    Profile       json.RawMessage   `json:"profile"`
+    Addresses     []json.RawMessage `json:"addresses"`
+    Rating        *ratingSummary    `json:"rating"` // ratings received in the current role
+    Notifications json.RawMessage   `json:"notification_preferences"`
+    Unavailable   []string          `json:"unavailable,omitempty"`
+}
+
+type collectorHome struct {
+    ActiveOrders       []json.RawMessage `json:"active_orders"`
+    AvailableOrders    []json.RawMessage `json:"available_orders"`
+    AvailablePageToken string            `json:"available_next_page_token"`
+    Penalty            json.RawMessage   `json:"penalty"`
+    Rating             *ratingSummary    `json:"rating"`
+    Unavailable        []string          `json:"unavailable,omitempty"`
+}
+
+// orderView is the order detail screen: the order, plus the collector's card once accepted
+func (s *Server) orderView(w http.ResponseWriter, r *http.Request) {
+    ctx, cancel := s.outgoing(r)
+    defer cancel()
+    o, err := s.orders.GetOrder(ctx, &pb.GetOrderRequest{OrderId: r.PathValue("order_id")})
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    v := orderView{Order: raw(o)}
+    if o.AcceptedBy != "" {
+        var (
+            user              *accountpb.User
+            rating            *pb.UserRating
+            userErr, ratedErr error
+        )
+        fanout(
+            func() { user, userErr = s.accounts.GetUser(ctx, &accountpb.GetUserRequest{UserId: o.AcceptedBy}) },
+            func() { rating, ratedErr = s.orders.GetUserRating(ctx, collectorRating(o.AcceptedBy)) },
+        )
+        v.Collector = &collectorCard{UserID: o.AcceptedBy}
+        if s.section(&v.Unavailable, "collector.profile", userErr) {
+            v.Collector.DisplayName, v.Collector.AvatarURL = user.DisplayName, user.AvatarUrl
+        }
+        if s.section(&v.Unavailable, "collector.rating", ratedErr) {
+            v.Collector.Rating = summarize(rating)
+        }
+    }
+    writeJSON(w, v)
+}
+
+// meView is the profile screen of the signed-in user, in either app
+func (s *Server) meView(w http.ResponseWriter, r *http.Request) {
+    u := userFrom(r.Context())
+    ctx, cancel := s.outgoing(r)
+    defer cancel()
+    role := pb.UserRole_USER_ROLE_CUSTOMER
+    if u.Role == auth.RoleCollector {
+        role = pb.UserRole_USER_ROLE_COLLECTOR
+    }
+    var (
+        profile                        *accountpb.User
+        addresses                      *accountpb.ListAddressesResponse
+        rating                         *pb.UserRating
+        prefs                          *pb.NotificationPreferences
+        profErr, addrErr, rateErr, prefErr error
+    )
+    fanout(
+        func() { profile, profErr = s.accounts.GetUser(ctx, &accountpb.GetUserRequest{UserId: u.ID}) },
+        func() { addresses, addrErr = s.accounts.ListAddresses(ctx, &accountpb.ListAddressesRequest{UserId: u.ID}) },
+        func() { rating, rateErr = s.orders.GetUserRating(ctx, &pb.GetUserRatingRequest{UserId: u.ID, Role: role}) },
+        func() { prefs, prefErr = s.orders.GetNotificationPreferences(ctx, &pb.GetNotificationPreferencesRequest{UserId: u.ID}) },
+    )
+    v := meView{UserID: u.ID, Role: u.Role, Addresses: []json.RawMessage{}}
+    if s.section(&v.Unavailable, "profile", profErr) {
+        v.Profile = raw(profile)
+    }
+    if s.section(&v.Unavailable, "addresses", addrErr) {
+        v.Addresses = rawList(addresses.Addresses)
+    }
+    if s.section(&v.Unavailable, "rating", rateErr) {
+        v.Rating = summarize(rating)
+    }
+    if s.section(&v.Unavailable, "notification_preferences", prefErr) {
+        v.Notifications = raw(prefs)
+    }
+    writeJSON(w, v)
+}
+
+// collectorHome is the collector app's start screen: current jobs, a glimpse of the pool,
+// cooldown standing and rating
+func (s *Server) collectorHome(w http.ResponseWriter, r *http.Request) {
+    u := userFrom(r.Context())
+    if u.Role != auth.RoleCollector {
+        writeError(w, status.Error(codes.PermissionDenied, "this view is for collectors"))
+        return
+    }
+    ctx, cancel := s.outgoing(r)
+    defer cancel()
+    var (
+        active                             *pb.ListOrdersResponse
+        available                          *pb.ListAvailableOrdersResponse
+        penalty                            *pb.CollectorPenalty
+        rating                             *pb.UserRating
+        actErr, availErr, penErr, rateErr  error
+    )
+    fanout(
+        func() { active, actErr = s.orders.ListMyActiveOrders(ctx, &pb.ListMyActiveOrdersRequest{CollectorId: u.ID}) },
+        func() { available, availErr = s.orders.ListAvailableOrders(ctx, &pb.ListAvailableOrdersRequest{PageSize: availablePreview}) },
+        func() { penalty, penErr = s.orders.GetCollectorPenalty(ctx, &pb.GetCollectorPenaltyRequest{CollectorId: u.ID}) },
+        func() { rating, rateErr = s.orders.GetUserRating(ctx, collectorRating(u.ID)) },
+    )
+    if actErr != nil {
+        writeError(w, actErr)
+        return
+    }
+    v := collectorHome{ActiveOrders: rawList(active.Orders), AvailableOrders: []json.RawMessage{}}
+    if s.section(&v.Unavailable, "available_orders", availErr) {
+        v.AvailableOrders, v.AvailablePageToken = rawList(available.Orders), available.NextPageToken
+    }
+    if s.section(&v.Unavailable, "penalty", penErr) {
+        v.Penalty = raw(penalty)
+    }
+    if s.section(&v.Unavailable, "rating", rateErr) {
+        v.Rating = summarize(rating)
+    }
+    writeJSON(w, v)
+}
+
+// section reports whether a secondary call succeeded; failures are logged and listed
+func (s *Server) section(unavailable *[]string, name string, err error) bool {
+    if err == nil {
+        return true
+    }
+    s.log.Warn("view section unavailable", "section", name, "code", status.Code(err), "error", err)
+    *unavailable = append(*unavailable, name)
+    return false
+}
+
+// fanout runs calls concurrently and waits for all of them
+func fanout(calls ...func()) {
+    var wg sync.WaitGroup
+    for _, call := range calls {
+        wg.Add(1)
+        go func() {
+            defer wg.Done()
+            call()
+        }()
+    }
+    wg.Wait()
+}
+
+func collectorRating(userID string) *pb.GetUserRatingRequest {
+    return &pb.GetUserRatingRequest{UserId: userID, Role: pb.UserRole_USER_ROLE_COLLECTOR}
+}
+
+func summarize(r *pb.UserRating) *ratingSummary {
+    sum := &ratingSummary{Average: r.Average, Count: r.Count, TopTags: []string{}}
+    for i, t := range r.Tags {
+        if i == topTags {
+            break
+        }
+        sum.TopTags = append(sum.TopTags, t.Tag)
+    }
+    return sum
+}
+
+func raw(m proto.Message) json.RawMessage {
+    data, err := marshal.Marshal(m)
+    if err != nil {
+        return nil
+    }
+    return data
+}
+
+func rawList[T proto.Message](items []T) []json.RawMessage {
+    res := make([]json.RawMessage, 0, len(items))
+    for _, m := range items {
+        res = append(res, raw(m))
+    }
+    return res
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+    w.Header().Set("Content-Type", "application/json")
+    _ = json.NewEncoder(w).Encode(v)
+}
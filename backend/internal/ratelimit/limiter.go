@@ -0,0 +1,70 @@
+// Package ratelimit keeps one token bucket per client (a user ID or an IP address)
+package ratelimit
+
+import (
+    "sync"
+    "time"
+
+    "golang.org/x/time/rate"
+)
+
+// idleAfter is how long an unused bucket is kept; a returning client starts with a full one
+const idleAfter = 10 * time.Minute
+
+type Limiter struct {
+    rate  rate.Limit
+    burst int
+    now   func() time.Time
+
+    mu      sync.Mutex
+    buckets map[string]*bucket
+    swept   time.Time
+}
+
+type bucket struct {
+    lim  *rate.Limiter
+    seen time.Time
+}
+
+// New allows each client perSecond requests on average and bursts of burst
+func New(perSecond float64, burst int) *Limiter {
+    return &Limiter{rate: rate.Limit(perSecond), burst: burst, now: time.Now, buckets: map[string]*bucket{}}
+}
+
+// Allow takes a token from key's bucket. When it is empty Allow returns false and how long
+// until the next token, for Retry-After.
+func (l *Limiter) Allow(key string) (bool, time.Duration) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    now := l.now()
+    if now.Sub(l.swept) > idleAfter {
+        for k, b := range l.buckets {
+            if now.Sub(b.seen) > idleAfter {
+                delete(l.buckets, k)
+            }
+        }
+        l.swept = now
+    }
+    b, ok := l.buckets[key]
+    if !ok {
+        b = &bucket{lim: rate.NewLimiter(l.rate, l.burst)}
+        l.buckets[key] = b
+    }
+    b.seen = now
+    r := b.lim.ReserveN(now, 1)
+    if !r.OK() {
+        return false, time.Second
+    }
+    if d := r.DelayFrom(now); d > 0 {
+        r.CancelAt(now)
+        return false, d
+    }
+    return true, 0
+}
+
+// Len is the number of clients currently tracked
+func (l *Limiter) Len() int {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    return len(l.buckets)
+}
@@ -0,0 +1,36 @@
+package ratelimit
+
+import (
+    "testing"
+    "time"
+)
+
+func TestLimiterPerClient(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    l := New(1, 2)
+    l.now = func() time.Time { return now }
+
+    for i := 0; i < 2; i++ {
+        if ok, _ := l.Allow("uid:a"); !ok {
+            t.Fatalf("request %d within the burst was refused", i)
+        }
+    }
+    ok, wait := l.Allow("uid:a")
+    if ok || wait <= 0 || wait > time.Second {
+        t.Fatalf("third request: ok=%v wait=%v", ok, wait)
+    }
+    if ok, _ := l.Allow("uid:b"); !ok {
+        t.Fatal("another client must have its own bucket")
+    }
+
+    now = now.Add(time.Second)
+    if ok, _ := l.Allow("uid:a"); !ok {
+        t.Fatal("a token must be back after a second")
+    }
+
+    now = now.Add(idleAfter + time.Second)
+    l.Allow("uid:c")
+    if l.Len() != 1 {
+        t.Errorf("idle buckets must be dropped, have %d", l.Len())
+    }
+}
@@ -42,6 +42,10 @@ async function main() {
       await devs.upsertToken(call.request.user_id, call.request.token, call.request.platform);
       cb(null, {});
     },
+    async ListDeviceTokens(call: any, cb: any) {
+      const tokens = await devs.listTokensForUser(call.request.user_id);
+      cb(null, { tokens });
+    },
   };
 
   server.addService(svc.AccountService.service, impl);
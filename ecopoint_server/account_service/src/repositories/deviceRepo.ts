@@ -10,6 +10,14 @@ export class DeviceRepo {
     await this.pool.execute(sql, [user_id, token, platform]);
   }
 
+  async listTokensForUser(user_id: string) {
+    const [rows] = await this.pool.query(
+      `SELECT fcm_token, platform FROM devices WHERE user_id = ? ORDER BY last_seen_at DESC`,
+      [user_id]
+    );
+    return (rows as any[]).map(r => ({ token: r.fcm_token as string, platform: r.platform as string }));
+  }
+
   async listTokensForRole(role: 'collector'|'customer') {
     const [rows] = await this.pool.query(`
       SELECT d.fcm_token
@@ -1,27 +1,87 @@
+// Command bootstrap prepares the database:
+//
+//	bootstrap [up] [-to N]   apply pending migrations (up to version N), then ensure indexes
+//	bootstrap dry-run        show what up would change, without writing
+//	bootstrap status         list migrations and when they were applied
+//
+// Config flags such as -config and -mongo-uri follow the command.
 package main
 
 import (
     "context"
+    "flag"
     "fmt"
     "log"
+    "os"
+    "os/signal"
+    "strings"
+    "syscall"
+    "text/tabwriter"
+    "time"
 
     "ecopoint/collecting_service/internal/config"
+    "ecopoint/collecting_service/internal/migrations"
     "ecopoint/collecting_service/internal/repository"
 )
 
 func main() {
-    cfg := config.Load()
-    ctx := context.Background()
+    cmd, args := "up", os.Args[1:]
+    if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+        cmd, args = args[0], args[1:]
+    }
+    fs := flag.NewFlagSet("bootstrap "+cmd, flag.ExitOnError)
+    flags := config.Bind(fs)
+    to := fs.Int("to", 0, "apply migrations up to and including this version (0 = all)")
+    _ = fs.Parse(args)
+    cfg, err := flags.Load()
+    if err != nil { log.Fatal(err) }
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
 
     repo, err := repository.NewMongoRepo(ctx, cfg.MongoURI, cfg.MongoDBName)
     if err != nil { log.Fatalf("mongo connect error: %v", err) }
-    defer repo.Close(ctx)
+    defer repo.Close(context.Background())
+    runner, err := migrations.NewRunner(repo.Database(), migrations.All)
+    if err != nil { log.Fatal(err) }
 
-    if err := repo.InitIndexes(ctx); err != nil {
-        log.Fatalf("init indexes error: %v", err)
+    switch cmd {
+    case "up":
+        if err := runner.Init(ctx); err != nil { log.Fatalf("init %s: %v", migrations.Collection, err) }
+        results, err := runner.Up(ctx, *to, false)
+        for _, r := range results {
+            fmt.Printf("applied %d %s: %d documents in %s\n", r.Version, r.Name, r.Affected, r.Duration.Round(time.Millisecond))
+        }
+        if err != nil { log.Fatal(err) }
+        if len(results) == 0 {
+            fmt.Println("Schema is up to date.")
+        }
+        // indexes come last: a new one may need the data in its new shape
+        repo.SetOrderTTL(cfg.OrderTTL)
+        if err := repo.InitIndexes(ctx); err != nil { log.Fatalf("init indexes error: %v", err) }
+        fmt.Println("Indexes ensured.")
+    case "dry-run":
+        results, err := runner.Up(ctx, *to, true)
+        for _, r := range results {
+            fmt.Printf("would apply %d %s: %d documents\n", r.Version, r.Name, r.Affected)
+        }
+        if err != nil { log.Fatal(err) }
+        if len(results) == 0 {
+            fmt.Println("Schema is up to date.")
+        }
+    case "status":
+        states, err := runner.Status(ctx)
+        w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+        fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED\tDOCUMENTS")
+        for _, s := range states {
+            applied, docs := "pending", "-"
+            if s.Applied != nil {
+                applied, docs = s.Applied.AppliedAt.Local().Format(time.RFC3339), fmt.Sprint(s.Applied.Affected)
+            }
+            fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, applied, docs)
+        }
+        _ = w.Flush()
+        if err != nil { log.Fatal(err) }
+    default:
+        log.Fatalf("unknown command %q; want up, dry-run or status", cmd)
     }
-
-    fmt.Println("Mongo connected and indexes ensured.")
 }
-
-
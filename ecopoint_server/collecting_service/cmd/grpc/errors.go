@@ -0,0 +1,114 @@
+package main
+
+import (
+	"errors"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ecopoint/collecting_service/internal/models"
+	"ecopoint/collecting_service/internal/service"
+	"ecopoint/collecting_service/internal/validation"
+)
+
+// toStatus maps service errors to gRPC status errors; unknown errors pass through unchanged
+func toStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	var verr *validation.Error
+	if errors.As(err, &verr) {
+		return invalidArgument(verr.Error(), verr.Violations...)
+	}
+	var cerr *service.CooldownError
+	if errors.As(err, &cerr) {
+		return cooldown(cerr)
+	}
+	var qerr *service.QuotaError
+	if errors.As(err, &qerr) {
+		return quotaExceeded(qerr)
+	}
+	switch {
+	case errors.Is(err, models.ErrInvalidStatusTransition), errors.Is(err, service.ErrOrderNotEditable),
+		errors.Is(err, service.ErrChatNotOpen), errors.Is(err, service.ErrChatClosed),
+		errors.Is(err, service.ErrAttachmentClosed), errors.Is(err, service.ErrUploadIncomplete),
+		errors.Is(err, service.ErrUploadURLUnsupported), errors.Is(err, service.ErrAttachmentsDisabled):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, models.ErrVersionConflict):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrIdempotencyKeyReused):
+		return invalidArgument(err.Error(), validation.Violation{Field: "idempotency_key", Description: err.Error()})
+	case errors.Is(err, service.ErrIdempotencyInProgress):
+		return status.Error(codes.Aborted, err.Error())
+	case errors.Is(err, service.ErrAlreadyRated):
+		return status.Error(codes.AlreadyExists, err.Error())
+	case errors.Is(err, service.ErrNotRateable):
+		return status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, service.ErrNotParticipant):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, service.ErrInvalidRange):
+		return invalidArgument(err.Error(), validation.Violation{Field: "from_unix", Description: "must be before to_unix and span at most 366 days"})
+	case errors.Is(err, service.ErrInvalidGroupBy):
+		return invalidArgument(err.Error(), validation.Violation{Field: "group_by", Description: err.Error()})
+	case errors.Is(err, service.ErrAccountUnavailable):
+		return status.Error(codes.Unavailable, err.Error())
+	case errors.Is(err, service.ErrCustomerNotFound):
+		return invalidArgument(err.Error(), validation.Violation{Field: "customer_id", Description: "is not a known user"})
+	case errors.Is(err, service.ErrAddressNotFound):
+		return invalidArgument(err.Error(), validation.Violation{Field: "address_id", Description: err.Error()})
+	case errors.Is(err, service.ErrOrderNotFound), errors.Is(err, service.ErrAttachmentNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, service.ErrInvalidPageToken):
+		return invalidArgument(err.Error(), validation.Violation{Field: "page_token", Description: "is malformed; pass next_page_token back unchanged"})
+	}
+	return err
+}
+
+// invalidArgument builds an InvalidArgument status carrying BadRequest field violations
+func invalidArgument(msg string, violations ...validation.Violation) error {
+	st := status.New(codes.InvalidArgument, msg)
+	if len(violations) == 0 {
+		return st.Err()
+	}
+	br := &errdetails.BadRequest{}
+	for _, v := range violations {
+		br.FieldViolations = append(br.FieldViolations, &errdetails.BadRequest_FieldViolation{
+			Field:       v.Field,
+			Description: v.Description,
+		})
+	}
+	if withDetails, err := st.WithDetails(br); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
+
+// cooldown builds a FailedPrecondition status with a COLLECTOR_COOLDOWN precondition violation
+func cooldown(e *service.CooldownError) error {
+	st := status.New(codes.FailedPrecondition, e.Error())
+	pf := &errdetails.PreconditionFailure{Violations: []*errdetails.PreconditionFailure_Violation{{
+		Type:        "COLLECTOR_COOLDOWN",
+		Subject:     e.CollectorID,
+		Description: "accepting orders is blocked until " + e.Until.UTC().Format(time.RFC3339) + ": " + e.Reason,
+	}}}
+	if withDetails, err := st.WithDetails(pf); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
+
+// quotaExceeded builds a ResourceExhausted status with a QuotaFailure naming the limit. It has
+// no RetryInfo: the slot frees up when an order leaves the pool, not after a known delay.
+func quotaExceeded(e *service.QuotaError) error {
+	st := status.New(codes.ResourceExhausted, e.Error())
+	qf := &errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+		Subject:     "user:" + e.Subject,
+		Description: e.Error(),
+	}}}
+	if withDetails, err := st.WithDetails(qf); err == nil {
+		return withDetails.Err()
+	}
+	return st.Err()
+}
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"ecopoint/collecting_service/internal/caller"
+	pb "ecopoint/collecting_service/pb"
+)
+
+// logUnary logs every finished call with its caller, order, status code and latency
+func logUnary(l *slog.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		orderID := orderIDOf(req)
+		if orderID == "" && err == nil {
+			orderID = orderIDOf(resp)
+		}
+		logCall(ctx, l, info.FullMethod, orderID, start, err)
+		return resp, err
+	}
+}
+
+// logStream logs a stream once it ends; the order comes from the first client message
+func logStream(l *slog.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ls := &loggedStream{ServerStream: ss}
+		err := handler(srv, ls)
+		logCall(ss.Context(), l, info.FullMethod, ls.orderID, start, err)
+		return err
+	}
+}
+
+type loggedStream struct {
+	grpc.ServerStream
+	once    sync.Once
+	orderID string
+}
+
+func (s *loggedStream) RecvMsg(m any) error {
+	err := s.ServerStream.RecvMsg(m)
+	if err == nil {
+		s.once.Do(func() { s.orderID = orderIDOf(m) })
+	}
+	return err
+}
+
+func logCall(ctx context.Context, l *slog.Logger, method, orderID string, start time.Time, err error) {
+	code := status.Code(err)
+	level := callLevel(method, code)
+	if !l.Enabled(ctx, level) {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("method", method),
+		slog.String("code", code.String()),
+		slog.Duration("latency", time.Since(start)),
+	}
+	if c := caller.FromContext(ctx); !c.Anonymous() {
+		attrs = append(attrs, slog.String("user_id", c.UserID), slog.String("role", string(c.Role)))
+	}
+	if orderID != "" {
+		attrs = append(attrs, slog.String("order_id", orderID))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+	}
+	l.LogAttrs(ctx, level, "rpc", attrs...)
+}
+
+// callLevel keeps client mistakes at warn and reserves error for failures on our side;
+// health checks and reflection only show at debug level
+func callLevel(method string, code codes.Code) slog.Level {
+	switch code {
+	case codes.OK, codes.Canceled:
+		if strings.HasPrefix(method, "/grpc.health.v1.") || strings.HasPrefix(method, "/grpc.reflection.") {
+			return slog.LevelDebug
+		}
+		return slog.LevelInfo
+	case codes.Unknown, codes.Internal, codes.DataLoss, codes.Unimplemented, codes.Unavailable, codes.DeadlineExceeded:
+		return slog.LevelError
+	}
+	return slog.LevelWarn
+}
+
+func orderIDOf(msg any) string {
+	switch m := msg.(type) {
+	case interface{ GetOrderId() string }:
+		return m.GetOrderId()
+	case *pb.Order:
+		return m.GetId()
+	case *pb.ChatClientFrame:
+		return m.GetJoin().GetOrderId()
+	case *pb.UploadAttachmentRequest:
+		return m.GetInfo().GetOrderId()
+	}
+	return ""
+}
@@ -2,106 +2,866 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
 	"log"
+	"log/slog"
 	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"syscall"
+	"time"
+	_ "time/tzdata"
 	"github.com/google/uuid"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc/filters"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
 	"google.golang.org/grpc/reflection"
 
 	pb "ecopoint/collecting_service/pb"
+	"ecopoint/collecting_service/pb/accountpb"
+	"ecopoint/collecting_service/internal/account"
+	"ecopoint/collecting_service/internal/blob"
+	"ecopoint/collecting_service/internal/caller"
 	"ecopoint/collecting_service/internal/config"
+	"ecopoint/collecting_service/internal/gateway"
+	"ecopoint/collecting_service/internal/health"
+	"ecopoint/collecting_service/internal/logging"
+	"ecopoint/collecting_service/internal/metrics"
+	"ecopoint/collecting_service/internal/migrations"
 	"ecopoint/collecting_service/internal/models"
+	"ecopoint/collecting_service/internal/notify"
+	"ecopoint/collecting_service/internal/privacy"
+	"ecopoint/collecting_service/internal/ratelimit"
 	"ecopoint/collecting_service/internal/repository"
 	"ecopoint/collecting_service/internal/service"
+	"ecopoint/collecting_service/internal/tracing"
+	"ecopoint/collecting_service/internal/validation"
 )
 
 type server struct {
 	pb.UnimplementedCollectingServiceServer
-	svc *service.Service
+	svc    *service.Service
+	loc    *time.Location // default zone for earnings buckets
+	prefs  notify.PreferenceStore
+	redact *privacy.Redactor
+	shutdown <-chan struct{} // closed when the server starts draining
 }
 
+// orderView maps o as the calling user may see it
+func (s *server) orderView(ctx context.Context, o *models.Order) *pb.Order {
+	return orderModelToPb(s.redact.Order(o, caller.FromContext(ctx)))
+}
+
+// signedIn is the caller of ctx, failing with Unauthenticated for an anonymous call
+func signedIn(ctx context.Context) (caller.Caller, error) {
+	c := caller.FromContext(ctx)
+	if c.Anonymous() {
+		return c, status.Error(codes.Unauthenticated, "this call needs a signed-in user")
+	}
+	return c, nil
+}
+
+// self is the signed-in caller's id. Requests still carry the user in fields like rater_id;
+// such a field may be left empty, but naming anyone else is PermissionDenied.
+func self(ctx context.Context, field, named string) (string, error) {
+	c, err := signedIn(ctx)
+	if err != nil {
+		return "", err
+	}
+	if named != "" && named != c.UserID {
+		return "", status.Errorf(codes.PermissionDenied, "%s must be the calling user", field)
+	}
+	return c.UserID, nil
+}
+
+// selfOrAdmin is like self, but lets an admin name any user
+func selfOrAdmin(ctx context.Context, field, named string) (string, error) {
+	c, err := signedIn(ctx)
+	if err != nil {
+		return "", err
+	}
+	if named == "" {
+		return c.UserID, nil
+	}
+	if named != c.UserID && !c.IsAdmin() {
+		return "", status.Errorf(codes.PermissionDenied, "%s must be the calling user", field)
+	}
+	return named, nil
+}
+// adminOnly fails with PermissionDenied unless the caller is an admin
+func adminOnly(ctx context.Context) (caller.Caller, error) {
+	c, err := signedIn(ctx)
+	if err == nil && !c.IsAdmin() {
+		err = status.Error(codes.PermissionDenied, "this call is for admins")
+	}
+	return c, err
+}
 
-func (s *server) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
 
-	o, err := s.svc.CreateOrder(service.CreateOrderInput{
+func (s *server) CreateOrder(ctx context.Context, req *pb.CreateOrderRequest) (*pb.Order, error) {
+	// the open-orders quota is counted per customer, so it must be the caller's
+	customerID, err := self(ctx, "customer_id", req.CustomerId)
+	if err != nil {
+		return nil, err
+	}
+	svc, end := s.svc.Op(ctx, "CreateOrder")
+	o, err := svc.CreateOrder(service.CreateOrderInput{
 		ID:               uuid.NewString(),
-		CustomerID:       req.CustomerId,
+		CustomerID:       customerID,
 		Address:          addressPbToModel(req.PickAddress),
+		AddressID:        req.AddressId,
 		CustomerSnapshot: customerPbToModel(req.CustomerSnapshot),
 		Items:            itemsPbToModel(req.Items),
 		TotalWeight:      req.TotalWeight,
 		EstimatedPrice:   req.EstimatedPrice,
 		Note:             req.Note,
+		IdempotencyKey:   req.IdempotencyKey,
 	})
+	end(err)
 	if err != nil {
-		return nil, err
+		return nil, toStatus(err)
 	}
-	return orderModelToPb(o), nil
+	return s.orderView(ctx, o), nil
 }
 
 func (s *server) ListAvailableOrders(ctx context.Context, req *pb.ListAvailableOrdersRequest) (*pb.ListAvailableOrdersResponse, error) {
-    limit := int(req.Limit)
-    if limit <= 0 { limit = 20 }
-    list, err := s.svc.ListAvailable(limit)
-    if err != nil { return nil, err }
-    res := &pb.ListAvailableOrdersResponse{}
-    for _, o := range list { res.Orders = append(res.Orders, orderModelToPb(o)) }
+    size := int(req.PageSize)
+    if size <= 0 { size = int(req.Limit) }
+    svc, end := s.svc.Op(ctx, "ListAvailable")
+    list, next, err := svc.ListAvailable(req.PageToken, size)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.ListAvailableOrdersResponse{NextPageToken: next}
+    for _, o := range list { res.Orders = append(res.Orders, s.orderView(ctx, o)) }
     return res, nil
 }
 
 func (s *server) AcceptOrder(ctx context.Context, req *pb.AcceptOrderRequest) (*pb.Order, error) {
-    o, err := s.svc.AcceptOrder(req.OrderId, req.CollectorId)
+    // a collector accepts as itself, so nobody can take a slot (and the no-show strikes) in its name
+    collectorID, err := self(ctx, "collector_id", req.CollectorId)
     if err != nil { return nil, err }
-    return orderModelToPb(o), nil
+    svc, end := s.svc.Op(ctx, "AcceptOrder")
+    o, err := svc.Idempotent(collectorID, req.IdempotencyKey, service.OpAcceptOrder, req.OrderId, func() (*models.Order, error) {
+        return svc.AcceptOrder(req.OrderId, collectorID)
+    })
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
 }
 
 func (s *server) UpdateOrderStatus(ctx context.Context, req *pb.UpdateOrderStatusRequest) (*pb.Order, error) {
-    o, err := s.svc.UpdateStatus(req.OrderId, statusFromString(req.Status), req.CollectorId)
+    next, err := nextStatusFromRequest(req)
+    if err != nil { return nil, err }
+    collectorID, err := self(ctx, "collector_id", req.CollectorId)
     if err != nil { return nil, err }
-    return orderModelToPb(o), nil
+    svc, end := s.svc.Op(ctx, "UpdateStatus")
+    o, err := svc.Idempotent(collectorID, req.IdempotencyKey, service.OpUpdateStatus, req.OrderId, func() (*models.Order, error) {
+        return svc.UpdateStatus(req.OrderId, next, collectorID)
+    })
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
 }
 
 func (s *server) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
-    o, err := s.svc.GetOrder(req.OrderId)
-    if err != nil { return nil, err }
-    return orderModelToPb(o), nil
+    svc, end := s.svc.Op(ctx, "GetOrder")
+    o, err := svc.GetOrder(req.OrderId)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
 }
 
 func (s *server) ListMyActiveOrders(ctx context.Context, req *pb.ListMyActiveOrdersRequest) (*pb.ListOrdersResponse, error) {
-    list, err := s.svc.ListMyActiveOrders(req.CollectorId)
+    collectorID, err := selfOrAdmin(ctx, "collector_id", req.CollectorId)
     if err != nil { return nil, err }
-    res := &pb.ListOrdersResponse{}
-    for _, o := range list { res.Orders = append(res.Orders, orderModelToPb(o)) }
+    svc, end := s.svc.Op(ctx, "ListMyActiveOrders")
+    list, next, err := svc.ListMyActiveOrders(collectorID, req.PageToken, int(req.PageSize))
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.ListOrdersResponse{NextPageToken: next}
+    for _, o := range list { res.Orders = append(res.Orders, s.orderView(ctx, o)) }
     return res, nil
 }
 
 func (s *server) ListMyOrders(ctx context.Context, req *pb.ListMyOrdersRequest) (*pb.ListOrdersResponse, error) {
-    list, err := s.svc.ListMyOrders(req.CustomerId, int(req.Page), int(req.Size))
+    if req.Page > 1 {
+        return nil, invalidArgument("page numbers are no longer supported", validation.Violation{Field: "page", Description: "use page_token from the previous response"})
+    }
+    customerID, err := selfOrAdmin(ctx, "customer_id", req.CustomerId)
+    if err != nil { return nil, err }
+    size := int(req.PageSize)
+    if size <= 0 { size = int(req.Size) }
+    svc, end := s.svc.Op(ctx, "ListMyOrders")
+    list, next, err := svc.ListMyOrders(customerID, req.PageToken, size)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.ListOrdersResponse{NextPageToken: next}
+    for _, o := range list { res.Orders = append(res.Orders, s.orderView(ctx, o)) }
+    return res, nil
+}
+
+func (s *server) CancelOrder(ctx context.Context, req *pb.CancelOrderRequest) (*pb.Order, error) {
+    c, err := signedIn(ctx)
+    if err != nil { return nil, err }
+    // either side cancels as the caller, so nobody can cancel (and charge) another user's order
+    var cancel func(svc *service.Service) (*models.Order, error)
+    switch req.Side {
+    case pb.CancelSide_CANCEL_SIDE_CUSTOMER:
+        cancel = func(svc *service.Service) (*models.Order, error) { return svc.CancelOrderByCustomer(req.OrderId, c.UserID, req.Reason) }
+    case pb.CancelSide_CANCEL_SIDE_COLLECTOR:
+        collectorID, err := self(ctx, "collector_id", req.CollectorId)
+        if err != nil { return nil, err }
+        cancel = func(svc *service.Service) (*models.Order, error) { return svc.CancelOrderByCollector(req.OrderId, collectorID, req.Reason) }
+    default:
+        return nil, invalidArgument("side must be CUSTOMER or COLLECTOR", validation.Violation{Field: "side", Description: "must be CUSTOMER or COLLECTOR"})
+    }
+    svc, end := s.svc.Op(ctx, "CancelOrder")
+    // keys are scoped to the caller, and a replay must repeat the whole request
+    fingerprint := service.RequestFingerprint(req.Side.String(), req.CollectorId, req.Reason)
+    o, err := svc.IdempotentRequest(c.UserID, req.IdempotencyKey, service.OpCancelOrder, req.OrderId, fingerprint, func() (*models.Order, error) {
+        return cancel(svc)
+    })
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
+}
+
+func (s *server) UpdateOrderDetails(ctx context.Context, req *pb.UpdateOrderDetailsRequest) (*pb.Order, error) {
+    customerID, err := self(ctx, "customer_id", req.CustomerId)
+    if err != nil { return nil, err }
+    in := service.UpdateOrderDetailsInput{
+        OrderID:        req.OrderId,
+        CustomerID:     customerID,
+        Version:        req.Version,
+        Items:          itemsPbToModel(req.Items),
+        EstimatedPrice: req.EstimatedPrice,
+        Note:           req.Note,
+    }
+    if req.PickAddress != nil {
+        addr := addressPbToModel(req.PickAddress)
+        in.Address = &addr
+    }
+    svc, end := s.svc.Op(ctx, "UpdateOrderDetails")
+    o, err := svc.UpdateOrderDetails(in)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
+}
+
+func (s *server) ListCollectorOrders(ctx context.Context, req *pb.ListCollectorOrdersRequest) (*pb.ListOrdersResponse, error) {
+    collectorID, err := selfOrAdmin(ctx, "collector_id", req.CollectorId)
+    if err != nil { return nil, err }
+    f := service.OrderFilter{From: unixOrZero(req.FromUnix), To: unixOrZero(req.ToUnix)}
+    for i, st := range req.Statuses {
+        m, ok := statusPbToModel(st)
+        if !ok {
+            return nil, invalidArgument("unknown status filter", validation.Violation{Field: fmt.Sprintf("statuses[%d]", i), Description: "unknown value " + st.String()})
+        }
+        f.Statuses = append(f.Statuses, m)
+    }
+    svc, end := s.svc.Op(ctx, "ListCollectorOrders")
+    list, next, err := svc.ListCollectorOrders(collectorID, f, req.PageToken, int(req.PageSize))
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.ListOrdersResponse{NextPageToken: next}
+    for _, o := range list { res.Orders = append(res.Orders, s.orderView(ctx, o)) }
+    return res, nil
+}
+
+var earningsGroupFromPb = map[pb.EarningsGroupBy]service.EarningsGroup{
+    pb.EarningsGroupBy_EARNINGS_GROUP_BY_UNSPECIFIED: service.GroupByDay,
+    pb.EarningsGroupBy_EARNINGS_GROUP_BY_DAY:         service.GroupByDay,
+    pb.EarningsGroupBy_EARNINGS_GROUP_BY_WEEK:        service.GroupByWeek,
+    pb.EarningsGroupBy_EARNINGS_GROUP_BY_MONTH:       service.GroupByMonth,
+}
+
+func (s *server) GetCollectorEarnings(ctx context.Context, req *pb.GetCollectorEarningsRequest) (*pb.CollectorEarnings, error) {
+    collectorID, err := selfOrAdmin(ctx, "collector_id", req.CollectorId)
+    if err != nil { return nil, err }
+    q := service.EarningsQuery{From: unixOrZero(req.FromUnix), To: unixOrZero(req.ToUnix), Location: s.loc}
+    g, ok := earningsGroupFromPb[req.GroupBy]
+    if !ok {
+        return nil, invalidArgument("unknown group_by", validation.Violation{Field: "group_by", Description: "unknown value " + req.GroupBy.String()})
+    }
+    q.GroupBy = g
+    if req.TimeZone != "" {
+        loc, err := time.LoadLocation(req.TimeZone)
+        if err != nil {
+            return nil, invalidArgument("unknown time_zone", validation.Violation{Field: "time_zone", Description: err.Error()})
+        }
+        q.Location = loc
+    }
+    svc, end := s.svc.Op(ctx, "GetCollectorEarnings")
+    sum, err := svc.GetCollectorEarnings(collectorID, q)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.CollectorEarnings{Total: earningsBucketToPb(sum.Total)}
+    for _, b := range sum.Buckets { res.Buckets = append(res.Buckets, earningsBucketToPb(b)) }
+    return res, nil
+}
+
+var roleFromPb = map[pb.UserRole]models.Role{
+    pb.UserRole_USER_ROLE_CUSTOMER:  models.RoleCustomer,
+    pb.UserRole_USER_ROLE_COLLECTOR: models.RoleCollector,
+}
+
+func roleToPb(r models.Role) pb.UserRole {
+    for p, m := range roleFromPb {
+        if m == r { return p }
+    }
+    return pb.UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (s *server) RateOrder(ctx context.Context, req *pb.RateOrderRequest) (*pb.Rating, error) {
+    side, ok := roleFromPb[req.Side]
+    if !ok {
+        return nil, invalidArgument("side must be CUSTOMER or COLLECTOR", validation.Violation{Field: "side", Description: "must be CUSTOMER or COLLECTOR"})
+    }
+    rater, err := self(ctx, "rater_id", req.RaterId)
+    if err != nil { return nil, err }
+    svc, end := s.svc.Op(ctx, "RateOrder")
+    r, err := svc.RateOrder(service.RateOrderInput{
+        OrderID: req.OrderId,
+        RaterID: rater,
+        Side:    side,
+        Stars:   int(req.Stars),
+        Tags:    req.Tags,
+        Comment: req.Comment,
+    })
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return &pb.Rating{
+        OrderId:       r.OrderID,
+        Side:          roleToPb(r.Side),
+        RaterId:       r.RaterID,
+        RateeId:       r.RateeID,
+        Stars:         int32(r.Stars),
+        Tags:          r.Tags,
+        Comment:       r.Comment,
+        CreatedAtUnix: r.CreatedAt.Unix(),
+    }, nil
+}
+
+func (s *server) GetUserRating(ctx context.Context, req *pb.GetUserRatingRequest) (*pb.UserRating, error) {
+    role, ok := roleFromPb[req.Role]
+    if !ok {
+        return nil, invalidArgument("role must be CUSTOMER or COLLECTOR", validation.Violation{Field: "role", Description: "must be CUSTOMER or COLLECTOR"})
+    }
+    svc, end := s.svc.Op(ctx, "GetUserRating")
+    u, err := svc.GetUserRating(req.UserId, role)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return userRatingToPb(u), nil
+}
+
+func (s *server) SearchUserRatings(ctx context.Context, req *pb.SearchUserRatingsRequest) (*pb.SearchUserRatingsResponse, error) {
+    if _, err := adminOnly(ctx); err != nil { return nil, err }
+    role, ok := roleFromPb[req.Role]
+    if !ok {
+        return nil, invalidArgument("role must be CUSTOMER or COLLECTOR", validation.Violation{Field: "role", Description: "must be CUSTOMER or COLLECTOR"})
+    }
+    svc, end := s.svc.Op(ctx, "SearchUserRatings")
+    list, next, err := svc.SearchUserRatings(role, service.RatingFilter{MaxAverage: req.MaxAverage, MinCount: req.MinCount}, req.PageToken, int(req.PageSize))
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.SearchUserRatingsResponse{NextPageToken: next}
+    for _, u := range list { res.Ratings = append(res.Ratings, userRatingToPb(u)) }
+    return res, nil
+}
+
+func (s *server) GetCollectorPenalty(ctx context.Context, req *pb.GetCollectorPenaltyRequest) (*pb.CollectorPenalty, error) {
+    collectorID, err := selfOrAdmin(ctx, "collector_id", req.CollectorId)
+    if err != nil { return nil, err }
+    svc, end := s.svc.Op(ctx, "GetCollectorPenalty")
+    st, err := svc.GetCollectorPenalty(collectorID)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return penaltyToPb(collectorID, st), nil
+}
+
+func (s *server) ClearCollectorPenalty(ctx context.Context, req *pb.ClearCollectorPenaltyRequest) (*pb.CollectorPenalty, error) {
+    admin, err := adminOnly(ctx)
+    if err != nil { return nil, err }
+    if req.CollectorId == "" {
+        return nil, invalidArgument("collector_id is required", validation.Violation{Field: "collector_id", Description: "is required"})
+    }
+    if req.AdminId != "" && req.AdminId != admin.UserID {
+        return nil, status.Error(codes.PermissionDenied, "admin_id must be the calling user")
+    }
+    svc, end := s.svc.Op(ctx, "ClearCollectorPenalty")
+    st, err := svc.ClearCollectorPenalty(req.CollectorId, admin.UserID)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return penaltyToPb(req.CollectorId, st), nil
+}
+
+func (s *server) GetNotificationPreferences(ctx context.Context, req *pb.GetNotificationPreferencesRequest) (*pb.NotificationPreferences, error) {
+    userID, err := selfOrAdmin(ctx, "user_id", req.UserId)
+    if err != nil { return nil, err }
+    p, err := s.prefs.GetPreferences(userID)
+    if err != nil { return nil, toStatus(err) }
+    return preferencesToPb(p), nil
+}
+
+func (s *server) SetNotificationPreferences(ctx context.Context, req *pb.NotificationPreferences) (*pb.NotificationPreferences, error) {
+    userID, err := selfOrAdmin(ctx, "user_id", req.UserId)
+    if err != nil { return nil, err }
+    var violations []validation.Violation
+    if req.Locale != "" && req.Locale != "vi" && req.Locale != "en" {
+        violations = append(violations, validation.Violation{Field: "locale", Description: "must be vi or en"})
+    }
+    p := &notify.Preferences{UserID: userID, Locale: req.Locale, Disabled: req.Disabled, UpdatedAt: time.Now()}
+    for i, e := range req.MutedEvents {
+        t, ok := models.ParseEventType(e)
+        if !ok {
+            violations = append(violations, validation.Violation{Field: fmt.Sprintf("muted_events[%d]", i), Description: "unknown event " + e})
+            continue
+        }
+        p.Muted = append(p.Muted, t)
+    }
+    if len(violations) > 0 {
+        return nil, invalidArgument("invalid notification preferences", violations...)
+    }
+    if err := s.prefs.SavePreferences(p); err != nil { return nil, toStatus(err) }
+    return preferencesToPb(p), nil
+}
+
+func (s *server) Chat(stream pb.CollectingService_ChatServer) error {
+    first, err := stream.Recv()
+    if err != nil { return err }
+    join := first.GetJoin()
+    if join == nil {
+        return invalidArgument("first frame must be join", validation.Violation{Field: "join", Description: "is required as the first frame"})
+    }
+    userID, err := self(stream.Context(), "user_id", join.UserId)
+    if err != nil { return err }
+    sub, err := s.svc.SubscribeChat(join.OrderId, userID)
+    if err != nil { return toStatus(err) }
+    defer sub.Close()
+
+    // the reader posts into the service; only this goroutine writes to the stream
+    recvErr := make(chan error, 1)
+    go func() {
+        for {
+            f, err := stream.Recv()
+            if err == io.EOF { recvErr <- nil; return }
+            if err != nil { recvErr <- err; return }
+            switch x := f.Frame.(type) {
+            case *pb.ChatClientFrame_Send:
+                _, err = s.svc.SendChatMessage(join.OrderId, userID, x.Send.Text)
+            case *pb.ChatClientFrame_Read:
+                _, err = s.svc.MarkChatRead(join.OrderId, userID, x.Read.LastMessageId)
+            default:
+                err = invalidArgument("expected send or read frame", validation.Violation{Field: "frame", Description: "join may only be sent once"})
+            }
+            if err != nil { recvErr <- toStatus(err); return }
+        }
+    }()
+
+    for {
+        select {
+        case ev, ok := <-sub.Events:
+            if !ok { return nil }
+            if err := stream.Send(chatEventToPb(ev)); err != nil { return err }
+            if ev.Closed { return nil }
+        case err := <-recvErr:
+            return err
+        case <-stream.Context().Done():
+            return nil
+        case <-s.shutdown:
+            return status.Error(codes.Unavailable, "server is shutting down; reconnect")
+        }
+    }
+}
+
+func (s *server) ListChatMessages(ctx context.Context, req *pb.ListChatMessagesRequest) (*pb.ListChatMessagesResponse, error) {
+    userID, err := self(ctx, "user_id", req.UserId)
     if err != nil { return nil, err }
-    res := &pb.ListOrdersResponse{}
-    for _, o := range list { res.Orders = append(res.Orders, orderModelToPb(o)) }
+    var after time.Time
+    if req.AfterUnixMs > 0 { after = time.UnixMilli(req.AfterUnixMs) }
+    svc, end := s.svc.Op(ctx, "ListChatMessages")
+    list, err := svc.ListChatMessages(req.OrderId, userID, after, int(req.PageSize))
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    res := &pb.ListChatMessagesResponse{}
+    for _, m := range list { res.Messages = append(res.Messages, chatMessageToPb(m)) }
     return res, nil
 }
 
+func (s *server) CreateAttachmentUpload(ctx context.Context, req *pb.AttachmentInfo) (*pb.AttachmentUpload, error) {
+    userID, err := self(ctx, "user_id", req.UserId)
+    if err != nil { return nil, err }
+    svc, end := s.svc.Op(ctx, "CreateAttachmentUpload")
+    t, err := svc.CreateAttachmentUpload(attachmentInfoToInput(req, userID))
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return &pb.AttachmentUpload{
+        AttachmentId:  t.Attachment.ID,
+        UploadUrl:     t.URL,
+        Headers:       t.Headers,
+        ExpiresAtUnix: t.ExpiresAt.Unix(),
+    }, nil
+}
+
+func (s *server) CompleteAttachmentUpload(ctx context.Context, req *pb.CompleteAttachmentUploadRequest) (*pb.Order, error) {
+    userID, err := self(ctx, "user_id", req.UserId)
+    if err != nil { return nil, err }
+    svc, end := s.svc.Op(ctx, "CompleteAttachmentUpload")
+    o, err := svc.CompleteAttachmentUpload(req.AttachmentId, userID)
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return s.orderView(ctx, o), nil
+}
+
+func (s *server) UploadAttachment(stream pb.CollectingService_UploadAttachmentServer) error {
+    first, err := stream.Recv()
+    if err != nil { return err }
+    info := first.GetInfo()
+    if info == nil {
+        return invalidArgument("first frame must be info", validation.Violation{Field: "info", Description: "is required as the first frame"})
+    }
+    userID, err := self(stream.Context(), "user_id", info.UserId)
+    if err != nil { return err }
+    // chunks are piped into the blob store as they arrive
+    pr, pw := io.Pipe()
+    go func() {
+        for {
+            f, err := stream.Recv()
+            if err == io.EOF { pw.Close(); return }
+            if err != nil { pw.CloseWithError(err); return }
+            if f.GetInfo() != nil {
+                pw.CloseWithError(invalidArgument("info may only be sent once", validation.Violation{Field: "info", Description: "sent twice"}))
+                return
+            }
+            if _, err := pw.Write(f.GetChunk()); err != nil { return }
+        }
+    }()
+    svc, end := s.svc.Op(stream.Context(), "UploadAttachment")
+    o, err := svc.UploadAttachment(attachmentInfoToInput(info, userID), pr)
+    end(err)
+    pr.Close()
+    if err != nil { return toStatus(err) }
+    return stream.SendAndClose(s.orderView(stream.Context(), o))
+}
+
+func (s *server) GetAttachmentURL(ctx context.Context, req *pb.GetAttachmentURLRequest) (*pb.AttachmentURL, error) {
+    userID, err := selfOrAdmin(ctx, "user_id", req.UserId)
+    if err != nil { return nil, err }
+    svc, end := s.svc.Op(ctx, "AttachmentURL")
+    url, expires, err := svc.AttachmentURL(req.AttachmentId, userID, caller.FromContext(ctx).IsAdmin())
+    end(err)
+    if err != nil { return nil, toStatus(err) }
+    return &pb.AttachmentURL{Url: url, ExpiresAtUnix: expires.Unix()}, nil
+}
+
+// downloadChunkSize keeps each message well below the default 4 MiB gRPC limit
+const downloadChunkSize = 64 << 10
+
+func (s *server) DownloadAttachment(req *pb.DownloadAttachmentRequest, stream pb.CollectingService_DownloadAttachmentServer) error {
+    userID, err := selfOrAdmin(stream.Context(), "user_id", req.UserId)
+    if err != nil { return err }
+    svc, end := s.svc.Op(stream.Context(), "OpenAttachment")
+    rc, a, err := svc.OpenAttachment(req.AttachmentId, userID, caller.FromContext(stream.Context()).IsAdmin())
+    end(err)
+    if err != nil { return toStatus(err) }
+    defer rc.Close()
+    buf := make([]byte, downloadChunkSize)
+    first := true
+    for {
+        n, err := io.ReadFull(rc, buf)
+        if n > 0 {
+            chunk := &pb.AttachmentChunk{Data: buf[:n]}
+            if first { chunk.ContentType = a.ContentType; first = false }
+            if err := stream.Send(chunk); err != nil { return err }
+        }
+        if err == io.EOF || err == io.ErrUnexpectedEOF { return nil }
+        if err != nil { return err }
+    }
+}
+
+// newBlobStore builds the attachment store selected by BLOB_STORE; nil disables attachments
+func newBlobStore(c config.BlobConfig) (service.BlobStore, error) {
+    switch c.Driver {
+    case "s3":
+        return blob.NewS3Store(c.S3)
+    case "none":
+        return nil, nil
+    default:
+        return blob.NewFSStore(c.Dir)
+    }
+}
+
+// newRelay builds the number masking provider selected by RELAY_DRIVER; nil hands out no numbers
+func newRelay(c config.RelayConfig) (privacy.RelayProvider, error) {
+    switch c.Driver {
+    case "http":
+        return privacy.NewHTTPRelay(c.HTTP)
+    case "local":
+        slog.Warn("relay: local numbers forward no calls; use the http driver outside development")
+        return privacy.NewLocalRelay(c.Prefix), nil
+    default:
+        return nil, nil
+    }
+}
+
+// newNotifier builds the push notifier selected by NOTIFIER
+func newNotifier(ctx context.Context, c config.NotifyConfig, accounts *grpc.ClientConn) (notify.Notifier, error) {
+    switch c.Driver {
+    case "fcm":
+        if accounts == nil { return nil, errors.New("fcm needs ACCOUNT_GRPC_ADDR for device tokens") }
+        return notify.NewFCMNotifier(ctx, c.FCMCredentials, notify.AccountTokens{Client: accountpb.NewAccountServiceClient(accounts)})
+    case "none":
+        return nil, nil
+    default:
+        return notify.NewFileNotifier(c.File)
+    }
+}
+
 func main(){
-    cfg := config.Load()
-    ctx := context.Background()
-    repo, err := repository.NewMongoRepo(ctx, cfg.MongoURI, cfg.MongoDBName)
-    if err != nil { log.Fatalf("mongo: %v", err) }
-    defer repo.Close(ctx)
-    _ = repo.InitIndexes(ctx)
+    flags := config.Bind(flag.CommandLine)
+    printConfig := flag.Bool("print-config", false, "print the effective configuration with secrets redacted and exit")
+    flag.Parse()
+    cfg, err := flags.Load()
+    if err != nil { log.Fatal(err) }
+    if *printConfig {
+        if err := cfg.Print(os.Stdout); err != nil { log.Fatal(err) }
+        return
+    }
+    logger, err := logging.New(os.Stderr, cfg.Log.Level, cfg.Log.Format)
+    if err != nil { log.Fatal(err) }
+    slog.SetDefault(logger)
+    // SIGINT/SIGTERM cancel ctx: background workers stop and the server drains
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+    tp, err := tracing.New(ctx, cfg.Trace)
+    if err != nil { fatal("tracing", err) }
+    m := metrics.New()
+    repo, err := repository.NewMongoRepo(ctx, cfg.MongoURI, cfg.MongoDBName,
+        repository.WithCommandObserver(m.ObserveMongo),
+        repository.WithTracerProvider(tp),
+    )
+    if err != nil { fatal("mongo", err) }
+    repo.SetOrderTTL(cfg.OrderTTL)
+    // the server runs on an older schema, but says when bootstrap up is due; indexes wait for
+    // the migrations, as a new one may reject documents still in the old shape
+    migrator, err := migrations.NewRunner(repo.Database(), migrations.All)
+    if err != nil { fatal("migrations", err) }
+    if pending, err := migrator.Pending(ctx); err != nil {
+        slog.Warn("schema version unknown; indexes left to bootstrap up", "error", err)
+    } else if len(pending) > 0 {
+        slog.Warn("schema migrations pending; run bootstrap up", "pending", len(pending), "next", pending[0].Name)
+    } else if err := repo.InitIndexes(ctx); err != nil {
+        fatal("mongo indexes", err)
+    }
+
+    loc, err := time.LoadLocation(cfg.TimeZone)
+    if err != nil { fatal("time zone", err) }
 
-    s := &server{ svc: service.NewService(repo) }
+    var accounts *grpc.ClientConn
+    if cfg.Account.Addr != "" {
+        accounts, err = grpc.NewClient(cfg.Account.Addr,
+            grpc.WithTransportCredentials(insecure.NewCredentials()),
+            grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+        )
+        if err != nil { fatal("account client", err) }
+        defer accounts.Close()
+    }
 
-    grpcServer := grpc.NewServer()
+    notifier, err := newNotifier(ctx, cfg.Notify, accounts)
+    if err != nil { fatal("notifier", err) }
+    relay, err := newRelay(cfg.Relay)
+    if err != nil { fatal("relay", err) }
+    redact := privacy.NewRedactor(relay)
+    events := []service.EventPublisher{redact, m}
+    // the dispatcher outlives ctx so notifications queued during the drain still go out
+    notifyCtx, cancelNotify := context.WithCancel(context.Background())
+    defer cancelNotify()
+    var dispatcher *notify.Dispatcher
+    if notifier != nil {
+        dispatcher = notify.NewDispatcher(notifier,
+            notify.WithPreferences(repo),
+            notify.WithDeadLetters(repo),
+            notify.WithRetryPolicy(cfg.Notify.Retry),
+            notify.WithDefaultLocale(cfg.Notify.DefaultLocale),
+        )
+        dispatcher.Start(notifyCtx)
+        events = append(events, dispatcher)
+    }
+
+    opts := []service.Option{
+        service.WithValidator(validation.New(cfg.OrderLimits)),
+        service.WithIdempotency(repo, cfg.IdempotencyTTL),
+        service.WithRatingStore(repo),
+        service.WithStrikeStore(repo),
+        service.WithWatchdogPolicy(cfg.Watchdog),
+        service.WithOrderTTL(cfg.OrderTTL),
+        service.WithPenaltyPolicy(cfg.Penalties),
+        service.WithLedgerStore(repo),
+        service.WithCancelPolicy(cfg.Cancel),
+        service.WithQuotaPolicy(cfg.Quotas),
+        service.WithChatStore(repo),
+        service.WithEventPublisher(service.MultiPublisher(events...)),
+        service.WithAttachmentStore(repo),
+        service.WithAttachmentURLTTL(cfg.Blob.URLTTL),
+        service.WithPricing(cfg.Pricing),
+        service.WithPageSizes(cfg.Pages),
+        service.WithLogger(logger),
+        service.WithOrderObserver(m),
+        service.WithTracerProvider(tp),
+    }
+    blobs, err := newBlobStore(cfg.Blob)
+    if err != nil { fatal("blob store", err) }
+    if blobs != nil {
+        opts = append(opts, service.WithBlobStore(blobs))
+    }
+    if accounts != nil {
+        opts = append(opts, service.WithSnapshotSource(account.NewClient(accounts, cfg.Account.Options)))
+    }
+    shutdown := make(chan struct{})
+    s := &server{ svc: service.NewService(repo, opts...), loc: loc, prefs: repo, redact: redact, shutdown: shutdown }
+    m.WatchPool(s.svc.PoolByArea, cfg.PoolMetrics)
+
+    monitor := health.NewMonitor(pb.CollectingService_ServiceDesc.ServiceName)
+    monitor.AddCheck("mongo", repo.Ping)
+    if cfg.WatchdogInterval > 0 {
+        // a sweep that stops reporting for three intervals is stuck
+        beat := monitor.Heartbeat("watchdog", 3*cfg.WatchdogInterval)
+        go s.svc.RunWatchdog(ctx, cfg.WatchdogInterval, func(rep service.WatchdogReport, err error) {
+            beat()
+            if err != nil {
+                slog.Error("watchdog sweep failed", "error", err)
+            } else if rep.Released+rep.Cancelled+rep.Expired > 0 {
+                slog.Info("watchdog sweep", "released", rep.Released, "cancelled", rep.Cancelled, "expired", rep.Expired, "conflicts", rep.Conflicts)
+            }
+        })
+    }
+    go monitor.Run(ctx, cfg.Shutdown.HealthInterval)
+
+    unary := []grpc.UnaryServerInterceptor{m.UnaryServerInterceptor(), logUnary(logger)}
+    streams := []grpc.StreamServerInterceptor{m.StreamServerInterceptor(), logStream(logger)}
+    // identity metadata is believed from the same peers as x-forwarded-for
+    proxies, err := caller.ParseProxies(cfg.RateLimit.TrustedProxies)
+    if err != nil { fatal("rate_limit.trusted_proxies", err) }
+    caller.Trust(proxies)
+    if cfg.RateLimit.Enabled {
+        // last in the chain so rejected calls are still counted and logged
+        limiter, err := ratelimit.New(cfg.RateLimit, ratelimit.WithObserver(m))
+        if err != nil { fatal("rate limiter", err) }
+        unary = append(unary, limiter.UnaryServerInterceptor())
+        streams = append(streams, limiter.StreamServerInterceptor())
+    }
+    grpcServer := grpc.NewServer(
+        grpc.StatsHandler(otelgrpc.NewServerHandler(
+            otelgrpc.WithTracerProvider(tp),
+            otelgrpc.WithFilter(filters.Not(filters.HealthCheck())),
+        )),
+        grpc.ChainUnaryInterceptor(unary...),
+        grpc.ChainStreamInterceptor(streams...),
+    )
     pb.RegisterCollectingServiceServer(grpcServer, s)
+    healthpb.RegisterHealthServer(grpcServer, monitor.Server())
     reflection.Register(grpcServer)
 
-    lis, err := net.Listen("tcp", ":50052")
-    if err != nil { log.Fatalf("listen: %v", err) }
-    log.Println("Collecting gRPC listening on :50052")
-    if err := grpcServer.Serve(lis); err != nil { log.Fatal(err) }
+    lis, err := net.Listen("tcp", cfg.GRPCAddr)
+    if err != nil { fatal("listen", err) }
+    slog.Info("collecting gRPC listening", "addr", cfg.GRPCAddr)
+    serveErr := make(chan error, 3)
+    go func() { serveErr <- grpcServer.Serve(lis) }()
+    var httpServer *http.Server
+    if cfg.HTTPAddr != "" {
+        // the gateway calls this process over gRPC so every interceptor applies to REST calls too
+        local, err := grpc.NewClient("passthrough:///"+lis.Addr().String(),
+            grpc.WithTransportCredentials(insecure.NewCredentials()),
+            grpc.WithStatsHandler(otelgrpc.NewClientHandler(otelgrpc.WithTracerProvider(tp))),
+        )
+        if err != nil { fatal("gateway client", err) }
+        defer local.Close()
+        peers, err := caller.ParseProxies(cfg.HTTPTrustedPeers)
+        if err != nil { fatal("http_trusted_peers", err) }
+        gw := gateway.New(pb.NewCollectingServiceClient(local), gateway.WithCORSOrigins(cfg.CORSOrigins), gateway.WithTrustedProxies(peers))
+        httpServer = &http.Server{Addr: cfg.HTTPAddr, Handler: gw, ReadHeaderTimeout: 5 * time.Second}
+        slog.Info("REST gateway listening", "addr", cfg.HTTPAddr)
+        go func() {
+            if err := httpServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+                serveErr <- err
+            }
+        }()
+    }
+    var metricsServer *http.Server
+    if cfg.MetricsAddr != "" {
+        mux := http.NewServeMux()
+        mux.Handle("/metrics", m.Handler())
+        metricsServer = &http.Server{Addr: cfg.MetricsAddr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+        slog.Info("metrics listening", "addr", cfg.MetricsAddr)
+        go func() {
+            if err := metricsServer.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+                serveErr <- err
+            }
+        }()
+    }
+    select {
+    case err := <-serveErr:
+        fatal("serve", err)
+    case <-ctx.Done():
+    }
+
+    slog.Info("shutting down")
+    monitor.Shutdown()
+    time.Sleep(cfg.Shutdown.Delay) // give load balancers time to see NOT_SERVING
+    close(shutdown)                // ends chat streams, which would otherwise hold the drain open
+    // REST calls finish first: they hold gRPC calls open, and afterwards the gateway has nowhere to go
+    if httpServer != nil {
+        gwCtx, cancelGw := context.WithTimeout(context.Background(), cfg.Shutdown.Grace)
+        if err := httpServer.Shutdown(gwCtx); err != nil { slog.Warn("gateway close", "error", err) }
+        cancelGw()
+    }
+    if !within(cfg.Shutdown.Grace, grpcServer.GracefulStop) {
+        slog.Warn("calls still running after the grace period; closing them", "grace", cfg.Shutdown.Grace)
+        grpcServer.Stop()
+    }
+    if dispatcher != nil && !within(cfg.Shutdown.Grace, dispatcher.Close) {
+        slog.Warn("notifications still pending after the grace period; dead-lettering them", "grace", cfg.Shutdown.Grace)
+        cancelNotify()
+        dispatcher.Close()
+    }
+    closeCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+    defer cancel()
+    // metrics stay up during the drain so the last scrape sees it
+    if metricsServer != nil {
+        if err := metricsServer.Shutdown(closeCtx); err != nil { slog.Warn("metrics close", "error", err) }
+    }
+    if err := repo.Close(closeCtx); err != nil { slog.Warn("mongo close", "error", err) }
+    // flushes spans buffered by the batcher, including those of the drain
+    if err := tp.Shutdown(closeCtx); err != nil { slog.Warn("tracing close", "error", err) }
+    slog.Info("stopped")
+}
+
+func fatal(msg string, err error) {
+    slog.Error(msg, "error", err)
+    os.Exit(1)
+}
+
+// within runs fn and reports whether it returned before timeout; fn keeps running otherwise
+func within(timeout time.Duration, fn func()) bool {
+    done := make(chan struct{})
+    go func() { fn(); close(done) }()
+    select {
+    case <-done:
+        return true
+    case <-time.After(timeout):
+        return false
+    }
 }
 
 // mapping helpers
@@ -126,7 +886,77 @@ func orderModelToPb(o *models.Order) *pb.Order {
 		EtaMinutes:     int32(o.EtaMinutes),
 		Note:           o.Note,
 		Version:        o.Version,
+		OrderStatus:    statusToPb[o.Status],
+		CancelSide:     cancelSideToPb[o.CancelSide],
+		CancelReason:   o.CancelReason,
+		CreatedAtUnix:   o.CreatedAt.Unix(),
+		AcceptedAtUnix:  timeUnixOrZero(o.AcceptedAt),
+		CompletedAtUnix: timeUnixOrZero(o.CompletedAt),
+		CancelFee:       o.CancelFee,
+		Items:           itemsModelToPb(o.Items),
+		Attachments:     attachmentsToPb(o.Attachments),
+	}
+}
+
+func itemsModelToPb(items []models.WasteItem) []*pb.WasteItem {
+	res := make([]*pb.WasteItem, 0, len(items))
+	for _, it := range items {
+		res = append(res, &pb.WasteItem{Type: it.Type, Weight: it.Weight, Attachments: attachmentsToPb(it.Attachments)})
+	}
+	return res
+}
+
+func attachmentsToPb(refs []models.AttachmentRef) []*pb.Attachment {
+	if len(refs) == 0 { return nil }
+	res := make([]*pb.Attachment, 0, len(refs))
+	for _, a := range refs {
+		res = append(res, &pb.Attachment{
+			Id:             a.ID,
+			Kind:           attachmentKindToPb[a.Kind],
+			ContentType:    a.ContentType,
+			Size:           a.Size,
+			UploadedBy:     a.UploadedBy,
+			UploadedAtUnix: a.UploadedAt.Unix(),
+		})
+	}
+	return res
+}
+
+var attachmentKindToPb = map[models.AttachmentKind]pb.AttachmentKind{
+	models.AttachmentItemPhoto:       pb.AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO,
+	models.AttachmentCompletionProof: pb.AttachmentKind_ATTACHMENT_KIND_COMPLETION_PROOF,
+}
+
+// attachmentInfoToInput leaves an unknown kind empty; the service rejects it.
+// userID is the caller, not in.UserId.
+func attachmentInfoToInput(in *pb.AttachmentInfo, userID string) service.AttachmentInput {
+	res := service.AttachmentInput{
+		OrderID:     in.OrderId,
+		UserID:      userID,
+		ItemIndex:   int(in.ItemIndex),
+		ContentType: in.ContentType,
+		Size:        in.Size,
+	}
+	for m, p := range attachmentKindToPb {
+		if p == in.Kind { res.Kind = m }
+	}
+	return res
+}
+
+func earningsBucketToPb(b service.EarningsBucket) *pb.EarningsBucket {
+	res := &pb.EarningsBucket{
+		PeriodStartUnix: b.PeriodStart.Unix(),
+		Orders:          int32(b.Orders),
+		TotalWeight:     b.TotalWeight,
+		Amount:          b.Amount,
+	}
+	types := make([]string, 0, len(b.WeightByType))
+	for t := range b.WeightByType { types = append(types, t) }
+	sort.Strings(types)
+	for _, t := range types {
+		res.Weights = append(res.Weights, &pb.WasteWeight{Type: t, Weight: b.WeightByType[t]})
 	}
+	return res
 }
 
 // lightweight helpers below
@@ -149,22 +979,127 @@ func itemsPbToModel(items []*pb.WasteItem) []models.WasteItem {
 	return res
 }
 
-func statusFromString(s string) models.OrderStatus {
-	switch s {
-	case string(models.StatusCreated):
-		return models.StatusCreated
-	case string(models.StatusAccepted):
-		return models.StatusAccepted
-	case string(models.StatusOnWay):
-		return models.StatusOnWay
-	case string(models.StatusComplete):
-		return models.StatusComplete
-	case string(models.StatusCancelled):
-		return models.StatusCancelled
-	default:
-		return models.StatusCreated
+var statusToPb = map[models.OrderStatus]pb.OrderStatus{
+	models.StatusCreated:   pb.OrderStatus_ORDER_STATUS_CREATED,
+	models.StatusAccepted:  pb.OrderStatus_ORDER_STATUS_ACCEPTED,
+	models.StatusOnWay:     pb.OrderStatus_ORDER_STATUS_ON_WAY,
+	models.StatusComplete:  pb.OrderStatus_ORDER_STATUS_COMPLETE,
+	models.StatusCancelled: pb.OrderStatus_ORDER_STATUS_CANCELLED,
+}
+
+var cancelSideToPb = map[models.CancelBy]pb.CancelSide{
+	models.CancelByCustomer:  pb.CancelSide_CANCEL_SIDE_CUSTOMER,
+	models.CancelByCollector: pb.CancelSide_CANCEL_SIDE_COLLECTOR,
+	models.CancelBySystem:    pb.CancelSide_CANCEL_SIDE_SYSTEM,
+}
+
+func statusPbToModel(s pb.OrderStatus) (models.OrderStatus, bool) {
+	for m, p := range statusToPb {
+		if p == s {
+			return m, true
+		}
+	}
+	return "", false
+}
+
+// nextStatusFromRequest prefers the typed next_status and falls back to the
+// deprecated string form; anything unknown is rejected with InvalidArgument
+func nextStatusFromRequest(req *pb.UpdateOrderStatusRequest) (models.OrderStatus, error) {
+	if req.NextStatus != pb.OrderStatus_ORDER_STATUS_UNSPECIFIED {
+		st, ok := statusPbToModel(req.NextStatus)
+		if !ok {
+			return "", invalidArgument("unknown next_status", validation.Violation{Field: "next_status", Description: "unknown value " + req.NextStatus.String()})
+		}
+		if req.Status != "" && req.Status != string(st) {
+			return "", invalidArgument("status and next_status disagree", validation.Violation{Field: "status", Description: "conflicts with next_status"})
+		}
+		return st, nil
+	}
+	if req.Status == "" {
+		return "", invalidArgument("next_status is required", validation.Violation{Field: "next_status", Description: "is required"})
 	}
+	st, err := models.ParseOrderStatus(req.Status)
+	if err != nil {
+		return "", invalidArgument(err.Error(), validation.Violation{Field: "status", Description: "unknown status " + strconv.Quote(req.Status)})
+	}
+	return st, nil
 }
 
 func valueOrEmpty(p *string) string { if p == nil { return "" }; return *p }
 
+func chatMessageToPb(m *models.ChatMessage) *pb.ChatMessage {
+	res := &pb.ChatMessage{
+		Id:           m.ID,
+		OrderId:      m.OrderID,
+		SenderId:     m.SenderID,
+		SenderRole:   roleToPb(m.SenderRole),
+		Text:         m.Text,
+		SentAtUnixMs: m.SentAt.UnixMilli(),
+	}
+	if m.ReadAt != nil {
+		res.ReadAtUnixMs = m.ReadAt.UnixMilli()
+	}
+	return res
+}
+
+func chatEventToPb(ev service.ChatEvent) *pb.ChatServerFrame {
+	switch {
+	case ev.Message != nil:
+		return &pb.ChatServerFrame{Frame: &pb.ChatServerFrame_Message{Message: chatMessageToPb(ev.Message)}}
+	case ev.Receipt != nil:
+		return &pb.ChatServerFrame{Frame: &pb.ChatServerFrame_Receipt{Receipt: &pb.ChatReceipt{
+			ReaderId:     ev.Receipt.ReaderID,
+			UpToUnixMs:   ev.Receipt.UpTo.UnixMilli(),
+			ReadAtUnixMs: ev.Receipt.At.UnixMilli(),
+		}}}
+	}
+	return &pb.ChatServerFrame{Frame: &pb.ChatServerFrame_Closed{Closed: &pb.ChatClosed{Reason: service.ErrChatClosed.Error()}}}
+}
+
+func userRatingToPb(u *models.UserRating) *pb.UserRating {
+	res := &pb.UserRating{UserId: u.UserID, Role: roleToPb(u.Role), Count: u.Count, Average: u.Average}
+	for t, n := range u.TagCounts {
+		res.Tags = append(res.Tags, &pb.TagCount{Tag: t, Count: n})
+	}
+	sort.Slice(res.Tags, func(i, j int) bool {
+		if res.Tags[i].Count == res.Tags[j].Count {
+			return res.Tags[i].Tag < res.Tags[j].Tag
+		}
+		return res.Tags[i].Count > res.Tags[j].Count
+	})
+	return res
+}
+
+func preferencesToPb(p *notify.Preferences) *pb.NotificationPreferences {
+	res := &pb.NotificationPreferences{UserId: p.UserID, Locale: p.Locale, Disabled: p.Disabled}
+	for _, t := range p.Muted {
+		res.MutedEvents = append(res.MutedEvents, string(t))
+	}
+	return res
+}
+
+func penaltyToPb(collectorID string, st *service.PenaltyStatus) *pb.CollectorPenalty {
+	p := st.Penalty
+	res := &pb.CollectorPenalty{
+		CollectorId: collectorID,
+		Suspended:   p.Active(time.Now()),
+		Points:      int32(st.Points),
+		ClearedBy:   p.ClearedBy,
+	}
+	if res.Suspended {
+		res.CooldownUntilUnix = p.CooldownUntil.Unix()
+		res.Reason = p.Reason
+	}
+	if !p.ClearedAt.IsZero() {
+		res.ClearedAtUnix = p.ClearedAt.Unix()
+	}
+	for _, sk := range st.Strikes {
+		res.Strikes = append(res.Strikes, &pb.Strike{OrderId: sk.OrderID, Kind: string(sk.Kind), AtUnix: sk.At.Unix()})
+	}
+	return res
+}
+
+func timeUnixOrZero(t *time.Time) int64 { if t == nil { return 0 }; return t.Unix() }
+
+func unixOrZero(sec int64) time.Time { if sec == 0 { return time.Time{} }; return time.Unix(sec, 0) }
+
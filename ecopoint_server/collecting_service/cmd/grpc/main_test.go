@@ -0,0 +1,437 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+
+	"ecopoint/collecting_service/internal/blob"
+	"ecopoint/collecting_service/internal/caller"
+	"ecopoint/collecting_service/internal/logging"
+	"ecopoint/collecting_service/internal/models"
+	"ecopoint/collecting_service/internal/notify"
+	"ecopoint/collecting_service/internal/privacy"
+	"ecopoint/collecting_service/internal/service"
+	pb "ecopoint/collecting_service/pb"
+)
+
+func TestNextStatusFromRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *pb.UpdateOrderStatusRequest
+		want models.OrderStatus
+		code codes.Code
+	}{
+		{"enum", &pb.UpdateOrderStatusRequest{NextStatus: pb.OrderStatus_ORDER_STATUS_ON_WAY}, models.StatusOnWay, codes.OK},
+		{"legacy string", &pb.UpdateOrderStatusRequest{Status: "complete"}, models.StatusComplete, codes.OK},
+		{"both agree", &pb.UpdateOrderStatusRequest{Status: "on_way", NextStatus: pb.OrderStatus_ORDER_STATUS_ON_WAY}, models.StatusOnWay, codes.OK},
+		{"typo", &pb.UpdateOrderStatusRequest{Status: "onway"}, "", codes.InvalidArgument},
+		{"missing", &pb.UpdateOrderStatusRequest{}, "", codes.InvalidArgument},
+		{"unknown enum", &pb.UpdateOrderStatusRequest{NextStatus: pb.OrderStatus(42)}, "", codes.InvalidArgument},
+		{"conflict", &pb.UpdateOrderStatusRequest{Status: "complete", NextStatus: pb.OrderStatus_ORDER_STATUS_ON_WAY}, "", codes.InvalidArgument},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := nextStatusFromRequest(tc.req)
+			if code := status.Code(err); code != tc.code {
+				t.Fatalf("expected code %v, got %v (%v)", tc.code, code, err)
+			}
+			if got != tc.want {
+				t.Fatalf("expected %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestOrderModelToPbEnums(t *testing.T) {
+	o := &models.Order{ID: "o1", Status: models.StatusCancelled, CancelSide: models.CancelBySystem, CancelReason: "expired"}
+	p := orderModelToPb(o)
+	if p.OrderStatus != pb.OrderStatus_ORDER_STATUS_CANCELLED || p.Status != "cancelled" {
+		t.Fatalf("unexpected status %v / %q", p.OrderStatus, p.Status)
+	}
+	if p.CancelSide != pb.CancelSide_CANCEL_SIDE_SYSTEM || p.CancelReason != "expired" {
+		t.Fatalf("unexpected cancel fields %v / %q", p.CancelSide, p.CancelReason)
+	}
+}
+
+// startServer serves s on loopback, a trusted peer, and returns a client for it
+func startServer(t *testing.T, s *server, opts ...grpc.ServerOption) pb.CollectingServiceClient {
+	t.Helper()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := grpc.NewServer(opts...)
+	pb.RegisterCollectingServiceServer(srv, s)
+	go func() { _ = srv.Serve(lis) }()
+	conn, err := grpc.NewClient("passthrough:///"+lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close(); srv.Stop() })
+	return pb.NewCollectingServiceClient(conn)
+}
+
+func TestChatStream(t *testing.T) {
+	svc := service.NewService(service.NewInMemoryRepo())
+	client := startServer(t, &server{svc: svc})
+	ctx := context.Background()
+	u1 := caller.NewOutgoingContext(ctx, caller.Caller{UserID: "u1", Role: models.RoleCustomer})
+	c1 := caller.NewOutgoingContext(ctx, caller.Caller{UserID: "c1", Role: models.RoleCollector})
+	o, err := client.CreateOrder(u1, &pb.CreateOrderRequest{
+		CustomerId:  "u1",
+		PickAddress: &pb.Address{FullText: "A", Lat: 1, Lng: 2},
+		Items:       []*pb.WasteItem{{Type: "paper", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+
+	stream, _ := client.Chat(u1)
+	_ = stream.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Join{Join: &pb.ChatJoin{OrderId: o.Id}}})
+	if _, err := stream.Recv(); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("expected FailedPrecondition before acceptance, got %v", err)
+	}
+
+	if _, err := client.AcceptOrder(c1, &pb.AcceptOrderRequest{OrderId: o.Id, CollectorId: "c1"}); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	// the joining user is the caller, whatever the join frame names
+	impostor, _ := client.Chat(c1)
+	_ = impostor.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Join{Join: &pb.ChatJoin{OrderId: o.Id, UserId: "u1"}}})
+	if _, err := impostor.Recv(); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("joining as someone else: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.ListChatMessages(ctx, &pb.ListChatMessagesRequest{OrderId: o.Id, UserId: "u1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous history: expected Unauthenticated, got %v", err)
+	}
+
+	customer, _ := client.Chat(u1)
+	_ = customer.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Join{Join: &pb.ChatJoin{OrderId: o.Id}}})
+	// each side is subscribed once its own message comes back, so the customer's
+	// echo must arrive before the collector posts
+	_ = customer.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Send{Send: &pb.ChatSend{Text: "hello"}}})
+	if f, err := customer.Recv(); err != nil || f.GetMessage().GetText() != "hello" {
+		t.Fatalf("customer expected their own message, got %v err %v", f, err)
+	}
+	collector, _ := client.Chat(c1)
+	_ = collector.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Join{Join: &pb.ChatJoin{OrderId: o.Id, UserId: "c1"}}})
+	for {
+		_ = collector.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Send{Send: &pb.ChatSend{Text: "on my way"}}})
+		f, err := collector.Recv()
+		if err != nil {
+			t.Fatalf("collector recv: %v", err)
+		}
+		if f.GetMessage().GetText() == "on my way" {
+			break
+		}
+	}
+	f, err := customer.Recv()
+	if err != nil || f.GetMessage().GetSenderId() != "c1" || f.GetMessage().GetSenderRole() != pb.UserRole_USER_ROLE_COLLECTOR {
+		t.Fatalf("customer expected the collector's message, got %v err %v", f, err)
+	}
+	_ = customer.Send(&pb.ChatClientFrame{Frame: &pb.ChatClientFrame_Read{Read: &pb.ChatRead{LastMessageId: f.GetMessage().GetId()}}})
+	if f, err := collector.Recv(); err != nil || f.GetReceipt().GetReaderId() != "u1" {
+		t.Fatalf("collector expected a read receipt, got %v err %v", f, err)
+	}
+
+	if _, err := client.CancelOrder(ctx, &pb.CancelOrderRequest{OrderId: o.Id, Side: pb.CancelSide_CANCEL_SIDE_CUSTOMER}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected Unauthenticated for an anonymous cancel, got %v", err)
+	}
+	if _, err := client.CancelOrder(u1, &pb.CancelOrderRequest{OrderId: o.Id, Side: pb.CancelSide_CANCEL_SIDE_CUSTOMER}); err != nil {
+		t.Fatalf("cancel: %v", err)
+	}
+	for {
+		f, err := customer.Recv()
+		if err != nil {
+			t.Fatalf("expected closed frame before the stream ends, got %v", err)
+		}
+		if f.GetClosed() != nil {
+			break
+		}
+	}
+}
+
+func TestGetOrderRedactsPhoneByCaller(t *testing.T) {
+	svc := service.NewService(service.NewInMemoryRepo())
+	client := startServer(t, &server{svc: svc, redact: privacy.NewRedactor(privacy.NewLocalRelay(""))})
+	ctx := context.Background()
+	as := func(id string, role models.Role) context.Context {
+		return caller.NewOutgoingContext(ctx, caller.Caller{UserID: id, Role: role})
+	}
+	o, err := client.CreateOrder(as("u1", models.RoleCustomer), &pb.CreateOrderRequest{
+		PickAddress:      &pb.Address{FullText: "12 Lê Lợi, Quận 1, TP.HCM", Lat: 10.7731, Lng: 106.7004},
+		CustomerSnapshot: &pb.CustomerSnapshot{DisplayName: "Lan", Phone: "0901234567"},
+		Items:            []*pb.WasteItem{{Type: "paper", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if got, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: o.Id}); err != nil || got.GetCustomerSnapshot().GetPhone() != "" {
+		t.Fatalf("anonymous caller should not see the phone, got %v err %v", got, err)
+	}
+	if _, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "missing"}); status.Code(err) != codes.NotFound {
+		t.Fatalf("missing order: expected NotFound, got %v", err)
+	}
+	got, err := client.GetOrder(as("u1", models.RoleCustomer), &pb.GetOrderRequest{OrderId: o.Id})
+	if err != nil || got.GetCustomerSnapshot().GetPhone() != "0901234567" {
+		t.Fatalf("customer should see their phone, got %v err %v", got, err)
+	}
+	got, err = client.GetOrder(as("c2", models.RoleCollector), &pb.GetOrderRequest{OrderId: o.Id})
+	if err != nil || got.GetCustomerSnapshot().GetPhone() != "" || got.GetPickAddressSnapshot().GetFullText() != "Quận 1, TP.HCM" {
+		t.Fatalf("other collector should see a redacted order, got %v err %v", got, err)
+	}
+	got, err = client.AcceptOrder(as("c1", models.RoleCollector), &pb.AcceptOrderRequest{OrderId: o.Id, CollectorId: "c1"})
+	if err != nil || got.GetCustomerSnapshot().GetPhone() == "" || got.GetCustomerSnapshot().GetPhone() == "0901234567" {
+		t.Fatalf("accepting collector should see a relay number, got %v err %v", got, err)
+	}
+}
+
+func TestRequestLogging(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(&buf, "info", "json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewService(service.NewInMemoryRepo(), service.WithLogger(logger))
+	client := startServer(t, &server{svc: svc, redact: privacy.NewRedactor(nil)},
+		grpc.ChainUnaryInterceptor(logUnary(logger)), grpc.ChainStreamInterceptor(logStream(logger)))
+	ctx := caller.NewOutgoingContext(context.Background(), caller.Caller{UserID: "u1", Role: models.RoleCustomer})
+	o, err := client.CreateOrder(ctx, &pb.CreateOrderRequest{
+		CustomerId:       "u1",
+		PickAddress:      &pb.Address{FullText: "A", Lat: 1, Lng: 2},
+		CustomerSnapshot: &pb.CustomerSnapshot{DisplayName: "Lan", Phone: "0901234567"},
+		Items:            []*pb.WasteItem{{Type: "paper", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := client.GetOrder(ctx, &pb.GetOrderRequest{OrderId: "missing"}); err == nil {
+		t.Fatal("expected an error for a missing order")
+	}
+
+	if bytes.Contains(buf.Bytes(), []byte("0901234567")) {
+		t.Fatalf("phone leaked into the logs:\n%s", buf.String())
+	}
+	calls := map[string]map[string]any{}
+	for _, line := range bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n")) {
+		var rec map[string]any
+		if err := json.Unmarshal(line, &rec); err != nil {
+			t.Fatalf("bad record %q: %v", line, err)
+		}
+		if rec["msg"] == "rpc" {
+			calls[rec["method"].(string)] = rec
+		}
+	}
+	created := calls[pb.CollectingService_CreateOrder_FullMethodName]
+	if created == nil || created["level"] != "INFO" || created["code"] != "OK" || created["order_id"] != o.Id || created["user_id"] != "u1" || created["role"] != "customer" {
+		t.Fatalf("unexpected CreateOrder record %v", created)
+	}
+	failed := calls[pb.CollectingService_GetOrder_FullMethodName]
+	if failed == nil || failed["order_id"] != "missing" || failed["error"] == nil || failed["latency"] == nil {
+		t.Fatalf("failed call not logged: %v", failed)
+	}
+}
+
+func TestAttachmentUploadStream(t *testing.T) {
+	store, err := blob.NewFSStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc := service.NewService(service.NewInMemoryRepo(), service.WithBlobStore(store))
+	client := startServer(t, &server{svc: svc})
+	ctx := context.Background()
+	u1 := caller.NewOutgoingContext(ctx, caller.Caller{UserID: "u1", Role: models.RoleCustomer})
+	u2 := caller.NewOutgoingContext(ctx, caller.Caller{UserID: "u2", Role: models.RoleCustomer})
+	o, err := client.CreateOrder(u1, &pb.CreateOrderRequest{
+		CustomerId:  "u1",
+		PickAddress: &pb.Address{FullText: "A", Lat: 1, Lng: 2},
+		Items:       []*pb.WasteItem{{Type: "paper", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if _, err := client.CreateAttachmentUpload(u2, &pb.AttachmentInfo{OrderId: o.Id, UserId: "u1", Kind: pb.AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO, ContentType: "image/png", Size: 10}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("uploading as someone else: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.CreateAttachmentUpload(u1, &pb.AttachmentInfo{OrderId: o.Id, Kind: pb.AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO, ContentType: "image/png", Size: 10}); status.Code(err) != codes.FailedPrecondition {
+		t.Fatalf("the fs store cannot sign URLs, got %v", err)
+	}
+
+	file := append([]byte("\x89PNG\r\n\x1a\n"), bytes.Repeat([]byte{1}, 100<<10)...)
+	up, _ := client.UploadAttachment(u1)
+	_ = up.Send(&pb.UploadAttachmentRequest{Part: &pb.UploadAttachmentRequest_Info{Info: &pb.AttachmentInfo{
+		OrderId: o.Id, Kind: pb.AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO, ContentType: "image/png", Size: int64(len(file)),
+	}}})
+	for rest := file; len(rest) > 0; {
+		n := min(len(rest), 32<<10)
+		_ = up.Send(&pb.UploadAttachmentRequest{Part: &pb.UploadAttachmentRequest_Chunk{Chunk: rest[:n]}})
+		rest = rest[n:]
+	}
+	got, err := up.CloseAndRecv()
+	if err != nil || len(got.Items) != 1 || len(got.Items[0].Attachments) != 1 {
+		t.Fatalf("upload failed: %v err %v", got, err)
+	}
+
+	id := got.Items[0].Attachments[0].Id
+	if _, err := client.GetAttachmentURL(ctx, &pb.GetAttachmentURLRequest{AttachmentId: id, UserId: "u1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous read: expected Unauthenticated, got %v", err)
+	}
+	if _, err := client.GetAttachmentURL(u2, &pb.GetAttachmentURLRequest{AttachmentId: id, UserId: "u1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("reading as someone else: expected PermissionDenied, got %v", err)
+	}
+	down, _ := client.DownloadAttachment(u1, &pb.DownloadAttachmentRequest{AttachmentId: id})
+	var data []byte
+	for {
+		c, err := down.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("download: %v", err)
+		}
+		data = append(data, c.Data...)
+	}
+	if !bytes.Equal(data, file) {
+		t.Fatalf("downloaded %d bytes, want %d", len(data), len(file))
+	}
+}
+
+// TestCallerIdentity checks that calls act as the caller in metadata, not as the user a
+// request field names
+func TestCallerIdentity(t *testing.T) {
+	svc := service.NewService(service.NewInMemoryRepo())
+	client := startServer(t, &server{svc: svc, prefs: notify.NewInMemoryPreferenceStore()})
+	ctx := context.Background()
+	as := func(id string, role models.Role) context.Context {
+		return caller.NewOutgoingContext(ctx, caller.Caller{UserID: id, Role: role})
+	}
+	customer, collector, admin := as("u1", models.RoleCustomer), as("c1", models.RoleCollector), as("ops", models.RoleAdmin)
+	o, err := client.CreateOrder(customer, &pb.CreateOrderRequest{
+		CustomerId:  "u1",
+		PickAddress: &pb.Address{FullText: "A", Lat: 1, Lng: 2},
+		Items:       []*pb.WasteItem{{Type: "paper", Weight: 1}},
+	})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	create := &pb.CreateOrderRequest{CustomerId: "u1", PickAddress: &pb.Address{FullText: "A", Lat: 1, Lng: 2}, Items: []*pb.WasteItem{{Type: "paper", Weight: 1}}}
+	if _, err := client.CreateOrder(as("u2", models.RoleCustomer), create); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("creating for someone else: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.CreateOrder(ctx, create); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous create: expected Unauthenticated, got %v", err)
+	}
+
+	note := "gate code 42"
+	edit := &pb.UpdateOrderDetailsRequest{OrderId: o.Id, CustomerId: "u1", Version: o.Version, Note: &note}
+	if _, err := client.UpdateOrderDetails(as("u2", models.RoleCustomer), edit); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("editing as someone else: expected PermissionDenied, got %v", err)
+	}
+	edit.CustomerId = ""
+	if up, err := client.UpdateOrderDetails(customer, edit); err != nil || up.Note != note {
+		t.Fatalf("editing as the caller: got %v err %v", up, err)
+	}
+
+	accept := &pb.AcceptOrderRequest{OrderId: o.Id, CollectorId: "c1", IdempotencyKey: "k1"}
+	if _, err := client.AcceptOrder(as("c2", models.RoleCollector), accept); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("accepting as another collector: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.AcceptOrder(collector, accept); err != nil {
+		t.Fatalf("accept: %v", err)
+	}
+	// a key is scoped to the caller, so naming c1 does not replay c1's response
+	if _, err := client.AcceptOrder(as("c2", models.RoleCollector), &pb.AcceptOrderRequest{OrderId: o.Id, IdempotencyKey: "k1"}); status.Code(err) == codes.OK {
+		t.Fatal("another collector's accept with the same key must not replay c1's")
+	}
+	onWay := &pb.UpdateOrderStatusRequest{OrderId: o.Id, CollectorId: "c1", NextStatus: pb.OrderStatus_ORDER_STATUS_ON_WAY}
+	if _, err := client.UpdateOrderStatus(as("c2", models.RoleCollector), onWay); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("updating as another collector: expected PermissionDenied, got %v", err)
+	}
+	_, _ = client.UpdateOrderStatus(collector, onWay)
+	_, _ = client.UpdateOrderStatus(collector, &pb.UpdateOrderStatusRequest{OrderId: o.Id, CollectorId: "c1", NextStatus: pb.OrderStatus_ORDER_STATUS_COMPLETE})
+
+	rate := &pb.RateOrderRequest{OrderId: o.Id, RaterId: "u1", Side: pb.UserRole_USER_ROLE_CUSTOMER, Stars: 5}
+	if _, err := client.RateOrder(ctx, rate); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous rating: expected Unauthenticated, got %v", err)
+	}
+	if _, err := client.RateOrder(as("u2", models.RoleCustomer), rate); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("rating as someone else: expected PermissionDenied, got %v", err)
+	}
+	rate.RaterId = ""
+	if r, err := client.RateOrder(customer, rate); err != nil || r.RaterId != "u1" {
+		t.Fatalf("rating as the caller: got %v err %v", r, err)
+	}
+	search := &pb.SearchUserRatingsRequest{Role: pb.UserRole_USER_ROLE_COLLECTOR}
+	if _, err := client.SearchUserRatings(collector, search); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("search by a collector: expected PermissionDenied, got %v", err)
+	}
+	if res, err := client.SearchUserRatings(admin, search); err != nil || len(res.Ratings) != 1 || res.Ratings[0].UserId != "c1" {
+		t.Fatalf("admin search: got %v err %v", res, err)
+	}
+
+	if _, err := client.CancelOrder(as("u2", models.RoleCustomer), &pb.CancelOrderRequest{OrderId: o.Id, Side: pb.CancelSide_CANCEL_SIDE_CUSTOMER}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("cancelling another customer's order: expected PermissionDenied, got %v", err)
+	}
+
+	prefs := &pb.NotificationPreferences{UserId: "u1", Locale: "en"}
+	if _, err := client.SetNotificationPreferences(as("u2", models.RoleCustomer), prefs); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("setting someone else's preferences: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.GetNotificationPreferences(ctx, &pb.GetNotificationPreferencesRequest{UserId: "u1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous preferences: expected Unauthenticated, got %v", err)
+	}
+	if p, err := client.SetNotificationPreferences(customer, &pb.NotificationPreferences{Locale: "en"}); err != nil || p.UserId != "u1" {
+		t.Fatalf("setting own preferences: got %v err %v", p, err)
+	}
+	if p, err := client.GetNotificationPreferences(admin, &pb.GetNotificationPreferencesRequest{UserId: "u1"}); err != nil || p.Locale != "en" {
+		t.Fatalf("admin reading preferences: got %v err %v", p, err)
+	}
+
+	// a collector's history, earnings and penalty are for that collector and admins
+	other := as("c2", models.RoleCollector)
+	if _, err := client.ListCollectorOrders(other, &pb.ListCollectorOrdersRequest{CollectorId: "c1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("listing another collector's orders: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.GetCollectorEarnings(other, &pb.GetCollectorEarningsRequest{CollectorId: "c1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("reading another collector's earnings: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.GetCollectorPenalty(other, &pb.GetCollectorPenaltyRequest{CollectorId: "c1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("reading another collector's penalty: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.ListMyActiveOrders(other, &pb.ListMyActiveOrdersRequest{CollectorId: "c1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("listing another collector's active orders: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.ListMyOrders(as("u2", models.RoleCustomer), &pb.ListMyOrdersRequest{CustomerId: "u1"}); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("listing another customer's orders: expected PermissionDenied, got %v", err)
+	}
+	if _, err := client.ListMyOrders(ctx, &pb.ListMyOrdersRequest{CustomerId: "u1"}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("anonymous order list: expected Unauthenticated, got %v", err)
+	}
+	if res, err := client.ListMyOrders(customer, &pb.ListMyOrdersRequest{}); err != nil || len(res.Orders) != 1 {
+		t.Fatalf("own orders: got %v err %v", res, err)
+	}
+	if res, err := client.ListCollectorOrders(collector, &pb.ListCollectorOrdersRequest{}); err != nil || len(res.Orders) != 1 {
+		t.Fatalf("own collector orders: got %v err %v", res, err)
+	}
+	if p, err := client.GetCollectorPenalty(admin, &pb.GetCollectorPenaltyRequest{CollectorId: "c1"}); err != nil || p.CollectorId != "c1" {
+		t.Fatalf("admin reading a penalty: got %v err %v", p, err)
+	}
+
+	clear := &pb.ClearCollectorPenaltyRequest{CollectorId: "c1", AdminId: "ops"}
+	if _, err := client.ClearCollectorPenalty(collector, clear); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("collector clearing their own penalty: expected PermissionDenied, got %v", err)
+	}
+	clear.AdminId = ""
+	if p, err := client.ClearCollectorPenalty(admin, clear); err != nil || p.ClearedBy != "ops" {
+		t.Fatalf("admin clear should record the caller, got %v err %v", p, err)
+	}
+}
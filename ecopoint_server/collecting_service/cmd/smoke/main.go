@@ -20,7 +20,10 @@ func pp(label string, v any) {
 
 func main() {
     ctx := context.Background()
-    cfg := config.Load()
+    cfg, err := config.Load()
+    if err != nil {
+        log.Fatal(err)
+    }
 
     repo, err := repository.NewMongoRepo(ctx, cfg.MongoURI, cfg.MongoDBName)
     if err != nil {
@@ -49,7 +52,7 @@ func main() {
     pp("CreateOrder", order)
 
     // 2) List available
-    list, err := svc.ListAvailable(10)
+    list, _, err := svc.ListAvailable("", 10)
     if err != nil { log.Fatalf("ListAvailable error: %v", err) }
     fmt.Printf("Available count: %d\n", len(list))
 
@@ -66,7 +69,7 @@ func main() {
     }
 
     // 5) List my active orders
-    actives, err := svc.ListMyActiveOrders("collector_demo")
+    actives, _, err := svc.ListMyActiveOrders("collector_demo", "", 10)
     if err != nil { log.Fatalf("ListMyActiveOrders error: %v", err) }
     fmt.Printf("Active orders for collector_demo: %d\n", len(actives))
 
@@ -0,0 +1,144 @@
+package account
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/service"
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// Options tune the client; zero values use the defaults
+type Options struct {
+    Timeout  time.Duration `yaml:"timeout"`   // per call, default 2s
+    CacheTTL time.Duration `yaml:"cache_ttl"` // fresh entries are served without calling, default 5m
+    StaleTTL time.Duration `yaml:"stale_ttl"` // expired entries still answer while the service is down, default 24h
+}
+
+func (o Options) withDefaults() Options {
+    if o.Timeout <= 0 {
+        o.Timeout = 2 * time.Second
+    }
+    if o.CacheTTL <= 0 {
+        o.CacheTTL = 5 * time.Minute
+    }
+    if o.StaleTTL <= 0 {
+        o.StaleTTL = 24 * time.Hour
+    }
+    return o
+}
+
+// Client reads users and addresses from AccountService and implements service.SnapshotSource
+type Client struct {
+    rpc  accountpb.AccountServiceClient
+    opts Options
+    now  func() time.Time
+
+    mu        sync.Mutex
+    users     map[string]entry[models.CustomerSnapshot]
+    addresses map[string]entry[[]*accountpb.Address]
+}
+
+type entry[T any] struct {
+    val     T
+    fetched time.Time
+}
+
+func NewClient(conn grpc.ClientConnInterface, opts Options) *Client {
+    return &Client{
+        rpc:       accountpb.NewAccountServiceClient(conn),
+        opts:      opts.withDefaults(),
+        now:       time.Now,
+        users:     map[string]entry[models.CustomerSnapshot]{},
+        addresses: map[string]entry[[]*accountpb.Address]{},
+    }
+}
+
+func (c *Client) CustomerSnapshot(customerID string) (models.CustomerSnapshot, error) {
+    return cached(c, c.users, customerID, func(ctx context.Context) (models.CustomerSnapshot, error) {
+        u, err := c.rpc.GetUser(ctx, &accountpb.GetUserRequest{UserId: customerID})
+        if err != nil {
+            return models.CustomerSnapshot{}, err
+        }
+        // the account service answers unknown users with an empty message
+        if u.UserId == "" {
+            return models.CustomerSnapshot{}, service.ErrCustomerNotFound
+        }
+        return models.CustomerSnapshot{DisplayName: u.DisplayName, Phone: u.Phone}, nil
+    })
+}
+
+func (c *Client) PickAddress(customerID string, addressID uint64) (models.Address, error) {
+    list, err := cached(c, c.addresses, customerID, func(ctx context.Context) ([]*accountpb.Address, error) {
+        res, err := c.rpc.ListAddresses(ctx, &accountpb.ListAddressesRequest{UserId: customerID})
+        if err != nil {
+            return nil, err
+        }
+        return res.Addresses, nil
+    })
+    if err != nil {
+        return models.Address{}, err
+    }
+    for _, a := range list {
+        if a.Id == addressID {
+            return models.Address{FullText: a.FullText, Lat: a.Lat, Lng: a.Lng}, nil
+        }
+    }
+    return models.Address{}, service.ErrAddressNotFound
+}
+
+// Invalidate drops cached data of a user, e.g. after the user edited their profile
+func (c *Client) Invalidate(userID string) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    delete(c.users, userID)
+    delete(c.addresses, userID)
+}
+
+// cached serves fresh entries from the cache, otherwise calls fetch. When the call fails
+// because the service is unreachable, an entry younger than StaleTTL is served instead;
+// without one the error is service.ErrAccountUnavailable.
+func cached[T any](c *Client, m map[string]entry[T], key string, fetch func(ctx context.Context) (T, error)) (T, error) {
+    now := c.now()
+    c.mu.Lock()
+    e, ok := m[key]
+    c.mu.Unlock()
+    if ok && now.Sub(e.fetched) < c.opts.CacheTTL {
+        return e.val, nil
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), c.opts.Timeout)
+    defer cancel()
+    val, err := fetch(ctx)
+    if err == nil {
+        c.mu.Lock()
+        m[key] = entry[T]{val: val, fetched: now}
+        c.mu.Unlock()
+        return val, nil
+    }
+    if !unavailable(err) {
+        return val, err
+    }
+    if ok && now.Sub(e.fetched) < c.opts.StaleTTL {
+        return e.val, nil
+    }
+    return val, service.ErrAccountUnavailable
+}
+
+// unavailable reports gRPC failures worth falling back on; other errors are answers
+func unavailable(err error) bool {
+    st, ok := status.FromError(err)
+    if !ok {
+        return false
+    }
+    switch st.Code() {
+    case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+        return true
+    }
+    return false
+}
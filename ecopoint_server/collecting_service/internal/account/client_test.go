@@ -0,0 +1,105 @@
+package account
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/service"
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+func startFake(t *testing.T) (*FakeServer, *Client) {
+    t.Helper()
+    fake := NewFakeServer()
+    fake.AddUser(&accountpb.User{UserId: "u1", DisplayName: "Lan", Phone: "0901234567"})
+    fake.AddAddress(&accountpb.Address{Id: 7, UserId: "u1", FullText: "12 Lê Lợi, Q1", Lat: 10.77, Lng: 106.70})
+    conn, stop, err := fake.Start()
+    if err != nil {
+        t.Fatalf("start fake: %v", err)
+    }
+    t.Cleanup(stop)
+    return fake, NewClient(conn, Options{CacheTTL: time.Minute, StaleTTL: time.Hour})
+}
+
+func TestClientCachesAndFallsBack(t *testing.T) {
+    fake, c := startFake(t)
+    now := time.Now()
+    c.now = func() time.Time { return now }
+
+    snap, err := c.CustomerSnapshot("u1")
+    if err != nil || snap.DisplayName != "Lan" || snap.Phone != "0901234567" {
+        t.Fatalf("unexpected snapshot %+v err %v", snap, err)
+    }
+    _, _ = c.CustomerSnapshot("u1")
+    if fake.Calls() != 1 {
+        t.Fatalf("expected cached second lookup, got %d calls", fake.Calls())
+    }
+
+    // Expired but within the stale window: served while the service is down
+    fake.SetDown(true)
+    now = now.Add(2 * time.Minute)
+    if snap, err := c.CustomerSnapshot("u1"); err != nil || snap.DisplayName != "Lan" {
+        t.Fatalf("expected stale snapshot, got %+v err %v", snap, err)
+    }
+    if _, err := c.CustomerSnapshot("u2"); !errors.Is(err, service.ErrAccountUnavailable) {
+        t.Fatalf("expected unavailable without cache, got %v", err)
+    }
+    now = now.Add(2 * time.Hour)
+    if _, err := c.CustomerSnapshot("u1"); !errors.Is(err, service.ErrAccountUnavailable) {
+        t.Fatalf("expected unavailable after the stale window, got %v", err)
+    }
+
+    fake.SetDown(false)
+    if _, err := c.CustomerSnapshot("nobody"); !errors.Is(err, service.ErrCustomerNotFound) {
+        t.Fatalf("expected not found, got %v", err)
+    }
+}
+
+func TestClientPickAddress(t *testing.T) {
+    _, c := startFake(t)
+    addr, err := c.PickAddress("u1", 7)
+    if err != nil || addr.FullText != "12 Lê Lợi, Q1" || addr.Lat != 10.77 {
+        t.Fatalf("unexpected address %+v err %v", addr, err)
+    }
+    if _, err := c.PickAddress("u1", 8); !errors.Is(err, service.ErrAddressNotFound) {
+        t.Fatalf("expected address not found, got %v", err)
+    }
+    if _, err := c.PickAddress("u2", 7); !errors.Is(err, service.ErrAddressNotFound) {
+        t.Fatalf("another customer's address must not resolve, got %v", err)
+    }
+}
+
+func TestCreateOrderEnrichment(t *testing.T) {
+    fake, c := startFake(t)
+    svc := service.NewService(service.NewInMemoryRepo(), service.WithSnapshotSource(c))
+    in := service.CreateOrderInput{
+        ID:               "o1",
+        CustomerID:       "u1",
+        AddressID:        7,
+        CustomerSnapshot: models.CustomerSnapshot{DisplayName: "spoofed"},
+        Items:            []models.WasteItem{{Type: "paper", Weight: 2}},
+    }
+    o, err := svc.CreateOrder(in)
+    if err != nil {
+        t.Fatalf("create failed: %v", err)
+    }
+    if o.CustomerSnapshot.DisplayName != "Lan" || o.PickAddressSnapshot.FullText != "12 Lê Lợi, Q1" {
+        t.Fatalf("expected server-side snapshots, got %+v %+v", o.CustomerSnapshot, o.PickAddressSnapshot)
+    }
+
+    // Down and nothing cached: the client address keeps the order going, no address fails
+    fake.SetDown(true)
+    c.Invalidate("u1")
+    in.ID = "o2"
+    if _, err := svc.CreateOrder(in); !errors.Is(err, service.ErrAccountUnavailable) {
+        t.Fatalf("expected unavailable without a client address, got %v", err)
+    }
+    in.ID = "o3"
+    in.Address = models.Address{FullText: "client text", Lat: 1, Lng: 2}
+    o, err = svc.CreateOrder(in)
+    if err != nil || o.PickAddressSnapshot.FullText != "client text" || o.CustomerSnapshot.DisplayName != "spoofed" {
+        t.Fatalf("expected client fallback, got %+v err %v", o, err)
+    }
+}
@@ -0,0 +1,124 @@
+package account
+
+import (
+    "context"
+    "net"
+    "sync"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+    "google.golang.org/grpc/test/bufconn"
+
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// FakeServer is an in-process AccountService for tests, served over bufconn
+type FakeServer struct {
+    accountpb.UnimplementedAccountServiceServer
+
+    mu        sync.Mutex
+    users     map[string]*accountpb.User
+    addresses map[string][]*accountpb.Address
+    tokens    map[string][]*accountpb.DeviceToken
+    down      bool
+    calls     int
+}
+
+func NewFakeServer() *FakeServer {
+    return &FakeServer{
+        users:     map[string]*accountpb.User{},
+        addresses: map[string][]*accountpb.Address{},
+        tokens:    map[string][]*accountpb.DeviceToken{},
+    }
+}
+
+func (f *FakeServer) AddUser(u *accountpb.User) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.users[u.UserId] = u
+}
+
+func (f *FakeServer) AddAddress(a *accountpb.Address) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.addresses[a.UserId] = append(f.addresses[a.UserId], a)
+}
+
+func (f *FakeServer) AddDeviceToken(userID, token string) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.tokens[userID] = append(f.tokens[userID], &accountpb.DeviceToken{Token: token, Platform: "android"})
+}
+
+// SetDown makes every call fail with codes.Unavailable
+func (f *FakeServer) SetDown(down bool) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.down = down
+}
+
+// Calls counts requests received, including failed ones
+func (f *FakeServer) Calls() int {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return f.calls
+}
+
+func (f *FakeServer) begin() error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    f.calls++
+    if f.down {
+        return status.Error(codes.Unavailable, "account service is down")
+    }
+    return nil
+}
+
+func (f *FakeServer) GetUser(ctx context.Context, req *accountpb.GetUserRequest) (*accountpb.User, error) {
+    if err := f.begin(); err != nil {
+        return nil, err
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if u, ok := f.users[req.UserId]; ok {
+        return u, nil
+    }
+    return &accountpb.User{}, nil
+}
+
+func (f *FakeServer) ListAddresses(ctx context.Context, req *accountpb.ListAddressesRequest) (*accountpb.ListAddressesResponse, error) {
+    if err := f.begin(); err != nil {
+        return nil, err
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return &accountpb.ListAddressesResponse{Addresses: f.addresses[req.UserId]}, nil
+}
+
+func (f *FakeServer) ListDeviceTokens(ctx context.Context, req *accountpb.ListDeviceTokensRequest) (*accountpb.ListDeviceTokensResponse, error) {
+    if err := f.begin(); err != nil {
+        return nil, err
+    }
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return &accountpb.ListDeviceTokensResponse{Tokens: f.tokens[req.UserId]}, nil
+}
+
+// Start serves f in memory and returns a connection to it; stop closes both
+func (f *FakeServer) Start() (conn *grpc.ClientConn, stop func(), err error) {
+    lis := bufconn.Listen(1 << 20)
+    srv := grpc.NewServer()
+    accountpb.RegisterAccountServiceServer(srv, f)
+    go func() { _ = srv.Serve(lis) }()
+    conn, err = grpc.NewClient("passthrough:///bufnet",
+        grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+        grpc.WithTransportCredentials(insecure.NewCredentials()),
+    )
+    if err != nil {
+        srv.Stop()
+        return nil, nil, err
+    }
+    return conn, func() { _ = conn.Close(); srv.Stop() }, nil
+}
@@ -0,0 +1,104 @@
+// Package blob implements service.BlobStore on a local directory and on S3-compatible storage
+package blob
+
+import (
+    "errors"
+    "fmt"
+    "io"
+    "io/fs"
+    "os"
+    "path"
+    "path/filepath"
+    "strings"
+
+    "ecopoint/collecting_service/internal/service"
+)
+
+// FSStore keeps blobs as files under a root directory. It cannot sign URLs,
+// so clients upload and download through the streaming RPCs.
+type FSStore struct {
+    root string
+}
+
+func NewFSStore(root string) (*FSStore, error) {
+    if err := os.MkdirAll(root, 0o755); err != nil {
+        return nil, err
+    }
+    return &FSStore{root: root}, nil
+}
+
+// path maps a key to a file below root, rejecting keys that would escape it
+func (s *FSStore) path(key string) (string, error) {
+    clean := path.Clean("/" + key)
+    if key == "" || clean != "/"+key || strings.HasSuffix(key, "/") {
+        return "", fmt.Errorf("invalid blob key %q", key)
+    }
+    return filepath.Join(s.root, filepath.FromSlash(clean)), nil
+}
+
+// Put writes to a temporary file first so a failed upload never leaves a partial blob
+func (s *FSStore) Put(key string, r io.Reader, size int64, contentType string) error {
+    p, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+        return err
+    }
+    tmp, err := os.CreateTemp(filepath.Dir(p), ".upload-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    n, err := io.Copy(tmp, r)
+    if cerr := tmp.Close(); err == nil {
+        err = cerr
+    }
+    if err != nil {
+        return err
+    }
+    if n != size {
+        return fmt.Errorf("blob %s: wrote %d bytes, expected %d", key, n, size)
+    }
+    return os.Rename(tmp.Name(), p)
+}
+
+func (s *FSStore) Open(key string) (io.ReadCloser, error) {
+    p, err := s.path(key)
+    if err != nil {
+        return nil, err
+    }
+    f, err := os.Open(p)
+    if errors.Is(err, fs.ErrNotExist) {
+        return nil, service.ErrBlobNotFound
+    }
+    return f, err
+}
+
+func (s *FSStore) Stat(key string) (service.BlobInfo, error) {
+    p, err := s.path(key)
+    if err != nil {
+        return service.BlobInfo{}, err
+    }
+    fi, err := os.Stat(p)
+    if errors.Is(err, fs.ErrNotExist) {
+        return service.BlobInfo{}, service.ErrBlobNotFound
+    }
+    if err != nil {
+        return service.BlobInfo{}, err
+    }
+    return service.BlobInfo{Size: fi.Size()}, nil
+}
+
+func (s *FSStore) Delete(key string) error {
+    p, err := s.path(key)
+    if err != nil {
+        return err
+    }
+    if err := os.Remove(p); err != nil && !errors.Is(err, fs.ErrNotExist) {
+        return err
+    }
+    return nil
+}
+
+var _ service.BlobStore = (*FSStore)(nil)
@@ -0,0 +1,49 @@
+package blob
+
+import (
+    "errors"
+    "io"
+    "strings"
+    "testing"
+
+    "ecopoint/collecting_service/internal/service"
+)
+
+func TestFSStoreRoundTrip(t *testing.T) {
+    s, err := NewFSStore(t.TempDir())
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := s.Put("orders/o1/a1", strings.NewReader("hello"), 5, "image/png"); err != nil {
+        t.Fatalf("put: %v", err)
+    }
+    if info, err := s.Stat("orders/o1/a1"); err != nil || info.Size != 5 {
+        t.Fatalf("unexpected stat %+v err %v", info, err)
+    }
+    rc, err := s.Open("orders/o1/a1")
+    if err != nil {
+        t.Fatalf("open: %v", err)
+    }
+    b, _ := io.ReadAll(rc)
+    rc.Close()
+    if string(b) != "hello" {
+        t.Fatalf("unexpected body %q", b)
+    }
+
+    if err := s.Put("orders/o1/a2", strings.NewReader("hel"), 5, "image/png"); err == nil {
+        t.Fatalf("expected a short write to fail")
+    }
+    if _, err := s.Stat("orders/o1/a2"); !errors.Is(err, service.ErrBlobNotFound) {
+        t.Fatalf("a failed put must not leave a blob, got %v", err)
+    }
+    if err := s.Put("../escape", strings.NewReader("x"), 1, "image/png"); err == nil {
+        t.Fatalf("expected keys outside the root to be rejected")
+    }
+
+    if err := s.Delete("orders/o1/a1"); err != nil {
+        t.Fatalf("delete: %v", err)
+    }
+    if _, err := s.Open("orders/o1/a1"); !errors.Is(err, service.ErrBlobNotFound) {
+        t.Fatalf("expected not found after delete, got %v", err)
+    }
+}
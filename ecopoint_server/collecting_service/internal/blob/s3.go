@@ -0,0 +1,229 @@
+package blob
+
+import (
+    "crypto/hmac"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+
+    "ecopoint/collecting_service/internal/service"
+)
+
+// S3Config addresses a bucket on AWS S3 or a compatible server (MinIO, R2, ...)
+type S3Config struct {
+    Endpoint  string `yaml:"endpoint"`                 // e.g. https://s3.ap-southeast-1.amazonaws.com or http://localhost:9000
+    Region    string `yaml:"region"`                   // default us-east-1
+    Bucket    string `yaml:"bucket"`
+    AccessKey string `yaml:"access_key" secret:"true"`
+    SecretKey string `yaml:"secret_key" secret:"true"`
+    PathStyle bool   `yaml:"path_style"`               // bucket in the path instead of the host name; most self-hosted servers need this
+}
+
+// S3Store talks to the bucket with presigned requests (SigV4 query auth), the same URLs
+// it hands to clients for direct uploads and downloads
+type S3Store struct {
+    cfg    S3Config
+    base   *url.URL
+    client *http.Client
+    now    func() time.Time
+}
+
+func NewS3Store(cfg S3Config) (*S3Store, error) {
+    if cfg.Bucket == "" || cfg.AccessKey == "" || cfg.SecretKey == "" {
+        return nil, errors.New("s3: bucket, access key and secret key are required")
+    }
+    base, err := url.Parse(cfg.Endpoint)
+    if err != nil || base.Host == "" {
+        return nil, fmt.Errorf("s3: invalid endpoint %q", cfg.Endpoint)
+    }
+    if cfg.Region == "" {
+        cfg.Region = "us-east-1"
+    }
+    return &S3Store{cfg: cfg, base: base, client: &http.Client{Timeout: 2 * time.Minute}, now: time.Now}, nil
+}
+
+// presign returns a URL for method on key, valid for ttl. headers (lowercase names) are
+// signed and must be sent unchanged with the request.
+func (s *S3Store) presign(method, key string, headers map[string]string, ttl time.Duration) string {
+    host, path := s.base.Host, "/"+key
+    if s.cfg.PathStyle {
+        path = "/" + s.cfg.Bucket + path
+    } else {
+        host = s.cfg.Bucket + "." + host
+    }
+    t := s.now().UTC()
+    date := t.Format("20060102")
+    scope := date + "/" + s.cfg.Region + "/s3/aws4_request"
+
+    signed := map[string]string{"host": host}
+    for k, v := range headers {
+        signed[strings.ToLower(k)] = strings.TrimSpace(v)
+    }
+    names := make([]string, 0, len(signed))
+    for k := range signed {
+        names = append(names, k)
+    }
+    sort.Strings(names)
+    var canonHeaders strings.Builder
+    for _, k := range names {
+        canonHeaders.WriteString(k + ":" + signed[k] + "\n")
+    }
+    signedHeaders := strings.Join(names, ";")
+
+    query := canonicalQuery(map[string]string{
+        "X-Amz-Algorithm":     "AWS4-HMAC-SHA256",
+        "X-Amz-Credential":    s.cfg.AccessKey + "/" + scope,
+        "X-Amz-Date":          t.Format("20060102T150405Z"),
+        "X-Amz-Expires":       strconv.Itoa(int(ttl / time.Second)),
+        "X-Amz-SignedHeaders": signedHeaders,
+    })
+    canonPath := uriEncode(path, false)
+    request := strings.Join([]string{method, canonPath, query, canonHeaders.String(), signedHeaders, "UNSIGNED-PAYLOAD"}, "\n")
+    sum := sha256.Sum256([]byte(request))
+    toSign := "AWS4-HMAC-SHA256\n" + t.Format("20060102T150405Z") + "\n" + scope + "\n" + hex.EncodeToString(sum[:])
+
+    k := hmacSHA256([]byte("AWS4"+s.cfg.SecretKey), date)
+    k = hmacSHA256(k, s.cfg.Region)
+    k = hmacSHA256(k, "s3")
+    k = hmacSHA256(k, "aws4_request")
+    sig := hex.EncodeToString(hmacSHA256(k, toSign))
+    return s.base.Scheme + "://" + host + canonPath + "?" + query + "&X-Amz-Signature=" + sig
+}
+
+func (s *S3Store) UploadURL(key, contentType string, ttl time.Duration) (string, map[string]string, error) {
+    return s.presign(http.MethodPut, key, map[string]string{"content-type": contentType}, ttl), map[string]string{"Content-Type": contentType}, nil
+}
+
+func (s *S3Store) DownloadURL(key string, ttl time.Duration) (string, error) {
+    return s.presign(http.MethodGet, key, nil, ttl), nil
+}
+
+// requestTTL only needs to cover the request the server itself is about to send
+const requestTTL = 5 * time.Minute
+
+func (s *S3Store) do(method, key string, body io.Reader, size int64, contentType string) (*http.Response, error) {
+    var headers map[string]string
+    if contentType != "" {
+        headers = map[string]string{"content-type": contentType}
+    }
+    req, err := http.NewRequest(method, s.presign(method, key, headers, requestTTL), body)
+    if err != nil {
+        return nil, err
+    }
+    if body != nil {
+        req.ContentLength = size
+    }
+    if contentType != "" {
+        req.Header.Set("Content-Type", contentType)
+    }
+    return s.client.Do(req)
+}
+
+func (s *S3Store) Put(key string, r io.Reader, size int64, contentType string) error {
+    resp, err := s.do(http.MethodPut, key, r, size, contentType)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK {
+        return s3Error("put", key, resp)
+    }
+    return nil
+}
+
+func (s *S3Store) Open(key string) (io.ReadCloser, error) {
+    resp, err := s.do(http.MethodGet, key, nil, 0, "")
+    if err != nil {
+        return nil, err
+    }
+    if resp.StatusCode == http.StatusOK {
+        return resp.Body, nil
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode == http.StatusNotFound {
+        return nil, service.ErrBlobNotFound
+    }
+    return nil, s3Error("get", key, resp)
+}
+
+func (s *S3Store) Stat(key string) (service.BlobInfo, error) {
+    resp, err := s.do(http.MethodHead, key, nil, 0, "")
+    if err != nil {
+        return service.BlobInfo{}, err
+    }
+    defer resp.Body.Close()
+    switch resp.StatusCode {
+    case http.StatusOK:
+        return service.BlobInfo{Size: resp.ContentLength, ContentType: resp.Header.Get("Content-Type")}, nil
+    case http.StatusNotFound:
+        return service.BlobInfo{}, service.ErrBlobNotFound
+    }
+    return service.BlobInfo{}, s3Error("head", key, resp)
+}
+
+func (s *S3Store) Delete(key string) error {
+    resp, err := s.do(http.MethodDelete, key, nil, 0, "")
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode == http.StatusNoContent || resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusNotFound {
+        return nil
+    }
+    return s3Error("delete", key, resp)
+}
+
+func s3Error(op, key string, resp *http.Response) error {
+    msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+    return fmt.Errorf("s3 %s %s: %s: %s", op, key, resp.Status, strings.TrimSpace(string(msg)))
+}
+
+func canonicalQuery(params map[string]string) string {
+    keys := make([]string, 0, len(params))
+    for k := range params {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    parts := make([]string, 0, len(keys))
+    for _, k := range keys {
+        parts = append(parts, uriEncode(k, true)+"="+uriEncode(params[k], true))
+    }
+    return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but RFC 3986 unreserved characters, as SigV4 requires;
+// slashes are kept unless encodeSlash is set
+func uriEncode(s string, encodeSlash bool) string {
+    var b strings.Builder
+    for i := 0; i < len(s); i++ {
+        c := s[i]
+        switch {
+        case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+            b.WriteByte(c)
+        case c == '/' && !encodeSlash:
+            b.WriteByte(c)
+        default:
+            fmt.Fprintf(&b, "%%%02X", c)
+        }
+    }
+    return b.String()
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+    h := hmac.New(sha256.New, key)
+    h.Write([]byte(data))
+    return h.Sum(nil)
+}
+
+var (
+    _ service.BlobStore     = (*S3Store)(nil)
+    _ service.BlobURLSigner = (*S3Store)(nil)
+)
@@ -0,0 +1,72 @@
+// Package caller carries the identity of the end user behind a gRPC call.
+// The BFF authenticates the user and forwards the identity as metadata; the
+// headers are believed only from the peers given to Trust, by default loopback.
+package caller
+
+import (
+    "context"
+    "net/netip"
+    "sync/atomic"
+
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+const (
+    HeaderUserID = "x-user-id"
+    HeaderRole   = "x-user-role" // customer | collector | admin
+    // HeaderForwardedFor lists the client address and the proxies it went through, client first
+    HeaderForwardedFor = "x-forwarded-for"
+)
+
+type Caller struct {
+    UserID string
+    Role   models.Role
+}
+
+func (c Caller) Anonymous() bool { return c.UserID == "" }
+
+func (c Caller) IsAdmin() bool { return c.Role == models.RoleAdmin }
+
+var (
+    trusted  atomic.Pointer[Proxies]
+    loopback = Proxies{netip.MustParsePrefix("127.0.0.0/8"), netip.MustParsePrefix("::1/128")}
+)
+
+func init() { Trust(loopback) }
+
+// Trust replaces the peers whose identity headers are believed, normally the BFF and the
+// REST gateway; call it before serving
+func Trust(p Proxies) { trusted.Store(&p) }
+
+// FromContext reads the caller from incoming metadata; unknown roles are dropped. A call
+// from a peer that is not trusted is anonymous whatever its headers say.
+func FromContext(ctx context.Context) Caller {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return Caller{}
+    }
+    if p, ok := peer.FromContext(ctx); !ok || p.Addr == nil || !trusted.Load().ContainsHost(p.Addr.String()) {
+        return Caller{}
+    }
+    c := Caller{UserID: first(md, HeaderUserID)}
+    switch r := models.Role(first(md, HeaderRole)); r {
+    case models.RoleCustomer, models.RoleCollector, models.RoleAdmin:
+        c.Role = r
+    }
+    return c
+}
+
+// NewOutgoingContext attaches c to calls made with the returned context
+func NewOutgoingContext(ctx context.Context, c Caller) context.Context {
+    return metadata.AppendToOutgoingContext(ctx, HeaderUserID, c.UserID, HeaderRole, string(c.Role))
+}
+
+func first(md metadata.MD, key string) string {
+    if v := md.Get(key); len(v) > 0 {
+        return v[0]
+    }
+    return ""
+}
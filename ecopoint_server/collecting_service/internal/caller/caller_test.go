@@ -0,0 +1,40 @@
+package caller
+
+import (
+    "context"
+    "net"
+    "net/netip"
+    "testing"
+
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+func from(addr string) context.Context {
+    ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 40000}})
+    return metadata.NewIncomingContext(ctx, metadata.Pairs(HeaderUserID, "u1", HeaderRole, "admin"))
+}
+
+func TestFromContextTrustsOnlyProxies(t *testing.T) {
+    if c := FromContext(from("127.0.0.1")); c.UserID != "u1" || c.Role != models.RoleAdmin {
+        t.Fatalf("loopback is trusted by default, got %+v", c)
+    }
+    if c := FromContext(from("203.0.113.7")); !c.Anonymous() || c.Role != "" {
+        t.Fatalf("a direct caller must be anonymous, got %+v", c)
+    }
+    ctx := metadata.NewIncomingContext(context.Background(), metadata.Pairs(HeaderUserID, "u1"))
+    if c := FromContext(ctx); !c.Anonymous() {
+        t.Fatalf("a call without a peer must be anonymous, got %+v", c)
+    }
+
+    Trust(Proxies{netip.MustParsePrefix("10.0.0.0/8")})
+    t.Cleanup(func() { Trust(loopback) })
+    if c := FromContext(from("10.1.2.3")); c.UserID != "u1" {
+        t.Fatalf("configured proxy not trusted, got %+v", c)
+    }
+    if c := FromContext(from("127.0.0.1")); !c.Anonymous() {
+        t.Fatalf("Trust replaces the defaults, got %+v", c)
+    }
+}
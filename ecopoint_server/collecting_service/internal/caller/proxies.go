@@ -0,0 +1,47 @@
+package caller
+
+import (
+    "fmt"
+    "net"
+    "net/netip"
+)
+
+// Proxies are the peers whose identity and x-forwarded-for headers are believed
+type Proxies []netip.Prefix
+
+// ParseProxies accepts CIDRs and single addresses
+func ParseProxies(list []string) (Proxies, error) {
+    var ps Proxies
+    for _, s := range list {
+        p, err := netip.ParsePrefix(s)
+        if err != nil {
+            a, aerr := netip.ParseAddr(s)
+            if aerr != nil {
+                return nil, fmt.Errorf("trusted proxy %q: %w", s, err)
+            }
+            p = netip.PrefixFrom(a, a.BitLen())
+        }
+        ps = append(ps, p.Masked())
+    }
+    return ps, nil
+}
+
+func (ps Proxies) Contains(a netip.Addr) bool {
+    a = a.Unmap()
+    for _, p := range ps {
+        if p.Contains(a) {
+            return true
+        }
+    }
+    return false
+}
+
+// ContainsHost reports whether addr, a host or host:port, is a trusted proxy
+func (ps Proxies) ContainsHost(addr string) bool {
+    host, _, err := net.SplitHostPort(addr)
+    if err != nil {
+        host = addr
+    }
+    a, err := netip.ParseAddr(host)
+    return err == nil && ps.Contains(a)
+}
@@ -1,39 +1,567 @@
+// Package config builds the service configuration in layers: Default, then the YAML file
+// named by -config or CONFIG_FILE, then environment variables (and .env), then flags.
+// Keys in the file and in -set are the yaml tags below, e.g. pricing.per_kg or watchdog.action;
+// durations are strings such as "90s" or "2h".
 package config
 
 import (
+    "bytes"
+    "errors"
+    "flag"
+    "fmt"
+    "io"
     "os"
     "strconv"
+    "strings"
+    "time"
 
     "github.com/joho/godotenv"
+    "gopkg.in/yaml.v3"
+
+    "ecopoint/collecting_service/internal/account"
+    "ecopoint/collecting_service/internal/blob"
+    "ecopoint/collecting_service/internal/metrics"
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/notify"
+    "ecopoint/collecting_service/internal/privacy"
+    "ecopoint/collecting_service/internal/ratelimit"
+    "ecopoint/collecting_service/internal/repository"
+    "ecopoint/collecting_service/internal/service"
+    "ecopoint/collecting_service/internal/tracing"
+    "ecopoint/collecting_service/internal/validation"
 )
 
+// Config is the whole service configuration. Fields tagged secret are masked by Redacted;
+// secret:"url" only masks the password of a connection string.
 type Config struct {
-    MongoURI        string
-    MongoDBName     string
-    OrdersTTLMinutes int
+    GRPCAddr         string                 `yaml:"grpc_addr"`
+    MetricsAddr      string                 `yaml:"metrics_addr"` // HTTP listener for /metrics; empty disables
+    PoolMetrics      metrics.PoolOptions    `yaml:"pool_metrics"`
+    HTTPAddr         string                 `yaml:"http_addr"`    // REST/JSON gateway, e.g. 127.0.0.1:8080 next to the BFF; empty disables
+    HTTPTrustedPeers []string               `yaml:"http_trusted_peers"` // CIDRs allowed to set X-User-Id/X-User-Role on the gateway, i.e. the BFF
+    CORSOrigins      []string               `yaml:"cors_origins"` // origins allowed to call the gateway from a browser; "*" allows any
+    MongoURI         string                 `yaml:"mongo_uri" secret:"url"`
+    MongoDBName      string                 `yaml:"mongo_db"`
+    OrderTTL         time.Duration          `yaml:"order_ttl"` // created orders nobody accepts expire after this
+    OrderLimits      validation.Limits      `yaml:"order_limits"`
+    Pricing          service.Pricing        `yaml:"pricing"`
+    Pages            service.PageSizes      `yaml:"page_sizes"`
+    IdempotencyTTL   time.Duration          `yaml:"idempotency_ttl"`
+    TimeZone         string                 `yaml:"time_zone"` // IANA zone for day/week/month reports
+    Watchdog         service.WatchdogPolicy `yaml:"watchdog"`
+    WatchdogInterval time.Duration          `yaml:"watchdog_interval"`
+    Penalties        service.PenaltyPolicy  `yaml:"penalties"`
+    Cancel           service.CancelPolicy   `yaml:"cancel"`
+    Quotas           service.QuotaPolicy    `yaml:"quotas"`
+    RateLimit        ratelimit.Options      `yaml:"rate_limit"`
+    Notify           NotifyConfig           `yaml:"notify"`
+    Account          AccountConfig          `yaml:"account"`
+    Relay            RelayConfig            `yaml:"relay"`
+    Blob             BlobConfig             `yaml:"blob"`
+    Shutdown         ShutdownConfig         `yaml:"shutdown"`
+    Log              LogConfig              `yaml:"log"`
+    Trace            tracing.Options        `yaml:"trace"`
+}
+
+// LogConfig selects the slog handler; phone numbers and secrets are always masked
+type LogConfig struct {
+    Level  string `yaml:"level"`  // debug | info | warn | error
+    Format string `yaml:"format"` // text | json
+}
+
+// ShutdownConfig controls health reporting and the drain on SIGTERM
+type ShutdownConfig struct {
+    Grace          time.Duration `yaml:"grace"`           // how long in-flight calls may run before they are cut off
+    Delay          time.Duration `yaml:"delay"`           // NOT_SERVING is reported this long before draining starts
+    HealthInterval time.Duration `yaml:"health_interval"` // how often dependencies are checked
+}
+
+// BlobConfig selects where attachment files are kept
+type BlobConfig struct {
+    Driver string        `yaml:"driver"` // fs | s3 | none
+    Dir    string        `yaml:"dir"`    // root of the fs driver
+    S3     blob.S3Config `yaml:"s3"`
+    URLTTL time.Duration `yaml:"url_ttl"` // lifetime of signed upload and download URLs
 }
 
-func Load() *Config {
+// RelayConfig selects who hands out the masked numbers collectors call customers on
+type RelayConfig struct {
+    Driver string                  `yaml:"driver"` // http | local | none; local forwards nothing and is for development only
+    Prefix string                  `yaml:"prefix"` // prefix of local driver numbers
+    HTTP   privacy.HTTPRelayConfig `yaml:"http"`
+}
+
+// AccountConfig points at AccountService; an empty Addr disables snapshot enrichment
+type AccountConfig struct {
+    Addr    string          `yaml:"addr"`
+    Options account.Options `yaml:"options"`
+}
+
+// NotifyConfig selects how push notifications are delivered
+type NotifyConfig struct {
+    Driver         string             `yaml:"driver"`          // fcm | file | none
+    File           string             `yaml:"file"`            // output of the file driver; "-" is stdout
+    FCMCredentials string             `yaml:"fcm_credentials"` // service account key for the fcm driver
+    DefaultLocale  string             `yaml:"default_locale"`
+    Retry          notify.RetryPolicy `yaml:"retry"`
+}
+
+// Default is the configuration before any file, variable or flag is applied
+func Default() *Config {
+    return &Config{
+        GRPCAddr:         ":50052",
+        MetricsAddr:      ":9090",
+        PoolMetrics:      metrics.DefaultPoolOptions(),
+        HTTPTrustedPeers: []string{"127.0.0.0/8", "::1/128"},
+        MongoURI:         "mongodb://localhost:27017",
+        MongoDBName:      "ecopoint",
+        OrderTTL:         repository.DefaultOrderTTL,
+        OrderLimits:      validation.DefaultLimits(),
+        Pricing:          service.DefaultPricing(),
+        Pages:            service.DefaultPageSizes(),
+        IdempotencyTTL:   service.DefaultIdempotencyTTL,
+        TimeZone:         "Asia/Ho_Chi_Minh",
+        Watchdog:         service.DefaultWatchdogPolicy(),
+        WatchdogInterval: time.Minute,
+        Penalties:        service.DefaultPenaltyPolicy(),
+        Cancel:           service.DefaultCancelPolicy(),
+        Quotas:           service.DefaultQuotaPolicy(),
+        RateLimit:        ratelimit.DefaultOptions(),
+        Notify: NotifyConfig{
+            Driver:        "file",
+            File:          "-",
+            DefaultLocale: notify.DefaultLocale,
+            Retry:         notify.DefaultRetryPolicy(),
+        },
+        Account: AccountConfig{
+            Addr:    "localhost:50051",
+            Options: account.Options{Timeout: 2 * time.Second, CacheTTL: 5 * time.Minute, StaleTTL: 24 * time.Hour},
+        },
+        Relay: RelayConfig{Driver: "none", HTTP: privacy.HTTPRelayConfig{Timeout: 5 * time.Second}},
+        Blob: BlobConfig{
+            Driver: "fs",
+            Dir:    "data/attachments",
+            S3:     blob.S3Config{Endpoint: "https://s3.amazonaws.com"},
+            URLTTL: service.DefaultAttachmentURLTTL,
+        },
+        Shutdown: ShutdownConfig{Grace: 20 * time.Second, HealthInterval: 10 * time.Second},
+        Log:      LogConfig{Level: "info", Format: "text"},
+        Trace:    tracing.Options{Exporter: "none", Endpoint: "localhost:4317", SampleRatio: 1, ServiceName: "collecting_service"},
+    }
+}
+
+// Load is the configuration without a flag layer, for tools that take no config flags
+func Load() (*Config, error) {
+    return (&Flags{}).Load()
+}
+
+// Flags is the command-line layer. Bind registers it before flag.Parse; Load applies it last.
+type Flags struct {
+    File        string
+    GRPCAddr    string
+    MetricsAddr string
+    HTTPAddr    string
+    MongoURI    string
+    MongoDB     string
+    Set         []string // key.path=value
+}
+
+func Bind(fs *flag.FlagSet) *Flags {
+    f := &Flags{}
+    fs.StringVar(&f.File, "config", "", "YAML config file (default $CONFIG_FILE)")
+    fs.StringVar(&f.GRPCAddr, "grpc-addr", "", "gRPC listen address")
+    fs.StringVar(&f.MetricsAddr, "metrics-addr", "", "HTTP listen address of /metrics (\"none\" disables)")
+    fs.StringVar(&f.HTTPAddr, "http-addr", "", "HTTP listen address of the REST gateway (\"none\" disables)")
+    fs.StringVar(&f.MongoURI, "mongo-uri", "", "MongoDB connection string")
+    fs.StringVar(&f.MongoDB, "mongo-db", "", "MongoDB database name")
+    fs.Func("set", "override one setting, e.g. -set pricing.per_kg=2500 (repeatable)", func(v string) error {
+        if k, _, ok := strings.Cut(v, "="); !ok || k == "" {
+            return errors.New("want key.path=value")
+        }
+        f.Set = append(f.Set, v)
+        return nil
+    })
+    return f
+}
+
+// Load layers defaults, the file, the environment and f, then validates the result
+func (f *Flags) Load() (*Config, error) {
     _ = godotenv.Load()
-    ttl := 60
-    if v := os.Getenv("ORDERS_TTL_MINUTES"); v != "" {
-        if n, err := strconv.Atoi(v); err == nil && n > 0 {
-            ttl = n
+    c := Default()
+    path := f.File
+    if path == "" {
+        path = os.Getenv("CONFIG_FILE")
+    }
+    if path != "" {
+        if err := c.loadFile(path); err != nil {
+            return nil, err
         }
     }
-    dbName := os.Getenv("MONGO_DB_NAME")
-    if dbName == "" {
-        dbName = "ecopoint"
+    if err := c.applyEnv(); err != nil {
+        return nil, fmt.Errorf("environment: %w", err)
     }
-    uri := os.Getenv("MONGO_URI")
-    if uri == "" {
-        uri = "mongodb://localhost:27017/" + dbName
+    if f.GRPCAddr != "" {
+        c.GRPCAddr = f.GRPCAddr
     }
-    return &Config{
-        MongoURI: uri,
-        MongoDBName: dbName,
-        OrdersTTLMinutes: ttl,
+    switch f.MetricsAddr {
+    case "":
+    case "none":
+        c.MetricsAddr = ""
+    default:
+        c.MetricsAddr = f.MetricsAddr
+    }
+    switch f.HTTPAddr {
+    case "":
+    case "none":
+        c.HTTPAddr = ""
+    default:
+        c.HTTPAddr = f.HTTPAddr
+    }
+    if f.MongoURI != "" {
+        c.MongoURI = f.MongoURI
+    }
+    if f.MongoDB != "" {
+        c.MongoDBName = f.MongoDB
     }
+    for _, kv := range f.Set {
+        if err := c.set(kv); err != nil {
+            return nil, err
+        }
+    }
+    if err := c.Validate(); err != nil {
+        return nil, fmt.Errorf("invalid config: %w", err)
+    }
+    return c, nil
 }
 
+func (c *Config) loadFile(path string) error {
+    f, err := os.Open(path)
+    if err != nil {
+        return fmt.Errorf("config file: %w", err)
+    }
+    defer f.Close()
+    if err := decode(f, c); err != nil {
+        return fmt.Errorf("config file %s: %w", path, err)
+    }
+    return nil
+}
 
+// decode overlays YAML on c: absent keys keep their value, maps are merged, unknown keys fail
+func decode(r io.Reader, c *Config) error {
+    dec := yaml.NewDecoder(r)
+    dec.KnownFields(true)
+    if err := dec.Decode(c); err != nil && !errors.Is(err, io.EOF) {
+        return err
+    }
+    return nil
+}
+
+// set applies one key.path=value; the value is YAML, so lists are written as [a, b]
+func (c *Config) set(kv string) error {
+    key, val, _ := strings.Cut(kv, "=")
+    node := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str"}
+    if val != "" {
+        var doc yaml.Node
+        if err := yaml.Unmarshal([]byte(val), &doc); err != nil {
+            return fmt.Errorf("-set %s: %w", key, err)
+        }
+        node = doc.Content[0]
+    }
+    parts := strings.Split(key, ".")
+    for i := len(parts) - 1; i >= 0; i-- {
+        node = &yaml.Node{Kind: yaml.MappingNode, Content: []*yaml.Node{{Kind: yaml.ScalarNode, Value: parts[i]}, node}}
+    }
+    b, err := yaml.Marshal(node)
+    if err != nil {
+        return fmt.Errorf("-set %s: %w", key, err)
+    }
+    if err := decode(bytes.NewReader(b), c); err != nil {
+        return fmt.Errorf("-set %s: %w", key, err)
+    }
+    return nil
+}
+
+// applyEnv overlays every variable that is set. Unset variables leave the value alone, so a
+// duration from the file keeps its precision; malformed values are all reported together.
+func (c *Config) applyEnv() error {
+    e := &env{}
+    e.str("GRPC_ADDR", &c.GRPCAddr)
+    e.str("METRICS_ADDR", &c.MetricsAddr)
+    if c.MetricsAddr == "none" {
+        c.MetricsAddr = ""
+    }
+    e.str("HTTP_ADDR", &c.HTTPAddr)
+    if c.HTTPAddr == "none" {
+        c.HTTPAddr = ""
+    }
+    e.list("HTTP_TRUSTED_PEERS", &c.HTTPTrustedPeers)
+    e.list("CORS_ORIGINS", &c.CORSOrigins)
+    e.str("MONGO_URI", &c.MongoURI)
+    e.str("MONGO_DB_NAME", &c.MongoDBName)
+    e.duration("ORDERS_TTL_MINUTES", time.Minute, &c.OrderTTL)
+    loadOrderLimits(e, &c.OrderLimits)
+    e.float("PRICE_BASE", &c.Pricing.Base)
+    e.float("PRICE_PER_KG", &c.Pricing.PerKg)
+    e.float("PRICE_PER_KM", &c.Pricing.PerKm)
+    e.float("AVG_SPEED_KMH", &c.Pricing.AvgSpeedKmH)
+    e.int("PAGE_SIZE_DEFAULT", &c.Pages.Default)
+    e.int("PAGE_SIZE_MAX", &c.Pages.Max)
+    e.duration("IDEMPOTENCY_TTL_HOURS", time.Hour, &c.IdempotencyTTL)
+    e.str("TIME_ZONE", &c.TimeZone)
+    loadWatchdogPolicy(e, &c.Watchdog)
+    e.duration("WATCHDOG_INTERVAL_SECONDS", time.Second, &c.WatchdogInterval)
+    loadPenaltyPolicy(e, &c.Penalties)
+    loadCancelPolicy(e, &c.Cancel)
+    e.int("ORDER_MAX_OPEN", &c.Quotas.MaxOpenOrders)
+    loadRateLimit(e, &c.RateLimit)
+    loadNotifyConfig(e, &c.Notify)
+    loadAccountConfig(e, &c.Account)
+    loadRelayConfig(e, &c.Relay)
+    loadBlobConfig(e, &c.Blob)
+    e.duration("SHUTDOWN_GRACE_SECONDS", time.Second, &c.Shutdown.Grace)
+    e.duration("SHUTDOWN_DELAY_SECONDS", time.Second, &c.Shutdown.Delay)
+    e.duration("HEALTH_CHECK_SECONDS", time.Second, &c.Shutdown.HealthInterval)
+    e.str("LOG_LEVEL", &c.Log.Level)
+    e.str("LOG_FORMAT", &c.Log.Format)
+    loadTraceConfig(e, &c.Trace)
+    return errors.Join(e.errs...)
+}
+
+// loadTraceConfig reads the standard OTEL_TRACES_EXPORTER, OTEL_EXPORTER_OTLP_ENDPOINT,
+// OTEL_EXPORTER_OTLP_INSECURE, OTEL_TRACES_SAMPLER_ARG and OTEL_SERVICE_NAME
+func loadTraceConfig(e *env, c *tracing.Options) {
+    e.str("OTEL_TRACES_EXPORTER", &c.Exporter)
+    e.str("OTEL_EXPORTER_OTLP_ENDPOINT", &c.Endpoint)
+    e.bool("OTEL_EXPORTER_OTLP_INSECURE", &c.Insecure)
+    e.float("OTEL_TRACES_SAMPLER_ARG", &c.SampleRatio)
+    e.str("OTEL_SERVICE_NAME", &c.ServiceName)
+}
+
+// loadRateLimit reads RATE_LIMIT_ENABLED, RATE_LIMIT_USER_PER_MINUTE, RATE_LIMIT_IP_PER_MINUTE
+// and TRUSTED_PROXIES (comma list of CIDRs); method budgets come from the file or -set
+func loadRateLimit(e *env, o *ratelimit.Options) {
+    e.bool("RATE_LIMIT_ENABLED", &o.Enabled)
+    e.float("RATE_LIMIT_USER_PER_MINUTE", &o.User.PerMinute)
+    e.float("RATE_LIMIT_IP_PER_MINUTE", &o.IP.PerMinute)
+    e.list("TRUSTED_PROXIES", &o.TrustedProxies)
+}
+
+// loadBlobConfig reads BLOB_STORE, BLOB_DIR, ATTACHMENT_URL_TTL_MINUTES and, for the s3 driver,
+// S3_ENDPOINT, S3_REGION, S3_BUCKET, S3_ACCESS_KEY, S3_SECRET_KEY and S3_PATH_STYLE
+func loadBlobConfig(e *env, c *BlobConfig) {
+    e.str("BLOB_STORE", &c.Driver)
+    e.str("BLOB_DIR", &c.Dir)
+    e.str("S3_ENDPOINT", &c.S3.Endpoint)
+    e.str("S3_REGION", &c.S3.Region)
+    e.str("S3_BUCKET", &c.S3.Bucket)
+    e.str("S3_ACCESS_KEY", &c.S3.AccessKey)
+    e.str("S3_SECRET_KEY", &c.S3.SecretKey)
+    e.bool("S3_PATH_STYLE", &c.S3.PathStyle)
+    e.duration("ATTACHMENT_URL_TTL_MINUTES", time.Minute, &c.URLTTL)
+}
+
+// loadRelayConfig reads RELAY_DRIVER, RELAY_NUMBER_PREFIX, RELAY_URL, RELAY_API_KEY and RELAY_TIMEOUT_MS
+func loadRelayConfig(e *env, c *RelayConfig) {
+    e.str("RELAY_DRIVER", &c.Driver)
+    e.str("RELAY_NUMBER_PREFIX", &c.Prefix)
+    e.str("RELAY_URL", &c.HTTP.Endpoint)
+    e.str("RELAY_API_KEY", &c.HTTP.APIKey)
+    e.duration("RELAY_TIMEOUT_MS", time.Millisecond, &c.HTTP.Timeout)
+}
+
+// loadAccountConfig reads ACCOUNT_GRPC_ADDR ("none" disables), ACCOUNT_TIMEOUT_MS,
+// ACCOUNT_CACHE_SECONDS and ACCOUNT_STALE_MINUTES
+func loadAccountConfig(e *env, c *AccountConfig) {
+    e.str("ACCOUNT_GRPC_ADDR", &c.Addr)
+    if c.Addr == "none" {
+        c.Addr = ""
+    }
+    e.duration("ACCOUNT_TIMEOUT_MS", time.Millisecond, &c.Options.Timeout)
+    e.duration("ACCOUNT_CACHE_SECONDS", time.Second, &c.Options.CacheTTL)
+    e.duration("ACCOUNT_STALE_MINUTES", time.Minute, &c.Options.StaleTTL)
+}
+
+// loadNotifyConfig reads NOTIFIER, NOTIFY_FILE, FCM_CREDENTIALS_FILE (or GOOGLE_APPLICATION_CREDENTIALS),
+// NOTIFY_DEFAULT_LOCALE, NOTIFY_MAX_ATTEMPTS, NOTIFY_RETRY_BASE_MS and NOTIFY_RETRY_MAX_SECONDS
+func loadNotifyConfig(e *env, c *NotifyConfig) {
+    e.str("NOTIFIER", &c.Driver)
+    e.str("NOTIFY_FILE", &c.File)
+    e.str("GOOGLE_APPLICATION_CREDENTIALS", &c.FCMCredentials)
+    e.str("FCM_CREDENTIALS_FILE", &c.FCMCredentials)
+    e.str("NOTIFY_DEFAULT_LOCALE", &c.DefaultLocale)
+    e.int("NOTIFY_MAX_ATTEMPTS", &c.Retry.MaxAttempts)
+    e.duration("NOTIFY_RETRY_BASE_MS", time.Millisecond, &c.Retry.BaseDelay)
+    e.duration("NOTIFY_RETRY_MAX_SECONDS", time.Second, &c.Retry.MaxDelay)
+}
+
+// loadCancelPolicy reads CANCEL_FREE_MINUTES, CANCEL_FEE_ACCEPTED and CANCEL_FEE_ON_WAY (points)
+func loadCancelPolicy(e *env, p *service.CancelPolicy) {
+    e.duration("CANCEL_FREE_MINUTES", time.Minute, &p.FreeWindow)
+    e.float("CANCEL_FEE_ACCEPTED", &p.AcceptedFee)
+    e.float("CANCEL_FEE_ON_WAY", &p.OnWayFee)
+}
+
+// loadPenaltyPolicy reads PENALTY_WINDOW_DAYS, PENALTY_LATE_GRACE_MINUTES,
+// PENALTY_WEIGHTS (e.g. "cancelled=1,no_show=2") and PENALTY_TIERS (points=minutes, e.g. "3=60,5=1440")
+func loadPenaltyPolicy(e *env, p *service.PenaltyPolicy) {
+    e.duration("PENALTY_WINDOW_DAYS", 24*time.Hour, &p.Window)
+    e.duration("PENALTY_LATE_GRACE_MINUTES", time.Minute, &p.LateGrace)
+    if weights, ok := e.pairs("PENALTY_WEIGHTS"); ok {
+        if p.Weights == nil {
+            p.Weights = map[models.StrikeKind]int{}
+        }
+        for k, n := range weights {
+            if n != float64(int(n)) {
+                e.bad("PENALTY_WEIGHTS", os.Getenv("PENALTY_WEIGHTS"), "want whole weights")
+                continue
+            }
+            p.Weights[models.StrikeKind(k)] = int(n)
+        }
+    }
+    if tiers, ok := e.pairs("PENALTY_TIERS"); ok {
+        p.Tiers = nil
+        for k, n := range tiers {
+            pts, err := strconv.Atoi(k)
+            if err != nil || pts <= 0 || n != float64(int(n)) {
+                e.bad("PENALTY_TIERS", os.Getenv("PENALTY_TIERS"), "want points=minutes with whole positive points")
+                continue
+            }
+            p.Tiers = append(p.Tiers, service.PenaltyTier{Points: pts, Cooldown: time.Duration(n) * time.Minute})
+        }
+    }
+}
+
+// loadWatchdogPolicy reads WATCHDOG_ACCEPT_TIMEOUT_MINUTES, WATCHDOG_ONWAY_TIMEOUT_MINUTES
+// (0 disables a stage) and WATCHDOG_ACTION (release | cancel)
+func loadWatchdogPolicy(e *env, p *service.WatchdogPolicy) {
+    e.duration("WATCHDOG_ACCEPT_TIMEOUT_MINUTES", time.Minute, &p.AcceptTimeout)
+    e.duration("WATCHDOG_ONWAY_TIMEOUT_MINUTES", time.Minute, &p.OnWayTimeout)
+    if v := os.Getenv("WATCHDOG_ACTION"); v != "" {
+        p.Action = service.WatchdogAction(v)
+    }
+}
+
+// loadOrderLimits applies
+// ORDER_MAX_ITEMS, ORDER_MAX_NOTE_LENGTH, ORDER_WASTE_TYPES (comma list),
+// ORDER_MAX_WEIGHT_PER_TYPE (e.g. "plastic=50,paper=120"), ATTACHMENT_MAX_BYTES,
+// ATTACHMENT_TYPES (comma list) and ATTACHMENT_MAX_PER_ORDER
+func loadOrderLimits(e *env, l *validation.Limits) {
+    e.int("ORDER_MAX_ITEMS", &l.MaxItems)
+    e.int("ORDER_MAX_NOTE_LENGTH", &l.MaxNoteLength)
+    e.list("ORDER_WASTE_TYPES", &l.WasteTypes)
+    if caps, ok := e.pairs("ORDER_MAX_WEIGHT_PER_TYPE"); ok {
+        l.MaxWeightPerType = caps
+    }
+    e.int64("ATTACHMENT_MAX_BYTES", &l.MaxAttachmentSize)
+    e.list("ATTACHMENT_TYPES", &l.AttachmentTypes)
+    e.int("ATTACHMENT_MAX_PER_ORDER", &l.MaxAttachments)
+}
+
+// env reads the variables of applyEnv. Each setter changes its target only when the variable is
+// set to a non-empty value; a malformed value leaves the target alone and is kept in errs.
+type env struct {
+    errs []error
+}
+
+func (e *env) bad(key, v, want string) {
+    e.errs = append(e.errs, fmt.Errorf("%s=%q: %s", key, v, want))
+}
+
+func (e *env) str(key string, dst *string) {
+    if v := os.Getenv(key); v != "" {
+        *dst = v
+    }
+}
+
+func (e *env) list(key string, dst *[]string) {
+    if v := os.Getenv(key); v != "" {
+        *dst = splitList(v)
+    }
+}
+
+func (e *env) bool(key string, dst *bool) {
+    if v := os.Getenv(key); v != "" {
+        b, err := strconv.ParseBool(v)
+        if err != nil {
+            e.bad(key, v, "want true or false")
+            return
+        }
+        *dst = b
+    }
+}
+
+// int accepts a non-negative integer
+func (e *env) int(key string, dst *int) {
+    var n int64
+    if e.count(key, &n) {
+        *dst = int(n)
+    }
+}
+
+func (e *env) int64(key string, dst *int64) {
+    e.count(key, dst)
+}
+
+func (e *env) count(key string, dst *int64) bool {
+    v := os.Getenv(key)
+    if v == "" {
+        return false
+    }
+    n, err := strconv.ParseInt(v, 10, 64)
+    if err != nil || n < 0 {
+        e.bad(key, v, "want a non-negative integer")
+        return false
+    }
+    *dst = n
+    return true
+}
+
+// float accepts a non-negative number
+func (e *env) float(key string, dst *float64) {
+    if v := os.Getenv(key); v != "" {
+        f, err := strconv.ParseFloat(v, 64)
+        if err != nil || f < 0 {
+            e.bad(key, v, "want a non-negative number")
+            return
+        }
+        *dst = f
+    }
+}
+
+// duration reads a whole number of unit, e.g. ORDERS_TTL_MINUTES=90
+func (e *env) duration(key string, unit time.Duration, dst *time.Duration) {
+    var n int64
+    if e.count(key, &n) {
+        *dst = time.Duration(n) * unit
+    }
+}
+
+// pairs parses "key=n,key=n" with non-negative numbers; ok is false when the variable is
+// unset or any entry is malformed
+func (e *env) pairs(key string) (map[string]float64, bool) {
+    v := os.Getenv(key)
+    if v == "" {
+        return nil, false
+    }
+    res := map[string]float64{}
+    for _, pair := range splitList(v) {
+        k, n, ok := strings.Cut(pair, "=")
+        f, err := strconv.ParseFloat(strings.TrimSpace(n), 64)
+        if !ok || strings.TrimSpace(k) == "" || err != nil || f < 0 {
+            e.bad(key, v, "want key=n pairs separated by commas")
+            return nil, false
+        }
+        res[strings.TrimSpace(k)] = f
+    }
+    return res, true
+}
+
+func splitList(v string) []string {
+    var res []string
+    for _, p := range strings.Split(v, ",") {
+        if p = strings.TrimSpace(p); p != "" {
+            res = append(res, p)
+        }
+    }
+    return res
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+    "bytes"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/ratelimit"
+    "ecopoint/collecting_service/internal/service"
+)
+
+func writeFile(t *testing.T, body string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "config.yaml")
+    if err := os.WriteFile(path, []byte(body), 0o600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadLayers(t *testing.T) {
+    path := writeFile(t, `
+grpc_addr: ":6000"
+mongo_db: from_file
+order_ttl: 45m
+watchdog_interval: 90s
+shutdown:
+  grace: 1500ms
+pricing:
+  per_kg: 1500
+watchdog:
+  action: cancel
+penalties:
+  weights:
+    no_show: 4
+`)
+    t.Setenv("CONFIG_FILE", "")
+    t.Setenv("MONGO_DB_NAME", "from_env")
+    t.Setenv("PRICE_PER_KG", "1800")
+    t.Setenv("PAGE_SIZE_MAX", "50")
+
+    f := &Flags{File: path, MongoDB: "from_flag", Set: []string{"pricing.per_kg=2000", "relay.prefix=+84", "order_limits.waste_types=[paper, metal]"}}
+    c, err := f.Load()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if c.GRPCAddr != ":6000" || c.OrderTTL != 45*time.Minute || c.Watchdog.Action != service.WatchdogCancel {
+        t.Fatalf("file layer not applied: %q %s %q", c.GRPCAddr, c.OrderTTL, c.Watchdog.Action)
+    }
+    if c.MongoDBName != "from_flag" || c.Pricing.PerKg != 2000 || c.Pages.Max != 50 {
+        t.Fatalf("expected flags over env over file, got %q %v %d", c.MongoDBName, c.Pricing.PerKg, c.Pages.Max)
+    }
+    if c.Relay.Prefix != "+84" || strings.Join(c.OrderLimits.WasteTypes, ",") != "paper,metal" {
+        t.Fatalf("-set values not applied: %q %v", c.Relay.Prefix, c.OrderLimits.WasteTypes)
+    }
+    // maps from the file merge into the defaults
+    def := service.DefaultPenaltyPolicy()
+    if c.Penalties.Weights[models.StrikeNoShow] != 4 || c.Penalties.Weights[models.StrikeCancelled] != def.Weights[models.StrikeCancelled] {
+        t.Fatalf("unexpected weights %v", c.Penalties.Weights)
+    }
+    if c.Pricing.Base != service.DefaultPricing().Base {
+        t.Fatalf("unset keys must keep their defaults, got base %v", c.Pricing.Base)
+    }
+    // no variable is set for these, so the file's precision survives the env layer
+    if c.WatchdogInterval != 90*time.Second || c.Shutdown.Grace != 1500*time.Millisecond {
+        t.Fatalf("env layer rounded file durations: %s %s", c.WatchdogInterval, c.Shutdown.Grace)
+    }
+}
+
+func TestLoadRejectsMalformedEnv(t *testing.T) {
+    t.Setenv("CONFIG_FILE", "")
+    t.Setenv("ORDERS_TTL_MINUTES", "1h")
+    t.Setenv("RATE_LIMIT_ENABLED", "yes")
+    t.Setenv("PRICE_BASE", "-5")
+    t.Setenv("PENALTY_TIERS", "3=60,five=10")
+    _, err := Load()
+    if err == nil {
+        t.Fatal("expected malformed variables to fail the load")
+    }
+    for _, key := range []string{"ORDERS_TTL_MINUTES", "RATE_LIMIT_ENABLED", "PRICE_BASE", "PENALTY_TIERS"} {
+        if !strings.Contains(err.Error(), key+"=") {
+            t.Errorf("missing %s in %v", key, err)
+        }
+    }
+}
+
+func TestLoadRejectsUnknownKeys(t *testing.T) {
+    t.Setenv("CONFIG_FILE", "")
+    if _, err := (&Flags{File: writeFile(t, "pricing:\n  per_kilo: 3\n")}).Load(); err == nil || !strings.Contains(err.Error(), "per_kilo") {
+        t.Fatalf("expected an unknown field error, got %v", err)
+    }
+    if _, err := (&Flags{Set: []string{"watchdog.timeout=5m"}}).Load(); err == nil {
+        t.Fatalf("expected -set with an unknown key to fail")
+    }
+}
+
+func TestValidateReportsEveryError(t *testing.T) {
+    c := Default()
+    c.MongoURI = "localhost:27017"
+    c.Pages.Default = 0
+    c.Watchdog.Action = "nuke"
+    c.Blob.Driver = "s3"
+    c.Relay.Driver = "http"
+    c.Shutdown.Grace = 0
+    c.Log.Format = "xml"
+    c.Trace.Exporter = "jaeger"
+    c.CORSOrigins = []string{"http://localhost:3000", "localhost:3000"}
+    c.RateLimit.TrustedProxies = []string{"10.0.0.0/8", "gateway"}
+    c.HTTPTrustedPeers = []string{"bff"}
+    c.RateLimit.Methods["SendChatMessage"] = ratelimit.Budget{PerMinute: 30}
+    c.RateLimit.Messages["CreateOrder"] = ratelimit.Budget{PerMinute: 30}
+    err := c.Validate()
+    if err == nil {
+        t.Fatal("expected errors")
+    }
+    for _, key := range []string{"mongo_uri", "page_sizes", "watchdog.action", "blob.s3", "relay.http.endpoint", "shutdown.grace", "log.format", "trace.exporter", "cors_origins", "http_trusted_peers", "rate_limit.trusted_proxies", "rate_limit.methods.SendChatMessage", "rate_limit.messages.CreateOrder"} {
+        if !strings.Contains(err.Error(), key+":") {
+            t.Errorf("missing %s in %v", key, err)
+        }
+    }
+    if err := Default().Validate(); err != nil {
+        t.Fatalf("defaults must be valid: %v", err)
+    }
+}
+
+func TestPrintRedactsSecrets(t *testing.T) {
+    c := Default()
+    c.MongoURI = "mongodb://app:hunter2@db:27017/?authSource=admin"
+    c.Blob.S3.AccessKey = "AKIDEXAMPLE"
+    c.Blob.S3.SecretKey = "wJalrXUtnFEMI"
+    c.Relay.HTTP.APIKey = "relay-key-1"
+    var out bytes.Buffer
+    if err := c.Print(&out); err != nil {
+        t.Fatal(err)
+    }
+    for _, secret := range []string{"hunter2", "AKIDEXAMPLE", "wJalrXUtnFEMI", "relay-key-1"} {
+        if strings.Contains(out.String(), secret) {
+            t.Fatalf("%s leaked:\n%s", secret, out.String())
+        }
+    }
+    if !strings.Contains(out.String(), "mongodb://app:REDACTED@db:27017") || !strings.Contains(out.String(), "order_ttl: 1h0m0s") {
+        t.Fatalf("unexpected output:\n%s", out.String())
+    }
+    if c.Blob.S3.SecretKey != "wJalrXUtnFEMI" {
+        t.Fatalf("Print must not modify the config")
+    }
+
+    // the printed form loads back to the same values
+    t.Setenv("CONFIG_FILE", "")
+    back, err := (&Flags{File: writeFile(t, out.String())}).Load()
+    if err != nil {
+        t.Fatal(err)
+    }
+    if back.OrderTTL != c.OrderTTL || back.Pricing != c.Pricing || back.Penalties.Tiers[0] != c.Penalties.Tiers[0] {
+        t.Fatalf("round trip changed values")
+    }
+}
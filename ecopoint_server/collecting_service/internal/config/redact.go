@@ -0,0 +1,57 @@
+package config
+
+import (
+    "io"
+    "net/url"
+    "reflect"
+
+    "gopkg.in/yaml.v3"
+)
+
+const masked = "REDACTED"
+
+// Redacted returns a copy with every secret field masked; only strings are ever rewritten,
+// so the maps and slices it still shares with c are left untouched
+func (c *Config) Redacted() Config {
+    out := *c
+    redact(reflect.ValueOf(&out).Elem())
+    return out
+}
+
+func redact(v reflect.Value) {
+    t := v.Type()
+    for i := 0; i < t.NumField(); i++ {
+        f, fv := t.Field(i), v.Field(i)
+        switch {
+        case fv.Kind() == reflect.Struct:
+            redact(fv)
+        case fv.Kind() != reflect.String || fv.String() == "":
+        case f.Tag.Get("secret") == "true":
+            fv.SetString(masked)
+        case f.Tag.Get("secret") == "url":
+            fv.SetString(redactURL(fv.String()))
+        }
+    }
+}
+
+// redactURL masks the password of a connection string and leaves the rest readable
+func redactURL(s string) string {
+    u, err := url.Parse(s)
+    if err != nil {
+        return masked
+    }
+    if _, ok := u.User.Password(); ok {
+        u.User = url.UserPassword(u.User.Username(), masked)
+    }
+    return u.String()
+}
+
+// Print writes the redacted configuration as YAML, in the format the config file accepts
+func (c *Config) Print(w io.Writer) error {
+    enc := yaml.NewEncoder(w)
+    enc.SetIndent(2)
+    if err := enc.Encode(c.Redacted()); err != nil {
+        return err
+    }
+    return enc.Close()
+}
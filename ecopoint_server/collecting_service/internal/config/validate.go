@@ -0,0 +1,203 @@
+package config
+
+import (
+    "errors"
+    "fmt"
+    "net/url"
+    "time"
+
+    "ecopoint/collecting_service/internal/caller"
+    "ecopoint/collecting_service/internal/logging"
+    "ecopoint/collecting_service/internal/ratelimit"
+    "ecopoint/collecting_service/internal/service"
+    pb "ecopoint/collecting_service/pb"
+)
+
+// Validate reports every invalid setting at once, each prefixed with its yaml key
+func (c *Config) Validate() error {
+    var errs []error
+    bad := func(key, format string, args ...any) {
+        errs = append(errs, fmt.Errorf("%s: "+format, append([]any{key}, args...)...))
+    }
+    positive := func(key string, d time.Duration) {
+        if d <= 0 {
+            bad(key, "must be positive")
+        }
+    }
+
+    if c.GRPCAddr == "" {
+        bad("grpc_addr", "is required")
+    }
+    if u, err := url.Parse(c.MongoURI); err != nil || (u.Scheme != "mongodb" && u.Scheme != "mongodb+srv") {
+        bad("mongo_uri", "must be a mongodb:// or mongodb+srv:// URI")
+    }
+    if c.MongoDBName == "" {
+        bad("mongo_db", "is required")
+    }
+    if _, err := caller.ParseProxies(c.HTTPTrustedPeers); err != nil {
+        bad("http_trusted_peers", "%v", err)
+    }
+    for _, o := range c.CORSOrigins {
+        if u, err := url.Parse(o); o != "*" && (err != nil || u.Scheme == "" || u.Host == "" || u.Path != "") {
+            bad("cors_origins", "%q must be \"*\" or scheme://host[:port]", o)
+        }
+    }
+    if c.PoolMetrics.MaxAge < 0 {
+        bad("pool_metrics.max_age", "must not be negative")
+    }
+    positive("order_ttl", c.OrderTTL)
+    positive("idempotency_ttl", c.IdempotencyTTL)
+    if _, err := time.LoadLocation(c.TimeZone); err != nil {
+        bad("time_zone", "%v", err)
+    }
+
+    l := c.OrderLimits
+    if l.MaxItems <= 0 {
+        bad("order_limits.max_items", "must be positive")
+    }
+    if l.MaxNoteLength <= 0 {
+        bad("order_limits.max_note_length", "must be positive")
+    }
+    if l.WeightTolerance < 0 {
+        bad("order_limits.weight_tolerance", "must not be negative")
+    }
+    if l.MaxAttachmentSize <= 0 {
+        bad("order_limits.max_attachment_size", "must be positive")
+    }
+    for k, w := range l.MaxWeightPerType {
+        if w <= 0 {
+            bad("order_limits.max_weight_per_type."+k, "must be positive")
+        }
+    }
+
+    p := c.Pricing
+    if p.Base < 0 || p.PerKg < 0 || p.PerKm < 0 || p.AvgSpeedKmH < 0 {
+        bad("pricing", "factors must not be negative")
+    }
+    if c.Pages.Default <= 0 || c.Pages.Max < c.Pages.Default {
+        bad("page_sizes", "need 0 < default <= max, got %d and %d", c.Pages.Default, c.Pages.Max)
+    }
+
+    switch c.Watchdog.Action {
+    case service.WatchdogRelease, service.WatchdogCancel:
+    default:
+        bad("watchdog.action", "must be %s or %s, got %q", service.WatchdogRelease, service.WatchdogCancel, c.Watchdog.Action)
+    }
+    if c.Watchdog.AcceptTimeout < 0 || c.Watchdog.OnWayTimeout < 0 {
+        bad("watchdog", "timeouts must not be negative")
+    }
+    if c.Watchdog.BatchSize <= 0 {
+        bad("watchdog.batch_size", "must be positive")
+    }
+    positive("watchdog_interval", c.WatchdogInterval)
+    positive("penalties.window", c.Penalties.Window)
+    for _, t := range c.Penalties.Tiers {
+        if t.Points <= 0 || t.Cooldown <= 0 {
+            bad("penalties.tiers", "points and cooldown must be positive")
+            break
+        }
+    }
+    if c.Cancel.AcceptedFee < 0 || c.Cancel.OnWayFee < 0 {
+        bad("cancel", "fees must not be negative")
+    }
+    if c.Quotas.MaxOpenOrders < 0 {
+        bad("quotas.max_open_orders", "must not be negative")
+    }
+
+    budget := func(key string, b ratelimit.Budget) {
+        if b.PerMinute < 0 || b.Burst < 0 {
+            bad(key, "per_minute and burst must not be negative")
+        }
+    }
+    budget("rate_limit.user", c.RateLimit.User)
+    budget("rate_limit.ip", c.RateLimit.IP)
+    rpcs, streams := map[string]bool{}, map[string]bool{}
+    for _, m := range pb.CollectingService_ServiceDesc.Methods {
+        rpcs[m.MethodName] = true
+    }
+    for _, st := range pb.CollectingService_ServiceDesc.Streams {
+        rpcs[st.StreamName], streams[st.StreamName] = true, st.ClientStreams
+    }
+    for m, b := range c.RateLimit.Methods {
+        budget("rate_limit.methods."+m, b)
+        if !rpcs[m] {
+            bad("rate_limit.methods."+m, "no such RPC")
+        }
+    }
+    for m, b := range c.RateLimit.Messages {
+        budget("rate_limit.messages."+m, b)
+        if !streams[m] {
+            bad("rate_limit.messages."+m, "no such client streaming RPC")
+        }
+    }
+    if _, err := ratelimit.New(c.RateLimit); err != nil {
+        bad("rate_limit.trusted_proxies", "%v", err)
+    }
+
+    switch c.Notify.Driver {
+    case "fcm":
+        if c.Notify.FCMCredentials == "" {
+            bad("notify.fcm_credentials", "is required by the fcm driver")
+        }
+    case "file":
+        if c.Notify.File == "" {
+            bad("notify.file", "is required by the file driver")
+        }
+    case "none":
+    default:
+        bad("notify.driver", "must be fcm, file or none, got %q", c.Notify.Driver)
+    }
+
+    switch c.Relay.Driver {
+    case "http":
+        if u, err := url.Parse(c.Relay.HTTP.Endpoint); err != nil || u.Host == "" {
+            bad("relay.http.endpoint", "must be an absolute URL")
+        }
+        positive("relay.http.timeout", c.Relay.HTTP.Timeout)
+    case "local", "none":
+    default:
+        bad("relay.driver", "must be http, local or none, got %q", c.Relay.Driver)
+    }
+
+    switch c.Blob.Driver {
+    case "fs":
+        if c.Blob.Dir == "" {
+            bad("blob.dir", "is required by the fs driver")
+        }
+    case "s3":
+        if c.Blob.S3.Bucket == "" || c.Blob.S3.AccessKey == "" || c.Blob.S3.SecretKey == "" {
+            bad("blob.s3", "bucket, access_key and secret_key are required by the s3 driver")
+        }
+        if u, err := url.Parse(c.Blob.S3.Endpoint); err != nil || u.Host == "" {
+            bad("blob.s3.endpoint", "must be an absolute URL")
+        }
+    case "none":
+    default:
+        bad("blob.driver", "must be fs, s3 or none, got %q", c.Blob.Driver)
+    }
+    positive("blob.url_ttl", c.Blob.URLTTL)
+
+    positive("shutdown.grace", c.Shutdown.Grace)
+    if c.Shutdown.Delay < 0 || c.Shutdown.HealthInterval < 0 {
+        bad("shutdown", "delay and health_interval must not be negative")
+    }
+    if _, err := logging.ParseLevel(c.Log.Level); err != nil {
+        bad("log.level", "must be debug, info, warn or error, got %q", c.Log.Level)
+    }
+    if c.Log.Format != "text" && c.Log.Format != "json" {
+        bad("log.format", "must be text or json, got %q", c.Log.Format)
+    }
+    switch c.Trace.Exporter {
+    case "otlp":
+        if c.Trace.Endpoint == "" {
+            bad("trace.endpoint", "is required by the otlp exporter")
+        }
+    case "stdout", "none":
+    default:
+        bad("trace.exporter", "must be otlp, stdout or none, got %q", c.Trace.Exporter)
+    }
+    if c.Trace.SampleRatio < 0 || c.Trace.SampleRatio > 1 {
+        bad("trace.sample_ratio", "must be between 0 and 1, got %v", c.Trace.SampleRatio)
+    }
+    return errors.Join(errs...)
+}
@@ -0,0 +1,108 @@
+package gateway
+
+import (
+    "errors"
+    "io"
+    "net/http"
+
+    pb "ecopoint/collecting_service/pb"
+)
+
+// uploadChunkSize matches the chunks the gRPC server streams back on download
+const uploadChunkSize = 64 << 10
+
+// upload sends the raw request body through UploadAttachment. The file's type and size are
+// the Content-Type and Content-Length headers; user_id, kind and item_index are query parameters.
+func (g *Gateway) upload(w http.ResponseWriter, r *http.Request) {
+    info := &pb.AttachmentInfo{}
+    for key, values := range r.URL.Query() {
+        if err := setField(info.ProtoReflect(), key, values); err != nil {
+            writeError(w, err)
+            return
+        }
+    }
+    info.OrderId = r.PathValue("order_id")
+    info.ContentType = r.Header.Get("Content-Type")
+    if r.ContentLength < 0 {
+        writeError(w, badRequest("size", "Content-Length is required"))
+        return
+    }
+    info.Size = r.ContentLength
+
+    stream, err := g.client.UploadAttachment(outgoingContext(r))
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    if err := stream.Send(&pb.UploadAttachmentRequest{Part: &pb.UploadAttachmentRequest_Info{Info: info}}); err != nil {
+        // the server rejected the info frame; its status comes with CloseAndRecv
+        g.finishUpload(w, stream)
+        return
+    }
+    buf := make([]byte, uploadChunkSize)
+    for {
+        n, err := r.Body.Read(buf)
+        if n > 0 {
+            chunk := append([]byte(nil), buf[:n]...)
+            if serr := stream.Send(&pb.UploadAttachmentRequest{Part: &pb.UploadAttachmentRequest_Chunk{Chunk: chunk}}); serr != nil {
+                break
+            }
+        }
+        if errors.Is(err, io.EOF) {
+            break
+        }
+        if err != nil {
+            writeError(w, badRequest("body", "could not be read: "+err.Error()))
+            return
+        }
+    }
+    g.finishUpload(w, stream)
+}
+
+func (g *Gateway) finishUpload(w http.ResponseWriter, stream pb.CollectingService_UploadAttachmentClient) {
+    o, err := stream.CloseAndRecv()
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    writeMessage(w, http.StatusOK, o)
+}
+
+// download streams the file with its stored content type; errors before the first chunk
+// are JSON like every other route
+func (g *Gateway) download(w http.ResponseWriter, r *http.Request) {
+    req := &pb.DownloadAttachmentRequest{AttachmentId: r.PathValue("attachment_id"), UserId: r.URL.Query().Get("user_id")}
+    stream, err := g.client.DownloadAttachment(outgoingContext(r), req)
+    if err != nil {
+        writeError(w, err)
+        return
+    }
+    first, err := stream.Recv()
+    if err != nil {
+        if errors.Is(err, io.EOF) {
+            w.WriteHeader(http.StatusOK)
+            return
+        }
+        writeError(w, err)
+        return
+    }
+    if ct := first.GetContentType(); ct != "" {
+        w.Header().Set("Content-Type", ct)
+    }
+    w.Header().Set("Cache-Control", "private")
+    w.WriteHeader(http.StatusOK)
+    if _, err := w.Write(first.GetData()); err != nil {
+        return
+    }
+    for {
+        c, err := stream.Recv()
+        if err != nil {
+            // a failure after the headers can only be signalled by cutting the body short
+            return
+        }
+        if _, err := w.Write(c.GetData()); err != nil {
+            return
+        }
+    }
+}
+
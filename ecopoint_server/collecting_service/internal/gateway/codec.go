@@ -0,0 +1,225 @@
+package gateway
+
+import (
+    "fmt"
+    "io"
+    "math"
+    "net/http"
+    "strconv"
+    "strings"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/reflect/protoreflect"
+)
+
+var (
+    marshal   = protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}
+    unmarshal = protojson.UnmarshalOptions{DiscardUnknown: true}
+)
+
+// decodeRequest fills msg from the JSON body (or the query string) and then the path wildcards
+func decodeRequest(r *http.Request, msg proto.Message, body bool) error {
+    if body {
+        data, err := io.ReadAll(r.Body)
+        if err != nil {
+            return badRequest("body", "could not be read: "+err.Error())
+        }
+        if len(data) > 0 {
+            if err := unmarshal.Unmarshal(data, msg); err != nil {
+                return badRequest("body", "is not a valid JSON "+string(msg.ProtoReflect().Descriptor().Name())+": "+err.Error())
+            }
+        }
+    } else {
+        for key, values := range r.URL.Query() {
+            if err := setField(msg.ProtoReflect(), key, values); err != nil {
+                return err
+            }
+        }
+    }
+    fields := msg.ProtoReflect().Descriptor().Fields()
+    for i := 0; i < fields.Len(); i++ {
+        name := string(fields.Get(i).Name())
+        if v := r.PathValue(name); v != "" {
+            if err := setField(msg.ProtoReflect(), name, []string{v}); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// setField parses a query or path parameter into the top-level field named key (proto or
+// JSON name). Unknown keys are ignored, as protojson ignores unknown body fields.
+func setField(m protoreflect.Message, key string, values []string) error {
+    fields := m.Descriptor().Fields()
+    fd := fields.ByName(protoreflect.Name(key))
+    if fd == nil {
+        fd = fields.ByJSONName(key)
+    }
+    if fd == nil || fd.Message() != nil || fd.IsMap() {
+        return nil
+    }
+    if fd.IsList() {
+        list := m.Mutable(fd).List()
+        for _, s := range values {
+            for _, part := range strings.Split(s, ",") {
+                v, err := parseScalar(fd, part)
+                if err != nil {
+                    return err
+                }
+                list.Append(v)
+            }
+        }
+        return nil
+    }
+    v, err := parseScalar(fd, values[len(values)-1])
+    if err != nil {
+        return err
+    }
+    m.Set(fd, v)
+    return nil
+}
+
+func parseScalar(fd protoreflect.FieldDescriptor, s string) (protoreflect.Value, error) {
+    invalid := func() (protoreflect.Value, error) {
+        return protoreflect.Value{}, badRequest(string(fd.Name()), fmt.Sprintf("invalid %s %q", fd.Kind(), s))
+    }
+    switch fd.Kind() {
+    case protoreflect.StringKind:
+        return protoreflect.ValueOfString(s), nil
+    case protoreflect.BoolKind:
+        b, err := strconv.ParseBool(s)
+        if err != nil {
+            return invalid()
+        }
+        return protoreflect.ValueOfBool(b), nil
+    case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+        n, err := strconv.ParseInt(s, 10, 32)
+        if err != nil {
+            return invalid()
+        }
+        return protoreflect.ValueOfInt32(int32(n)), nil
+    case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+        n, err := strconv.ParseInt(s, 10, 64)
+        if err != nil {
+            return invalid()
+        }
+        return protoreflect.ValueOfInt64(n), nil
+    case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+        n, err := strconv.ParseUint(s, 10, 32)
+        if err != nil {
+            return invalid()
+        }
+        return protoreflect.ValueOfUint32(uint32(n)), nil
+    case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+        n, err := strconv.ParseUint(s, 10, 64)
+        if err != nil {
+            return invalid()
+        }
+        return protoreflect.ValueOfUint64(n), nil
+    case protoreflect.DoubleKind, protoreflect.FloatKind:
+        f, err := strconv.ParseFloat(s, 64)
+        if err != nil {
+            return invalid()
+        }
+        if fd.Kind() == protoreflect.FloatKind {
+            return protoreflect.ValueOfFloat32(float32(f)), nil
+        }
+        return protoreflect.ValueOfFloat64(f), nil
+    case protoreflect.EnumKind:
+        if n, ok := enumValue(fd.Enum(), s); ok {
+            return protoreflect.ValueOfEnum(n), nil
+        }
+        return invalid()
+    }
+    return invalid()
+}
+
+// enumValue accepts the full name (ORDER_STATUS_ON_WAY), the number, or the name without
+// its type prefix in any case (on_way)
+func enumValue(ed protoreflect.EnumDescriptor, s string) (protoreflect.EnumNumber, bool) {
+    if n, err := strconv.ParseInt(s, 10, 32); err == nil {
+        return protoreflect.EnumNumber(n), true
+    }
+    values := ed.Values()
+    if v := values.ByName(protoreflect.Name(s)); v != nil {
+        return v.Number(), true
+    }
+    suffix := "_" + strings.ToUpper(s)
+    for i := 0; i < values.Len(); i++ {
+        if v := values.Get(i); strings.HasSuffix(string(v.Name()), suffix) {
+            return v.Number(), true
+        }
+    }
+    return 0, false
+}
+
+func writeMessage(w http.ResponseWriter, code int, m proto.Message) {
+    data, err := marshal.Marshal(m)
+    if err != nil {
+        writeError(w, status.Error(codes.Internal, "encode response: "+err.Error()))
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(code)
+    _, _ = w.Write(data)
+}
+
+// writeError renders the gRPC status, details included, as google.rpc.Status JSON
+func writeError(w http.ResponseWriter, err error) {
+    st := status.Convert(err)
+    data, merr := marshal.Marshal(st.Proto())
+    if merr != nil {
+        data = []byte(`{"code":13,"message":"encode error"}`)
+    }
+    for _, d := range st.Details() {
+        if ri, ok := d.(*errdetails.RetryInfo); ok {
+            w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(ri.RetryDelay.AsDuration().Seconds()))))
+        }
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(HTTPStatus(st.Code()))
+    _, _ = w.Write(data)
+}
+
+// HTTPStatus maps gRPC codes the way grpc-gateway does
+func HTTPStatus(c codes.Code) int {
+    switch c {
+    case codes.OK:
+        return http.StatusOK
+    case codes.Canceled:
+        return 499 // client closed request
+    case codes.InvalidArgument, codes.FailedPrecondition, codes.OutOfRange:
+        return http.StatusBadRequest
+    case codes.DeadlineExceeded:
+        return http.StatusGatewayTimeout
+    case codes.NotFound:
+        return http.StatusNotFound
+    case codes.AlreadyExists, codes.Aborted:
+        return http.StatusConflict
+    case codes.PermissionDenied:
+        return http.StatusForbidden
+    case codes.Unauthenticated:
+        return http.StatusUnauthorized
+    case codes.ResourceExhausted:
+        return http.StatusTooManyRequests
+    case codes.Unimplemented:
+        return http.StatusNotImplemented
+    case codes.Unavailable:
+        return http.StatusServiceUnavailable
+    }
+    return http.StatusInternalServerError
+}
+
+// badRequest matches the InvalidArgument errors of the gRPC server, with one field violation
+func badRequest(field, desc string) error {
+    st := status.New(codes.InvalidArgument, field+" "+desc)
+    if d, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{{Field: field, Description: desc}}}); err == nil {
+        return d.Err()
+    }
+    return st.Err()
+}
@@ -0,0 +1,32 @@
+package gateway
+
+import (
+    "net/http"
+    "strings"
+)
+
+var (
+    corsMethods = strings.Join([]string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}, ", ")
+    // never the identity headers: browsers reach the gateway only through the BFF's origin
+    corsHeaders = strings.Join([]string{"Authorization", "Content-Type", "Traceparent", "Tracestate"}, ", ")
+)
+
+// cors sets the CORS response headers for allowed origins and answers preflight requests;
+// it reports whether the request has been handled
+func (g *Gateway) cors(w http.ResponseWriter, r *http.Request) bool {
+    origin := r.Header.Get("Origin")
+    w.Header().Add("Vary", "Origin")
+    if origin == "" || !(g.origins["*"] || g.origins[origin]) {
+        return false
+    }
+    w.Header().Set("Access-Control-Allow-Origin", origin)
+    w.Header().Set("Access-Control-Expose-Headers", "Retry-After")
+    if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+        return false
+    }
+    w.Header().Set("Access-Control-Allow-Methods", corsMethods)
+    w.Header().Set("Access-Control-Allow-Headers", corsHeaders)
+    w.Header().Set("Access-Control-Max-Age", "600")
+    w.WriteHeader(http.StatusNoContent)
+    return true
+}
@@ -0,0 +1,172 @@
+// Package gateway serves CollectingService as REST/JSON for the web frontend. Each route
+// decodes path and query parameters (or a JSON body) into the RPC request, calls the gRPC
+// server and writes the response with protojson, using the proto field names.
+//
+// The gateway does not verify bearer tokens. The BFF does, and sets X-User-Id and
+// X-User-Role on the requests it forwards; those headers are believed only from the peers
+// given to WithTrustedProxies and rejected from anyone else. They are forwarded as caller
+// metadata so the gRPC interceptors, redaction and permission checks see the same identity.
+// Bind the listener to loopback or a private network the BFF shares.
+package gateway
+
+import (
+    "context"
+    "net"
+    "net/http"
+    "strings"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/propagation"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/proto"
+
+    "ecopoint/collecting_service/internal/caller"
+    "ecopoint/collecting_service/internal/models"
+    pb "ecopoint/collecting_service/pb"
+)
+
+const (
+    HeaderUserID = "X-User-Id"
+    HeaderRole   = "X-User-Role"
+)
+
+// maxBodySize bounds JSON request bodies; files go through the upload route, which is unbounded here
+const maxBodySize = 1 << 20
+
+type Gateway struct {
+    client  pb.CollectingServiceClient
+    origins map[string]bool // nil: no CORS headers
+    trusted caller.Proxies  // peers allowed to set the identity headers
+    mux     *http.ServeMux
+}
+
+type Option func(*Gateway)
+
+// WithCORSOrigins allows browsers on these origins to call the gateway; "*" allows any origin
+func WithCORSOrigins(origins []string) Option {
+    return func(g *Gateway) {
+        g.origins = map[string]bool{}
+        for _, o := range origins {
+            g.origins[o] = true
+        }
+    }
+}
+
+// WithTrustedProxies believes the identity headers from these peers, normally the BFF.
+// Without it every request is anonymous.
+func WithTrustedProxies(p caller.Proxies) Option {
+    return func(g *Gateway) { g.trusted = p }
+}
+
+func New(client pb.CollectingServiceClient, opts ...Option) *Gateway {
+    g := &Gateway{client: client, mux: http.NewServeMux()}
+    for _, opt := range opts {
+        opt(g)
+    }
+    for _, rt := range routes {
+        g.mux.Handle(rt.pattern, g.unary(rt))
+    }
+    g.mux.HandleFunc("POST /v1/orders/{order_id}/attachments", g.upload)
+    g.mux.HandleFunc("GET /v1/attachments/{attachment_id}", g.download)
+    return g
+}
+
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+    if g.origins != nil && g.cors(w, r) {
+        return
+    }
+    if (r.Header.Get(HeaderUserID) != "" || r.Header.Get(HeaderRole) != "") && !g.trusted.ContainsHost(r.RemoteAddr) {
+        writeError(w, status.Error(codes.Unauthenticated, "identity headers are only accepted from the BFF"))
+        return
+    }
+    g.mux.ServeHTTP(w, r)
+}
+
+// route binds one unary RPC to an HTTP pattern. With body the JSON body is the request
+// message; otherwise fields come from the query string. Path wildcards always win.
+type route struct {
+    pattern string
+    body    bool
+    call    func(ctx context.Context, c pb.CollectingServiceClient, r *http.Request, body bool) (proto.Message, error)
+}
+
+func unary[Req any, PReq interface {
+    *Req
+    proto.Message
+}, Res proto.Message](pattern string, body bool, rpc func(pb.CollectingServiceClient, context.Context, PReq, ...grpc.CallOption) (Res, error)) route {
+    return route{pattern: pattern, body: body, call: func(ctx context.Context, c pb.CollectingServiceClient, r *http.Request, body bool) (proto.Message, error) {
+        req := PReq(new(Req))
+        if err := decodeRequest(r, req, body); err != nil {
+            return nil, err
+        }
+        return rpc(c, ctx, req)
+    }}
+}
+
+// routes mirrors openapi.yaml; Chat and UploadAttachment's stream form are gRPC only
+var routes = []route{
+    unary("POST /v1/orders", true, pb.CollectingServiceClient.CreateOrder),
+    unary("GET /v1/orders/available", false, pb.CollectingServiceClient.ListAvailableOrders),
+    unary("GET /v1/orders/{order_id}", false, pb.CollectingServiceClient.GetOrder),
+    unary("PATCH /v1/orders/{order_id}", true, pb.CollectingServiceClient.UpdateOrderDetails),
+    unary("POST /v1/orders/{order_id}/accept", true, pb.CollectingServiceClient.AcceptOrder),
+    unary("POST /v1/orders/{order_id}/status", true, pb.CollectingServiceClient.UpdateOrderStatus),
+    unary("POST /v1/orders/{order_id}/cancel", true, pb.CollectingServiceClient.CancelOrder),
+    unary("POST /v1/orders/{order_id}/ratings", true, pb.CollectingServiceClient.RateOrder),
+    unary("GET /v1/orders/{order_id}/messages", false, pb.CollectingServiceClient.ListChatMessages),
+    unary("GET /v1/customers/{customer_id}/orders", false, pb.CollectingServiceClient.ListMyOrders),
+    unary("GET /v1/collectors/{collector_id}/orders", false, pb.CollectingServiceClient.ListCollectorOrders),
+    unary("GET /v1/collectors/{collector_id}/orders/active", false, pb.CollectingServiceClient.ListMyActiveOrders),
+    unary("GET /v1/collectors/{collector_id}/earnings", false, pb.CollectingServiceClient.GetCollectorEarnings),
+    unary("GET /v1/collectors/{collector_id}/penalty", false, pb.CollectingServiceClient.GetCollectorPenalty),
+    unary("DELETE /v1/collectors/{collector_id}/penalty", false, pb.CollectingServiceClient.ClearCollectorPenalty),
+    unary("GET /v1/users/{user_id}/rating", false, pb.CollectingServiceClient.GetUserRating),
+    unary("GET /v1/ratings", false, pb.CollectingServiceClient.SearchUserRatings),
+    unary("GET /v1/users/{user_id}/notification-preferences", false, pb.CollectingServiceClient.GetNotificationPreferences),
+    unary("PUT /v1/users/{user_id}/notification-preferences", true, pb.CollectingServiceClient.SetNotificationPreferences),
+    unary("POST /v1/attachments", true, pb.CollectingServiceClient.CreateAttachmentUpload),
+    unary("POST /v1/attachments/{attachment_id}/complete", true, pb.CollectingServiceClient.CompleteAttachmentUpload),
+    unary("GET /v1/attachments/{attachment_id}/url", false, pb.CollectingServiceClient.GetAttachmentURL),
+}
+
+func (g *Gateway) unary(rt route) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if rt.body {
+            r.Body = http.MaxBytesReader(w, r.Body, maxBodySize)
+        }
+        res, err := rt.call(outgoingContext(r), g.client, r, rt.body)
+        if err != nil {
+            writeError(w, err)
+            return
+        }
+        writeMessage(w, http.StatusOK, res)
+    })
+}
+
+// outgoingContext carries the caller headers and the W3C trace context of r into the gRPC call;
+// ServeHTTP has already rejected identity headers from untrusted peers
+func outgoingContext(r *http.Request) context.Context {
+    ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+    // the service limits calls per client IP; this process is only a hop on the way
+    ctx = metadata.AppendToOutgoingContext(ctx, caller.HeaderForwardedFor, forwardedFor(r))
+    c := caller.Caller{UserID: r.Header.Get(HeaderUserID), Role: models.Role(r.Header.Get(HeaderRole))}
+    if c.Anonymous() && c.Role == "" {
+        return ctx
+    }
+    return caller.NewOutgoingContext(ctx, c)
+}
+
+// forwardedFor is X-Forwarded-For with the address of the peer appended
+func forwardedFor(r *http.Request) string {
+    host, _, err := net.SplitHostPort(r.RemoteAddr)
+    if err != nil {
+        host = r.RemoteAddr
+    }
+    if prior := r.Header.Values("X-Forwarded-For"); len(prior) > 0 {
+        return strings.Join(prior, ", ") + ", " + host
+    }
+    return host
+}
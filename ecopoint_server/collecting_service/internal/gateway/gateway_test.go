@@ -0,0 +1,275 @@
+package gateway
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "io"
+    "net"
+    "net/http"
+    "net/http/httptest"
+    "net/netip"
+    "strings"
+    "testing"
+    "time"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/types/known/durationpb"
+
+    "ecopoint/collecting_service/internal/caller"
+    pb "ecopoint/collecting_service/pb"
+)
+
+// stub records the last request and the caller it came from
+type stub struct {
+    pb.UnimplementedCollectingServiceServer
+    last   proto.Message
+    caller caller.Caller
+    via    []string // x-forwarded-for
+    upload []byte
+}
+
+func (s *stub) GetOrder(ctx context.Context, req *pb.GetOrderRequest) (*pb.Order, error) {
+    s.last, s.caller = req, caller.FromContext(ctx)
+    md, _ := metadata.FromIncomingContext(ctx)
+    s.via = md.Get(caller.HeaderForwardedFor)
+    if req.OrderId == "missing" {
+        return nil, status.Error(codes.NotFound, "order not found")
+    }
+    return &pb.Order{Id: req.OrderId, OrderStatus: pb.OrderStatus_ORDER_STATUS_CREATED, TotalWeight: 2.5}, nil
+}
+
+func (s *stub) ListCollectorOrders(ctx context.Context, req *pb.ListCollectorOrdersRequest) (*pb.ListOrdersResponse, error) {
+    s.last = req
+    return &pb.ListOrdersResponse{NextPageToken: "next"}, nil
+}
+
+func (s *stub) AcceptOrder(ctx context.Context, req *pb.AcceptOrderRequest) (*pb.Order, error) {
+    s.last = req
+    return nil, status.Error(codes.Aborted, "order was taken")
+}
+
+func (s *stub) ListAvailableOrders(ctx context.Context, req *pb.ListAvailableOrdersRequest) (*pb.ListAvailableOrdersResponse, error) {
+    st, _ := status.New(codes.ResourceExhausted, "rate limit exceeded").WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(1200 * time.Millisecond)})
+    return nil, st.Err()
+}
+
+func (s *stub) UploadAttachment(stream pb.CollectingService_UploadAttachmentServer) error {
+    first, err := stream.Recv()
+    if err != nil {
+        return err
+    }
+    s.last = first.GetInfo()
+    for {
+        f, err := stream.Recv()
+        if err == io.EOF {
+            return stream.SendAndClose(&pb.Order{Id: first.GetInfo().GetOrderId()})
+        }
+        if err != nil {
+            return err
+        }
+        s.upload = append(s.upload, f.GetChunk()...)
+    }
+}
+
+func (s *stub) DownloadAttachment(req *pb.DownloadAttachmentRequest, stream pb.CollectingService_DownloadAttachmentServer) error {
+    if err := stream.Send(&pb.AttachmentChunk{Data: []byte("ab"), ContentType: "image/png"}); err != nil {
+        return err
+    }
+    return stream.Send(&pb.AttachmentChunk{Data: []byte("cd")})
+}
+
+func startGateway(t *testing.T, opts ...Option) (*stub, *httptest.Server) {
+    t.Helper()
+    s := &stub{}
+    // loopback, as in production, so the service believes the forwarded identity
+    lis, err := net.Listen("tcp", "127.0.0.1:0")
+    if err != nil {
+        t.Fatal(err)
+    }
+    srv := grpc.NewServer()
+    pb.RegisterCollectingServiceServer(srv, s)
+    go func() { _ = srv.Serve(lis) }()
+    conn, err := grpc.NewClient("passthrough:///"+lis.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+    if err != nil {
+        t.Fatalf("dial: %v", err)
+    }
+    hs := httptest.NewServer(New(pb.NewCollectingServiceClient(conn), opts...))
+    t.Cleanup(func() { hs.Close(); _ = conn.Close(); srv.Stop() })
+    return s, hs
+}
+
+func do(t *testing.T, req *http.Request) (*http.Response, map[string]any) {
+    t.Helper()
+    res, err := http.DefaultClient.Do(req)
+    if err != nil {
+        t.Fatal(err)
+    }
+    defer res.Body.Close()
+    var body map[string]any
+    if err := json.NewDecoder(res.Body).Decode(&body); err != nil && err != io.EOF {
+        t.Fatalf("decode: %v", err)
+    }
+    return res, body
+}
+
+// loopback trusts the test client as if it were the BFF
+var loopback = WithTrustedProxies(caller.Proxies{netip.MustParsePrefix("127.0.0.0/8")})
+
+func TestUnaryRoute(t *testing.T) {
+    s, hs := startGateway(t, loopback)
+    req := mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/o1", nil)
+    req.Header.Set(HeaderUserID, "u1")
+    req.Header.Set(HeaderRole, "customer")
+    req.Header.Set("X-Forwarded-For", "198.51.100.20")
+    res, body := do(t, req)
+    if res.StatusCode != http.StatusOK || res.Header.Get("Content-Type") != "application/json" {
+        t.Fatalf("status %d, content type %q", res.StatusCode, res.Header.Get("Content-Type"))
+    }
+    if body["id"] != "o1" || body["order_status"] != "ORDER_STATUS_CREATED" || body["total_weight"] != 2.5 {
+        t.Errorf("body = %v", body)
+    }
+    if _, ok := body["accepted_by"]; !ok {
+        t.Error("unpopulated fields must be present")
+    }
+    if s.caller.UserID != "u1" || s.caller.Role != "customer" {
+        t.Errorf("caller = %+v", s.caller)
+    }
+    if len(s.via) != 1 || s.via[0] != "198.51.100.20, 127.0.0.1" {
+        t.Errorf("x-forwarded-for = %q", s.via)
+    }
+}
+
+func TestIdentityHeadersFromUntrustedPeer(t *testing.T) {
+    s, hs := startGateway(t, WithTrustedProxies(caller.Proxies{netip.MustParsePrefix("10.0.0.0/8")}))
+    req := mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/o1", nil)
+    req.Header.Set(HeaderUserID, "u1")
+    req.Header.Set(HeaderRole, "admin")
+    res, body := do(t, req)
+    if res.StatusCode != http.StatusUnauthorized || s.last != nil {
+        t.Fatalf("forged identity: %d %v", res.StatusCode, body)
+    }
+
+    res, _ = do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/o1", nil))
+    if res.StatusCode != http.StatusOK || !s.caller.Anonymous() || s.caller.Role != "" {
+        t.Fatalf("anonymous call: %d %+v", res.StatusCode, s.caller)
+    }
+}
+
+func TestQueryParameters(t *testing.T) {
+    s, hs := startGateway(t)
+    res, body := do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/collectors/c1/orders?statuses=created,ON_WAY&statuses=4&pageSize=5&from_unix=100&unknown=x", nil))
+    if res.StatusCode != http.StatusOK || body["next_page_token"] != "next" {
+        t.Fatalf("status %d body %v", res.StatusCode, body)
+    }
+    got := s.last.(*pb.ListCollectorOrdersRequest)
+    want := []pb.OrderStatus{pb.OrderStatus_ORDER_STATUS_CREATED, pb.OrderStatus_ORDER_STATUS_ON_WAY, pb.OrderStatus_ORDER_STATUS_COMPLETE}
+    if got.CollectorId != "c1" || got.PageSize != 5 || got.FromUnix != 100 || len(got.Statuses) != 3 {
+        t.Fatalf("request = %v", got)
+    }
+    for i := range want {
+        if got.Statuses[i] != want[i] {
+            t.Errorf("statuses = %v", got.Statuses)
+        }
+    }
+
+    res, body = do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/collectors/c1/orders?page_size=many", nil))
+    if res.StatusCode != http.StatusBadRequest || body["code"] != float64(codes.InvalidArgument) {
+        t.Errorf("status %d body %v", res.StatusCode, body)
+    }
+}
+
+func TestBodyAndPathAndErrors(t *testing.T) {
+    s, hs := startGateway(t)
+    // the path wins over the body
+    res, body := do(t, mustRequest(t, http.MethodPost, hs.URL+"/v1/orders/o1/accept", strings.NewReader(`{"order_id":"other","collectorId":"c1","idempotency_key":"k"}`)))
+    if res.StatusCode != http.StatusConflict || body["message"] != "order was taken" {
+        t.Fatalf("status %d body %v", res.StatusCode, body)
+    }
+    got := s.last.(*pb.AcceptOrderRequest)
+    if got.OrderId != "o1" || got.CollectorId != "c1" || got.IdempotencyKey != "k" {
+        t.Errorf("request = %v", got)
+    }
+
+    res, _ = do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/missing", nil))
+    if res.StatusCode != http.StatusNotFound {
+        t.Errorf("missing order: status %d", res.StatusCode)
+    }
+    res, body = do(t, mustRequest(t, http.MethodPost, hs.URL+"/v1/orders/o1/accept", strings.NewReader(`{"collector_id":`)))
+    details, _ := body["details"].([]any)
+    if res.StatusCode != http.StatusBadRequest || len(details) != 1 {
+        t.Errorf("malformed body: status %d body %v", res.StatusCode, body)
+    }
+    res, body = do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/o1/messages", nil))
+    if res.StatusCode != http.StatusNotImplemented {
+        t.Errorf("unimplemented rpc: status %d body %v", res.StatusCode, body)
+    }
+    res, _ = do(t, mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/available", nil))
+    if res.StatusCode != http.StatusTooManyRequests || res.Header.Get("Retry-After") != "2" {
+        t.Errorf("rate limited: status %d, Retry-After %q", res.StatusCode, res.Header.Get("Retry-After"))
+    }
+}
+
+func TestCORS(t *testing.T) {
+    _, hs := startGateway(t, WithCORSOrigins([]string{"http://localhost:3000"}))
+    pre := mustRequest(t, http.MethodOptions, hs.URL+"/v1/orders", nil)
+    pre.Header.Set("Origin", "http://localhost:3000")
+    pre.Header.Set("Access-Control-Request-Method", "POST")
+    res, _ := do(t, pre)
+    if res.StatusCode != http.StatusNoContent || res.Header.Get("Access-Control-Allow-Origin") != "http://localhost:3000" ||
+        !strings.Contains(res.Header.Get("Access-Control-Allow-Headers"), "Authorization") ||
+        strings.Contains(res.Header.Get("Access-Control-Allow-Headers"), HeaderUserID) {
+        t.Errorf("preflight: %d %v", res.StatusCode, res.Header)
+    }
+
+    get := mustRequest(t, http.MethodGet, hs.URL+"/v1/orders/o1", nil)
+    get.Header.Set("Origin", "http://evil.example")
+    res, _ = do(t, get)
+    if res.StatusCode != http.StatusOK || res.Header.Get("Access-Control-Allow-Origin") != "" {
+        t.Errorf("other origin: %d %v", res.StatusCode, res.Header)
+    }
+}
+
+func TestAttachmentStreams(t *testing.T) {
+    s, hs := startGateway(t)
+    file := bytes.Repeat([]byte{7}, uploadChunkSize+10)
+    up := mustRequest(t, http.MethodPost, hs.URL+"/v1/orders/o1/attachments?user_id=u1&kind=item_photo&item_index=1", bytes.NewReader(file))
+    up.Header.Set("Content-Type", "image/jpeg")
+    res, body := do(t, up)
+    if res.StatusCode != http.StatusOK || body["id"] != "o1" {
+        t.Fatalf("upload: %d %v", res.StatusCode, body)
+    }
+    info := s.last.(*pb.AttachmentInfo)
+    if info.OrderId != "o1" || info.UserId != "u1" || info.Kind != pb.AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO ||
+        info.ItemIndex != 1 || info.ContentType != "image/jpeg" || info.Size != int64(len(file)) {
+        t.Errorf("info = %v", info)
+    }
+    if !bytes.Equal(s.upload, file) {
+        t.Errorf("uploaded %d bytes, want %d", len(s.upload), len(file))
+    }
+
+    res, err := http.Get(hs.URL + "/v1/attachments/a1?user_id=u1")
+    if err != nil {
+        t.Fatal(err)
+    }
+    data, _ := io.ReadAll(res.Body)
+    res.Body.Close()
+    if string(data) != "abcd" || res.Header.Get("Content-Type") != "image/png" {
+        t.Errorf("download: %q %q", data, res.Header.Get("Content-Type"))
+    }
+}
+
+func mustRequest(t *testing.T, method, url string, body io.Reader) *http.Request {
+    t.Helper()
+    req, err := http.NewRequest(method, url, body)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return req
+}
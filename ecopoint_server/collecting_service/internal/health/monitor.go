@@ -0,0 +1,171 @@
+// Package health publishes the service state through the standard grpc.health.v1 service.
+//
+// Readiness is reported for the empty service name and for each name passed to NewMonitor:
+// SERVING while every dependency check passes and no background worker has stalled.
+// Liveness is reported for the "liveness" service and only fails when a worker stalls,
+// since a restart does not help while MongoDB is down. With Kubernetes gRPC probes:
+//
+//	readinessProbe: {grpc: {port: 50052}}
+//	livenessProbe:  {grpc: {port: 50052, service: liveness}}
+package health
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc/health"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Liveness is the service name of the liveness status
+const Liveness = "liveness"
+
+// Monitor runs dependency checks and tracks worker heartbeats
+type Monitor struct {
+    srv      *health.Server
+    services []string
+    timeout  time.Duration
+
+    mu      sync.Mutex
+    checks  map[string]func(context.Context) error
+    workers map[string]*worker
+    failing map[string]bool // last result per check or worker, for logging changes
+    now     func() time.Time
+}
+
+type worker struct {
+    maxSilence time.Duration
+    last       time.Time
+}
+
+// NewMonitor starts with everything NOT_SERVING until the first Check
+func NewMonitor(services ...string) *Monitor {
+    m := &Monitor{
+        srv:      health.NewServer(),
+        services: append([]string{""}, services...),
+        timeout:  2 * time.Second,
+        checks:   map[string]func(context.Context) error{},
+        workers:  map[string]*worker{},
+        failing:  map[string]bool{},
+        now:      time.Now,
+    }
+    for _, s := range m.services {
+        m.srv.SetServingStatus(s, healthpb.HealthCheckResponse_NOT_SERVING)
+    }
+    m.srv.SetServingStatus(Liveness, healthpb.HealthCheckResponse_SERVING)
+    return m
+}
+
+// Server is the grpc.health.v1 implementation to register on the gRPC server
+func (m *Monitor) Server() *health.Server { return m.srv }
+
+// AddCheck registers a dependency; readiness fails while check returns an error
+func (m *Monitor) AddCheck(name string, check func(context.Context) error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.checks[name] = check
+}
+
+// Heartbeat registers a background worker, which must call the returned function at least
+// every maxSilence; a silent worker fails both readiness and liveness
+func (m *Monitor) Heartbeat(name string, maxSilence time.Duration) func() {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    w := &worker{maxSilence: maxSilence, last: m.now()}
+    m.workers[name] = w
+    return func() {
+        m.mu.Lock()
+        w.last = m.now()
+        m.mu.Unlock()
+    }
+}
+
+// Check runs every dependency check once and updates the published statuses
+func (m *Monitor) Check(ctx context.Context) (ready, live bool) {
+    m.mu.Lock()
+    checks := make(map[string]func(context.Context) error, len(m.checks))
+    for name, c := range m.checks {
+        checks[name] = c
+    }
+    m.mu.Unlock()
+
+    results := map[string]error{}
+    for name, check := range checks {
+        cctx, cancel := context.WithTimeout(ctx, m.timeout)
+        results[name] = check(cctx)
+        cancel()
+    }
+
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    ready, live = true, true
+    for name, err := range results {
+        m.report(name, err)
+        if err != nil {
+            ready = false
+        }
+    }
+    now := m.now()
+    for name, w := range m.workers {
+        var err error
+        if silent := now.Sub(w.last); silent > w.maxSilence {
+            err = fmt.Errorf("no heartbeat for %s", silent.Round(time.Second))
+        }
+        m.report(name, err)
+        if err != nil {
+            ready, live = false, false
+        }
+    }
+    readiness := healthpb.HealthCheckResponse_NOT_SERVING
+    if ready {
+        readiness = healthpb.HealthCheckResponse_SERVING
+    }
+    for _, s := range m.services {
+        m.srv.SetServingStatus(s, readiness)
+    }
+    liveness := healthpb.HealthCheckResponse_NOT_SERVING
+    if live {
+        liveness = healthpb.HealthCheckResponse_SERVING
+    }
+    m.srv.SetServingStatus(Liveness, liveness)
+    return ready, live
+}
+
+// report logs a check only when its result changes; callers hold mu
+func (m *Monitor) report(name string, err error) {
+    was, seen := m.failing[name]
+    m.failing[name] = err != nil
+    switch {
+    case err != nil && (!seen || !was):
+        slog.Warn("health: check failing", "check", name, "error", err)
+    case err == nil && seen && was:
+        slog.Info("health: check recovered", "check", name)
+    }
+}
+
+// Run checks every interval until ctx is done; interval <= 0 checks once
+func (m *Monitor) Run(ctx context.Context, interval time.Duration) {
+    m.Check(ctx)
+    if interval <= 0 {
+        return
+    }
+    t := time.NewTicker(interval)
+    defer t.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case <-t.C:
+            m.Check(ctx)
+        }
+    }
+}
+
+// Shutdown reports NOT_SERVING everywhere and ignores later updates, so load balancers
+// stop sending traffic while in-flight calls drain
+func (m *Monitor) Shutdown() {
+    m.srv.Shutdown()
+}
@@ -0,0 +1,64 @@
+package health
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func status(t *testing.T, m *Monitor, service string) healthpb.HealthCheckResponse_ServingStatus {
+    t.Helper()
+    res, err := m.Server().Check(context.Background(), &healthpb.HealthCheckRequest{Service: service})
+    if err != nil {
+        t.Fatalf("check %q: %v", service, err)
+    }
+    return res.Status
+}
+
+func TestMonitorReadinessAndLiveness(t *testing.T) {
+    now := time.Now()
+    m := NewMonitor("ecopoint.collecting.v1.CollectingService")
+    m.now = func() time.Time { return now }
+    if status(t, m, "") != healthpb.HealthCheckResponse_NOT_SERVING {
+        t.Fatalf("expected NOT_SERVING before the first check")
+    }
+
+    var mongoErr error
+    m.AddCheck("mongo", func(context.Context) error { return mongoErr })
+    beat := m.Heartbeat("watchdog", time.Minute)
+    if ready, live := m.Check(context.Background()); !ready || !live {
+        t.Fatalf("expected ready and live, got %v %v", ready, live)
+    }
+    if status(t, m, "ecopoint.collecting.v1.CollectingService") != healthpb.HealthCheckResponse_SERVING {
+        t.Fatalf("expected the named service to be SERVING")
+    }
+
+    // a database outage fails readiness only
+    mongoErr = errors.New("connection refused")
+    m.Check(context.Background())
+    if status(t, m, "") != healthpb.HealthCheckResponse_NOT_SERVING || status(t, m, Liveness) != healthpb.HealthCheckResponse_SERVING {
+        t.Fatalf("expected not ready but live during an outage")
+    }
+    mongoErr = nil
+
+    // a stalled worker fails liveness until it beats again
+    now = now.Add(2 * time.Minute)
+    m.Check(context.Background())
+    if status(t, m, Liveness) != healthpb.HealthCheckResponse_NOT_SERVING {
+        t.Fatalf("expected liveness to fail for a stalled worker")
+    }
+    beat()
+    m.Check(context.Background())
+    if status(t, m, "") != healthpb.HealthCheckResponse_SERVING || status(t, m, Liveness) != healthpb.HealthCheckResponse_SERVING {
+        t.Fatalf("expected recovery after a heartbeat")
+    }
+
+    m.Shutdown()
+    m.Check(context.Background())
+    if status(t, m, "") != healthpb.HealthCheckResponse_NOT_SERVING {
+        t.Fatalf("expected NOT_SERVING after shutdown")
+    }
+}
@@ -0,0 +1,68 @@
+// Package logging builds the slog logger shared by the service. Sensitive attributes are
+// masked by key wherever they appear, including inside groups, so callers can log models
+// without picking fields by hand.
+package logging
+
+import (
+    "fmt"
+    "io"
+    "log/slog"
+    "strings"
+)
+
+const masked = "REDACTED"
+
+// secretKeys are never printed; phone numbers keep their last digits so support can match a call
+var secretKeys = map[string]bool{
+    "password":      true,
+    "secret":        true,
+    "token":         true,
+    "authorization": true,
+    "email":         true,
+}
+
+// ParseLevel accepts debug, info, warn and error, optionally with an offset such as warn+2
+func ParseLevel(s string) (slog.Level, error) {
+    var l slog.Level
+    err := l.UnmarshalText([]byte(s))
+    return l, err
+}
+
+// New returns a logger writing to w in format text or json, dropping records below level
+func New(w io.Writer, level, format string) (*slog.Logger, error) {
+    lvl, err := ParseLevel(level)
+    if err != nil {
+        return nil, err
+    }
+    opts := &slog.HandlerOptions{Level: lvl, ReplaceAttr: Redact}
+    switch format {
+    case "json":
+        return slog.New(slog.NewJSONHandler(w, opts)), nil
+    case "text", "":
+        return slog.New(slog.NewTextHandler(w, opts)), nil
+    }
+    return nil, fmt.Errorf("unknown log format %q", format)
+}
+
+// Redact is a slog ReplaceAttr function masking phone numbers and secrets
+func Redact(_ []string, a slog.Attr) slog.Attr {
+    key := strings.ToLower(a.Key)
+    switch {
+    case key == "phone" || strings.HasSuffix(key, "_phone"):
+        a.Value = slog.StringValue(MaskPhone(a.Value.String()))
+    case secretKeys[key] || strings.HasSuffix(key, "_token") || strings.HasSuffix(key, "_secret"):
+        a.Value = slog.StringValue(masked)
+    }
+    return a
+}
+
+// MaskPhone keeps the last three digits, e.g. 0909123456 -> *******456
+func MaskPhone(p string) string {
+    if p == "" {
+        return ""
+    }
+    if len(p) <= 3 {
+        return strings.Repeat("*", len(p))
+    }
+    return strings.Repeat("*", len(p)-3) + p[len(p)-3:]
+}
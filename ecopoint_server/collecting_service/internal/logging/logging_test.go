@@ -0,0 +1,46 @@
+package logging
+
+import (
+    "bytes"
+    "encoding/json"
+    "log/slog"
+    "strings"
+    "testing"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+func TestLoggerRedactsSensitiveFields(t *testing.T) {
+    var buf bytes.Buffer
+    l, err := New(&buf, "info", "json")
+    if err != nil {
+        t.Fatal(err)
+    }
+    snap := models.CustomerSnapshot{DisplayName: "Lan", Phone: "0909123456"}
+    l.Info("created", "customer", snap, slog.Group("contact", "phone", "0911222333"), "refresh_token", "abc", "order_id", "o1")
+    l.Debug("dropped below the level")
+
+    out := buf.String()
+    for _, leak := range []string{"0909123456", "0911222333", "abc"} {
+        if strings.Contains(out, leak) {
+            t.Fatalf("%s leaked: %s", leak, out)
+        }
+    }
+    var rec map[string]any
+    if err := json.Unmarshal(buf.Bytes(), &rec); err != nil {
+        t.Fatalf("expected one JSON record, got %q: %v", out, err)
+    }
+    if got := rec["customer"].(map[string]any)["phone"]; got != "*******456" {
+        t.Fatalf("expected a masked phone, got %v", got)
+    }
+    if rec["order_id"] != "o1" || rec["refresh_token"] != masked {
+        t.Fatalf("unexpected record %v", rec)
+    }
+
+    if _, err := New(&buf, "loud", "json"); err == nil {
+        t.Fatal("expected an invalid level to fail")
+    }
+    if _, err := New(&buf, "info", "xml"); err == nil {
+        t.Fatal("expected an invalid format to fail")
+    }
+}
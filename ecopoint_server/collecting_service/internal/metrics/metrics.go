@@ -0,0 +1,294 @@
+// Package metrics exposes Prometheus metrics for the service on its own registry:
+// gRPC calls, the order lifecycle, the open pool and MongoDB command latency.
+package metrics
+
+import (
+    "context"
+    "fmt"
+    "log/slog"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/status"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/ratelimit"
+    "ecopoint/collecting_service/internal/service"
+)
+
+// Order lifecycle labels of ecopoint_orders_total
+const (
+    OrderCreated   = "created"
+    OrderAccepted  = "accepted"
+    OrderOnWay     = "on_way"
+    OrderCompleted = "completed"
+    OrderReleased  = "released"
+    OrderCancelled = "cancelled"
+    OrderExpired   = "expired"
+)
+
+var eventLabels = map[models.EventType]string{
+    models.EventOrderAccepted:  OrderAccepted,
+    models.EventOrderOnWay:     OrderOnWay,
+    models.EventOrderCompleted: OrderCompleted,
+    models.EventOrderReleased:  OrderReleased,
+    models.EventOrderCancelled: OrderCancelled,
+}
+
+type Metrics struct {
+    reg *prometheus.Registry
+
+    orders         *prometheus.CounterVec
+    timeToAccept   prometheus.Histogram
+    timeToComplete prometheus.Histogram
+
+    rpcStarted *prometheus.CounterVec
+    rpcHandled *prometheus.CounterVec
+    rpcSeconds *prometheus.HistogramVec
+
+    mongoSeconds *prometheus.HistogramVec
+
+    rateLimited *prometheus.CounterVec
+}
+
+func New() *Metrics {
+    m := &Metrics{
+        reg: prometheus.NewRegistry(),
+        orders: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "ecopoint_orders_total",
+            Help: "Orders entering each lifecycle stage.",
+        }, []string{"status"}),
+        timeToAccept: prometheus.NewHistogram(prometheus.HistogramOpts{
+            Name:    "ecopoint_order_time_to_accept_seconds",
+            Help:    "Time from creation to acceptance by a collector.",
+            Buckets: []float64{30, 60, 120, 300, 600, 900, 1800, 3600, 7200, 14400},
+        }),
+        timeToComplete: prometheus.NewHistogram(prometheus.HistogramOpts{
+            Name:    "ecopoint_order_time_to_complete_seconds",
+            Help:    "Time from acceptance to completion.",
+            Buckets: []float64{300, 600, 900, 1800, 2700, 3600, 5400, 7200, 10800, 14400},
+        }),
+        rpcStarted: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "grpc_server_started_total",
+            Help: "RPCs started on the server.",
+        }, []string{"grpc_type", "grpc_service", "grpc_method"}),
+        rpcHandled: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "grpc_server_handled_total",
+            Help: "RPCs completed on the server, by status code.",
+        }, []string{"grpc_type", "grpc_service", "grpc_method", "grpc_code"}),
+        rpcSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Name:    "grpc_server_handling_seconds",
+            Help:    "Time until the server finished an RPC.",
+            Buckets: prometheus.DefBuckets,
+        }, []string{"grpc_type", "grpc_service", "grpc_method"}),
+        mongoSeconds: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+            Name:    "ecopoint_mongo_command_seconds",
+            Help:    "MongoDB command latency as seen by the driver.",
+            Buckets: []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5},
+        }, []string{"command", "collection", "result"}),
+        rateLimited: prometheus.NewCounterVec(prometheus.CounterOpts{
+            Name: "ecopoint_rate_limited_total",
+            Help: "Calls rejected by the rate limiter, by method and by the bucket that ran out.",
+        }, []string{"grpc_method", "scope"}),
+    }
+    m.reg.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+        m.orders, m.timeToAccept, m.timeToComplete,
+        m.rpcStarted, m.rpcHandled, m.rpcSeconds,
+        m.mongoSeconds,
+        m.rateLimited,
+    )
+    return m
+}
+
+// Registry is where every metric of the service is registered
+func (m *Metrics) Registry() *prometheus.Registry { return m.reg }
+
+// Handler serves the registry in the Prometheus text format
+func (m *Metrics) Handler() http.Handler {
+    return promhttp.HandlerFor(m.reg, promhttp.HandlerOpts{Registry: m.reg})
+}
+
+// Publish counts lifecycle events and records the accept and complete durations
+// (implements service.EventPublisher)
+func (m *Metrics) Publish(e models.OrderEvent) {
+    label, ok := eventLabels[e.Type]
+    if !ok {
+        return
+    }
+    m.orders.WithLabelValues(label).Inc()
+    o := e.Order
+    switch e.Type {
+    case models.EventOrderAccepted:
+        if o.AcceptedAt != nil {
+            m.timeToAccept.Observe(o.AcceptedAt.Sub(o.CreatedAt).Seconds())
+        }
+    case models.EventOrderCompleted:
+        if o.AcceptedAt != nil && o.CompletedAt != nil {
+            m.timeToComplete.Observe(o.CompletedAt.Sub(*o.AcceptedAt).Seconds())
+        }
+    }
+}
+
+// OrderCreated and OrderExpired implement service.OrderObserver
+func (m *Metrics) OrderCreated(*models.Order) { m.orders.WithLabelValues(OrderCreated).Inc() }
+
+func (m *Metrics) OrderExpired(*models.Order) { m.orders.WithLabelValues(OrderExpired).Inc() }
+
+// ObserveMongo is a repository.CommandObserver
+func (m *Metrics) ObserveMongo(command, collection string, d time.Duration, failed bool) {
+    result := "ok"
+    if failed {
+        result = "error"
+    }
+    m.mongoSeconds.WithLabelValues(command, collection, result).Observe(d.Seconds())
+}
+
+// RateLimited is a ratelimit.Observer
+func (m *Metrics) RateLimited(method, scope string) { m.rateLimited.WithLabelValues(method, scope).Inc() }
+
+// PoolOptions bounds what ecopoint_pool_orders costs: the label set and how often the pool is counted
+type PoolOptions struct {
+    Zones  []string      `yaml:"zones"`   // areas reported under their own label; any other is "other"
+    MaxAge time.Duration `yaml:"max_age"` // scrapes within this of the last count reuse it
+}
+
+// DefaultPoolOptions labels the districts of Ho Chi Minh City
+func DefaultPoolOptions() PoolOptions {
+    zones := []string{"Bình Thạnh", "Bình Tân", "Gò Vấp", "Phú Nhuận", "Tân Bình", "Tân Phú", "Thủ Đức"}
+    for i := 1; i <= 12; i++ {
+        zones = append(zones, fmt.Sprintf("Quận %d", i))
+    }
+    for i := range zones {
+        zones[i] += ", TP.HCM"
+    }
+    return PoolOptions{Zones: zones, MaxAge: 30 * time.Second}
+}
+
+// WatchPool publishes ecopoint_pool_orders{zone}, counted by pool at most once per MaxAge.
+// The zone is the area of the pickup address when it is one of opts.Zones, "unknown" when
+// the address has none and "other" otherwise, so addresses cannot grow the label set.
+func (m *Metrics) WatchPool(pool func() (map[string]int, error), opts PoolOptions) {
+    zones := make(map[string]string, len(opts.Zones))
+    for _, z := range opts.Zones {
+        zones[strings.ToLower(z)] = z
+    }
+    m.reg.MustRegister(&poolCollector{
+        count:  pool,
+        zones:  zones,
+        maxAge: opts.MaxAge,
+        desc:   prometheus.NewDesc("ecopoint_pool_orders", "Orders waiting in the open pool, by zone.", []string{"zone"}, nil),
+    })
+}
+
+type poolCollector struct {
+    count  func() (map[string]int, error)
+    zones  map[string]string // lower-cased area -> label
+    maxAge time.Duration
+    desc   *prometheus.Desc
+
+    mu   sync.Mutex // also keeps concurrent scrapes from counting at once
+    last map[string]int
+    at   time.Time
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) { ch <- c.desc }
+
+// Collect reports nothing when counting fails, so a database outage shows as missing data, not zero
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+    counts, err := c.counts()
+    if err != nil {
+        slog.Warn("metrics: count pool", "error", err)
+        return
+    }
+    for zone, n := range counts {
+        ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, float64(n), zone)
+    }
+}
+
+func (c *poolCollector) counts() (map[string]int, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    if c.last != nil && time.Since(c.at) < c.maxAge {
+        return c.last, nil
+    }
+    areas, err := c.count()
+    if err != nil {
+        return nil, err
+    }
+    res := map[string]int{}
+    for area, n := range areas {
+        res[c.zone(area)] += n
+    }
+    c.last, c.at = res, time.Now()
+    return res, nil
+}
+
+func (c *poolCollector) zone(area string) string {
+    if area == "" {
+        return "unknown"
+    }
+    if z, ok := c.zones[strings.ToLower(area)]; ok {
+        return z
+    }
+    return "other"
+}
+
+// UnaryServerInterceptor records grpc_server_* metrics for unary calls
+func (m *Metrics) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+        done := m.startRPC("unary", info.FullMethod)
+        resp, err := handler(ctx, req)
+        done(err)
+        return resp, err
+    }
+}
+
+// StreamServerInterceptor records grpc_server_* metrics for streams
+func (m *Metrics) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        typ := "bidi_stream"
+        switch {
+        case info.IsClientStream && !info.IsServerStream:
+            typ = "client_stream"
+        case info.IsServerStream && !info.IsClientStream:
+            typ = "server_stream"
+        }
+        done := m.startRPC(typ, info.FullMethod)
+        err := handler(srv, ss)
+        done(err)
+        return err
+    }
+}
+
+func (m *Metrics) startRPC(typ, fullMethod string) func(error) {
+    svc, method := splitMethod(fullMethod)
+    start := time.Now()
+    m.rpcStarted.WithLabelValues(typ, svc, method).Inc()
+    return func(err error) {
+        m.rpcHandled.WithLabelValues(typ, svc, method, status.Code(err).String()).Inc()
+        m.rpcSeconds.WithLabelValues(typ, svc, method).Observe(time.Since(start).Seconds())
+    }
+}
+
+// splitMethod turns "/pkg.Service/Method" into its service and method names
+func splitMethod(full string) (string, string) {
+    full = strings.TrimPrefix(full, "/")
+    if i := strings.LastIndex(full, "/"); i >= 0 {
+        return full[:i], full[i+1:]
+    }
+    return "unknown", full
+}
+
+var (
+    _ service.EventPublisher = (*Metrics)(nil)
+    _ service.OrderObserver  = (*Metrics)(nil)
+    _ ratelimit.Observer     = (*Metrics)(nil)
+)
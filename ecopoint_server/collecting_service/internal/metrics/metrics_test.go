@@ -0,0 +1,123 @@
+package metrics
+
+import (
+    "context"
+    "errors"
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/service"
+)
+
+func TestOrderLifecycleMetrics(t *testing.T) {
+    m := New()
+    svc := service.NewService(service.NewInMemoryRepo(), service.WithEventPublisher(m), service.WithOrderObserver(m))
+    create := func(id, address string) {
+        t.Helper()
+        _, err := svc.CreateOrder(service.CreateOrderInput{
+            ID:         id,
+            CustomerID: "u1",
+            Address:    models.Address{FullText: address, Lat: 10.77, Lng: 106.7},
+            Items:      []models.WasteItem{{Type: "paper", Weight: 2}},
+        })
+        if err != nil {
+            t.Fatalf("create %s: %v", id, err)
+        }
+    }
+    create("o1", "12 Lê Lợi, Bến Nghé, Quận 1, TP.HCM")
+    create("o2", "5 Nguyễn Trãi, Phường 2, Quận 5, TP.HCM")
+    create("o3", "somewhere")
+    create("o4", "8 Nguyễn Văn Linh, Tân Phong, Quận 7, TP.HCM")
+    create("o5", "1 Hùng Vương, Phường 1, TP. Vũng Tàu")
+    create("o6", "no area")
+    if _, err := svc.AcceptOrder("o1", "c1"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := svc.UpdateStatus("o1", models.StatusOnWay, "c1"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := svc.UpdateStatus("o1", models.StatusComplete, "c1"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := svc.CancelOrderByCustomer("o3", "u1", "changed my mind"); err != nil {
+        t.Fatal(err)
+    }
+
+    for label, want := range map[string]float64{OrderCreated: 6, OrderAccepted: 1, OrderOnWay: 1, OrderCompleted: 1, OrderCancelled: 1} {
+        if got := testutil.ToFloat64(m.orders.WithLabelValues(label)); got != want {
+            t.Errorf("orders{status=%q} = %v, want %v", label, got, want)
+        }
+    }
+    if n := testutil.CollectAndCount(m.timeToAccept); n != 1 {
+        t.Fatalf("expected the accept histogram to be collected, got %d", n)
+    }
+
+    // only configured zones get their own label
+    m.WatchPool(svc.PoolByArea, PoolOptions{Zones: []string{"quận 5, tp.hcm", "Quận 7, TP.HCM"}, MaxAge: time.Hour})
+    const want = `
+# HELP ecopoint_pool_orders Orders waiting in the open pool, by zone.
+# TYPE ecopoint_pool_orders gauge
+ecopoint_pool_orders{zone="other"} 1
+ecopoint_pool_orders{zone="quận 5, tp.hcm"} 1
+ecopoint_pool_orders{zone="Quận 7, TP.HCM"} 1
+ecopoint_pool_orders{zone="unknown"} 1
+`
+    if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(want), "ecopoint_pool_orders"); err != nil {
+        t.Fatal(err)
+    }
+    // within MaxAge a scrape reuses the last count instead of walking the pool again
+    create("o7", "9 Lê Văn Việt, Hiệp Phú, Quận 7, TP.HCM")
+    if err := testutil.GatherAndCompare(m.Registry(), strings.NewReader(want), "ecopoint_pool_orders"); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestRPCAndMongoMetrics(t *testing.T) {
+    m := New()
+    unary := m.UnaryServerInterceptor()
+    info := &grpc.UnaryServerInfo{FullMethod: "/ecopoint.collecting.v1.CollectingService/GetOrder"}
+    ok := func(context.Context, any) (any, error) { return "ok", nil }
+    missing := func(context.Context, any) (any, error) { return nil, status.Error(codes.NotFound, "no order") }
+    _, _ = unary(context.Background(), nil, info, ok)
+    _, _ = unary(context.Background(), nil, info, missing)
+    _, _ = unary(context.Background(), nil, info, missing)
+
+    labels := []string{"unary", "ecopoint.collecting.v1.CollectingService", "GetOrder"}
+    if got := testutil.ToFloat64(m.rpcStarted.WithLabelValues(labels...)); got != 3 {
+        t.Fatalf("started = %v, want 3", got)
+    }
+    if got := testutil.ToFloat64(m.rpcHandled.WithLabelValues(append(labels, "NotFound")...)); got != 2 {
+        t.Fatalf("handled{NotFound} = %v, want 2", got)
+    }
+
+    m.ObserveMongo("find", "orders", 3*time.Millisecond, false)
+    m.ObserveMongo("update", "orders", time.Second, true)
+
+    // a failing pool count leaves the gauge out instead of reporting zero
+    m.WatchPool(func() (map[string]int, error) { return nil, errors.New("mongo down") }, DefaultPoolOptions())
+
+    rec := httptest.NewRecorder()
+    m.Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+    body, _ := io.ReadAll(rec.Body)
+    for _, want := range []string{
+        `grpc_server_handled_total{grpc_code="OK",grpc_method="GetOrder"`,
+        `ecopoint_mongo_command_seconds_count{collection="orders",command="update",result="error"} 1`,
+        `go_goroutines`,
+    } {
+        if !strings.Contains(string(body), want) {
+            t.Errorf("missing %s", want)
+        }
+    }
+    if strings.Contains(string(body), "ecopoint_pool_orders{") {
+        t.Errorf("pool gauge reported despite a failed count")
+    }
+}
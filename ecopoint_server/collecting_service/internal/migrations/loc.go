@@ -0,0 +1,33 @@
+package migrations
+
+import (
+    "context"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+// backfillOrderLoc gives orders stored before the 2dsphere index their GeoJSON pickup point,
+// built the way the repository writes it: [lng, lat] of pick_address_snapshot. Orders without
+// numeric coordinates are left alone; a point of nulls would be rejected by the index.
+func backfillOrderLoc(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error) {
+    orders := db.Collection("orders")
+    filter := bson.M{
+        "loc":                       bson.M{"$exists": false},
+        "pick_address_snapshot.lat": bson.M{"$type": "number"},
+        "pick_address_snapshot.lng": bson.M{"$type": "number"},
+    }
+    if dryRun {
+        return orders.CountDocuments(ctx, filter)
+    }
+    res, err := orders.UpdateMany(ctx, filter, bson.A{
+        bson.M{"$set": bson.M{"loc": bson.M{
+            "type":        "Point",
+            "coordinates": bson.A{"$pick_address_snapshot.lng", "$pick_address_snapshot.lat"},
+        }}},
+    })
+    if err != nil {
+        return 0, err
+    }
+    return res.ModifiedCount, nil
+}
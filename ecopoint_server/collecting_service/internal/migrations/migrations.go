@@ -0,0 +1,272 @@
+// Package migrations versions the shape of the documents in MongoDB. Each Migration has a
+// version, applied in ascending order and recorded in schema_migrations once it succeeds.
+// A migration that fails is not recorded and runs again next time, so Up must be safe to
+// repeat after a partial run, usually by only touching documents still in the old shape.
+package migrations
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "log/slog"
+    "os"
+    "strconv"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Collection records applied migrations, one document per version, plus the run lock
+const Collection = "schema_migrations"
+
+// lockTTL bounds how long a crashed run keeps others out; a live run renews the lock every
+// lockTTL/3, so a migration may take longer than lockTTL
+const lockTTL = 10 * time.Minute
+
+var (
+    ErrLocked   = errors.New("another migration run holds the lock")
+    ErrLockLost = errors.New("migration lock lost to another run")
+)
+
+// All is every migration of this build, oldest first; append new ones with the next version
+var All = []Migration{
+    {Version: 1, Name: "backfill_order_loc", Up: backfillOrderLoc},
+}
+
+type Migration struct {
+    Version int
+    Name    string
+    // Up changes the documents still in the old shape and returns how many; with dryRun
+    // set it only counts them
+    Up func(ctx context.Context, db *mongo.Database, dryRun bool) (int64, error)
+}
+
+// Record is the schema_migrations document of an applied migration
+type Record struct {
+    Version   int       `bson:"version"`
+    Name      string    `bson:"name"`
+    AppliedAt time.Time `bson:"applied_at"`
+    Duration  int64     `bson:"duration_ms"`
+    Affected  int64     `bson:"affected"`
+}
+
+// State is a known migration and, once applied, its record
+type State struct {
+    Migration
+    Applied *Record
+}
+
+// Result is the outcome of one migration of a run
+type Result struct {
+    Migration
+    Affected int64
+    Duration time.Duration
+}
+
+type Runner struct {
+    db    *mongo.Database
+    col   *mongo.Collection
+    all   []Migration
+    now   func() time.Time
+    renew time.Duration // heartbeat of the run lock
+}
+
+// NewRunner checks that versions are positive and strictly ascending
+func NewRunner(db *mongo.Database, all []Migration) (*Runner, error) {
+    if err := check(all); err != nil {
+        return nil, err
+    }
+    return &Runner{db: db, col: db.Collection(Collection), all: all, now: time.Now, renew: lockTTL / 3}, nil
+}
+
+func check(all []Migration) error {
+    prev := 0
+    for _, m := range all {
+        if m.Version <= prev {
+            return fmt.Errorf("migration %d %s: versions must be positive and ascending", m.Version, m.Name)
+        }
+        if m.Up == nil {
+            return fmt.Errorf("migration %d %s has no Up", m.Version, m.Name)
+        }
+        prev = m.Version
+    }
+    return nil
+}
+
+// Init creates the unique index on version
+func (r *Runner) Init(ctx context.Context) error {
+    _, err := r.col.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "version", Value: 1}},
+        Options: options.Index().SetUnique(true).SetPartialFilterExpression(bson.M{"version": bson.M{"$exists": true}}),
+    })
+    return err
+}
+
+// Status lists every known migration with its record, and fails when the database has
+// versions this build does not know, i.e. it was migrated by a newer build
+func (r *Runner) Status(ctx context.Context) ([]State, error) {
+    applied, err := r.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+    known := map[int]bool{}
+    res := make([]State, 0, len(r.all))
+    for _, m := range r.all {
+        known[m.Version] = true
+        res = append(res, State{Migration: m, Applied: applied[m.Version]})
+    }
+    for v, rec := range applied {
+        if !known[v] {
+            return res, fmt.Errorf("database has migration %d %s, unknown to this build", v, rec.Name)
+        }
+    }
+    return res, nil
+}
+
+// Pending returns the migrations not applied yet
+func (r *Runner) Pending(ctx context.Context) ([]Migration, error) {
+    applied, err := r.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+    return plan(r.all, applied, 0), nil
+}
+
+// Up applies the pending migrations up to and including version to (0 = all) and stops at
+// the first failure. With dryRun nothing is written or recorded and Affected is what the
+// migration would change.
+func (r *Runner) Up(ctx context.Context, to int, dryRun bool) ([]Result, error) {
+    if !dryRun {
+        locked, release, err := r.lock(ctx)
+        if err != nil {
+            return nil, err
+        }
+        defer release()
+        ctx = locked
+    }
+    applied, err := r.applied(ctx)
+    if err != nil {
+        return nil, err
+    }
+    var res []Result
+    for _, m := range plan(r.all, applied, to) {
+        start := r.now()
+        n, err := m.Up(ctx, r.db, dryRun)
+        if err != nil {
+            if cause := context.Cause(ctx); errors.Is(cause, ErrLockLost) {
+                err = cause
+            }
+            return res, fmt.Errorf("migration %d %s: %w", m.Version, m.Name, err)
+        }
+        took := r.now().Sub(start)
+        res = append(res, Result{Migration: m, Affected: n, Duration: took})
+        if dryRun {
+            continue
+        }
+        rec := Record{Version: m.Version, Name: m.Name, AppliedAt: r.now(), Duration: took.Milliseconds(), Affected: n}
+        if _, err := r.col.InsertOne(ctx, rec); err != nil {
+            return res, fmt.Errorf("record migration %d %s: %w", m.Version, m.Name, err)
+        }
+    }
+    return res, nil
+}
+
+// plan is the migrations of all missing from applied, up to version to (0 = all)
+func plan(all []Migration, applied map[int]*Record, to int) []Migration {
+    var res []Migration
+    for _, m := range all {
+        if to > 0 && m.Version > to {
+            break
+        }
+        if applied[m.Version] == nil {
+            res = append(res, m)
+        }
+    }
+    return res
+}
+
+func (r *Runner) applied(ctx context.Context) (map[int]*Record, error) {
+    cur, err := r.col.Find(ctx, bson.M{"version": bson.M{"$exists": true}})
+    if err != nil {
+        return nil, err
+    }
+    var recs []*Record
+    if err := cur.All(ctx, &recs); err != nil {
+        return nil, err
+    }
+    res := make(map[int]*Record, len(recs))
+    for _, rec := range recs {
+        res[rec.Version] = rec
+    }
+    return res, nil
+}
+
+// lock takes the run lock, a document with a fixed _id: the upsert matches only an expired
+// lock, so while another run holds it the insert fails on the duplicate _id. Until release
+// the lock is renewed in the background; the returned context is cancelled with ErrLockLost
+// when a renewal finds the lock taken over, so the run stops instead of racing the other.
+func (r *Runner) lock(ctx context.Context) (context.Context, func(), error) {
+    host, _ := os.Hostname()
+    owner := host + "/" + strconv.Itoa(os.Getpid()) + "/" + strconv.FormatInt(r.now().UnixNano(), 36)
+    now := r.now()
+    _, err := r.col.UpdateOne(ctx,
+        bson.M{"_id": "lock", "expires_at": bson.M{"$lt": now}},
+        bson.M{"$set": bson.M{"owner": owner, "locked_at": now, "expires_at": now.Add(lockTTL)}},
+        options.Update().SetUpsert(true),
+    )
+    if mongo.IsDuplicateKeyError(err) {
+        var held struct {
+            Owner     string    `bson:"owner"`
+            ExpiresAt time.Time `bson:"expires_at"`
+        }
+        _ = r.col.FindOne(ctx, bson.M{"_id": "lock"}).Decode(&held)
+        return nil, nil, fmt.Errorf("%w: %s until %s", ErrLocked, held.Owner, held.ExpiresAt.UTC().Format(time.RFC3339))
+    }
+    if err != nil {
+        return nil, nil, err
+    }
+
+    locked, cancel := context.WithCancelCause(ctx)
+    done := make(chan struct{})
+    go func() {
+        defer close(done)
+        t := time.NewTicker(r.renew)
+        defer t.Stop()
+        for {
+            select {
+            case <-locked.Done():
+                return
+            case <-t.C:
+            }
+            if err := r.heartbeat(locked, owner); errors.Is(err, ErrLockLost) {
+                cancel(err)
+                return
+            } else if err != nil && locked.Err() == nil {
+                // a blip; the lock has time left until the next beat
+                slog.Warn("migration lock renewal", "error", err)
+            }
+        }
+    }()
+    return locked, func() {
+        cancel(nil)
+        <-done
+        // the run's own ctx may be done by now
+        ctx, stop := context.WithTimeout(context.Background(), 5*time.Second)
+        defer stop()
+        _, _ = r.col.DeleteOne(ctx, bson.M{"_id": "lock", "owner": owner})
+    }, nil
+}
+
+// heartbeat pushes the expiry of the lock held by owner out by lockTTL
+func (r *Runner) heartbeat(ctx context.Context, owner string) error {
+    res, err := r.col.UpdateOne(ctx, bson.M{"_id": "lock", "owner": owner}, bson.M{"$set": bson.M{"expires_at": r.now().Add(lockTTL)}})
+    if err != nil {
+        return err
+    }
+    if res.MatchedCount == 0 {
+        return ErrLockLost
+    }
+    return nil
+}
@@ -0,0 +1,54 @@
+package migrations
+
+import (
+    "context"
+    "strings"
+    "testing"
+
+    "go.mongodb.org/mongo-driver/mongo"
+)
+
+func noop(context.Context, *mongo.Database, bool) (int64, error) { return 0, nil }
+
+func TestAllIsOrdered(t *testing.T) {
+    if err := check(All); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestCheck(t *testing.T) {
+    for name, tc := range map[string]struct {
+        all  []Migration
+        want string
+    }{
+        "zero":      {[]Migration{{Version: 0, Name: "a", Up: noop}}, "positive and ascending"},
+        "duplicate": {[]Migration{{Version: 1, Name: "a", Up: noop}, {Version: 1, Name: "b", Up: noop}}, "1 b"},
+        "unordered": {[]Migration{{Version: 2, Name: "a", Up: noop}, {Version: 1, Name: "b", Up: noop}}, "1 b"},
+        "no up":     {[]Migration{{Version: 1, Name: "a"}}, "no Up"},
+    } {
+        if err := check(tc.all); err == nil || !strings.Contains(err.Error(), tc.want) {
+            t.Errorf("%s: err = %v", name, err)
+        }
+    }
+}
+
+func TestPlan(t *testing.T) {
+    all := []Migration{{Version: 1, Name: "a", Up: noop}, {Version: 2, Name: "b", Up: noop}, {Version: 5, Name: "c", Up: noop}}
+    versions := func(ms []Migration) []int {
+        var res []int
+        for _, m := range ms {
+            res = append(res, m.Version)
+        }
+        return res
+    }
+    applied := map[int]*Record{2: {Version: 2}}
+    if got := versions(plan(all, applied, 0)); len(got) != 2 || got[0] != 1 || got[1] != 5 {
+        t.Errorf("plan = %v, want [1 5]", got)
+    }
+    if got := versions(plan(all, applied, 4)); len(got) != 1 || got[0] != 1 {
+        t.Errorf("plan to 4 = %v, want [1]", got)
+    }
+    if got := plan(all, map[int]*Record{1: {}, 2: {}, 5: {}}, 0); len(got) != 0 {
+        t.Errorf("up to date: plan = %v", versions(got))
+    }
+}
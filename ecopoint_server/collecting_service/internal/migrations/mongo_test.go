@@ -0,0 +1,171 @@
+package migrations
+
+import (
+    "context"
+    "errors"
+    "strings"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/integration/mtest"
+)
+
+// the tests below run against the driver's mock deployment: each command gets the next
+// queued response and is recorded for inspection
+
+func mock(t *testing.T) *mtest.T {
+    return mtest.New(t, mtest.NewOptions().ClientType(mtest.Mock))
+}
+
+func ok(elems ...bson.E) bson.D { return mtest.CreateSuccessResponse(elems...) }
+
+// command is the next command sent, by name
+func command(mt *mtest.T) (string, bson.Raw) {
+    mt.Helper()
+    e := mt.GetStartedEvent()
+    if e == nil {
+        mt.Fatal("no command was sent")
+    }
+    return e.CommandName, e.Command
+}
+
+func TestBackfillOrderLoc(t *testing.T) {
+    mt := mock(t)
+    mt.Run("dry run counts", func(mt *mtest.T) {
+        mt.AddMockResponses(mtest.CreateCursorResponse(0, "db.orders", mtest.FirstBatch, bson.D{{Key: "n", Value: int32(3)}}))
+        n, err := backfillOrderLoc(context.Background(), mt.DB, true)
+        if err != nil || n != 3 {
+            t.Fatalf("n = %d, err = %v", n, err)
+        }
+        name, cmd := command(mt)
+        if name != "aggregate" || !strings.Contains(cmd.String(), `"pick_address_snapshot.lat"`) {
+            t.Errorf("sent %s %s", name, cmd)
+        }
+        if mt.GetStartedEvent() != nil {
+            t.Error("a dry run must not write")
+        }
+    })
+    mt.Run("update builds the point", func(mt *mtest.T) {
+        mt.AddMockResponses(ok(bson.E{Key: "n", Value: 2}, bson.E{Key: "nModified", Value: 2}))
+        n, err := backfillOrderLoc(context.Background(), mt.DB, false)
+        if err != nil || n != 2 {
+            t.Fatalf("n = %d, err = %v", n, err)
+        }
+        name, cmd := command(mt)
+        update := cmd.Lookup("updates", "0", "u").String()
+        if name != "update" || !strings.Contains(update, `"$pick_address_snapshot.lng","$pick_address_snapshot.lat"`) ||
+            !strings.Contains(cmd.Lookup("updates", "0", "q").String(), `"loc": {"$exists": false}`) {
+            t.Errorf("sent %s %s", name, cmd)
+        }
+    })
+}
+
+func TestLock(t *testing.T) {
+    mt := mock(t)
+    mt.Run("taken", func(mt *mtest.T) {
+        r := &Runner{col: mt.Coll, now: time.Now, renew: time.Hour}
+        mt.AddMockResponses(ok(bson.E{Key: "n", Value: 1}), ok(bson.E{Key: "n", Value: 1}))
+        _, release, err := r.lock(context.Background())
+        if err != nil {
+            t.Fatal(err)
+        }
+        release()
+        if name, cmd := command(mt); name != "update" || !strings.Contains(cmd.Lookup("updates", "0", "q").String(), `"expires_at": {"$lt"`) {
+            t.Errorf("lock sent %s %s", name, cmd)
+        }
+        if name, cmd := command(mt); name != "delete" || !strings.Contains(cmd.Lookup("deletes", "0", "q").String(), `"owner"`) {
+            t.Errorf("release sent %s %s", name, cmd)
+        }
+    })
+    mt.Run("held by another run", func(mt *mtest.T) {
+        r := &Runner{col: mt.Coll, now: time.Now, renew: time.Hour}
+        until := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+        mt.AddMockResponses(
+            mtest.CreateWriteErrorsResponse(mtest.WriteError{Index: 0, Code: 11000, Message: "duplicate key"}),
+            mtest.CreateCursorResponse(0, "db.schema_migrations", mtest.FirstBatch, bson.D{{Key: "_id", Value: "lock"}, {Key: "owner", Value: "host/1/x"}, {Key: "expires_at", Value: until}}),
+        )
+        _, _, err := r.lock(context.Background())
+        if !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), "host/1/x until 2026-10-19T12:00:00Z") {
+            t.Fatalf("err = %v", err)
+        }
+    })
+    mt.Run("lost while running", func(mt *mtest.T) {
+        r := &Runner{col: mt.Coll, now: time.Now, renew: 10 * time.Millisecond}
+        mt.AddMockResponses(
+            ok(bson.E{Key: "n", Value: 1}),                                     // lock
+            ok(bson.E{Key: "n", Value: 1}, bson.E{Key: "nModified", Value: 1}), // first beat
+            ok(bson.E{Key: "n", Value: 0}, bson.E{Key: "nModified", Value: 0}), // taken over
+            ok(bson.E{Key: "n", Value: 0}),                                     // release
+        )
+        ctx, release, err := r.lock(context.Background())
+        if err != nil {
+            t.Fatal(err)
+        }
+        select {
+        case <-ctx.Done():
+        case <-time.After(5 * time.Second):
+            t.Fatal("the run was not stopped")
+        }
+        release()
+        if !errors.Is(context.Cause(ctx), ErrLockLost) {
+            t.Fatalf("cause = %v", context.Cause(ctx))
+        }
+        command(mt)
+        if name, cmd := command(mt); name != "update" || !strings.Contains(cmd.Lookup("updates", "0", "q").String(), `"owner"`) {
+            t.Errorf("heartbeat sent %s %s", name, cmd)
+        }
+    })
+}
+
+func TestUp(t *testing.T) {
+    mt := mock(t)
+    var dry []bool
+    all := []Migration{{Version: 1, Name: "a", Up: func(_ context.Context, _ *mongo.Database, dryRun bool) (int64, error) {
+        dry = append(dry, dryRun)
+        return 4, nil
+    }}}
+    runner := func(mt *mtest.T) *Runner {
+        r, err := NewRunner(mt.DB, all)
+        if err != nil {
+            t.Fatal(err)
+        }
+        r.col, r.renew = mt.Coll, time.Hour
+        return r
+    }
+    noneApplied := mtest.CreateCursorResponse(0, "db.schema_migrations", mtest.FirstBatch)
+
+    mt.Run("dry run", func(mt *mtest.T) {
+        dry = nil
+        mt.AddMockResponses(noneApplied)
+        res, err := runner(mt).Up(context.Background(), 0, true)
+        if err != nil || len(res) != 1 || res[0].Affected != 4 || len(dry) != 1 || !dry[0] {
+            t.Fatalf("res = %v, err = %v, dry = %v", res, err, dry)
+        }
+        if name, _ := command(mt); name != "find" {
+            t.Errorf("sent %s; a dry run neither locks nor records", name)
+        }
+        if e := mt.GetStartedEvent(); e != nil {
+            t.Errorf("unexpected %s", e.CommandName)
+        }
+    })
+    mt.Run("applies and records", func(mt *mtest.T) {
+        dry = nil
+        mt.AddMockResponses(ok(bson.E{Key: "n", Value: 1}), noneApplied, ok(bson.E{Key: "n", Value: 1}), ok(bson.E{Key: "n", Value: 1}))
+        res, err := runner(mt).Up(context.Background(), 0, false)
+        if err != nil || len(res) != 1 || len(dry) != 1 || dry[0] {
+            t.Fatalf("res = %v, err = %v, dry = %v", res, err, dry)
+        }
+        var names []string
+        for _, e := range mt.GetAllStartedEvents() {
+            names = append(names, e.CommandName)
+        }
+        if strings.Join(names, ",") != "update,find,insert,delete" {
+            t.Errorf("commands = %v", names)
+        }
+        if rec := mt.GetAllStartedEvents()[2].Command.Lookup("documents", "0"); rec.Document().Lookup("affected").AsInt64() != 4 {
+            t.Errorf("record = %s", rec)
+        }
+    })
+}
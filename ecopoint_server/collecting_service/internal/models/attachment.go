@@ -0,0 +1,41 @@
+package models
+
+import "time"
+
+type AttachmentKind string
+
+const (
+    AttachmentItemPhoto       AttachmentKind = "item_photo"       // uploaded by the customer for one waste item
+    AttachmentCompletionProof AttachmentKind = "completion_proof" // uploaded by the collector at pickup
+)
+
+// Attachment is an uploaded file; the bytes live in the blob store under Key.
+// Pending attachments expire unless the upload is completed before ExpiresAt.
+type Attachment struct {
+    ID          string         `bson:"id"`
+    OrderID     string         `bson:"order_id"`
+    Kind        AttachmentKind `bson:"kind"`
+    ItemIndex   int            `bson:"item_index"` // item photos only
+    Key         string         `bson:"key"`
+    ContentType string         `bson:"content_type"`
+    Size        int64          `bson:"size"`
+    UploadedBy  string         `bson:"uploaded_by"`
+    CreatedAt   time.Time      `bson:"created_at"`
+    UploadedAt  *time.Time     `bson:"uploaded_at,omitempty"`
+    ExpiresAt   *time.Time     `bson:"expires_at,omitempty"`
+}
+
+// Ref is the copy kept on the order once the upload is complete
+func (a *Attachment) Ref() AttachmentRef {
+    return AttachmentRef{ID: a.ID, Kind: a.Kind, ContentType: a.ContentType, Size: a.Size, UploadedBy: a.UploadedBy, UploadedAt: *a.UploadedAt}
+}
+
+// AttachmentRef points from an order or waste item to a completed attachment
+type AttachmentRef struct {
+    ID          string         `bson:"id"`
+    Kind        AttachmentKind `bson:"kind"`
+    ContentType string         `bson:"content_type"`
+    Size        int64          `bson:"size"`
+    UploadedBy  string         `bson:"uploaded_by"`
+    UploadedAt  time.Time      `bson:"uploaded_at"`
+}
@@ -0,0 +1,22 @@
+package models
+
+import "time"
+
+// ChatMessage is one message in an order's conversation between customer and collector
+type ChatMessage struct {
+    ID         string     `bson:"id"`
+    OrderID    string     `bson:"order_id"`
+    SenderID   string     `bson:"sender_id"`
+    SenderRole Role       `bson:"sender_role"`
+    Text       string     `bson:"text"`
+    SentAt     time.Time  `bson:"sent_at"`
+    ReadAt     *time.Time `bson:"read_at,omitempty"` // when the other participant read it
+}
+
+// ChatReceipt tells the sender that ReaderID has read every message up to UpTo
+type ChatReceipt struct {
+    OrderID  string
+    ReaderID string
+    UpTo     time.Time
+    At       time.Time
+}
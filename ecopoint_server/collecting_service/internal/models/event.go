@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+type EventType string
+
+const (
+    EventOrderAccepted  EventType = "order.accepted"
+    EventOrderOnWay     EventType = "order.on_way"
+    EventOrderCompleted EventType = "order.completed"
+    EventOrderReleased  EventType = "order.released"  // watchdog put a stalled order back into the pool
+    EventOrderCancelled EventType = "order.cancelled"
+)
+
+// ParseEventType accepts the string form of a known event type
+func ParseEventType(s string) (EventType, bool) {
+    switch t := EventType(s); t {
+    case EventOrderAccepted, EventOrderOnWay, EventOrderCompleted, EventOrderReleased, EventOrderCancelled:
+        return t, true
+    }
+    return "", false
+}
+
+// OrderEvent is published after an order changed. Order is a snapshot taken at publish time.
+type OrderEvent struct {
+    Type       EventType
+    Order      Order
+    Actor      string
+    Reason     string
+    Recipients []string // user ids to notify
+    At         time.Time
+}
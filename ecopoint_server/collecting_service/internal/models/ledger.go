@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+type LedgerKind string
+
+const (
+    LedgerCancelFee          LedgerKind = "cancel_fee"          // charged to a customer who cancelled late
+    LedgerCancelCompensation LedgerKind = "cancel_compensation" // paid to the collector of that order
+)
+
+// LedgerEntry is one movement on a user's points balance; debits are negative.
+// ID is derived from the order and kind so retries cannot book an entry twice.
+type LedgerEntry struct {
+    ID      string     `bson:"_id"`
+    UserID  string     `bson:"user_id"`
+    OrderID string     `bson:"order_id"`
+    Kind    LedgerKind `bson:"kind"`
+    Points  float64    `bson:"points"`
+    At      time.Time  `bson:"at"`
+}
@@ -2,6 +2,9 @@ package models
 
 import (
     "errors"
+    "fmt"
+    "log/slog"
+    "strings"
     "time"
 )
 
@@ -21,14 +24,34 @@ type Address struct {
     Lng      float64 `bson:"lng"`
 }
 
+// Area is the trailing district and city of the address ("12 Lê Lợi, Bến Nghé, Quận 1, TP.HCM"
+// -> "Quận 1, TP.HCM"), or "" when the text has fewer than three parts
+func (a Address) Area() string {
+    parts := strings.Split(a.FullText, ",")
+    if len(parts) < 3 {
+        return ""
+    }
+    area := parts[len(parts)-2:]
+    for i := range area {
+        area[i] = strings.TrimSpace(area[i])
+    }
+    return strings.Join(area, ", ")
+}
+
 type CustomerSnapshot struct {
     DisplayName string `bson:"display_name"`
     Phone       string `bson:"phone"`
 }
 
+// LogValue names the phone so the logging handler can mask it
+func (c CustomerSnapshot) LogValue() slog.Value {
+    return slog.GroupValue(slog.String("display_name", c.DisplayName), slog.String("phone", c.Phone))
+}
+
 type WasteItem struct {
-    Type   string  `bson:"type"`
-    Weight float64 `bson:"weight"`
+    Type        string          `bson:"type"`
+    Weight      float64         `bson:"weight"`
+    Attachments []AttachmentRef `bson:"attachments,omitempty"` // photos of this item
 }
 
 type Order struct {
@@ -47,16 +70,91 @@ type Order struct {
     CreatedAt           time.Time         `bson:"created_at"`
     UpdatedAt           time.Time         `bson:"updated_at"`
     AcceptedAt          *time.Time        `bson:"accepted_at,omitempty"`
+    OnWayAt             *time.Time        `bson:"on_way_at,omitempty"`
     CompletedAt         *time.Time        `bson:"completed_at,omitempty"`
     CancelReason        string            `bson:"cancel_reason,omitempty"`
     CancelSide          CancelBy          `bson:"cancel_side,omitempty"`
+    CancelFee           float64           `bson:"cancel_fee,omitempty"` // points charged to the customer
     Version             int64             `bson:"version"`
+    History             []StatusChange    `bson:"history,omitempty"`
+    Attachments         []AttachmentRef   `bson:"attachments,omitempty"` // completion proofs
+}
+
+// StatusChange is one entry of an order's audit trail
+type StatusChange struct {
+    From   OrderStatus `bson:"from"`
+    To     OrderStatus `bson:"to"`
+    At     time.Time   `bson:"at"`
+    Actor  string      `bson:"actor"` // user id, or "system"
+    Reason string      `bson:"reason,omitempty"`
+}
+
+// ActorSystem marks changes made by background jobs
+const ActorSystem = "system"
+
+// AttachmentCount counts completion proofs and item photos
+func (o *Order) AttachmentCount() int {
+    n := len(o.Attachments)
+    for _, it := range o.Items {
+        n += len(it.Attachments)
+    }
+    return n
+}
+
+// Record appends a history entry for a move to next; call before changing Status
+func (o *Order) Record(next OrderStatus, at time.Time, actor, reason string) {
+    o.History = append(o.History, StatusChange{From: o.Status, To: next, At: at, Actor: actor, Reason: reason})
+}
+
+// LogValue keeps log lines short and free of contact details
+func (o *Order) LogValue() slog.Value {
+    attrs := []slog.Attr{slog.String("id", o.ID), slog.String("status", string(o.Status)), slog.String("customer_id", o.CustomerID)}
+    if o.AcceptedBy != nil {
+        attrs = append(attrs, slog.String("collector_id", *o.AcceptedBy))
+    }
+    return slog.GroupValue(append(attrs, slog.Int64("version", o.Version))...)
+}
+
+// Clone returns a copy that shares no slices or pointers with o
+func (o *Order) Clone() *Order {
+    cp := *o
+    cp.Items = append([]WasteItem(nil), o.Items...)
+    for i := range cp.Items {
+        cp.Items[i].Attachments = append([]AttachmentRef(nil), o.Items[i].Attachments...)
+    }
+    cp.History = append([]StatusChange(nil), o.History...)
+    cp.Attachments = append([]AttachmentRef(nil), o.Attachments...)
+    cp.AcceptedBy = clonePtr(o.AcceptedBy)
+    cp.AcceptedAt = clonePtr(o.AcceptedAt)
+    cp.OnWayAt = clonePtr(o.OnWayAt)
+    cp.CompletedAt = clonePtr(o.CompletedAt)
+    return &cp
+}
+
+func clonePtr[T any](p *T) *T {
+    if p == nil {
+        return nil
+    }
+    v := *p
+    return &v
 }
 
 var (
     ErrInvalidStatusTransition = errors.New("invalid status transition")
+    ErrVersionConflict         = errors.New("order was modified concurrently")
+    ErrUnknownStatus           = errors.New("unknown order status")
 )
 
+// ParseOrderStatus accepts only the known status strings; it never falls back to a default
+func ParseOrderStatus(s string) (OrderStatus, error) {
+    switch st := OrderStatus(s); st {
+    case StatusCreated, StatusAccepted, StatusOnWay, StatusComplete, StatusCancelled:
+        return st, nil
+    default:
+        return "", fmt.Errorf("%w: %q", ErrUnknownStatus, s)
+    }
+}
+
 // CanTransition validates allowed transitions
 func (o *Order) CanTransition(next OrderStatus) bool {
     switch o.Status {
@@ -0,0 +1,20 @@
+package models
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestParseOrderStatus(t *testing.T) {
+    for _, s := range []string{"created", "accepted", "on_way", "complete", "cancelled"} {
+        st, err := ParseOrderStatus(s)
+        if err != nil || string(st) != s {
+            t.Fatalf("parse %q: got %q err %v", s, st, err)
+        }
+    }
+    for _, s := range []string{"", "onway", "ON_WAY", "completed", " created"} {
+        if _, err := ParseOrderStatus(s); !errors.Is(err, ErrUnknownStatus) {
+            t.Fatalf("parse %q: expected ErrUnknownStatus, got %v", s, err)
+        }
+    }
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+type StrikeKind string
+
+const (
+    StrikeNoShow    StrikeKind = "no_show"   // accepted but never set off
+    StrikeAbandoned StrikeKind = "abandoned" // on the way but never completed
+    StrikeCancelled StrikeKind = "cancelled" // collector cancelled after accepting
+    StrikeLate      StrikeKind = "late"      // completed well after the promised ETA
+)
+
+// Strike is a mark against a collector, kept for the penalty policy
+type Strike struct {
+    CollectorID string     `bson:"collector_id"`
+    OrderID     string     `bson:"order_id"`
+    Kind        StrikeKind `bson:"kind"`
+    At          time.Time  `bson:"at"`
+}
+
+// CollectorPenalty is the current cooldown state of a collector. Strikes at or before
+// ClearedAt no longer count towards penalties.
+type CollectorPenalty struct {
+    CollectorID   string    `bson:"collector_id"`
+    CooldownUntil time.Time `bson:"cooldown_until,omitempty"`
+    Reason        string    `bson:"reason,omitempty"`
+    Points        int       `bson:"points"`
+    ClearedAt     time.Time `bson:"cleared_at,omitempty"`
+    ClearedBy     string    `bson:"cleared_by,omitempty"`
+    UpdatedAt     time.Time `bson:"updated_at"`
+}
+
+// Active reports whether the collector is in cooldown at now
+func (p *CollectorPenalty) Active(now time.Time) bool {
+    return p != nil && now.Before(p.CooldownUntil)
+}
@@ -0,0 +1,57 @@
+package models
+
+import "time"
+
+type Role string
+
+const (
+    RoleCustomer  Role = "customer"
+    RoleCollector Role = "collector"
+    RoleAdmin     Role = "admin" // operators; never a side of an order
+)
+
+// Rating is one side's feedback on a completed order. Side is the rater's role:
+// the customer rates the collector and the collector rates the customer.
+type Rating struct {
+    OrderID   string    `bson:"order_id"`
+    Side      Role      `bson:"side"`
+    RaterID   string    `bson:"rater_id"`
+    RateeID   string    `bson:"ratee_id"`
+    Stars     int       `bson:"stars"`
+    Tags      []string  `bson:"tags,omitempty"`
+    Comment   string    `bson:"comment,omitempty"`
+    CreatedAt time.Time `bson:"created_at"`
+}
+
+// RateeRole is the role the rated user acted in on the order
+func (r *Rating) RateeRole() Role {
+    if r.Side == RoleCustomer {
+        return RoleCollector
+    }
+    return RoleCustomer
+}
+
+// UserRating is the running aggregate of ratings a user received in one role
+type UserRating struct {
+    UserID    string           `bson:"user_id"`
+    Role      Role             `bson:"role"`
+    Count     int64            `bson:"count"`
+    Sum       int64            `bson:"sum"`
+    Average   float64          `bson:"average"`
+    TagCounts map[string]int64 `bson:"tag_counts,omitempty"`
+    UpdatedAt time.Time        `bson:"updated_at"`
+}
+
+// Add folds one rating into the aggregate
+func (u *UserRating) Add(r *Rating) {
+    u.Count++
+    u.Sum += int64(r.Stars)
+    u.Average = float64(u.Sum) / float64(u.Count)
+    if len(r.Tags) > 0 && u.TagCounts == nil {
+        u.TagCounts = map[string]int64{}
+    }
+    for _, t := range r.Tags {
+        u.TagCounts[t]++
+    }
+    u.UpdatedAt = r.CreatedAt
+}
@@ -0,0 +1,26 @@
+package notify
+
+import (
+    "context"
+
+    "ecopoint/collecting_service/pb/accountpb"
+)
+
+// AccountTokens reads device tokens registered through AccountService.UpsertDeviceToken
+type AccountTokens struct {
+    Client accountpb.AccountServiceClient
+}
+
+func (a AccountTokens) DeviceTokens(ctx context.Context, userID string) ([]string, error) {
+    res, err := a.Client.ListDeviceTokens(ctx, &accountpb.ListDeviceTokensRequest{UserId: userID})
+    if err != nil {
+        return nil, err
+    }
+    tokens := make([]string, 0, len(res.Tokens))
+    for _, t := range res.Tokens {
+        if t.Token != "" {
+            tokens = append(tokens, t.Token)
+        }
+    }
+    return tokens, nil
+}
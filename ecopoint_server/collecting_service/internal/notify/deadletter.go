@@ -0,0 +1,45 @@
+package notify
+
+import (
+    "sync"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// DeadLetter is a notification given up on, kept for inspection and manual replay
+type DeadLetter struct {
+    ID           string           `bson:"_id"`
+    Event        models.EventType `bson:"event"`
+    OrderID      string           `bson:"order_id"`
+    Notification Notification     `bson:"notification"`
+    Attempts     int              `bson:"attempts"`
+    Error        string           `bson:"error"`
+    At           time.Time        `bson:"at"`
+}
+
+type DeadLetterStore interface {
+    AddDeadLetter(d DeadLetter) error
+}
+
+type InMemoryDeadLetterStore struct {
+    mu      sync.Mutex
+    letters []DeadLetter
+}
+
+func NewInMemoryDeadLetterStore() *InMemoryDeadLetterStore {
+    return &InMemoryDeadLetterStore{}
+}
+
+func (m *InMemoryDeadLetterStore) AddDeadLetter(d DeadLetter) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.letters = append(m.letters, d)
+    return nil
+}
+
+func (m *InMemoryDeadLetterStore) List() []DeadLetter {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return append([]DeadLetter(nil), m.letters...)
+}
@@ -0,0 +1,240 @@
+package notify
+
+import (
+    "context"
+    "errors"
+    "log/slog"
+    "math/rand"
+    "sync"
+    "time"
+
+    "github.com/google/uuid"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrQueueFull        = errors.New("notification queue is full")
+    ErrDispatcherClosed = errors.New("notification dispatcher is closed")
+)
+
+// RetryPolicy retries transient failures with exponential backoff and jitter
+type RetryPolicy struct {
+    MaxAttempts int           `yaml:"max_attempts"` // including the first; <= 1 disables retries
+    BaseDelay   time.Duration `yaml:"base_delay"`
+    MaxDelay    time.Duration `yaml:"max_delay"`
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+    return RetryPolicy{MaxAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Minute}
+}
+
+// Backoff is the wait after the given failed attempt (1-based): half of
+// BaseDelay*2^(attempt-1), capped at MaxDelay, plus up to the same again at random
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+    d := p.BaseDelay
+    for i := 1; i < attempt && d < p.MaxDelay; i++ {
+        d *= 2
+    }
+    if p.MaxDelay > 0 && d > p.MaxDelay {
+        d = p.MaxDelay
+    }
+    if d <= 0 {
+        return 0
+    }
+    half := d / 2
+    return half + time.Duration(rand.Int63n(int64(d-half)+1))
+}
+
+// Dispatcher turns order events into push notifications. It implements
+// service.EventPublisher: Publish only enqueues, workers started by Start deliver.
+type Dispatcher struct {
+    notifier  Notifier
+    templates Templates
+    prefs     PreferenceStore
+    dead      DeadLetterStore
+    retry     RetryPolicy
+    locale    string
+    workers   int
+    queue     chan models.OrderEvent
+    wg        sync.WaitGroup
+    mu        sync.RWMutex // guards closed against Publish racing Close
+    closed    bool
+}
+
+type Option func(*Dispatcher)
+
+func WithTemplates(t Templates) Option { return func(d *Dispatcher) { d.templates = t } }
+func WithPreferences(p PreferenceStore) Option { return func(d *Dispatcher) { d.prefs = p } }
+func WithDeadLetters(s DeadLetterStore) Option { return func(d *Dispatcher) { d.dead = s } }
+func WithRetryPolicy(p RetryPolicy) Option { return func(d *Dispatcher) { d.retry = p } }
+func WithDefaultLocale(locale string) Option { return func(d *Dispatcher) { d.locale = locale } }
+func WithWorkers(n int) Option { return func(d *Dispatcher) { d.workers = n } }
+func WithQueueSize(n int) Option {
+    return func(d *Dispatcher) { d.queue = make(chan models.OrderEvent, n) }
+}
+
+func NewDispatcher(n Notifier, opts ...Option) *Dispatcher {
+    d := &Dispatcher{
+        notifier:  n,
+        templates: DefaultTemplates(),
+        prefs:     NewInMemoryPreferenceStore(),
+        dead:      NewInMemoryDeadLetterStore(),
+        retry:     DefaultRetryPolicy(),
+        locale:    DefaultLocale,
+        workers:   4,
+        queue:     make(chan models.OrderEvent, 1024),
+    }
+    for _, opt := range opts {
+        opt(d)
+    }
+    return d
+}
+
+// Publish enqueues e; when the queue is full the event's notifications are dead-lettered
+func (d *Dispatcher) Publish(e models.OrderEvent) {
+    d.mu.RLock()
+    defer d.mu.RUnlock()
+    if d.closed {
+        for _, n := range d.notifications(e) {
+            d.deadLetter(e, n, 0, ErrDispatcherClosed)
+        }
+        return
+    }
+    select {
+    case d.queue <- e:
+    default:
+        for _, n := range d.notifications(e) {
+            d.deadLetter(e, n, 0, ErrQueueFull)
+        }
+    }
+}
+
+// Start runs the workers until ctx is cancelled or Close drains the queue. Cancelling ctx
+// dead-letters whatever is still queued instead of sending it.
+func (d *Dispatcher) Start(ctx context.Context) {
+    for i := 0; i < d.workers; i++ {
+        d.wg.Add(1)
+        go func() {
+            defer d.wg.Done()
+            for {
+                select {
+                case <-ctx.Done():
+                    d.drain(ctx.Err())
+                    return
+                case e, ok := <-d.queue:
+                    if !ok {
+                        return
+                    }
+                    for _, n := range d.notifications(e) {
+                        d.deliver(ctx, e, n)
+                    }
+                }
+            }
+        }()
+    }
+}
+
+// Close stops accepting events and waits until queued ones are delivered or dead-lettered;
+// nothing is left in the queue when it returns, even if the workers were stopped first
+func (d *Dispatcher) Close() {
+    d.mu.Lock()
+    if !d.closed {
+        d.closed = true
+        close(d.queue)
+    }
+    d.mu.Unlock()
+    d.wg.Wait()
+    d.drain(ErrDispatcherClosed)
+}
+
+// drain dead-letters the queued events without waiting for new ones
+func (d *Dispatcher) drain(cause error) {
+    for {
+        select {
+        case e, ok := <-d.queue:
+            if !ok {
+                return
+            }
+            for _, n := range d.notifications(e) {
+                d.deadLetter(e, n, 0, cause)
+            }
+        default:
+            return
+        }
+    }
+}
+
+// notifications renders one message per recipient that has a template and allows the event.
+// The actor of the change is never notified about their own action.
+func (d *Dispatcher) notifications(e models.OrderEvent) []Notification {
+    res := make([]Notification, 0, len(e.Recipients))
+    for _, userID := range e.Recipients {
+        if userID == e.Actor {
+            continue
+        }
+        role := models.RoleCollector
+        if userID == e.Order.CustomerID {
+            role = models.RoleCustomer
+        }
+        p, err := d.prefs.GetPreferences(userID)
+        if err != nil {
+            slog.Warn("notify: load preferences", "user_id", userID, "error", err)
+            p = &Preferences{UserID: userID}
+        }
+        if !p.Allows(e.Type) {
+            continue
+        }
+        locale := p.Locale
+        if locale == "" {
+            locale = d.locale
+        }
+        title, body, ok := d.templates.Render(e, role, locale, d.locale)
+        if !ok {
+            continue
+        }
+        res = append(res, Notification{
+            UserID: userID,
+            Title:  title,
+            Body:   body,
+            Data:   map[string]string{"event": string(e.Type), "order_id": e.Order.ID, "status": string(e.Order.Status)},
+        })
+    }
+    return res
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, e models.OrderEvent, n Notification) {
+    var err error
+    attempt := 0
+    for {
+        attempt++
+        if err = d.notifier.Send(ctx, n); err == nil {
+            return
+        }
+        if IsPermanent(err) || attempt >= d.retry.MaxAttempts {
+            break
+        }
+        select {
+        case <-ctx.Done():
+            d.deadLetter(e, n, attempt, ctx.Err())
+            return
+        case <-time.After(d.retry.Backoff(attempt)):
+        }
+    }
+    d.deadLetter(e, n, attempt, err)
+}
+
+func (d *Dispatcher) deadLetter(e models.OrderEvent, n Notification, attempts int, cause error) {
+    err := d.dead.AddDeadLetter(DeadLetter{
+        ID:           uuid.NewString(),
+        Event:        e.Type,
+        OrderID:      e.Order.ID,
+        Notification: n,
+        Attempts:     attempts,
+        Error:        cause.Error(),
+        At:           time.Now(),
+    })
+    if err != nil {
+        slog.Error("notify: dead letter lost", "user_id", n.UserID, "error", err, "cause", cause)
+    }
+}
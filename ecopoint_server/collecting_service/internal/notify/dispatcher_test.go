@@ -0,0 +1,164 @@
+package notify
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+func onWayEvent() models.OrderEvent {
+    return models.OrderEvent{
+        Type:       models.EventOrderOnWay,
+        Order:      models.Order{ID: "o1", CustomerID: "u1", Status: models.StatusOnWay, PickAddressSnapshot: models.Address{FullText: "12 Lê Lợi"}},
+        Actor:      "c1",
+        Recipients: []string{"u1", "c1"},
+    }
+}
+
+func fastRetry() Option {
+    return WithRetryPolicy(RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond})
+}
+
+func TestDispatcherRendersForRecipients(t *testing.T) {
+    fake := NewFake()
+    prefs := NewInMemoryPreferenceStore()
+    _ = prefs.SavePreferences(&Preferences{UserID: "u1", Locale: "en"})
+    d := NewDispatcher(fake, WithPreferences(prefs))
+    d.Start(context.Background())
+    d.Publish(onWayEvent())
+    d.Close()
+
+    sent := fake.Sent()
+    // the collector is the actor and is not notified about their own change
+    if len(sent) != 1 || sent[0].UserID != "u1" {
+        t.Fatalf("expected one notification to the customer, got %+v", sent)
+    }
+    if sent[0].Title != "Your collector is on the way" || sent[0].Body != "Your collector is heading to 12 Lê Lợi." {
+        t.Fatalf("unexpected text %+v", sent[0])
+    }
+    if sent[0].Data["order_id"] != "o1" || sent[0].Data["event"] != string(models.EventOrderOnWay) {
+        t.Fatalf("unexpected data %+v", sent[0].Data)
+    }
+}
+
+func TestDispatcherDefaultLocaleAndMutes(t *testing.T) {
+    fake := NewFake()
+    prefs := NewInMemoryPreferenceStore()
+    d := NewDispatcher(fake, WithPreferences(prefs))
+    if n := d.notifications(onWayEvent()); len(n) != 1 || n[0].Title != "Người thu gom đang trên đường" {
+        t.Fatalf("expected Vietnamese default, got %+v", n)
+    }
+    _ = prefs.SavePreferences(&Preferences{UserID: "u1", Muted: []models.EventType{models.EventOrderOnWay}})
+    if n := d.notifications(onWayEvent()); len(n) != 0 {
+        t.Fatalf("expected muted event to be skipped, got %+v", n)
+    }
+    _ = prefs.SavePreferences(&Preferences{UserID: "u1", Disabled: true})
+    e := onWayEvent()
+    e.Type = models.EventOrderCompleted
+    if n := d.notifications(e); len(n) != 0 {
+        t.Fatalf("expected disabled user to be skipped, got %+v", n)
+    }
+}
+
+func TestDispatcherRetriesAndDeadLetters(t *testing.T) {
+    fake := NewFake()
+    fake.Fail = func(n Notification, attempt int) error {
+        switch {
+        case n.UserID == "u1" && attempt < 3:
+            return errors.New("unavailable")
+        case n.UserID == "c1":
+            return Permanent(errors.New("unregistered"))
+        }
+        return nil
+    }
+    dead := NewInMemoryDeadLetterStore()
+    d := NewDispatcher(fake, WithDeadLetters(dead), fastRetry())
+    d.Start(context.Background())
+    // system cancel notifies both sides
+    d.Publish(models.OrderEvent{
+        Type:       models.EventOrderCancelled,
+        Order:      models.Order{ID: "o2", CustomerID: "u1"},
+        Actor:      models.ActorSystem,
+        Recipients: []string{"u1", "c1"},
+    })
+    d.Close()
+
+    if sent := fake.Sent(); len(sent) != 1 || sent[0].UserID != "u1" {
+        t.Fatalf("expected customer delivery on the third attempt, got %+v", sent)
+    }
+    letters := dead.List()
+    if len(letters) != 1 || letters[0].Notification.UserID != "c1" || letters[0].Attempts != 1 || letters[0].OrderID != "o2" {
+        t.Fatalf("expected permanent failure dead-lettered after one attempt, got %+v", letters)
+    }
+}
+
+func TestDispatcherGivesUpAfterMaxAttempts(t *testing.T) {
+    fake := NewFake()
+    fake.Fail = func(Notification, int) error { return errors.New("timeout") }
+    dead := NewInMemoryDeadLetterStore()
+    d := NewDispatcher(fake, WithDeadLetters(dead), fastRetry())
+    d.Start(context.Background())
+    d.Publish(onWayEvent())
+    d.Close()
+    if letters := dead.List(); len(letters) != 1 || letters[0].Attempts != 3 || letters[0].Error != "timeout" {
+        t.Fatalf("expected dead letter after 3 attempts, got %+v", letters)
+    }
+}
+
+func TestRetryBackoff(t *testing.T) {
+    p := RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+    for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 3: 400 * time.Millisecond, 10: time.Second} {
+        if d := p.Backoff(attempt); d < want/2 || d > want {
+            t.Fatalf("attempt %d: backoff %v outside [%v, %v]", attempt, d, want/2, want)
+        }
+    }
+}
+
+func TestDispatcherPublishAfterClose(t *testing.T) {
+    fake := NewFake()
+    dead := NewInMemoryDeadLetterStore()
+    d := NewDispatcher(fake, WithDeadLetters(dead))
+    d.Start(context.Background())
+    d.Close()
+    d.Close() // closing twice is harmless
+    d.Publish(onWayEvent())
+    if len(fake.Sent()) != 0 {
+        t.Fatalf("nothing should be sent after Close")
+    }
+    if l := dead.List(); len(l) != 1 || l[0].Error != ErrDispatcherClosed.Error() {
+        t.Fatalf("expected the late event to be dead-lettered, got %+v", l)
+    }
+}
+
+// stuckNotifier never delivers; Send returns only when ctx is cancelled
+type stuckNotifier struct{ started chan struct{} }
+
+func (s stuckNotifier) Send(ctx context.Context, n Notification) error {
+    select {
+    case s.started <- struct{}{}:
+    default:
+    }
+    <-ctx.Done()
+    return ctx.Err()
+}
+
+func TestDispatcherCancelDeadLettersQueue(t *testing.T) {
+    dead := NewInMemoryDeadLetterStore()
+    stuck := stuckNotifier{started: make(chan struct{}, 1)}
+    d := NewDispatcher(stuck, WithDeadLetters(dead), WithWorkers(1))
+    ctx, cancel := context.WithCancel(context.Background())
+    d.Start(ctx)
+    for i := 0; i < 3; i++ {
+        d.Publish(onWayEvent())
+    }
+    <-stuck.started
+    // the shutdown path: the grace period ran out, so delivery is cut off
+    cancel()
+    d.Close()
+    if l := dead.List(); len(l) != 3 {
+        t.Fatalf("expected the in-flight and both queued notifications dead-lettered, got %d: %+v", len(l), l)
+    }
+}
@@ -0,0 +1,40 @@
+package notify
+
+import (
+    "context"
+    "sync"
+)
+
+// Fake records sent notifications. Fail, when set, is consulted before each
+// attempt (1-based per user and title) and its error is returned instead of sending.
+type Fake struct {
+    mu       sync.Mutex
+    sent     []Notification
+    attempts map[[2]string]int
+    Fail     func(n Notification, attempt int) error
+}
+
+func NewFake() *Fake {
+    return &Fake{attempts: map[[2]string]int{}}
+}
+
+func (f *Fake) Send(ctx context.Context, n Notification) error {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    k := [2]string{n.UserID, n.Title}
+    f.attempts[k]++
+    if f.Fail != nil {
+        if err := f.Fail(n, f.attempts[k]); err != nil {
+            return err
+        }
+    }
+    f.sent = append(f.sent, n)
+    return nil
+}
+
+// Sent returns a copy of the delivered notifications in order
+func (f *Fake) Sent() []Notification {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    return append([]Notification(nil), f.sent...)
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "net/http"
+    "os"
+
+    "golang.org/x/oauth2"
+    "golang.org/x/oauth2/google"
+)
+
+const (
+    fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+    fcmEndpoint = "https://fcm.googleapis.com/v1/projects/%s/messages:send"
+)
+
+// TokenSource resolves the FCM registration tokens of a user
+type TokenSource interface {
+    DeviceTokens(ctx context.Context, userID string) ([]string, error)
+}
+
+// FCMNotifier sends through the Firebase Cloud Messaging HTTP v1 API
+type FCMNotifier struct {
+    client *http.Client // adds the OAuth2 bearer token
+    url    string
+    tokens TokenSource
+}
+
+// NewFCMNotifier authenticates with a service account key file; the project comes from the key
+func NewFCMNotifier(ctx context.Context, credentialsFile string, tokens TokenSource) (*FCMNotifier, error) {
+    key, err := os.ReadFile(credentialsFile)
+    if err != nil {
+        return nil, err
+    }
+    creds, err := google.CredentialsFromJSON(ctx, key, fcmScope)
+    if err != nil {
+        return nil, err
+    }
+    if creds.ProjectID == "" {
+        return nil, errors.New("fcm: credentials have no project_id")
+    }
+    return newFCMNotifier(oauth2.NewClient(ctx, creds.TokenSource), fmt.Sprintf(fcmEndpoint, creds.ProjectID), tokens), nil
+}
+
+func newFCMNotifier(client *http.Client, url string, tokens TokenSource) *FCMNotifier {
+    return &FCMNotifier{client: client, url: url, tokens: tokens}
+}
+
+// Send succeeds when at least one device accepted the message. If none did, the error
+// is permanent only when every token was rejected for good (e.g. UNREGISTERED).
+func (f *FCMNotifier) Send(ctx context.Context, n Notification) error {
+    tokens, err := f.tokens.DeviceTokens(ctx, n.UserID)
+    if err != nil {
+        return err
+    }
+    if len(tokens) == 0 {
+        return nil
+    }
+    var errs []error
+    permanent := true
+    for _, t := range tokens {
+        err := f.sendOne(ctx, t, n)
+        if err == nil {
+            return nil
+        }
+        permanent = permanent && IsPermanent(err)
+        errs = append(errs, err)
+    }
+    // %v, not %w: a permanent failure of one token must not make the whole send permanent
+    err = fmt.Errorf("fcm: no device accepted the message: %v", errors.Join(errs...))
+    if permanent {
+        return Permanent(err)
+    }
+    return err
+}
+
+type fcmMessage struct {
+    Message struct {
+        Token        string            `json:"token"`
+        Notification fcmNotification   `json:"notification"`
+        Data         map[string]string `json:"data,omitempty"`
+    } `json:"message"`
+}
+
+type fcmNotification struct {
+    Title string `json:"title"`
+    Body  string `json:"body"`
+}
+
+type fcmError struct {
+    Error struct {
+        Status  string `json:"status"`
+        Message string `json:"message"`
+    } `json:"error"`
+}
+
+func (f *FCMNotifier) sendOne(ctx context.Context, token string, n Notification) error {
+    var msg fcmMessage
+    msg.Message.Token = token
+    msg.Message.Notification = fcmNotification{Title: n.Title, Body: n.Body}
+    msg.Message.Data = n.Data
+    body, err := json.Marshal(msg)
+    if err != nil {
+        return Permanent(err)
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, f.url, bytes.NewReader(body))
+    if err != nil {
+        return Permanent(err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    res, err := f.client.Do(req)
+    if err != nil {
+        return err
+    }
+    defer res.Body.Close()
+    if res.StatusCode == http.StatusOK {
+        return nil
+    }
+    raw, _ := io.ReadAll(io.LimitReader(res.Body, 4096))
+    var fe fcmError
+    _ = json.Unmarshal(raw, &fe)
+    err = fmt.Errorf("fcm: %d %s: %s", res.StatusCode, fe.Error.Status, fe.Error.Message)
+    // 429 and 5xx are transient; other 4xx (INVALID_ARGUMENT, UNREGISTERED, SENDER_ID_MISMATCH) are not
+    if res.StatusCode == http.StatusTooManyRequests || res.StatusCode >= 500 {
+        return err
+    }
+    return Permanent(err)
+}
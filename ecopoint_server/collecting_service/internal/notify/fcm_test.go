@@ -0,0 +1,60 @@
+package notify
+
+import (
+    "context"
+    "encoding/json"
+    "net/http"
+    "net/http/httptest"
+    "testing"
+)
+
+type staticTokens map[string][]string
+
+func (s staticTokens) DeviceTokens(ctx context.Context, userID string) ([]string, error) {
+    return s[userID], nil
+}
+
+func TestFCMNotifier(t *testing.T) {
+    var got []fcmMessage
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        var m fcmMessage
+        _ = json.NewDecoder(r.Body).Decode(&m)
+        got = append(got, m)
+        switch m.Message.Token {
+        case "gone":
+            w.WriteHeader(http.StatusNotFound)
+            _, _ = w.Write([]byte(`{"error":{"status":"UNREGISTERED","message":"token not registered"}}`))
+        case "busy":
+            w.WriteHeader(http.StatusServiceUnavailable)
+        default:
+            _, _ = w.Write([]byte(`{"name":"projects/p/messages/1"}`))
+        }
+    }))
+    defer srv.Close()
+    f := newFCMNotifier(srv.Client(), srv.URL, staticTokens{
+        "ok":   {"gone", "good"},
+        "dead": {"gone"},
+        "busy": {"gone", "busy"},
+    })
+    n := Notification{Title: "t", Body: "b", Data: map[string]string{"order_id": "o1"}}
+
+    n.UserID = "ok"
+    if err := f.Send(context.Background(), n); err != nil {
+        t.Fatalf("expected delivery through the second token, got %v", err)
+    }
+    if len(got) != 2 || got[1].Message.Token != "good" || got[1].Message.Notification.Title != "t" || got[1].Message.Data["order_id"] != "o1" {
+        t.Fatalf("unexpected requests %+v", got)
+    }
+    n.UserID = "dead"
+    if err := f.Send(context.Background(), n); err == nil || !IsPermanent(err) {
+        t.Fatalf("expected permanent error, got %v", err)
+    }
+    n.UserID = "busy"
+    if err := f.Send(context.Background(), n); err == nil || IsPermanent(err) {
+        t.Fatalf("expected transient error, got %v", err)
+    }
+    n.UserID = "nobody"
+    if err := f.Send(context.Background(), n); err != nil {
+        t.Fatalf("users without devices are not an error, got %v", err)
+    }
+}
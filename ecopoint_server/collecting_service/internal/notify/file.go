@@ -0,0 +1,46 @@
+package notify
+
+import (
+    "context"
+    "encoding/json"
+    "io"
+    "os"
+    "sync"
+    "time"
+)
+
+// WriterNotifier prints notifications as JSON lines; used in development instead of FCM
+type WriterNotifier struct {
+    mu sync.Mutex
+    w  io.Writer
+}
+
+func NewWriterNotifier(w io.Writer) *WriterNotifier {
+    return &WriterNotifier{w: w}
+}
+
+// NewFileNotifier appends to path; "" or "-" writes to stdout
+func NewFileNotifier(path string) (*WriterNotifier, error) {
+    if path == "" || path == "-" {
+        return NewWriterNotifier(os.Stdout), nil
+    }
+    f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+    if err != nil {
+        return nil, err
+    }
+    return NewWriterNotifier(f), nil
+}
+
+func (w *WriterNotifier) Send(ctx context.Context, n Notification) error {
+    b, err := json.Marshal(struct {
+        At time.Time `json:"at"`
+        Notification
+    }{time.Now().UTC(), n})
+    if err != nil {
+        return Permanent(err)
+    }
+    w.mu.Lock()
+    defer w.mu.Unlock()
+    _, err = w.w.Write(append(b, '\n'))
+    return err
+}
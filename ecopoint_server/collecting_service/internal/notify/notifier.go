@@ -0,0 +1,38 @@
+package notify
+
+import (
+    "context"
+    "errors"
+)
+
+// Notification is one push message addressed to one user
+type Notification struct {
+    UserID string            `bson:"user_id" json:"user_id"`
+    Title  string            `bson:"title" json:"title"`
+    Body   string            `bson:"body" json:"body"`
+    Data   map[string]string `bson:"data,omitempty" json:"data,omitempty"`
+}
+
+// Notifier delivers a notification to every device of the user.
+// Errors wrapped with Permanent are not retried.
+type Notifier interface {
+    Send(ctx context.Context, n Notification) error
+}
+
+type permanentError struct{ err error }
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as not worth retrying (invalid token, rejected payload)
+func Permanent(err error) error {
+    if err == nil {
+        return nil
+    }
+    return permanentError{err}
+}
+
+func IsPermanent(err error) bool {
+    var p permanentError
+    return errors.As(err, &p)
+}
@@ -0,0 +1,61 @@
+package notify
+
+import (
+    "sync"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// Preferences are a user's notification settings; the zero value allows everything
+type Preferences struct {
+    UserID    string             `bson:"user_id"`
+    Locale    string             `bson:"locale,omitempty"` // "" uses the dispatcher default
+    Disabled  bool               `bson:"disabled"`
+    Muted     []models.EventType `bson:"muted,omitempty"`
+    UpdatedAt time.Time          `bson:"updated_at"`
+}
+
+func (p *Preferences) Allows(t models.EventType) bool {
+    if p.Disabled {
+        return false
+    }
+    for _, m := range p.Muted {
+        if m == t {
+            return false
+        }
+    }
+    return true
+}
+
+// PreferenceStore returns default preferences for users who never saved any
+type PreferenceStore interface {
+    GetPreferences(userID string) (*Preferences, error)
+    SavePreferences(p *Preferences) error
+}
+
+type InMemoryPreferenceStore struct {
+    mu    sync.Mutex
+    prefs map[string]Preferences
+}
+
+func NewInMemoryPreferenceStore() *InMemoryPreferenceStore {
+    return &InMemoryPreferenceStore{prefs: map[string]Preferences{}}
+}
+
+func (m *InMemoryPreferenceStore) GetPreferences(userID string) (*Preferences, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    p, ok := m.prefs[userID]
+    if !ok {
+        p = Preferences{UserID: userID}
+    }
+    return &p, nil
+}
+
+func (m *InMemoryPreferenceStore) SavePreferences(p *Preferences) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.prefs[p.UserID] = *p
+    return nil
+}
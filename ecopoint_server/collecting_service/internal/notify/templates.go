@@ -0,0 +1,59 @@
+package notify
+
+import (
+    "strings"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+const DefaultLocale = "vi"
+
+// Template text may use {order_id} and {address}
+type Template struct {
+    Title string
+    Body  string
+}
+
+// TemplateKey selects a template by event, the role of the recipient and locale
+type TemplateKey struct {
+    Event  models.EventType
+    Role   models.Role
+    Locale string
+}
+
+type Templates map[TemplateKey]Template
+
+// DefaultTemplates covers the order lifecycle in Vietnamese and English
+func DefaultTemplates() Templates {
+    c, k := models.RoleCustomer, models.RoleCollector
+    return Templates{
+        {models.EventOrderAccepted, c, "en"}:  {"Order accepted", "A collector accepted your order and will set off soon."},
+        {models.EventOrderAccepted, c, "vi"}:  {"Đơn đã được nhận", "Người thu gom đã nhận đơn và sẽ sớm lên đường."},
+        {models.EventOrderOnWay, c, "en"}:     {"Your collector is on the way", "Your collector is heading to {address}."},
+        {models.EventOrderOnWay, c, "vi"}:     {"Người thu gom đang trên đường", "Người thu gom đang đến {address}."},
+        {models.EventOrderCompleted, c, "en"}: {"Pickup complete", "Thanks for recycling! Your order is complete."},
+        {models.EventOrderCompleted, c, "vi"}: {"Đã thu gom xong", "Cảm ơn bạn đã tái chế! Đơn hàng đã hoàn tất."},
+        {models.EventOrderReleased, c, "en"}:  {"Finding another collector", "Your collector could not make it, so your order is back in the queue."},
+        {models.EventOrderReleased, c, "vi"}:  {"Đang tìm người thu gom khác", "Người thu gom không thể đến, đơn của bạn đã quay lại hàng chờ."},
+        {models.EventOrderReleased, k, "en"}:  {"Order released", "Order {order_id} was released because it stalled."},
+        {models.EventOrderReleased, k, "vi"}:  {"Đơn đã bị thu hồi", "Đơn {order_id} đã bị thu hồi do quá thời gian."},
+        {models.EventOrderCancelled, c, "en"}: {"Order cancelled", "Your order {order_id} was cancelled."},
+        {models.EventOrderCancelled, c, "vi"}: {"Đơn đã bị huỷ", "Đơn {order_id} của bạn đã bị huỷ."},
+        {models.EventOrderCancelled, k, "en"}: {"Order cancelled", "Order {order_id} at {address} was cancelled."},
+        {models.EventOrderCancelled, k, "vi"}: {"Đơn đã bị huỷ", "Đơn {order_id} tại {address} đã bị huỷ."},
+    }
+}
+
+// Render picks the template for locale, falling back to fallback; ok is false when the
+// event has no template for the role, meaning the recipient is not notified
+func (t Templates) Render(e models.OrderEvent, role models.Role, locale, fallback string) (title, body string, ok bool) {
+    tpl, ok := t[TemplateKey{e.Type, role, locale}]
+    if !ok {
+        tpl, ok = t[TemplateKey{e.Type, role, fallback}]
+    }
+    if !ok {
+        return "", "", false
+    }
+    r := strings.NewReplacer("{order_id}", e.Order.ID, "{address}", e.Order.PickAddressSnapshot.FullText)
+    return r.Replace(tpl.Title), r.Replace(tpl.Body), true
+}
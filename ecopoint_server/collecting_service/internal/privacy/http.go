@@ -0,0 +1,122 @@
+package privacy
+
+import (
+    "bytes"
+    "encoding/json"
+    "fmt"
+    "io"
+    "net/http"
+    "net/url"
+    "strings"
+    "sync"
+    "time"
+)
+
+// HTTPRelayConfig points at the telephony provider's number masking API
+type HTTPRelayConfig struct {
+    Endpoint string        `yaml:"endpoint"` // base URL, e.g. https://relay.example.com/v1
+    APIKey   string        `yaml:"api_key" secret:"true"`
+    Timeout  time.Duration `yaml:"timeout"`
+}
+
+// HTTPRelay leases numbers from a masking provider:
+//
+//    PUT    {endpoint}/orders/{order_id}  {"phone": "..."} -> {"number": "..."}
+//    DELETE {endpoint}/orders/{order_id}
+//
+// PUT is idempotent on the provider side, which owns the number pool, so a number
+// is never handed to two orders at once. Numbers are cached until Release.
+type HTTPRelay struct {
+    cfg    HTTPRelayConfig
+    base   string
+    client *http.Client
+
+    mu      sync.Mutex
+    numbers map[string]string // order id -> relay number
+}
+
+func NewHTTPRelay(cfg HTTPRelayConfig) (*HTTPRelay, error) {
+    u, err := url.Parse(cfg.Endpoint)
+    if err != nil || u.Host == "" {
+        return nil, fmt.Errorf("relay: invalid endpoint %q", cfg.Endpoint)
+    }
+    if cfg.Timeout <= 0 {
+        cfg.Timeout = 5 * time.Second
+    }
+    return &HTTPRelay{
+        cfg:     cfg,
+        base:    strings.TrimSuffix(cfg.Endpoint, "/"),
+        client:  &http.Client{Timeout: cfg.Timeout},
+        numbers: map[string]string{},
+    }, nil
+}
+
+func (h *HTTPRelay) Number(orderID, phone string) (string, error) {
+    h.mu.Lock()
+    n, ok := h.numbers[orderID]
+    h.mu.Unlock()
+    if ok {
+        return n, nil
+    }
+    body, _ := json.Marshal(map[string]string{"phone": phone})
+    resp, err := h.do(http.MethodPut, orderID, body)
+    if err != nil {
+        return "", err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+        return "", relayError("lease", orderID, resp)
+    }
+    var res struct {
+        Number string `json:"number"`
+    }
+    if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+        return "", fmt.Errorf("relay: lease %s: %w", orderID, err)
+    }
+    if res.Number == "" {
+        return "", fmt.Errorf("relay: lease %s: %w", orderID, ErrNoRelay)
+    }
+    h.mu.Lock()
+    h.numbers[orderID] = res.Number
+    h.mu.Unlock()
+    return res.Number, nil
+}
+
+// Release treats an order the provider does not know as already released
+func (h *HTTPRelay) Release(orderID string) error {
+    h.mu.Lock()
+    delete(h.numbers, orderID)
+    h.mu.Unlock()
+    resp, err := h.do(http.MethodDelete, orderID, nil)
+    if err != nil {
+        return err
+    }
+    defer resp.Body.Close()
+    if resp.StatusCode/100 != 2 && resp.StatusCode != http.StatusNotFound {
+        return relayError("release", orderID, resp)
+    }
+    return nil
+}
+
+func (h *HTTPRelay) do(method, orderID string, body []byte) (*http.Response, error) {
+    var r io.Reader
+    if body != nil {
+        r = bytes.NewReader(body)
+    }
+    req, err := http.NewRequest(method, h.base+"/orders/"+url.PathEscape(orderID), r)
+    if err != nil {
+        return nil, err
+    }
+    if body != nil {
+        req.Header.Set("Content-Type", "application/json")
+    }
+    if h.cfg.APIKey != "" {
+        req.Header.Set("Authorization", "Bearer "+h.cfg.APIKey)
+    }
+    return h.client.Do(req)
+}
+
+func relayError(op, orderID string, resp *http.Response) error {
+    msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+    return fmt.Errorf("relay %s %s: %s: %s", op, orderID, resp.Status, strings.TrimSpace(string(msg)))
+}
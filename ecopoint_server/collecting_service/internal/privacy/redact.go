@@ -0,0 +1,101 @@
+// Package privacy decides which order fields a caller may see
+package privacy
+
+import (
+    "log/slog"
+    "math"
+
+    "ecopoint/collecting_service/internal/caller"
+    "ecopoint/collecting_service/internal/models"
+)
+
+// LocationDecimals is the precision of approximate coordinates (2 decimals is about 1 km)
+const LocationDecimals = 2
+
+// Redactor hides customer contact details from callers not entitled to them:
+//   - the customer and admins see everything
+//   - the accepting collector sees the exact address and, while the order is active,
+//     a relay number instead of the real phone
+//   - anyone else sees an approximate location and no phone
+type Redactor struct {
+    relay    RelayProvider // nil: no relay numbers are handed out
+    releases chan struct{} // bounds the releases in flight
+}
+
+// maxReleases is how many relay releases may run at once; past it Publish releases inline
+const maxReleases = 8
+
+func NewRedactor(relay RelayProvider) *Redactor {
+    return &Redactor{relay: relay, releases: make(chan struct{}, maxReleases)}
+}
+
+// Order returns o as c may see it; o itself is not modified
+func (r *Redactor) Order(o *models.Order, c caller.Caller) *models.Order {
+    if c.IsAdmin() || (!c.Anonymous() && c.UserID == o.CustomerID) {
+        return o
+    }
+    v := o.Clone()
+    v.CustomerSnapshot.Phone = ""
+    if !c.Anonymous() && o.AcceptedBy != nil && c.UserID == *o.AcceptedBy {
+        if active(o) && o.CustomerSnapshot.Phone != "" && r != nil && r.relay != nil {
+            n, err := r.relay.Number(o.ID, o.CustomerSnapshot.Phone)
+            if err != nil {
+                slog.Warn("privacy: relay number", "order_id", o.ID, "error", err)
+            }
+            v.CustomerSnapshot.Phone = n
+        }
+        return v
+    }
+    v.PickAddressSnapshot = Approximate(o.PickAddressSnapshot)
+    return v
+}
+
+// Publish releases relay numbers when an order leaves accepted/on_way, so a former
+// collector's number stops working (implements service.EventPublisher)
+func (r *Redactor) Publish(e models.OrderEvent) {
+    if r == nil || r.relay == nil || !leavesActive(e) {
+        return
+    }
+    select {
+    case r.releases <- struct{}{}:
+        go func() {
+            defer func() { <-r.releases }()
+            r.release(e.Order.ID)
+        }()
+    default:
+        // the provider is slow: hold the publisher back rather than pile up goroutines
+        r.release(e.Order.ID)
+    }
+}
+
+func (r *Redactor) release(orderID string) {
+    if err := r.relay.Release(orderID); err != nil {
+        slog.Error("privacy: release relay", "order_id", orderID, "error", err)
+    }
+}
+
+// leavesActive reports whether e ends a collector's hold on the order. A cancel is one
+// only when the order had been accepted; pool orders never leased a number.
+func leavesActive(e models.OrderEvent) bool {
+    switch e.Type {
+    case models.EventOrderCompleted, models.EventOrderReleased:
+        return true
+    case models.EventOrderCancelled:
+        return e.Order.AcceptedBy != nil
+    }
+    return false
+}
+
+func active(o *models.Order) bool {
+    return o.Status == models.StatusAccepted || o.Status == models.StatusOnWay
+}
+
+// Approximate rounds the coordinates and keeps only the area of the address (see Address.Area)
+func Approximate(a models.Address) models.Address {
+    scale := math.Pow(10, LocationDecimals)
+    return models.Address{
+        FullText: a.Area(),
+        Lat:      math.Round(a.Lat*scale) / scale,
+        Lng:      math.Round(a.Lng*scale) / scale,
+    }
+}
@@ -0,0 +1,186 @@
+package privacy
+
+import (
+    "errors"
+    "sync"
+    "testing"
+    "time"
+
+    "ecopoint/collecting_service/internal/caller"
+    "ecopoint/collecting_service/internal/models"
+)
+
+func testOrder() *models.Order {
+    collector := "c1"
+    return &models.Order{
+        ID:         "o1",
+        CustomerID: "u1",
+        Status:     models.StatusAccepted,
+        AcceptedBy: &collector,
+        CustomerSnapshot: models.CustomerSnapshot{DisplayName: "Lan", Phone: "0901234567"},
+        PickAddressSnapshot: models.Address{
+            FullText: "12 Lê Lợi, Bến Nghé, Quận 1, TP.HCM",
+            Lat:      10.77312,
+            Lng:      106.70045,
+        },
+    }
+}
+
+func TestRedactOrderByCaller(t *testing.T) {
+    relay := NewLocalRelay("")
+    r := NewRedactor(relay)
+    o := testOrder()
+
+    for _, c := range []caller.Caller{
+        {UserID: "u1", Role: models.RoleCustomer},
+        {UserID: "a1", Role: models.RoleAdmin},
+    } {
+        if v := r.Order(o, c); v.CustomerSnapshot.Phone != "0901234567" || v.PickAddressSnapshot.Lat != 10.77312 {
+            t.Fatalf("%+v should see the full order, got %+v", c, v)
+        }
+    }
+
+    v := r.Order(o, caller.Caller{UserID: "c1", Role: models.RoleCollector})
+    if v.CustomerSnapshot.Phone == "" || v.CustomerSnapshot.Phone == "0901234567" {
+        t.Fatalf("accepting collector should get a relay number, got %q", v.CustomerSnapshot.Phone)
+    }
+    if p, err := relay.Resolve(v.CustomerSnapshot.Phone); err != nil || p != "0901234567" {
+        t.Fatalf("relay should forward to the customer, got %q err %v", p, err)
+    }
+    if v.PickAddressSnapshot.FullText != o.PickAddressSnapshot.FullText {
+        t.Fatalf("accepting collector should see the exact address, got %q", v.PickAddressSnapshot.FullText)
+    }
+    if o.CustomerSnapshot.Phone != "0901234567" {
+        t.Fatalf("redaction must not modify the stored order")
+    }
+
+    for _, c := range []caller.Caller{{}, {UserID: "c2", Role: models.RoleCollector}} {
+        v := r.Order(o, c)
+        if v.CustomerSnapshot.Phone != "" {
+            t.Fatalf("%+v should not see a phone, got %q", c, v.CustomerSnapshot.Phone)
+        }
+        a := v.PickAddressSnapshot
+        if a.FullText != "Quận 1, TP.HCM" || a.Lat != 10.77 || a.Lng != 106.7 {
+            t.Fatalf("%+v should see an approximate address, got %+v", c, a)
+        }
+    }
+
+    done := o.Clone()
+    done.Status = models.StatusComplete
+    if v := r.Order(done, caller.Caller{UserID: "c1", Role: models.RoleCollector}); v.CustomerSnapshot.Phone != "" {
+        t.Fatalf("no phone after the order is closed, got %q", v.CustomerSnapshot.Phone)
+    }
+}
+
+func TestRedactorReleasesRelayOnClose(t *testing.T) {
+    relay := NewLocalRelay("+84000")
+    r := NewRedactor(relay)
+    o := testOrder()
+    n, _ := relay.Number(o.ID, o.CustomerSnapshot.Phone)
+
+    r.Publish(models.OrderEvent{Type: models.EventOrderOnWay, Order: *o})
+    if _, err := relay.Resolve(n); err != nil {
+        t.Fatalf("relay should stay while the order is active: %v", err)
+    }
+
+    o.Status = models.StatusComplete
+    r.Publish(models.OrderEvent{Type: models.EventOrderCompleted, Order: *o})
+    deadline := time.Now().Add(time.Second)
+    for {
+        if _, err := relay.Resolve(n); errors.Is(err, ErrNoRelay) {
+            break
+        }
+        if time.Now().After(deadline) {
+            t.Fatalf("relay %s still resolves after completion", n)
+        }
+        time.Sleep(5 * time.Millisecond)
+    }
+}
+
+type countingRelay struct {
+    mu       sync.Mutex
+    released []string
+}
+
+func (c *countingRelay) Number(orderID, phone string) (string, error) { return "+840000", nil }
+
+func (c *countingRelay) Release(orderID string) error {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    c.released = append(c.released, orderID)
+    return nil
+}
+
+func (c *countingRelay) count() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return len(c.released)
+}
+
+func TestRedactorReleasesOnlyHeldOrders(t *testing.T) {
+    relay := &countingRelay{}
+    r := NewRedactor(relay)
+
+    // a pool order never leased a number, so cancelling it calls no provider
+    pool := testOrder()
+    pool.Status, pool.AcceptedBy = models.StatusCancelled, nil
+    r.Publish(models.OrderEvent{Type: models.EventOrderCancelled, Order: *pool})
+    r.Publish(models.OrderEvent{Type: models.EventOrderAccepted, Order: *testOrder()})
+
+    held := testOrder()
+    held.Status = models.StatusCancelled
+    r.Publish(models.OrderEvent{Type: models.EventOrderCancelled, Order: *held})
+    released := testOrder()
+    released.Status, released.AcceptedBy = models.StatusCreated, nil
+    r.Publish(models.OrderEvent{Type: models.EventOrderReleased, Order: *released})
+
+    deadline := time.Now().Add(time.Second)
+    for relay.count() < 2 && time.Now().Before(deadline) {
+        time.Sleep(5 * time.Millisecond)
+    }
+    time.Sleep(20 * time.Millisecond)
+    if n := relay.count(); n != 2 {
+        t.Fatalf("expected releases for the held and released orders only, got %d", n)
+    }
+}
+
+type blockingRelay struct {
+    countingRelay
+    gate chan struct{}
+}
+
+func (b *blockingRelay) Release(orderID string) error {
+    <-b.gate
+    return b.countingRelay.Release(orderID)
+}
+
+func TestRedactorBoundsReleases(t *testing.T) {
+    relay := &blockingRelay{gate: make(chan struct{})}
+    r := NewRedactor(relay)
+    o := testOrder()
+    o.Status = models.StatusComplete
+    for i := 0; i < maxReleases; i++ {
+        r.Publish(models.OrderEvent{Type: models.EventOrderCompleted, Order: *o})
+    }
+
+    // with every slot taken the next release runs on the publisher's goroutine
+    done := make(chan struct{})
+    go func() {
+        r.Publish(models.OrderEvent{Type: models.EventOrderCompleted, Order: *o})
+        close(done)
+    }()
+    select {
+    case <-done:
+        t.Fatal("publish should wait for a release slot")
+    case <-time.After(50 * time.Millisecond):
+    }
+    close(relay.gate)
+    <-done
+    deadline := time.Now().Add(time.Second)
+    for relay.count() < maxReleases+1 && time.Now().Before(deadline) {
+        time.Sleep(5 * time.Millisecond)
+    }
+    if n := relay.count(); n != maxReleases+1 {
+        t.Fatalf("expected %d releases, got %d", maxReleases+1, n)
+    }
+}
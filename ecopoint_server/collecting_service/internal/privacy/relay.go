@@ -0,0 +1,80 @@
+package privacy
+
+import (
+    "errors"
+    "fmt"
+    "sync"
+)
+
+var (
+    ErrNoRelay        = errors.New("no relay number for this order")
+    ErrRelayExhausted = errors.New("every relay number is in use")
+)
+
+// RelayProvider hands out masked numbers that forward calls and SMS to the customer.
+// Numbers are per order and stop working once released.
+type RelayProvider interface {
+    // Number returns the order's relay number, allocating one on first use
+    Number(orderID, phone string) (string, error)
+    Release(orderID string) error
+}
+
+// LocalRelay is an in-memory provider for development and tests. It does not
+// forward anything; Resolve shows where a real provider would route a number.
+// Production uses HTTPRelay (relay.driver http).
+type LocalRelay struct {
+    mu      sync.Mutex
+    prefix  string
+    next    int
+    numbers map[string]string // order id -> relay number
+    targets map[string]string // relay number -> real phone
+}
+
+// NewLocalRelay numbers relays as prefix + 4 digits; the default prefix is "+841900"
+func NewLocalRelay(prefix string) *LocalRelay {
+    if prefix == "" {
+        prefix = "+841900"
+    }
+    return &LocalRelay{prefix: prefix, numbers: map[string]string{}, targets: map[string]string{}}
+}
+
+func (l *LocalRelay) Number(orderID, phone string) (string, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if n, ok := l.numbers[orderID]; ok {
+        return n, nil
+    }
+    // numbers wrap around after 9999; one still mapped to an active order is skipped,
+    // otherwise calls for that order would reach the new customer
+    for i := 0; i < 10000; i++ {
+        l.next = (l.next + 1) % 10000
+        n := fmt.Sprintf("%s%04d", l.prefix, l.next)
+        if _, taken := l.targets[n]; taken {
+            continue
+        }
+        l.numbers[orderID] = n
+        l.targets[n] = phone
+        return n, nil
+    }
+    return "", ErrRelayExhausted
+}
+
+func (l *LocalRelay) Release(orderID string) error {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if n, ok := l.numbers[orderID]; ok {
+        delete(l.targets, n)
+        delete(l.numbers, orderID)
+    }
+    return nil
+}
+
+// Resolve returns the phone a relay number forwards to
+func (l *LocalRelay) Resolve(number string) (string, error) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if p, ok := l.targets[number]; ok {
+        return p, nil
+    }
+    return "", ErrNoRelay
+}
@@ -0,0 +1,90 @@
+package privacy
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/http/httptest"
+    "sync"
+    "testing"
+)
+
+func TestLocalRelaySkipsMappedNumbersOnWrap(t *testing.T) {
+    relay := NewLocalRelay("+84")
+    kept, _ := relay.Number("o0", "0900000000")
+    for i := 1; i < 10000; i++ {
+        id := fmt.Sprintf("o%d", i)
+        if _, err := relay.Number(id, "0911111111"); err != nil {
+            t.Fatalf("number %d: %v", i, err)
+        }
+        _ = relay.Release(id)
+    }
+    n, err := relay.Number("next", "0922222222")
+    if err != nil || n == kept {
+        t.Fatalf("wrapped onto %q still held by o0 (got %q, %v)", kept, n, err)
+    }
+    if p, _ := relay.Resolve(kept); p != "0900000000" {
+        t.Fatalf("o0's number now forwards to %q", p)
+    }
+
+    full := NewLocalRelay("+84")
+    for i := 0; i < 10000; i++ {
+        full.targets[fmt.Sprintf("+84%04d", i)] = "x"
+    }
+    if _, err := full.Number("o1", "0900000000"); !errors.Is(err, ErrRelayExhausted) {
+        t.Fatalf("expected ErrRelayExhausted, got %v", err)
+    }
+}
+
+func TestHTTPRelay(t *testing.T) {
+    var mu sync.Mutex
+    leases, released := 0, []string{}
+    srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        if r.Header.Get("Authorization") != "Bearer k" {
+            w.WriteHeader(http.StatusUnauthorized)
+            return
+        }
+        mu.Lock()
+        defer mu.Unlock()
+        switch {
+        case r.Method == http.MethodPut && r.URL.Path == "/v1/orders/o1":
+            var body struct{ Phone string }
+            _ = json.NewDecoder(r.Body).Decode(&body)
+            if body.Phone != "0901234567" {
+                w.WriteHeader(http.StatusBadRequest)
+                return
+            }
+            leases++
+            _ = json.NewEncoder(w).Encode(map[string]string{"number": "+8419001234"})
+        case r.Method == http.MethodDelete:
+            released = append(released, r.URL.Path)
+            w.WriteHeader(http.StatusNotFound)
+        default:
+            w.WriteHeader(http.StatusInternalServerError)
+        }
+    }))
+    defer srv.Close()
+
+    relay, err := NewHTTPRelay(HTTPRelayConfig{Endpoint: srv.URL + "/v1/", APIKey: "k"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    for i := 0; i < 2; i++ {
+        if n, err := relay.Number("o1", "0901234567"); err != nil || n != "+8419001234" {
+            t.Fatalf("number: %q %v", n, err)
+        }
+    }
+    if leases != 1 {
+        t.Fatalf("expected one lease while cached, got %d", leases)
+    }
+    if err := relay.Release("o1"); err != nil {
+        t.Fatalf("a release the provider already forgot should succeed: %v", err)
+    }
+    if len(released) != 1 || released[0] != "/v1/orders/o1" {
+        t.Fatalf("released %v", released)
+    }
+    if _, err := relay.Number("o2", "0901234567"); err == nil {
+        t.Fatal("expected an error from a failed lease")
+    }
+}
@@ -0,0 +1,313 @@
+// Package ratelimit throttles gRPC calls with token buckets per caller and per client IP.
+// Every call of a user draws from that user's bucket for the method, or from the shared user
+// bucket when the method has no budget of its own; every call also draws from the bucket of
+// the client IP. A call over budget fails with ResourceExhausted and a RetryInfo delay.
+//
+// The user is the one caller.FromContext believes: a direct caller naming itself is
+// anonymous, so it cannot get fresh buckets by changing x-user-id and only its address is
+// charged. Streams are charged when they open and, for the methods in Messages, for every
+// message the client sends.
+package ratelimit
+
+import (
+    "context"
+    "fmt"
+    "math"
+    "net"
+    "net/netip"
+    "strings"
+    "sync"
+    "time"
+
+    "golang.org/x/time/rate"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/durationpb"
+
+    "ecopoint/collecting_service/internal/caller"
+)
+
+// Scopes of a rejection, as reported to the Observer
+const (
+    ScopeUser = "user"
+    ScopeIP   = "ip"
+)
+
+// idleAfter is how long an untouched bucket is kept; a full bucket carries no state
+const idleAfter = 10 * time.Minute
+
+// Budget is a token bucket: Burst calls at once, refilled at PerMinute
+type Budget struct {
+    PerMinute float64 `yaml:"per_minute"` // 0 = unlimited
+    Burst     int     `yaml:"burst"`
+}
+
+func (b Budget) unlimited() bool { return b.PerMinute <= 0 }
+
+type Options struct {
+    Enabled bool              `yaml:"enabled"`
+    User    Budget            `yaml:"user"`    // per user, shared by the methods without a budget
+    IP      Budget            `yaml:"ip"`      // per client IP over all methods, signed in or not
+    Methods map[string]Budget `yaml:"methods"` // per user and method, by method name (e.g. CreateOrder)
+    // Messages are per user budgets for the messages a client sends on a stream, by method name
+    Messages map[string]Budget `yaml:"messages"`
+    // TrustedProxies are the CIDRs whose x-forwarded-for is believed, such as the REST
+    // gateway on loopback and the BFF; calls from anywhere else are charged to the peer.
+    // The service also believes the caller identity from these peers only (caller.Trust).
+    TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+func DefaultOptions() Options {
+    return Options{
+        Enabled: true,
+        User:    Budget{PerMinute: 300, Burst: 60},
+        IP:      Budget{PerMinute: 1200, Burst: 200},
+        Methods: map[string]Budget{
+            "CreateOrder":         {PerMinute: 6, Burst: 3},
+            "UpdateOrderDetails":  {PerMinute: 20, Burst: 5},
+            "ListAvailableOrders": {PerMinute: 60, Burst: 10},
+            "UploadAttachment":    {PerMinute: 20, Burst: 5},
+        },
+        Messages: map[string]Budget{
+            "Chat": {PerMinute: 30, Burst: 10},
+        },
+        TrustedProxies: []string{"127.0.0.0/8", "::1/128"},
+    }
+}
+
+// Observer is told about every rejected call
+type Observer interface {
+    RateLimited(method, scope string)
+}
+
+type Limiter struct {
+    o       Options
+    trusted caller.Proxies
+    now     func() time.Time
+    observe Observer // nil: rejections are not reported
+
+    mu        sync.Mutex
+    buckets   map[string]*bucket
+    lastSweep time.Time
+}
+
+type bucket struct {
+    *rate.Limiter
+    seen time.Time
+}
+
+type Option func(*Limiter)
+
+func WithObserver(o Observer) Option { return func(l *Limiter) { l.observe = o } }
+
+// WithClock replaces time.Now, for tests
+func WithClock(now func() time.Time) Option { return func(l *Limiter) { l.now = now } }
+
+// New fails on a trusted proxy that is not a CIDR or an address
+func New(o Options, opts ...Option) (*Limiter, error) {
+    l := &Limiter{o: o, now: time.Now, buckets: map[string]*bucket{}}
+    trusted, err := caller.ParseProxies(o.TrustedProxies)
+    if err != nil {
+        return nil, err
+    }
+    l.trusted = trusted
+    for _, opt := range opts {
+        opt(l)
+    }
+    return l, nil
+}
+
+// Check charges one call of method by the caller in ctx, returning a ResourceExhausted
+// status with RetryInfo when a bucket is empty. method is the full gRPC method name.
+func (l *Limiter) Check(ctx context.Context, method string) error {
+    name := method[strings.LastIndex(method, "/")+1:]
+    suffix, b := "", l.o.User
+    if mb, ok := l.o.Methods[name]; ok {
+        suffix, b = ":"+name, mb
+    }
+    return l.charge(ctx, name, suffix, b)
+}
+
+// CheckMessage charges one message sent by the client on a stream of method; methods
+// without a Messages budget are free
+func (l *Limiter) CheckMessage(ctx context.Context, method string) error {
+    name := method[strings.LastIndex(method, "/")+1:]
+    b, ok := l.o.Messages[name]
+    if !ok {
+        return nil
+    }
+    return l.charge(ctx, name, ":"+name+":message", b)
+}
+
+// charge takes a token from the caller's bucket named by suffix, budgeted by b, and one
+// from the client IP's bucket
+func (l *Limiter) charge(ctx context.Context, name, suffix string, b Budget) error {
+    now := l.now()
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    l.sweep(now)
+
+    var held []*rate.Reservation
+    take := func(key string, b Budget) *rate.Reservation {
+        if b.unlimited() {
+            return nil
+        }
+        r := l.bucket(key, b, now).ReserveN(now, 1)
+        if r.OK() && r.DelayFrom(now) == 0 {
+            held = append(held, r)
+            return nil
+        }
+        return r
+    }
+    reject := func(scope, subject string, r *rate.Reservation, b Budget) error {
+        wait := time.Duration(float64(time.Minute) / b.PerMinute)
+        if r.OK() {
+            wait = r.DelayFrom(now)
+            r.CancelAt(now)
+        }
+        // an earlier bucket already paid for a call that will not happen
+        for _, h := range held {
+            h.CancelAt(now)
+        }
+        if l.observe != nil {
+            l.observe.RateLimited(name, scope)
+        }
+        return exhausted(subject, name, b, wait)
+    }
+
+    if c := caller.FromContext(ctx); !c.Anonymous() {
+        if r := take("user:"+c.UserID+suffix, b); r != nil {
+            return reject(ScopeUser, "user:"+c.UserID, r, b)
+        }
+    }
+    if ip := l.clientIP(ctx); ip != "" {
+        if r := take("ip:"+ip, l.o.IP); r != nil {
+            return reject(ScopeIP, "ip:"+ip, r, l.o.IP)
+        }
+    }
+    return nil
+}
+
+func (l *Limiter) bucket(key string, b Budget, now time.Time) *rate.Limiter {
+    bk, ok := l.buckets[key]
+    if !ok {
+        bk = &bucket{Limiter: rate.NewLimiter(rate.Limit(b.PerMinute/60), max(b.Burst, 1))}
+        l.buckets[key] = bk
+    }
+    bk.seen = now
+    return bk.Limiter
+}
+
+// sweep drops buckets idle long enough to have refilled
+func (l *Limiter) sweep(now time.Time) {
+    if now.Sub(l.lastSweep) < idleAfter {
+        return
+    }
+    l.lastSweep = now
+    for k, b := range l.buckets {
+        if now.Sub(b.seen) >= idleAfter {
+            delete(l.buckets, k)
+        }
+    }
+}
+
+// clientIP is the peer address or, behind trusted proxies, the nearest untrusted hop of
+// x-forwarded-for
+func (l *Limiter) clientIP(ctx context.Context) string {
+    p, ok := peer.FromContext(ctx)
+    if !ok || p.Addr == nil {
+        return ""
+    }
+    host, _, err := net.SplitHostPort(p.Addr.String())
+    if err != nil {
+        host = p.Addr.String()
+    }
+    addr, err := netip.ParseAddr(host)
+    if err != nil || !l.trusted.Contains(addr) {
+        return host
+    }
+    md, _ := metadata.FromIncomingContext(ctx)
+    var hops []string
+    for _, v := range md.Get(caller.HeaderForwardedFor) {
+        hops = append(hops, strings.Split(v, ",")...)
+    }
+    for i := len(hops) - 1; i >= 0; i-- {
+        hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+        if err != nil {
+            break
+        }
+        addr = hop
+        if !l.trusted.Contains(hop) {
+            break
+        }
+    }
+    return addr.Unmap().String()
+}
+
+// exhausted builds the ResourceExhausted status of a rejected call
+func exhausted(subject, method string, b Budget, wait time.Duration) error {
+    st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded for %s, retry in %s", method, wait.Round(time.Millisecond)))
+    // clients wait at least a whole millisecond, never zero
+    wait = time.Duration(math.Ceil(float64(wait)/float64(time.Millisecond))) * time.Millisecond
+    withDetails, err := st.WithDetails(
+        &errdetails.RetryInfo{RetryDelay: durationpb.New(wait)},
+        &errdetails.QuotaFailure{Violations: []*errdetails.QuotaFailure_Violation{{
+            Subject:     subject,
+            Description: fmt.Sprintf("%s: %g calls per minute, bursts of %d", method, b.PerMinute, max(b.Burst, 1)),
+        }}},
+    )
+    if err != nil {
+        return st.Err()
+    }
+    return withDetails.Err()
+}
+
+// exempt are the infrastructure services, which load balancers and tools call freely
+func exempt(method string) bool {
+    return strings.HasPrefix(method, "/grpc.")
+}
+
+func (l *Limiter) UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+    return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+        if !exempt(info.FullMethod) {
+            if err := l.Check(ctx, info.FullMethod); err != nil {
+                return nil, err
+            }
+        }
+        return handler(ctx, req)
+    }
+}
+
+// StreamServerInterceptor charges a stream when it opens and, for the methods in Messages,
+// for every message received; an exhausted budget fails the receive and so ends the stream
+func (l *Limiter) StreamServerInterceptor() grpc.StreamServerInterceptor {
+    return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+        if exempt(info.FullMethod) {
+            return handler(srv, ss)
+        }
+        if err := l.Check(ss.Context(), info.FullMethod); err != nil {
+            return err
+        }
+        if _, ok := l.o.Messages[info.FullMethod[strings.LastIndex(info.FullMethod, "/")+1:]]; ok {
+            ss = &chargedStream{ServerStream: ss, l: l, method: info.FullMethod}
+        }
+        return handler(srv, ss)
+    }
+}
+
+type chargedStream struct {
+    grpc.ServerStream
+    l      *Limiter
+    method string
+}
+
+func (s *chargedStream) RecvMsg(m any) error {
+    if err := s.ServerStream.RecvMsg(m); err != nil {
+        return err
+    }
+    return s.l.CheckMessage(s.Context(), s.method)
+}
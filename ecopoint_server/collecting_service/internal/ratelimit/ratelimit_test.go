@@ -0,0 +1,259 @@
+package ratelimit
+
+import (
+    "context"
+    "net"
+    "testing"
+    "time"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+
+    "ecopoint/collecting_service/internal/caller"
+)
+
+const (
+    create = "/ecopoint.collecting.v1.CollectingService/CreateOrder"
+    get    = "/ecopoint.collecting.v1.CollectingService/GetOrder"
+    list   = "/ecopoint.collecting.v1.CollectingService/ListMyOrders"
+    chat   = "/ecopoint.collecting.v1.CollectingService/Chat"
+)
+
+type rejections map[string]int
+
+func (r rejections) RateLimited(method, scope string) { r[method+"/"+scope]++ }
+
+// call is the context of a call from addr, by user when not empty, with x-forwarded-for hops
+func call(addr, user string, forwardedFor ...string) context.Context {
+    ctx := peer.NewContext(context.Background(), &peer.Peer{Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 40000}})
+    md := metadata.MD{}
+    if user != "" {
+        md.Set(caller.HeaderUserID, user)
+        md.Set(caller.HeaderRole, "customer")
+    }
+    for _, f := range forwardedFor {
+        md.Append(caller.HeaderForwardedFor, f)
+    }
+    return metadata.NewIncomingContext(ctx, md)
+}
+
+func newLimiter(t *testing.T, o Options, now *time.Time, obs Observer) *Limiter {
+    t.Helper()
+    l, err := New(o, WithClock(func() time.Time { return *now }), WithObserver(obs))
+    if err != nil {
+        t.Fatal(err)
+    }
+    return l
+}
+
+func TestUserBudgets(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    seen := rejections{}
+    l := newLimiter(t, Options{
+        User:           Budget{PerMinute: 60, Burst: 3},
+        Methods:        map[string]Budget{"CreateOrder": {PerMinute: 6, Burst: 1}},
+        TrustedProxies: []string{"127.0.0.0/8"},
+    }, &now, seen)
+
+    if err := l.Check(call("127.0.0.1", "u1"), create); err != nil {
+        t.Fatal(err)
+    }
+    err := l.Check(call("127.0.0.1", "u1"), create)
+    st := status.Convert(err)
+    if st.Code() != codes.ResourceExhausted {
+        t.Fatalf("second CreateOrder: %v", err)
+    }
+    var retry *errdetails.RetryInfo
+    var quota *errdetails.QuotaFailure
+    for _, d := range st.Details() {
+        switch d := d.(type) {
+        case *errdetails.RetryInfo:
+            retry = d
+        case *errdetails.QuotaFailure:
+            quota = d
+        }
+    }
+    if retry == nil || retry.RetryDelay.AsDuration() != 10*time.Second {
+        t.Errorf("retry info = %v", retry)
+    }
+    if quota == nil || quota.Violations[0].Subject != "user:u1" {
+        t.Errorf("quota failure = %v", quota)
+    }
+    if seen["CreateOrder/user"] != 1 {
+        t.Errorf("observer saw %v", seen)
+    }
+
+    // methods without a budget share the user bucket, apart from CreateOrder's
+    for i := 0; i < 3; i++ {
+        m := get
+        if i%2 == 1 {
+            m = list
+        }
+        if err := l.Check(call("127.0.0.1", "u1"), m); err != nil {
+            t.Fatalf("call %d: %v", i, err)
+        }
+    }
+    if err := l.Check(call("127.0.0.1", "u1"), get); status.Code(err) != codes.ResourceExhausted {
+        t.Fatalf("shared bucket over burst: %v", err)
+    }
+    if err := l.Check(call("127.0.0.1", "u2"), get); err != nil {
+        t.Fatalf("other users have their own buckets: %v", err)
+    }
+
+    now = now.Add(10 * time.Second)
+    if err := l.Check(call("127.0.0.1", "u1"), create); err != nil {
+        t.Fatalf("after the refill: %v", err)
+    }
+}
+
+func TestIPBehindProxies(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    l := newLimiter(t, Options{
+        User:           Budget{PerMinute: 60, Burst: 5},
+        IP:             Budget{PerMinute: 60, Burst: 1},
+        TrustedProxies: []string{"127.0.0.0/8", "10.0.0.5"},
+    }, &now, rejections{})
+
+    // the gateway on loopback forwards for the BFF, which forwards for the phone
+    viaProxies := func(user string) context.Context {
+        return call("127.0.0.1", user, "198.51.100.20, 10.0.0.5")
+    }
+    if err := l.Check(viaProxies("u1"), get); err != nil {
+        t.Fatal(err)
+    }
+    err := l.Check(viaProxies("u2"), get)
+    if status.Code(err) != codes.ResourceExhausted {
+        t.Fatalf("same client IP: %v", err)
+    }
+    // the rejected call did not cost u2 a user token
+    l.o.IP = Budget{}
+    for i := 0; i < 5; i++ {
+        if err := l.Check(viaProxies("u2"), get); err != nil {
+            t.Fatalf("u2 call %d: %v", i, err)
+        }
+    }
+    l.o.IP = Budget{PerMinute: 60, Burst: 1}
+
+    // an untrusted peer cannot pick its address
+    if err := l.Check(call("203.0.113.9", "", "198.51.100.99"), get); err != nil {
+        t.Fatal(err)
+    }
+    if err := l.Check(call("203.0.113.9", "", "198.51.100.98"), get); status.Code(err) != codes.ResourceExhausted {
+        t.Fatalf("spoofed x-forwarded-for: %v", err)
+    }
+    if got := l.clientIP(call("127.0.0.1", "", "198.51.100.20, 10.0.0.5")); got != "198.51.100.20" {
+        t.Errorf("client ip = %s", got)
+    }
+}
+
+func TestDirectCallerCannotRotateUsers(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    seen := rejections{}
+    l := newLimiter(t, Options{
+        User:           Budget{PerMinute: 60, Burst: 5},
+        IP:             Budget{PerMinute: 6, Burst: 1},
+        Methods:        map[string]Budget{"CreateOrder": {PerMinute: 6, Burst: 1}},
+        TrustedProxies: []string{"127.0.0.0/8"},
+    }, &now, seen)
+
+    // nobody vouches for the x-user-id of a direct caller, so only its address is charged
+    if err := l.Check(call("203.0.113.7", "u1"), create); err != nil {
+        t.Fatal(err)
+    }
+    err := l.Check(call("203.0.113.7", "u2"), create)
+    if status.Code(err) != codes.ResourceExhausted || seen["CreateOrder/ip"] != 1 {
+        t.Fatalf("rotated user id: %v, observer saw %v", err, seen)
+    }
+    if len(l.buckets) != 1 {
+        t.Errorf("buckets = %d, want only the address's", len(l.buckets))
+    }
+    // the same user through the proxy has its own bucket
+    if err := l.Check(call("127.0.0.1", "u2", "198.51.100.7"), create); err != nil {
+        t.Fatalf("proxied user: %v", err)
+    }
+}
+
+func TestCheckMessage(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    seen := rejections{}
+    l := newLimiter(t, Options{
+        User:           Budget{PerMinute: 60, Burst: 5},
+        Messages:       map[string]Budget{"Chat": {PerMinute: 6, Burst: 2}},
+        TrustedProxies: []string{"127.0.0.0/8"},
+    }, &now, seen)
+
+    ctx := call("127.0.0.1", "u1", "198.51.100.20")
+    for i := 0; i < 2; i++ {
+        if err := l.CheckMessage(ctx, chat); err != nil {
+            t.Fatalf("message %d: %v", i, err)
+        }
+    }
+    if err := l.CheckMessage(ctx, chat); status.Code(err) != codes.ResourceExhausted {
+        t.Fatalf("third message: %v", err)
+    }
+    if seen["Chat/user"] != 1 {
+        t.Errorf("observer saw %v", seen)
+    }
+    // opening a stream draws from the user bucket, not the message bucket
+    if err := l.Check(ctx, chat); err != nil {
+        t.Fatalf("open: %v", err)
+    }
+    if err := l.CheckMessage(ctx, create); err != nil {
+        t.Fatalf("methods without a message budget are free: %v", err)
+    }
+}
+
+func TestIdleBucketsAreDropped(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    l := newLimiter(t, Options{User: Budget{PerMinute: 60, Burst: 1}}, &now, rejections{})
+    _ = l.Check(call("127.0.0.1", "u1"), get)
+    _ = l.Check(call("127.0.0.1", "u2"), get)
+    now = now.Add(idleAfter)
+    _ = l.Check(call("127.0.0.1", "u3"), get)
+    if len(l.buckets) != 1 {
+        t.Errorf("buckets = %d, want 1", len(l.buckets))
+    }
+}
+
+func TestNewRejectsBadProxies(t *testing.T) {
+    if _, err := New(Options{TrustedProxies: []string{"gateway"}}); err == nil {
+        t.Fatal("expected an error")
+    }
+}
+
+// frames is a client stream that always has another message
+type frames struct {
+    grpc.ServerStream
+    ctx context.Context
+}
+
+func (f frames) Context() context.Context { return f.ctx }
+func (f frames) RecvMsg(any) error        { return nil }
+
+func TestStreamInterceptorChargesMessages(t *testing.T) {
+    now := time.Unix(1_700_000_000, 0)
+    l := newLimiter(t, Options{
+        User:           Budget{PerMinute: 60, Burst: 5},
+        Messages:       map[string]Budget{"Chat": {PerMinute: 6, Burst: 3}},
+        TrustedProxies: []string{"127.0.0.0/8"},
+    }, &now, rejections{})
+
+    received := 0
+    handler := func(_ any, ss grpc.ServerStream) error {
+        for {
+            if err := ss.RecvMsg(nil); err != nil {
+                return err
+            }
+            received++
+        }
+    }
+    ss := frames{ctx: call("127.0.0.1", "u1", "198.51.100.20")}
+    err := l.StreamServerInterceptor()(nil, ss, &grpc.StreamServerInfo{FullMethod: chat}, handler)
+    if status.Code(err) != codes.ResourceExhausted || received != 3 {
+        t.Fatalf("received %d frames, then %v", received, err)
+    }
+}
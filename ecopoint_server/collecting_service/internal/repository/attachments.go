@@ -0,0 +1,37 @@
+package repository
+
+import (
+    "context"
+    "errors"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) initAttachmentIndexes(ctx context.Context) error {
+    _, err := r.attachmentsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+        {Keys: bson.D{{Key: "order_id", Value: 1}}},
+        // pending uploads carry expires_at; completed ones have it unset
+        {Keys: bson.D{{Key: "expires_at", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+    })
+    return err
+}
+
+func (r *MongoRepo) SaveAttachment(a *models.Attachment) error {
+    _, err := r.attachmentsCol.ReplaceOne(context.Background(), bson.M{"id": a.ID}, a, options.Replace().SetUpsert(true))
+    return err
+}
+
+func (r *MongoRepo) GetAttachment(id string) (*models.Attachment, error) {
+    var a models.Attachment
+    err := r.attachmentsCol.FindOne(context.Background(), bson.M{"id": id}).Decode(&a)
+    if errors.Is(err, mongo.ErrNoDocuments) { return nil, svc.ErrAttachmentNotFound }
+    if err != nil { return nil, err }
+    return &a, nil
+}
+
+var _ svc.AttachmentStore = (*MongoRepo)(nil)
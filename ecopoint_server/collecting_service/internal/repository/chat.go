@@ -0,0 +1,62 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) initChatIndexes(ctx context.Context) error {
+    _, err := r.chatCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "id", Value: 1}}, Options: options.Index().SetUnique(true)},
+        {Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "sent_at", Value: 1}}},
+    })
+    return err
+}
+
+func (r *MongoRepo) AddChatMessage(m *models.ChatMessage) error {
+    _, err := r.chatCol.InsertOne(context.Background(), m)
+    return err
+}
+
+func (r *MongoRepo) GetChatMessage(orderID, id string) (*models.ChatMessage, error) {
+    var m models.ChatMessage
+    err := r.chatCol.FindOne(context.Background(), bson.M{"order_id": orderID, "id": id}).Decode(&m)
+    if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("message not found") }
+    if err != nil { return nil, err }
+    return &m, nil
+}
+
+func (r *MongoRepo) ListChatMessages(orderID string, after time.Time, limit int) ([]*models.ChatMessage, error) {
+    ctx := context.Background()
+    filter := bson.M{"order_id": orderID}
+    if !after.IsZero() {
+        filter["sent_at"] = bson.M{"$gt": after}
+    }
+    opts := options.Find().SetSort(bson.D{{Key: "sent_at", Value: 1}}).SetLimit(int64(limit))
+    cursor, err := r.chatCol.Find(ctx, filter, opts)
+    if err != nil { return nil, err }
+    defer cursor.Close(ctx)
+    res := make([]*models.ChatMessage, 0)
+    if err := cursor.All(ctx, &res); err != nil { return nil, err }
+    return res, nil
+}
+
+func (r *MongoRepo) MarkChatRead(orderID, readerID string, upTo, at time.Time) (int, error) {
+    res, err := r.chatCol.UpdateMany(context.Background(), bson.M{
+        "order_id":  orderID,
+        "sender_id": bson.M{"$ne": readerID},
+        "read_at":   bson.M{"$exists": false},
+        "sent_at":   bson.M{"$lte": upTo},
+    }, bson.M{"$set": bson.M{"read_at": at}})
+    if err != nil { return 0, err }
+    return int(res.ModifiedCount), nil
+}
+
+var _ svc.ChatStore = (*MongoRepo)(nil)
@@ -0,0 +1,125 @@
+package repository
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// initCollectorIndexes backs collector history (accepted_by + created_at) and
+// earnings (accepted_by + status + completed_at)
+func (r *MongoRepo) initCollectorIndexes(ctx context.Context) error {
+    _, err := r.ordersCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "accepted_by", Value: 1}, {Key: "created_at", Value: -1}, {Key: "id", Value: -1}}},
+        {Keys: bson.D{{Key: "accepted_by", Value: 1}, {Key: "status", Value: 1}, {Key: "completed_at", Value: -1}}},
+    })
+    return err
+}
+
+func (r *MongoRepo) ListByCollector(collectorID string, f svc.OrderFilter, after *svc.Cursor, limit int) ([]*models.Order, error) {
+    filter := bson.M{"accepted_by": collectorID}
+    if len(f.Statuses) > 0 {
+        filter["status"] = bson.M{"$in": f.Statuses}
+    }
+    created := bson.M{}
+    if !f.From.IsZero() { created["$gte"] = f.From }
+    if !f.To.IsZero() { created["$lt"] = f.To }
+    if len(created) > 0 {
+        filter["created_at"] = created
+    }
+    opts := options.Find().SetSort(newestFirst).SetLimit(int64(limit))
+    return r.findOrders(afterCursor(filter, after), opts)
+}
+
+type earningsRow struct {
+    ID struct {
+        Period time.Time `bson:"period"`
+        Type   string    `bson:"type"`
+    } `bson:"_id"`
+    Orders      int     `bson:"orders"`
+    TotalWeight float64 `bson:"total_weight"`
+    Amount      float64 `bson:"amount"`
+    Kg          float64 `bson:"kg"`
+}
+
+// CollectorEarnings buckets completed orders with $dateTrunc (MongoDB 5.0+). Order totals and
+// per-type weights are grouped in separate facets so unwinding items does not double count prices.
+func (r *MongoRepo) CollectorEarnings(collectorID string, q svc.EarningsQuery) ([]svc.EarningsBucket, error) {
+    ctx := r.context()
+    trunc := bson.M{"date": "$completed_at", "unit": string(q.GroupBy), "timezone": mongoTimeZone(q.Location, q.From)}
+    if q.GroupBy == svc.GroupByWeek {
+        trunc["startOfWeek"] = "monday"
+    }
+    pipeline := mongo.Pipeline{
+        {{Key: "$match", Value: bson.M{
+            "accepted_by":  collectorID,
+            "status":       models.StatusComplete,
+            "completed_at": bson.M{"$gte": q.From, "$lt": q.To},
+        }}},
+        {{Key: "$addFields", Value: bson.M{"period": bson.M{"$dateTrunc": trunc}}}},
+        {{Key: "$facet", Value: bson.M{
+            "orders": bson.A{
+                bson.M{"$group": bson.M{
+                    "_id":          bson.M{"period": "$period"},
+                    "orders":       bson.M{"$sum": 1},
+                    "total_weight": bson.M{"$sum": "$total_weight"},
+                    "amount":       bson.M{"$sum": "$estimated_price"},
+                }},
+            },
+            "weights": bson.A{
+                bson.M{"$unwind": "$items"},
+                bson.M{"$group": bson.M{
+                    "_id": bson.M{"period": "$period", "type": "$items.type"},
+                    "kg":  bson.M{"$sum": "$items.weight"},
+                }},
+            },
+        }}},
+    }
+    cursor, err := r.ordersCol.Aggregate(ctx, pipeline)
+    if err != nil { return nil, err }
+    defer cursor.Close(ctx)
+    var facets []struct {
+        Orders  []earningsRow `bson:"orders"`
+        Weights []earningsRow `bson:"weights"`
+    }
+    if err := cursor.All(ctx, &facets); err != nil { return nil, err }
+    if len(facets) == 0 { return nil, nil }
+
+    byStart := map[int64]*svc.EarningsBucket{}
+    for _, row := range facets[0].Orders {
+        byStart[row.ID.Period.Unix()] = &svc.EarningsBucket{
+            PeriodStart:  row.ID.Period.In(q.Location),
+            Orders:       row.Orders,
+            TotalWeight:  row.TotalWeight,
+            Amount:       row.Amount,
+            WeightByType: map[string]float64{},
+        }
+    }
+    for _, row := range facets[0].Weights {
+        if b, ok := byStart[row.ID.Period.Unix()]; ok {
+            b.WeightByType[row.ID.Type] += row.Kg
+        }
+    }
+    res := make([]svc.EarningsBucket, 0, len(byStart))
+    for _, b := range byStart {
+        res = append(res, *b)
+    }
+    sort.Slice(res, func(i, j int) bool { return res[i].PeriodStart.Before(res[j].PeriodStart) })
+    return res, nil
+}
+
+// mongoTimeZone passes IANA names through; fixed or Local zones become a UTC offset
+func mongoTimeZone(loc *time.Location, at time.Time) string {
+    if name := loc.String(); name != "Local" {
+        if _, err := time.LoadLocation(name); err == nil {
+            return name
+        }
+    }
+    return at.In(loc).Format("-07:00")
+}
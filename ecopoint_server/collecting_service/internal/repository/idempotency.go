@@ -0,0 +1,85 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type idempotencyDoc struct {
+    Scope       string    `bson:"scope"`
+    Key         string    `bson:"key"`
+    Operation   string    `bson:"operation"`
+    OrderID     string    `bson:"order_id"`
+    Fingerprint string    `bson:"fingerprint,omitempty"`
+    CreatedAt   time.Time `bson:"created_at"`
+    ExpireAt    time.Time `bson:"expire_at"`
+}
+
+func (d idempotencyDoc) toRecord() *svc.IdempotencyRecord {
+    return &svc.IdempotencyRecord{
+        Scope:       d.Scope,
+        Key:         d.Key,
+        Operation:   d.Operation,
+        OrderID:     d.OrderID,
+        Fingerprint: d.Fingerprint,
+        CreatedAt:   d.CreatedAt,
+        ExpiresAt:   d.ExpireAt,
+    }
+}
+
+// initIdempotencyIndexes: unique key per scope (customer/collector) and TTL cleanup
+func (r *MongoRepo) initIdempotencyIndexes(ctx context.Context) error {
+    _, err := r.idemCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {
+            Keys:    bson.D{{Key: "scope", Value: 1}, {Key: "key", Value: 1}},
+            Options: options.Index().SetUnique(true),
+        },
+        {
+            Keys:    bson.D{{Key: "expire_at", Value: 1}},
+            Options: options.Index().SetExpireAfterSeconds(0),
+        },
+    })
+    return err
+}
+
+// ReserveIdempotencyKey upserts over an expired record (the TTL monitor only runs once a minute);
+// a live record makes the upsert collide with the unique index.
+func (r *MongoRepo) ReserveIdempotencyKey(rec svc.IdempotencyRecord) (*svc.IdempotencyRecord, error) {
+    ctx := context.Background()
+    doc := idempotencyDoc{
+        Scope:       rec.Scope,
+        Key:         rec.Key,
+        Operation:   rec.Operation,
+        OrderID:     rec.OrderID,
+        Fingerprint: rec.Fingerprint,
+        CreatedAt:   rec.CreatedAt,
+        ExpireAt:    rec.ExpiresAt,
+    }
+    filter := bson.M{"scope": rec.Scope, "key": rec.Key, "expire_at": bson.M{"$lte": rec.CreatedAt}}
+    _, err := r.idemCol.ReplaceOne(ctx, filter, doc, options.Replace().SetUpsert(true))
+    if err == nil {
+        return doc.toRecord(), nil
+    }
+    if !mongo.IsDuplicateKeyError(err) {
+        return nil, err
+    }
+    var existing idempotencyDoc
+    if err := r.idemCol.FindOne(ctx, bson.M{"scope": rec.Scope, "key": rec.Key}).Decode(&existing); err != nil {
+        if errors.Is(err, mongo.ErrNoDocuments) { return nil, svc.ErrIdempotencyInProgress }
+        return nil, err
+    }
+    return existing.toRecord(), svc.ErrIdempotencyKeyExists
+}
+
+func (r *MongoRepo) ReleaseIdempotencyKey(scope, key string) error {
+    _, err := r.idemCol.DeleteOne(context.Background(), bson.M{"scope": scope, "key": key})
+    return err
+}
+
+var _ svc.IdempotencyStore = (*MongoRepo)(nil)
@@ -0,0 +1,38 @@
+package repository
+
+import (
+    "context"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) initLedgerIndexes(ctx context.Context) error {
+    _, err := r.ledgerCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "at", Value: -1}},
+    })
+    return err
+}
+
+// AddLedgerEntry relies on the deterministic _id: a duplicate means the entry is already booked
+func (r *MongoRepo) AddLedgerEntry(e models.LedgerEntry) error {
+    _, err := r.ledgerCol.InsertOne(context.Background(), e)
+    if mongo.IsDuplicateKeyError(err) { return nil }
+    return err
+}
+
+func (r *MongoRepo) ListLedgerEntries(userID string) ([]models.LedgerEntry, error) {
+    ctx := context.Background()
+    opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}})
+    cursor, err := r.ledgerCol.Find(ctx, bson.M{"user_id": userID}, opts)
+    if err != nil { return nil, err }
+    defer cursor.Close(ctx)
+    res := make([]models.LedgerEntry, 0)
+    if err := cursor.All(ctx, &res); err != nil { return nil, err }
+    return res, nil
+}
+
+var _ svc.LedgerStore = (*MongoRepo)(nil)
@@ -3,6 +3,7 @@ package repository
 import (
     "context"
     "errors"
+    "log/slog"
     "time"
 
     "ecopoint/collecting_service/internal/models"
@@ -10,16 +11,53 @@ import (
     "go.mongodb.org/mongo-driver/bson"
     "go.mongodb.org/mongo-driver/mongo"
     "go.mongodb.org/mongo-driver/mongo/options"
+    "go.opentelemetry.io/otel/trace"
 )
 
 type MongoRepo struct {
     client    *mongo.Client
     db        *mongo.Database
     ordersCol *mongo.Collection
+    idemCol   *mongo.Collection
+    ratingsCol     *mongo.Collection
+    userRatingsCol *mongo.Collection
+    strikesCol     *mongo.Collection
+    penaltiesCol   *mongo.Collection
+    ledgerCol      *mongo.Collection
+    notifyPrefsCol *mongo.Collection
+    deadLettersCol *mongo.Collection
+    chatCol        *mongo.Collection
+    attachmentsCol *mongo.Collection
+    orderTTL       time.Duration // unaccepted orders expire this long after creation
+    log            *slog.Logger
+    observe        CommandObserver // nil: no latency reporting
+    tracer         trace.Tracer    // nil: commands are not traced
+    ctx            context.Context // set by WithContext; nil means context.Background
 }
 
-func NewMongoRepo(ctx context.Context, uri string, dbName string) (*MongoRepo, error) {
-    client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri))
+// Option customises a MongoRepo at construction time
+type Option func(*MongoRepo)
+
+// WithCommandObserver reports the duration of every command sent to MongoDB
+func WithCommandObserver(o CommandObserver) Option {
+    return func(r *MongoRepo) { r.observe = o }
+}
+
+// WithTracerProvider traces commands issued under a context that already carries a span
+func WithTracerProvider(tp trace.TracerProvider) Option {
+    return func(r *MongoRepo) { r.tracer = tp.Tracer(tracerName) }
+}
+
+// NewMongoRepo logs through slog.Default as it is at the time of the call
+func NewMongoRepo(ctx context.Context, uri string, dbName string, opts ...Option) (*MongoRepo, error) {
+    repo := &MongoRepo{
+        orderTTL: DefaultOrderTTL,
+        log:      slog.Default().With("component", "mongo"),
+    }
+    for _, opt := range opts {
+        opt(repo)
+    }
+    client, err := mongo.Connect(ctx, options.Client().ApplyURI(uri).SetMonitor(commandMonitor(repo.log, repo.observe, repo.tracer)))
     if err != nil {
         return nil, err
     }
@@ -27,18 +65,64 @@ func NewMongoRepo(ctx context.Context, uri string, dbName string) (*MongoRepo, e
         return nil, err
     }
     db := client.Database(dbName)
-    repo := &MongoRepo{
-        client:    client,
-        db:        db,
-        ordersCol: db.Collection("orders"),
-    }
+    repo.client = client
+    repo.db = db
+    repo.ordersCol = db.Collection("orders")
+    repo.idemCol = db.Collection("idempotency_keys")
+    repo.ratingsCol = db.Collection("ratings")
+    repo.userRatingsCol = db.Collection("user_ratings")
+    repo.strikesCol = db.Collection("collector_strikes")
+    repo.penaltiesCol = db.Collection("collector_penalties")
+    repo.ledgerCol = db.Collection("points_ledger")
+    repo.notifyPrefsCol = db.Collection("notification_preferences")
+    repo.deadLettersCol = db.Collection("notification_dead_letters")
+    repo.chatCol = db.Collection("chat_messages")
+    repo.attachmentsCol = db.Collection("attachments")
     return repo, nil
 }
 
+// WithContext returns a copy of the repo whose order queries run under ctx, so command
+// spans become children of the caller's span
+func (r *MongoRepo) WithContext(ctx context.Context) svc.Repository {
+    c := *r
+    c.ctx = ctx
+    return &c
+}
+
+func (r *MongoRepo) context() context.Context {
+    if r.ctx == nil {
+        return context.Background()
+    }
+    return r.ctx
+}
+
+// DefaultOrderTTL is how long a created order stays in the pool before it expires
+const DefaultOrderTTL = 60 * time.Minute
+
+// OrderTTLBackstop is how long after its TTL MongoDB deletes a created order. The watchdog
+// expires orders at the TTL itself (see service.WithOrderTTL); the index only removes the
+// ones it missed, e.g. while it was disabled.
+const OrderTTLBackstop = time.Hour
+
+// SetOrderTTL changes the expiry of orders created from now on; ttl <= 0 keeps the current value
+func (r *MongoRepo) SetOrderTTL(ttl time.Duration) {
+    if ttl > 0 {
+        r.orderTTL = ttl
+    }
+}
+
 func (r *MongoRepo) Close(ctx context.Context) error {
     return r.client.Disconnect(ctx)
 }
 
+// Database is the database of the repo, for tools such as migrations
+func (r *MongoRepo) Database() *mongo.Database { return r.db }
+
+// Ping checks that the primary is reachable; used by health checks
+func (r *MongoRepo) Ping(ctx context.Context) error {
+    return r.client.Ping(ctx, nil)
+}
+
 // InitIndexes creates recommended indexes for performance and TTL
 func (r *MongoRepo) InitIndexes(ctx context.Context) error {
     // unique id
@@ -49,16 +133,31 @@ func (r *MongoRepo) InitIndexes(ctx context.Context) error {
     if err != nil {
         return err
     }
-    // status + created_at
+    // status + created_at + id (available pool, keyset pagination)
     _, err = r.ordersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
-        Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}},
+        Keys: bson.D{{Key: "status", Value: 1}, {Key: "created_at", Value: -1}, {Key: "id", Value: -1}},
+    })
+    if err != nil {
+        return err
+    }
+    // accepted_by + status + created_at + id
+    _, err = r.ordersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys: bson.D{{Key: "accepted_by", Value: 1}, {Key: "status", Value: 1}, {Key: "created_at", Value: -1}, {Key: "id", Value: -1}},
+    })
+    if err != nil {
+        return err
+    }
+    // status + accepted_at / on_way_at (no-show watchdog)
+    _, err = r.ordersCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "status", Value: 1}, {Key: "accepted_at", Value: 1}}},
+        {Keys: bson.D{{Key: "status", Value: 1}, {Key: "on_way_at", Value: 1}}},
     })
     if err != nil {
         return err
     }
-    // accepted_by + status
+    // customer_id + created_at + id (my orders)
     _, err = r.ordersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
-        Keys: bson.D{{Key: "accepted_by", Value: 1}, {Key: "status", Value: 1}},
+        Keys: bson.D{{Key: "customer_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "id", Value: -1}},
     })
     if err != nil {
         return err
@@ -72,94 +171,85 @@ func (r *MongoRepo) InitIndexes(ctx context.Context) error {
         return err
     }
     // Geo index (optional) if using loc
-    _, _ = r.ordersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+    if _, err := r.ordersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
         Keys: bson.D{{Key: "loc", Value: "2dsphere"}},
-    })
-    return nil
-}
-
-// Helpers to build the Mongo document from domain model
-func orderToDoc(o *models.Order) bson.M {
-    doc := bson.M{
-        "id":                   o.ID,
-        "customer_id":          o.CustomerID,
-        "status":               o.Status,
-        "pick_address_snapshot": o.PickAddressSnapshot,
-        "customer_snapshot":     o.CustomerSnapshot,
-        "items":                o.Items,
-        "total_weight":         o.TotalWeight,
-        "estimated_price":      o.EstimatedPrice,
-        "distance_km":          o.DistanceKm,
-        "eta_minutes":          o.EtaMinutes,
-        "note":                 o.Note,
-        "created_at":           o.CreatedAt,
-        "updated_at":           o.UpdatedAt,
-        "version":              o.Version,
+    }); err != nil {
+        r.log.Warn("optional geo index not created", "error", err)
     }
-    if o.AcceptedBy != nil {
-        doc["accepted_by"] = *o.AcceptedBy
+    if err := r.initCollectorIndexes(ctx); err != nil {
+        return err
     }
-    if o.AcceptedAt != nil {
-        doc["accepted_at"] = *o.AcceptedAt
+    if err := r.initRatingIndexes(ctx); err != nil {
+        return err
     }
-    if o.CompletedAt != nil {
-        doc["completed_at"] = *o.CompletedAt
+    if err := r.initStrikeIndexes(ctx); err != nil {
+        return err
     }
-    if o.CancelReason != "" {
-        doc["cancel_reason"] = o.CancelReason
-        doc["cancel_side"] = o.CancelSide
+    if err := r.initLedgerIndexes(ctx); err != nil {
+        return err
     }
-    // GeoJSON point for near queries
-    doc["loc"] = bson.M{"type": "Point", "coordinates": []float64{o.PickAddressSnapshot.Lng, o.PickAddressSnapshot.Lat}}
-    return doc
-}
-
-func docToOrder(doc *bson.M) *models.Order {
-    if doc == nil {
-        return nil
+    if err := r.initNotifyIndexes(ctx); err != nil {
+        return err
+    }
+    if err := r.initChatIndexes(ctx); err != nil {
+        return err
     }
-    var o models.Order
-    b, _ := bson.Marshal(doc)
-    _ = bson.Unmarshal(b, &o)
-    return &o
+    if err := r.initAttachmentIndexes(ctx); err != nil {
+        return err
+    }
+    return r.initIdempotencyIndexes(ctx)
 }
 
 // Implement service.Repository
 func (r *MongoRepo) Create(order *models.Order) error {
-    // set expire_at for created orders (TTL), e.g., 60 minutes default here; can be moved to config
-    expireAt := order.CreatedAt.Add(60 * time.Minute)
-    doc := orderToDoc(order)
+    // set expire_at for created orders (TTL backstop)
+    expireAt := order.CreatedAt.Add(r.orderTTL + OrderTTLBackstop)
+    doc := newOrderDoc(order)
     if order.Status == models.StatusCreated {
-        doc["expire_at"] = expireAt
+        doc.ExpireAt = &expireAt
     }
-    _, err := r.ordersCol.InsertOne(context.Background(), doc)
+    _, err := r.ordersCol.InsertOne(r.context(), doc)
     return err
 }
 
 func (r *MongoRepo) Get(id string) (*models.Order, error) {
-    var m bson.M
-    err := r.ordersCol.FindOne(context.Background(), bson.M{"id": id}).Decode(&m)
-    if err != nil {
-        if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("not found") }
-        return nil, err
+    o, err := decodeOrder(r.ordersCol.FindOne(r.context(), bson.M{"id": id}).Decode)
+    if errors.Is(err, mongo.ErrNoDocuments) { return nil, svc.ErrOrderNotFound }
+    return o, err
+}
+
+// newestFirst is the list ordering shared with the in-memory repo: created_at desc, id desc
+var newestFirst = bson.D{{Key: "created_at", Value: -1}, {Key: "id", Value: -1}}
+
+// afterCursor narrows filter to orders that sort strictly after c
+func afterCursor(filter bson.M, c *svc.Cursor) bson.M {
+    if c == nil { return filter }
+    filter["$or"] = bson.A{
+        bson.M{"created_at": bson.M{"$lt": c.CreatedAt}},
+        bson.M{"created_at": c.CreatedAt, "id": bson.M{"$lt": c.ID}},
     }
-    return docToOrder(&m), nil
+    return filter
 }
 
-func (r *MongoRepo) ListAvailable(limit int) ([]*models.Order, error) {
-    opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetLimit(int64(limit))
-    cursor, err := r.ordersCol.Find(context.Background(), bson.M{"status": models.StatusCreated}, opts)
+func (r *MongoRepo) findOrders(filter bson.M, opts ...*options.FindOptions) ([]*models.Order, error) {
+    ctx := r.context()
+    cursor, err := r.ordersCol.Find(ctx, filter, opts...)
     if err != nil { return nil, err }
-    defer cursor.Close(context.Background())
+    defer cursor.Close(ctx)
     var res []*models.Order
-    for cursor.Next(context.Background()) {
-        var m bson.M
-        if err := cursor.Decode(&m); err != nil { return nil, err }
-        res = append(res, docToOrder(&m))
+    for cursor.Next(ctx) {
+        o, err := decodeOrder(cursor.Decode)
+        if err != nil { return nil, err }
+        res = append(res, o)
     }
     return res, cursor.Err()
 }
 
+func (r *MongoRepo) ListAvailable(after *svc.Cursor, limit int) ([]*models.Order, error) {
+    opts := options.Find().SetSort(newestFirst).SetLimit(int64(limit))
+    return r.findOrders(afterCursor(bson.M{"status": models.StatusCreated}, after), opts)
+}
+
 func (r *MongoRepo) AtomicAccept(id string, collectorID string) (*models.Order, error) {
     now := time.Now()
     filter := bson.M{"id": id, "status": models.StatusCreated}
@@ -172,80 +262,88 @@ func (r *MongoRepo) AtomicAccept(id string, collectorID string) (*models.Order,
         },
         "$inc": bson.M{"version": 1},
         "$unset": bson.M{"expire_at": ""},
+        "$push": bson.M{"history": models.StatusChange{From: models.StatusCreated, To: models.StatusAccepted, At: now, Actor: collectorID}},
     }
     opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
-    var m bson.M
-    err := r.ordersCol.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&m)
-    if err != nil {
-        if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("already taken or not found") }
-        return nil, err
-    }
-    return docToOrder(&m), nil
+    o, err := decodeOrder(r.ordersCol.FindOneAndUpdate(r.context(), filter, update, opts).Decode)
+    if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("already taken or not found") }
+    return o, err
+}
+
+func orderUpdate(o *models.Order) bson.M {
+    doc := newOrderDoc(o)
+    return bson.M{"$set": doc, "$unset": doc.missing()}
 }
 
 func (r *MongoRepo) Update(order *models.Order) error {
-    doc := orderToDoc(order)
-    _, err := r.ordersCol.UpdateOne(context.Background(), bson.M{"id": order.ID}, bson.M{"$set": doc})
+    _, err := r.ordersCol.UpdateOne(r.context(), bson.M{"id": order.ID}, orderUpdate(order))
     return err
 }
 
+func (r *MongoRepo) UpdateVersioned(order *models.Order, expectedVersion int64) error {
+    res, err := r.ordersCol.UpdateOne(r.context(), bson.M{"id": order.ID, "version": expectedVersion}, orderUpdate(order))
+    if err != nil { return err }
+    if res.MatchedCount == 0 {
+        if _, err := r.Get(order.ID); err != nil { return err }
+        r.log.Debug("version conflict", "order_id", order.ID, "expected_version", expectedVersion)
+        return models.ErrVersionConflict
+    }
+    return nil
+}
+
+// ListStalled uses created_at / accepted_at / on_way_at; on_way orders written before on_way_at
+// existed fall back to updated_at. Only created orders with an expire_at expire: a released
+// order has none, as before.
+func (r *MongoRepo) ListStalled(status models.OrderStatus, cutoff time.Time, limit int) ([]*models.Order, error) {
+    filter := bson.M{"status": status}
+    switch status {
+    case models.StatusCreated:
+        filter["created_at"] = bson.M{"$lt": cutoff}
+        filter["expire_at"] = bson.M{"$exists": true}
+    case models.StatusAccepted:
+        filter["accepted_at"] = bson.M{"$lt": cutoff}
+    case models.StatusOnWay:
+        filter["$or"] = bson.A{
+            bson.M{"on_way_at": bson.M{"$lt": cutoff}},
+            bson.M{"on_way_at": bson.M{"$exists": false}, "updated_at": bson.M{"$lt": cutoff}},
+        }
+    default:
+        filter["updated_at"] = bson.M{"$lt": cutoff}
+    }
+    return r.findOrders(filter, options.Find().SetLimit(int64(limit)))
+}
+
 func (r *MongoRepo) FindActiveOrderByCollector(collectorID string) (*models.Order, error) {
     filter := bson.M{"accepted_by": collectorID, "status": bson.M{"$in": []models.OrderStatus{models.StatusAccepted, models.StatusOnWay}}}
-    var m bson.M
-    err := r.ordersCol.FindOne(context.Background(), filter).Decode(&m)
-    if err != nil {
-        if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("not found") }
-        return nil, err
-    }
-    return docToOrder(&m), nil
+    o, err := decodeOrder(r.ordersCol.FindOne(r.context(), filter).Decode)
+    if errors.Is(err, mongo.ErrNoDocuments) { return nil, errors.New("not found") }
+    return o, err
 }
 
 func (r *MongoRepo) ListAll() ([]*models.Order, error) {
-    cursor, err := r.ordersCol.Find(context.Background(), bson.M{})
-    if err != nil { return nil, err }
-    defer cursor.Close(context.Background())
-    var res []*models.Order
-    for cursor.Next(context.Background()) {
-        var m bson.M
-        if err := cursor.Decode(&m); err != nil { return nil, err }
-        res = append(res, docToOrder(&m))
-    }
-    return res, cursor.Err()
+    return r.findOrders(bson.M{})
 }
 
-func (r *MongoRepo) ListByCustomer(customerID string, page, size int) ([]*models.Order, error) {
-    if page < 1 { page = 1 }
-    if size <= 0 { size = 20 }
-    skip := int64((page-1) * size)
-    limit := int64(size)
-    opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}}).SetSkip(skip).SetLimit(limit)
-    cursor, err := r.ordersCol.Find(context.Background(), bson.M{"customer_id": customerID}, opts)
-    if err != nil { return nil, err }
-    defer cursor.Close(context.Background())
-    var res []*models.Order
-    for cursor.Next(context.Background()) {
-        var m bson.M
-        if err := cursor.Decode(&m); err != nil { return nil, err }
-        res = append(res, docToOrder(&m))
-    }
-    return res, cursor.Err()
+func (r *MongoRepo) ListByCustomer(customerID string, after *svc.Cursor, limit int) ([]*models.Order, error) {
+    opts := options.Find().SetSort(newestFirst).SetLimit(int64(limit))
+    return r.findOrders(afterCursor(bson.M{"customer_id": customerID}, after), opts)
+}
+
+func (r *MongoRepo) CountByCustomer(customerID string, status models.OrderStatus) (int, error) {
+    n, err := r.ordersCol.CountDocuments(r.context(), bson.M{"customer_id": customerID, "status": status})
+    return int(n), err
 }
 
-func (r *MongoRepo) ListActiveByCollector(collectorID string) ([]*models.Order, error) {
+func (r *MongoRepo) ListActiveByCollector(collectorID string, after *svc.Cursor, limit int) ([]*models.Order, error) {
     filter := bson.M{"accepted_by": collectorID, "status": bson.M{"$in": []models.OrderStatus{models.StatusAccepted, models.StatusOnWay}}}
-    cursor, err := r.ordersCol.Find(context.Background(), filter)
-    if err != nil { return nil, err }
-    defer cursor.Close(context.Background())
-    var res []*models.Order
-    for cursor.Next(context.Background()) {
-        var m bson.M
-        if err := cursor.Decode(&m); err != nil { return nil, err }
-        res = append(res, docToOrder(&m))
-    }
-    return res, cursor.Err()
+    opts := options.Find().SetSort(newestFirst).SetLimit(int64(limit))
+    return r.findOrders(afterCursor(filter, after), opts)
 }
 
 // Ensure MongoRepo implements Repository
-var _ svc.Repository = (*MongoRepo)(nil)
+var (
+    _ svc.Repository    = (*MongoRepo)(nil)
+    _ svc.ContextBinder = (*MongoRepo)(nil)
+)
 
 
@@ -0,0 +1,85 @@
+package repository
+
+import (
+    "context"
+    "log/slog"
+    "sync"
+    "time"
+
+    "go.mongodb.org/mongo-driver/event"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// slowCommand is the duration above which a MongoDB command is logged at warn level
+const slowCommand = 250 * time.Millisecond
+
+// tracerName is the instrumentation scope of MongoDB command spans
+const tracerName = "ecopoint/collecting_service/internal/repository"
+
+// CommandObserver receives every finished MongoDB command, e.g. to record latency metrics.
+// collection is empty for commands that do not target one (ping, hello, ...).
+type CommandObserver func(command, collection string, d time.Duration, failed bool)
+
+// inflight is what Started hands over to Succeeded / Failed for one request id
+type inflight struct {
+    collection string
+    span       trace.Span // nil when the command is not traced
+}
+
+// commandMonitor logs every command at debug level, and failed or slow ones as warnings.
+// With a tracer, commands issued under a traced context get a child span; commands
+// without a parent (pool maintenance, health pings) are not traced.
+func commandMonitor(log *slog.Logger, observe CommandObserver, tracer trace.Tracer) *event.CommandMonitor {
+    var pending sync.Map // request id -> inflight, between started and finished
+    finished := func(e event.CommandFinishedEvent, failed bool, failure string) {
+        v, _ := pending.LoadAndDelete(e.RequestID)
+        f, _ := v.(inflight)
+        if observe != nil {
+            observe(e.CommandName, f.collection, e.Duration, failed)
+        }
+        if f.span != nil {
+            if failed {
+                f.span.SetStatus(codes.Error, failure)
+            }
+            f.span.End()
+        }
+    }
+    return &event.CommandMonitor{
+        Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+            var f inflight
+            // the first element names the command and, for collection commands, the collection;
+            // sensitive commands arrive redacted to an empty document
+            if first, err := e.Command.IndexErr(0); err == nil {
+                f.collection, _ = first.Value().StringValueOK()
+            }
+            if tracer != nil && trace.SpanContextFromContext(ctx).IsValid() {
+                attrs := []attribute.KeyValue{
+                    attribute.String("db.system", "mongodb"),
+                    attribute.String("db.name", e.DatabaseName),
+                    attribute.String("db.operation", e.CommandName),
+                }
+                if f.collection != "" {
+                    attrs = append(attrs, attribute.String("db.mongodb.collection", f.collection))
+                }
+                _, f.span = tracer.Start(ctx, "mongo."+e.CommandName, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+            }
+            if observe != nil || f.span != nil {
+                pending.Store(e.RequestID, f)
+            }
+        },
+        Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+            finished(e.CommandFinishedEvent, false, "")
+            level := slog.LevelDebug
+            if e.Duration > slowCommand {
+                level = slog.LevelWarn
+            }
+            log.Log(ctx, level, "mongo command", "command", e.CommandName, "db", e.DatabaseName, "duration", e.Duration)
+        },
+        Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+            finished(e.CommandFinishedEvent, true, e.Failure)
+            log.WarnContext(ctx, "mongo command failed", "command", e.CommandName, "db", e.DatabaseName, "duration", e.Duration, "error", e.Failure)
+        },
+    }
+}
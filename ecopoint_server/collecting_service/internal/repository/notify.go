@@ -0,0 +1,51 @@
+package repository
+
+import (
+    "context"
+    "errors"
+
+    "ecopoint/collecting_service/internal/notify"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) initNotifyIndexes(ctx context.Context) error {
+    _, err := r.notifyPrefsCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "user_id", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    if err != nil {
+        return err
+    }
+    _, err = r.deadLettersCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys: bson.D{{Key: "at", Value: -1}},
+    })
+    return err
+}
+
+func (r *MongoRepo) GetPreferences(userID string) (*notify.Preferences, error) {
+    var p notify.Preferences
+    err := r.notifyPrefsCol.FindOne(context.Background(), bson.M{"user_id": userID}).Decode(&p)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return &notify.Preferences{UserID: userID}, nil
+    }
+    if err != nil { return nil, err }
+    return &p, nil
+}
+
+func (r *MongoRepo) SavePreferences(p *notify.Preferences) error {
+    opts := options.Replace().SetUpsert(true)
+    _, err := r.notifyPrefsCol.ReplaceOne(context.Background(), bson.M{"user_id": p.UserID}, p, opts)
+    return err
+}
+
+func (r *MongoRepo) AddDeadLetter(d notify.DeadLetter) error {
+    _, err := r.deadLettersCol.InsertOne(context.Background(), d)
+    return err
+}
+
+var (
+    _ notify.PreferenceStore = (*MongoRepo)(nil)
+    _ notify.DeadLetterStore = (*MongoRepo)(nil)
+)
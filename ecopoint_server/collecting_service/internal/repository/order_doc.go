@@ -0,0 +1,154 @@
+package repository
+
+import (
+    "errors"
+    "fmt"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// orderDoc is an order as stored in the orders collection. Nested values keep the bson tags
+// of their models; _id and fields unknown to this build are ignored when decoding.
+type orderDoc struct {
+    ID                  string                  `bson:"id"`
+    CustomerID          string                  `bson:"customer_id"`
+    Status              models.OrderStatus      `bson:"status"`
+    PickAddressSnapshot models.Address          `bson:"pick_address_snapshot"`
+    CustomerSnapshot    models.CustomerSnapshot `bson:"customer_snapshot"`
+    Items               []models.WasteItem      `bson:"items"`
+    TotalWeight         float64                 `bson:"total_weight"`
+    EstimatedPrice      float64                 `bson:"estimated_price"`
+    DistanceKm          float64                 `bson:"distance_km"`
+    EtaMinutes          int                     `bson:"eta_minutes"`
+    Note                string                  `bson:"note"`
+    CreatedAt           time.Time               `bson:"created_at"`
+    UpdatedAt           time.Time               `bson:"updated_at"`
+    Version             int64                   `bson:"version"`
+
+    // optional: left out when empty, see missing
+    AcceptedBy   *string                `bson:"accepted_by,omitempty"`
+    AcceptedAt   *time.Time             `bson:"accepted_at,omitempty"`
+    OnWayAt      *time.Time             `bson:"on_way_at,omitempty"`
+    CompletedAt  *time.Time             `bson:"completed_at,omitempty"`
+    History      []models.StatusChange  `bson:"history,omitempty"`
+    CancelReason string                 `bson:"cancel_reason,omitempty"`
+    CancelSide   models.CancelBy        `bson:"cancel_side,omitempty"`
+    CancelFee    float64                `bson:"cancel_fee,omitempty"`
+    Attachments  []models.AttachmentRef `bson:"attachments,omitempty"`
+
+    // derived: loc backs the 2dsphere index; expire_at the TTL of unaccepted orders is
+    // only written by Create, so updates leave it alone until the order leaves created
+    Loc      *geoPoint  `bson:"loc,omitempty"`
+    ExpireAt *time.Time `bson:"expire_at,omitempty"`
+}
+
+// geoPoint is a GeoJSON point; coordinates are [lng, lat]
+type geoPoint struct {
+    Type        string    `bson:"type"`
+    Coordinates []float64 `bson:"coordinates"`
+}
+
+var errBadOrderDoc = errors.New("malformed order document")
+
+func newOrderDoc(o *models.Order) *orderDoc {
+    a := o.PickAddressSnapshot
+    return &orderDoc{
+        ID:                  o.ID,
+        CustomerID:          o.CustomerID,
+        Status:              o.Status,
+        PickAddressSnapshot: a,
+        CustomerSnapshot:    o.CustomerSnapshot,
+        Items:               o.Items,
+        TotalWeight:         o.TotalWeight,
+        EstimatedPrice:      o.EstimatedPrice,
+        DistanceKm:          o.DistanceKm,
+        EtaMinutes:          o.EtaMinutes,
+        Note:                o.Note,
+        CreatedAt:           o.CreatedAt,
+        UpdatedAt:           o.UpdatedAt,
+        Version:             o.Version,
+        AcceptedBy:          o.AcceptedBy,
+        AcceptedAt:          o.AcceptedAt,
+        OnWayAt:             o.OnWayAt,
+        CompletedAt:         o.CompletedAt,
+        History:             o.History,
+        CancelReason:        o.CancelReason,
+        CancelSide:          o.CancelSide,
+        CancelFee:           o.CancelFee,
+        Attachments:         o.Attachments,
+        Loc:                 &geoPoint{Type: "Point", Coordinates: []float64{a.Lng, a.Lat}},
+    }
+}
+
+// order converts d back, rejecting documents no build of this service would have written
+func (d *orderDoc) order() (*models.Order, error) {
+    if d.ID == "" {
+        return nil, fmt.Errorf("%w: no id", errBadOrderDoc)
+    }
+    switch d.Status {
+    case models.StatusCreated, models.StatusAccepted, models.StatusOnWay, models.StatusComplete, models.StatusCancelled:
+    default:
+        return nil, fmt.Errorf("%w: order %s has status %q", errBadOrderDoc, d.ID, d.Status)
+    }
+    return &models.Order{
+        ID:                  d.ID,
+        CustomerID:          d.CustomerID,
+        Status:              d.Status,
+        AcceptedBy:          d.AcceptedBy,
+        PickAddressSnapshot: d.PickAddressSnapshot,
+        CustomerSnapshot:    d.CustomerSnapshot,
+        Items:               d.Items,
+        TotalWeight:         d.TotalWeight,
+        EstimatedPrice:      d.EstimatedPrice,
+        DistanceKm:          d.DistanceKm,
+        EtaMinutes:          d.EtaMinutes,
+        Note:                d.Note,
+        CreatedAt:           d.CreatedAt,
+        UpdatedAt:           d.UpdatedAt,
+        AcceptedAt:          d.AcceptedAt,
+        OnWayAt:             d.OnWayAt,
+        CompletedAt:         d.CompletedAt,
+        CancelReason:        d.CancelReason,
+        CancelSide:          d.CancelSide,
+        CancelFee:           d.CancelFee,
+        Version:             d.Version,
+        History:             d.History,
+        Attachments:         d.Attachments,
+    }, nil
+}
+
+// missing lists the optional fields d leaves out; an update must $unset them, otherwise
+// $set would keep stale values (e.g. accepted_by after a release)
+func (d *orderDoc) missing() bson.M {
+    unset := bson.M{}
+    for field, empty := range map[string]bool{
+        "accepted_by":   d.AcceptedBy == nil,
+        "accepted_at":   d.AcceptedAt == nil,
+        "on_way_at":     d.OnWayAt == nil,
+        "completed_at":  d.CompletedAt == nil,
+        "history":       len(d.History) == 0,
+        "cancel_reason": d.CancelReason == "",
+        "cancel_side":   d.CancelSide == "",
+        "cancel_fee":    d.CancelFee == 0,
+        "attachments":   len(d.Attachments) == 0,
+        // only the pool expires; an expired or cancelled order is kept like any other
+        "expire_at":     d.Status != models.StatusCreated,
+    } {
+        if empty {
+            unset[field] = ""
+        }
+    }
+    return unset
+}
+
+// decodeOrder decodes one document of a find or update result
+func decodeOrder(decode func(any) error) (*models.Order, error) {
+    var d orderDoc
+    if err := decode(&d); err != nil {
+        return nil, err
+    }
+    return d.order()
+}
@@ -0,0 +1,163 @@
+package repository
+
+import (
+    "errors"
+    "reflect"
+    "testing"
+    "time"
+
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/bson/primitive"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// at is a time as MongoDB stores it: UTC, millisecond precision
+func at(s string) time.Time {
+    t, err := time.Parse(time.RFC3339Nano, s)
+    if err != nil {
+        panic(err)
+    }
+    return t.UTC()
+}
+
+func ptr[T any](v T) *T { return &v }
+
+// fullOrder sets every field of models.Order
+func fullOrder() *models.Order {
+    photo := models.AttachmentRef{ID: "a1", Kind: "item_photo", ContentType: "image/jpeg", Size: 2048, UploadedBy: "u1", UploadedAt: at("2025-03-01T08:00:01.250Z")}
+    return &models.Order{
+        ID:                  "o1",
+        CustomerID:          "u1",
+        Status:              models.StatusCancelled,
+        AcceptedBy:          ptr("c1"),
+        PickAddressSnapshot: models.Address{FullText: "12 Lê Lợi, Bến Nghé, Quận 1, TP.HCM", Lat: 10.7769, Lng: 106.7009},
+        CustomerSnapshot:    models.CustomerSnapshot{DisplayName: "Chị Hai", Phone: "0909123456"},
+        Items:               []models.WasteItem{{Type: "paper", Weight: 2.5, Attachments: []models.AttachmentRef{photo}}, {Type: "metal", Weight: 1}},
+        TotalWeight:         3.5,
+        EstimatedPrice:      21000,
+        DistanceKm:          3.2,
+        EtaMinutes:          12,
+        Note:                "gọi trước khi tới",
+        CreatedAt:           at("2025-03-01T08:00:00.125Z"),
+        UpdatedAt:           at("2025-03-01T08:40:00Z"),
+        AcceptedAt:          ptr(at("2025-03-01T08:05:00Z")),
+        OnWayAt:             ptr(at("2025-03-01T08:10:00Z")),
+        CompletedAt:         ptr(at("2025-03-01T08:30:00Z")),
+        CancelReason:        "customer not home",
+        CancelSide:          models.CancelByCollector,
+        CancelFee:           5,
+        Version:             6,
+        History: []models.StatusChange{
+            {From: models.StatusCreated, To: models.StatusAccepted, At: at("2025-03-01T08:05:00Z"), Actor: "c1"},
+            {From: models.StatusOnWay, To: models.StatusCancelled, At: at("2025-03-01T08:40:00Z"), Actor: "c1", Reason: "customer not home"},
+        },
+        Attachments: []models.AttachmentRef{{ID: "a2", Kind: "completion_proof", ContentType: "image/png", Size: 4096, UploadedBy: "c1", UploadedAt: at("2025-03-01T08:29:00Z")}},
+    }
+}
+
+// store encodes o as the repository writes it and returns the raw document
+func store(t *testing.T, d *orderDoc) bson.Raw {
+    t.Helper()
+    raw, err := bson.Marshal(d)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return raw
+}
+
+func load(raw bson.Raw) (*models.Order, error) {
+    return decodeOrder(func(v any) error { return bson.Unmarshal(raw, v) })
+}
+
+func TestOrderDocRoundTrip(t *testing.T) {
+    want := fullOrder()
+    // a field added to models.Order must be mapped by orderDoc and set above
+    v := reflect.ValueOf(*want)
+    for i := 0; i < v.NumField(); i++ {
+        if v.Field(i).IsZero() {
+            t.Fatalf("fullOrder leaves %s unset", v.Type().Field(i).Name)
+        }
+    }
+    got, err := load(store(t, newOrderDoc(want)))
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !reflect.DeepEqual(got, want) {
+        t.Fatalf("round trip changed the order:\n got %+v\nwant %+v", got, want)
+    }
+    if got.AcceptedAt == want.AcceptedAt {
+        t.Error("decoded pointers must not alias the original")
+    }
+}
+
+func TestOrderDocOptionalFields(t *testing.T) {
+    o := &models.Order{ID: "o2", CustomerID: "u1", Status: models.StatusCreated, CreatedAt: at("2025-03-01T08:00:00Z"), UpdatedAt: at("2025-03-01T08:00:00Z"), Version: 1}
+    d := newOrderDoc(o)
+    raw := store(t, d)
+    for _, f := range []string{"accepted_by", "accepted_at", "on_way_at", "completed_at", "history", "cancel_reason", "cancel_side", "cancel_fee", "attachments", "expire_at"} {
+        if _, err := raw.LookupErr(f); err == nil {
+            t.Errorf("%s must be left out when empty", f)
+        }
+        if _, ok := d.missing()[f]; !ok && f != "expire_at" {
+            t.Errorf("%s must be unset by updates", f)
+        }
+    }
+    if _, ok := d.missing()["expire_at"]; ok {
+        t.Error("updates must keep the TTL of a created order")
+    }
+    // a full order has left the pool, so only its TTL goes
+    if m := newOrderDoc(fullOrder()).missing(); len(m) != 1 {
+        t.Errorf("a full order unsets %v", m)
+    }
+    got, err := load(raw)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got.AcceptedAt != nil || got.OnWayAt != nil || got.CompletedAt != nil || got.AcceptedBy != nil {
+        t.Errorf("absent timestamps must decode as nil: %+v", got)
+    }
+}
+
+func TestOrderDocLoc(t *testing.T) {
+    o := fullOrder()
+    raw := store(t, newOrderDoc(o))
+    loc, err := raw.LookupErr("loc")
+    if err != nil {
+        t.Fatal(err)
+    }
+    var p geoPoint
+    if err := loc.Unmarshal(&p); err != nil {
+        t.Fatal(err)
+    }
+    if p.Type != "Point" || len(p.Coordinates) != 2 || p.Coordinates[0] != o.PickAddressSnapshot.Lng || p.Coordinates[1] != o.PickAddressSnapshot.Lat {
+        t.Errorf("loc = %+v, want [lng, lat]", p)
+    }
+
+    // documents written before loc, with the fields Mongo adds, still load
+    legacy, _ := bson.Marshal(bson.M{
+        "_id": primitive.NewObjectID(), "id": "o3", "customer_id": "u1", "status": "created",
+        "pick_address_snapshot": bson.M{"full_text": "A", "lat": 1.5, "lng": 2.5},
+        "created_at": at("2024-01-01T00:00:00Z"), "expire_at": at("2024-01-01T01:00:00Z"), "version": int64(1),
+    })
+    got, err := load(legacy)
+    if err != nil || got.ID != "o3" || got.PickAddressSnapshot.Lng != 2.5 {
+        t.Fatalf("legacy document: %+v, %v", got, err)
+    }
+}
+
+func TestOrderDocErrors(t *testing.T) {
+    for name, doc := range map[string]bson.M{
+        "no id":          {"status": "created"},
+        "unknown status": {"id": "o4", "status": "archived"},
+    } {
+        raw, _ := bson.Marshal(doc)
+        if _, err := load(raw); !errors.Is(err, errBadOrderDoc) {
+            t.Errorf("%s: err = %v", name, err)
+        }
+    }
+    raw, _ := bson.Marshal(bson.M{"id": "o5", "status": "created", "total_weight": "heavy"})
+    if o, err := load(raw); err == nil {
+        t.Errorf("a field of the wrong type must fail decoding, got %+v", o)
+    }
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// initRatingIndexes: one rating per order and side; aggregates are unique per user and role
+// and sortable by average for dispatch and admin search (SearchUserRatings' keyset order)
+func (r *MongoRepo) initRatingIndexes(ctx context.Context) error {
+    _, err := r.ratingsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "order_id", Value: 1}, {Key: "side", Value: 1}}, Options: options.Index().SetUnique(true)},
+        {Keys: bson.D{{Key: "ratee_id", Value: 1}, {Key: "created_at", Value: -1}}},
+    })
+    if err != nil {
+        return err
+    }
+    _, err = r.userRatingsCol.Indexes().CreateMany(ctx, []mongo.IndexModel{
+        {Keys: bson.D{{Key: "user_id", Value: 1}, {Key: "role", Value: 1}}, Options: options.Index().SetUnique(true)},
+        {Keys: bson.D{{Key: "role", Value: 1}, {Key: "average", Value: 1}, {Key: "user_id", Value: 1}}},
+    })
+    return err
+}
+
+func (r *MongoRepo) CreateRating(rt *models.Rating) error {
+    _, err := r.ratingsCol.InsertOne(context.Background(), rt)
+    if mongo.IsDuplicateKeyError(err) {
+        return svc.ErrAlreadyRated
+    }
+    return err
+}
+
+// ApplyRating recomputes the aggregate from the ratee's ratings instead of incrementing it, so
+// it can be retried. The write only lands over an aggregate counting no more ratings; when a
+// concurrent apply already stored a newer one, that one is returned.
+func (r *MongoRepo) ApplyRating(rt *models.Rating) (*models.UserRating, error) {
+    ctx := context.Background()
+    role := rt.RateeRole()
+    cur, err := r.ratingsCol.Aggregate(ctx, mongo.Pipeline{
+        {{Key: "$match", Value: bson.M{"ratee_id": rt.RateeID, "side": rt.Side}}},
+        {{Key: "$facet", Value: bson.M{
+            "totals": bson.A{bson.M{"$group": bson.M{
+                "_id": nil, "count": bson.M{"$sum": 1}, "sum": bson.M{"$sum": "$stars"}, "last": bson.M{"$max": "$created_at"},
+            }}},
+            "tags": bson.A{bson.M{"$unwind": "$tags"}, bson.M{"$group": bson.M{"_id": "$tags", "n": bson.M{"$sum": 1}}}},
+        }}},
+    })
+    if err != nil {
+        return nil, err
+    }
+    var res []struct {
+        Totals []struct {
+            Count int64     `bson:"count"`
+            Sum   int64     `bson:"sum"`
+            Last  time.Time `bson:"last"`
+        } `bson:"totals"`
+        Tags []struct {
+            Tag string `bson:"_id"`
+            N   int64  `bson:"n"`
+        } `bson:"tags"`
+    }
+    if err := cur.All(ctx, &res); err != nil {
+        return nil, err
+    }
+    u := models.UserRating{UserID: rt.RateeID, Role: role}
+    if len(res) == 1 && len(res[0].Totals) == 1 {
+        t := res[0].Totals[0]
+        u.Count, u.Sum, u.Average, u.UpdatedAt = t.Count, t.Sum, float64(t.Sum)/float64(t.Count), t.Last
+        for _, tc := range res[0].Tags {
+            if u.TagCounts == nil {
+                u.TagCounts = map[string]int64{}
+            }
+            u.TagCounts[tc.Tag] = tc.N
+        }
+    }
+    filter := bson.M{"user_id": u.UserID, "role": role, "count": bson.M{"$lte": u.Count}}
+    _, err = r.userRatingsCol.UpdateOne(ctx, filter, bson.M{"$set": u}, options.Update().SetUpsert(true))
+    if mongo.IsDuplicateKeyError(err) {
+        return r.GetUserRating(u.UserID, role)
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+func (r *MongoRepo) GetUserRating(userID string, role models.Role) (*models.UserRating, error) {
+    var u models.UserRating
+    err := r.userRatingsCol.FindOne(context.Background(), bson.M{"user_id": userID, "role": role}).Decode(&u)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return &models.UserRating{UserID: userID, Role: role}, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    return &u, nil
+}
+
+// SearchUserRatings walks the (role, average, user_id) index
+func (r *MongoRepo) SearchUserRatings(role models.Role, f svc.RatingFilter, after *svc.RatingCursor, limit int) ([]*models.UserRating, error) {
+    ctx := context.Background()
+    filter := bson.M{"role": role}
+    if f.MaxAverage > 0 {
+        filter["average"] = bson.M{"$lte": f.MaxAverage}
+    }
+    if f.MinCount > 0 {
+        filter["count"] = bson.M{"$gte": f.MinCount}
+    }
+    if after != nil {
+        filter["$or"] = bson.A{
+            bson.M{"average": bson.M{"$gt": after.Average}},
+            bson.M{"average": after.Average, "user_id": bson.M{"$gt": after.UserID}},
+        }
+    }
+    opts := options.Find().SetSort(bson.D{{Key: "average", Value: 1}, {Key: "user_id", Value: 1}}).SetLimit(int64(limit))
+    cur, err := r.userRatingsCol.Find(ctx, filter, opts)
+    if err != nil {
+        return nil, err
+    }
+    var res []*models.UserRating
+    if err := cur.All(ctx, &res); err != nil {
+        return nil, err
+    }
+    return res, nil
+}
+
+var _ svc.RatingStore = (*MongoRepo)(nil)
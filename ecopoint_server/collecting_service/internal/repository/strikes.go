@@ -0,0 +1,61 @@
+package repository
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+    svc "ecopoint/collecting_service/internal/service"
+    "go.mongodb.org/mongo-driver/bson"
+    "go.mongodb.org/mongo-driver/mongo"
+    "go.mongodb.org/mongo-driver/mongo/options"
+)
+
+func (r *MongoRepo) initStrikeIndexes(ctx context.Context) error {
+    _, err := r.strikesCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys: bson.D{{Key: "collector_id", Value: 1}, {Key: "at", Value: -1}},
+    })
+    if err != nil {
+        return err
+    }
+    _, err = r.penaltiesCol.Indexes().CreateOne(ctx, mongo.IndexModel{
+        Keys:    bson.D{{Key: "collector_id", Value: 1}},
+        Options: options.Index().SetUnique(true),
+    })
+    return err
+}
+
+func (r *MongoRepo) AddStrike(st models.Strike) error {
+    _, err := r.strikesCol.InsertOne(context.Background(), st)
+    return err
+}
+
+func (r *MongoRepo) ListStrikes(collectorID string, since time.Time) ([]models.Strike, error) {
+    ctx := context.Background()
+    opts := options.Find().SetSort(bson.D{{Key: "at", Value: -1}})
+    cursor, err := r.strikesCol.Find(ctx, bson.M{"collector_id": collectorID, "at": bson.M{"$gte": since}}, opts)
+    if err != nil { return nil, err }
+    defer cursor.Close(ctx)
+    res := make([]models.Strike, 0)
+    if err := cursor.All(ctx, &res); err != nil { return nil, err }
+    return res, nil
+}
+
+func (r *MongoRepo) GetPenalty(collectorID string) (*models.CollectorPenalty, error) {
+    var p models.CollectorPenalty
+    err := r.penaltiesCol.FindOne(context.Background(), bson.M{"collector_id": collectorID}).Decode(&p)
+    if errors.Is(err, mongo.ErrNoDocuments) {
+        return &models.CollectorPenalty{CollectorID: collectorID}, nil
+    }
+    if err != nil { return nil, err }
+    return &p, nil
+}
+
+func (r *MongoRepo) SavePenalty(p *models.CollectorPenalty) error {
+    opts := options.Replace().SetUpsert(true)
+    _, err := r.penaltiesCol.ReplaceOne(context.Background(), bson.M{"collector_id": p.CollectorID}, p, opts)
+    return err
+}
+
+var _ svc.StrikeStore = (*MongoRepo)(nil)
@@ -0,0 +1,428 @@
+package service
+
+import (
+    "bufio"
+    "errors"
+    "io"
+    "mime"
+    "net/http"
+    "strings"
+    "sync"
+    "time"
+
+    "github.com/google/uuid"
+
+    "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/validation"
+)
+
+var (
+    ErrAttachmentsDisabled  = errors.New("attachments are not configured")
+    ErrUploadURLUnsupported = errors.New("blob store cannot issue upload URLs; use the streaming upload")
+    ErrAttachmentNotFound   = errors.New("attachment not found")
+    ErrAttachmentClosed     = errors.New("attachments of this kind cannot be added at the order's status")
+    ErrUploadIncomplete     = errors.New("the file has not been uploaded yet")
+    ErrBlobNotFound         = errors.New("blob not found")
+)
+
+// DefaultAttachmentURLTTL is how long signed upload and download URLs stay valid
+const DefaultAttachmentURLTTL = 15 * time.Minute
+
+// sniffLen is how much of a file http.DetectContentType looks at
+const sniffLen = 512
+
+// BlobStore keeps attachment bytes outside the database; keys are slash-separated paths
+type BlobStore interface {
+    // Put stores exactly size bytes read from r
+    Put(key string, r io.Reader, size int64, contentType string) error
+    Open(key string) (io.ReadCloser, error)
+    // Stat returns ErrBlobNotFound for a missing key
+    Stat(key string) (BlobInfo, error)
+    Delete(key string) error
+}
+
+type BlobInfo struct {
+    Size        int64
+    ContentType string // empty when the store does not record it
+}
+
+// BlobURLSigner is implemented by blob stores that clients can reach directly
+type BlobURLSigner interface {
+    // UploadURL returns a URL for an HTTP PUT and the headers the client must send with it
+    UploadURL(key, contentType string, ttl time.Duration) (string, map[string]string, error)
+    DownloadURL(key string, ttl time.Duration) (string, error)
+}
+
+// AttachmentStore persists attachment metadata, including uploads that were never completed
+type AttachmentStore interface {
+    SaveAttachment(a *models.Attachment) error
+    // GetAttachment returns ErrAttachmentNotFound for an unknown id
+    GetAttachment(id string) (*models.Attachment, error)
+}
+
+type InMemoryAttachmentStore struct {
+    mu    sync.Mutex
+    items map[string]models.Attachment
+}
+
+func NewInMemoryAttachmentStore() *InMemoryAttachmentStore {
+    return &InMemoryAttachmentStore{items: map[string]models.Attachment{}}
+}
+
+func (m *InMemoryAttachmentStore) SaveAttachment(a *models.Attachment) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.items[a.ID] = *a
+    return nil
+}
+
+func (m *InMemoryAttachmentStore) GetAttachment(id string) (*models.Attachment, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    a, ok := m.items[id]
+    if !ok {
+        return nil, ErrAttachmentNotFound
+    }
+    return &a, nil
+}
+
+// WithBlobStore enables attachments; without it every attachment call returns ErrAttachmentsDisabled
+func WithBlobStore(b BlobStore) Option {
+    return func(s *Service) { s.blobs = b }
+}
+
+func WithAttachmentStore(store AttachmentStore) Option {
+    return func(s *Service) { s.attachments = store }
+}
+
+// WithAttachmentURLTTL sets the lifetime of signed URLs
+func WithAttachmentURLTTL(ttl time.Duration) Option {
+    return func(s *Service) { s.attachmentURLTTL = ttl }
+}
+
+// AttachmentInput describes a file before its bytes are sent
+type AttachmentInput struct {
+    OrderID     string
+    UserID      string
+    Kind        models.AttachmentKind
+    ItemIndex   int // item photos only
+    ContentType string
+    Size        int64
+}
+
+// UploadTicket tells the client where to PUT the file before calling CompleteAttachmentUpload
+type UploadTicket struct {
+    Attachment *models.Attachment
+    URL        string
+    Headers    map[string]string
+    ExpiresAt  time.Time
+}
+
+// canAttach applies the ownership rules: the customer adds item photos while the order is
+// in the pool, the accepting collector adds completion proofs from acceptance on
+func canAttach(o *models.Order, kind models.AttachmentKind, userID string, itemIndex int) error {
+    switch kind {
+    case models.AttachmentItemPhoto:
+        if o.CustomerID != userID {
+            return ErrNotParticipant
+        }
+        if o.Status != models.StatusCreated {
+            return ErrAttachmentClosed
+        }
+        if itemIndex < 0 || itemIndex >= len(o.Items) {
+            return validation.Invalid("item_index", "must be between 0 and %d", len(o.Items)-1)
+        }
+    case models.AttachmentCompletionProof:
+        if valueOrEmpty(o.AcceptedBy) != userID {
+            return ErrNotParticipant
+        }
+        if !(o.Status == models.StatusAccepted || o.Status == models.StatusOnWay || o.Status == models.StatusComplete) {
+            return ErrAttachmentClosed
+        }
+    default:
+        return validation.Invalid("kind", "unknown attachment kind %q", kind)
+    }
+    return nil
+}
+
+func (s *Service) newAttachment(in AttachmentInput, now time.Time) (*models.Attachment, error) {
+    if s.blobs == nil {
+        return nil, ErrAttachmentsDisabled
+    }
+    o, err := s.repo.Get(in.OrderID)
+    if err != nil {
+        return nil, err
+    }
+    if err := canAttach(o, in.Kind, in.UserID, in.ItemIndex); err != nil {
+        return nil, err
+    }
+    id := uuid.NewString()
+    a := &models.Attachment{
+        ID:          id,
+        OrderID:     o.ID,
+        Kind:        in.Kind,
+        Key:         "orders/" + o.ID + "/" + id,
+        ContentType: normalizeContentType(in.ContentType),
+        Size:        in.Size,
+        UploadedBy:  in.UserID,
+        CreatedAt:   now,
+    }
+    if in.Kind == models.AttachmentItemPhoto {
+        a.ItemIndex = in.ItemIndex
+    }
+    if err := s.validator.Attachment(a, o.AttachmentCount()); err != nil {
+        return nil, err
+    }
+    return a, nil
+}
+
+// CreateAttachmentUpload reserves an attachment and signs a direct upload URL for it.
+// Stores that cannot sign URLs return ErrUploadURLUnsupported.
+func (s *Service) CreateAttachmentUpload(in AttachmentInput) (*UploadTicket, error) {
+    signer, ok := s.blobs.(BlobURLSigner)
+    if s.blobs != nil && !ok {
+        return nil, ErrUploadURLUnsupported
+    }
+    now := time.Now()
+    a, err := s.newAttachment(in, now)
+    if err != nil {
+        return nil, err
+    }
+    expires := now.Add(s.attachmentURLTTL)
+    a.ExpiresAt = &expires
+    url, headers, err := signer.UploadURL(a.Key, a.ContentType, s.attachmentURLTTL)
+    if err != nil {
+        return nil, err
+    }
+    if err := s.attachments.SaveAttachment(a); err != nil {
+        return nil, err
+    }
+    return &UploadTicket{Attachment: a, URL: url, Headers: headers, ExpiresAt: expires}, nil
+}
+
+// CompleteAttachmentUpload checks the uploaded file against what was declared and links it to
+// the order. Completing twice returns the order unchanged.
+func (s *Service) CompleteAttachmentUpload(id, userID string) (*models.Order, error) {
+    if s.blobs == nil {
+        return nil, ErrAttachmentsDisabled
+    }
+    a, err := s.attachments.GetAttachment(id)
+    if err != nil {
+        return nil, err
+    }
+    if a.UploadedBy != userID {
+        return nil, ErrNotParticipant
+    }
+    if a.UploadedAt != nil {
+        return s.repo.Get(a.OrderID)
+    }
+    info, err := s.blobs.Stat(a.Key)
+    if errors.Is(err, ErrBlobNotFound) {
+        return nil, ErrUploadIncomplete
+    }
+    if err != nil {
+        return nil, err
+    }
+    if info.Size != a.Size {
+        s.dropBlob(a.Key)
+        return nil, validation.Invalid("size", "declared %d bytes but %d were uploaded", a.Size, info.Size)
+    }
+    rc, err := s.blobs.Open(a.Key)
+    if err != nil {
+        return nil, err
+    }
+    head := make([]byte, sniffLen)
+    n, err := io.ReadFull(rc, head)
+    rc.Close()
+    if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+        return nil, err
+    }
+    if err := checkContent(a, head[:n]); err != nil {
+        s.dropBlob(a.Key)
+        return nil, err
+    }
+    return s.finishAttachment(a, time.Now())
+}
+
+// UploadAttachment streams a file from r into the blob store and links it to the order
+func (s *Service) UploadAttachment(in AttachmentInput, r io.Reader) (*models.Order, error) {
+    a, err := s.newAttachment(in, time.Now())
+    if err != nil {
+        return nil, err
+    }
+    br := bufio.NewReaderSize(r, sniffLen)
+    head, err := br.Peek(sniffLen)
+    if err != nil && !errors.Is(err, io.EOF) {
+        return nil, err
+    }
+    if err := checkContent(a, head); err != nil {
+        return nil, err
+    }
+    body := &sizedReader{r: br, left: a.Size}
+    if err := s.blobs.Put(a.Key, body, a.Size, a.ContentType); err != nil {
+        s.dropBlob(a.Key)
+        if body.err != nil {
+            return nil, body.err
+        }
+        return nil, err
+    }
+    // the store may stop at size bytes; anything left over means the declared size was wrong
+    if _, err := body.Read(make([]byte, 1)); !errors.Is(err, io.EOF) {
+        s.dropBlob(a.Key)
+        return nil, err
+    }
+    return s.finishAttachment(a, time.Now())
+}
+
+// finishAttachment marks a as uploaded and adds its reference to the order, re-checking the
+// rules because the order may have moved on while the file was uploading
+func (s *Service) finishAttachment(a *models.Attachment, now time.Time) (*models.Order, error) {
+    a.UploadedAt = &now
+    a.ExpiresAt = nil
+    for attempt := 0; ; attempt++ {
+        o, err := s.repo.Get(a.OrderID)
+        if err != nil {
+            return nil, err
+        }
+        if err := canAttach(o, a.Kind, a.UploadedBy, a.ItemIndex); err != nil {
+            s.dropBlob(a.Key)
+            return nil, err
+        }
+        if err := s.validator.Attachment(a, o.AttachmentCount()); err != nil {
+            s.dropBlob(a.Key)
+            return nil, err
+        }
+        if err := s.attachments.SaveAttachment(a); err != nil {
+            return nil, err
+        }
+        next := o.Clone()
+        if a.Kind == models.AttachmentItemPhoto {
+            next.Items[a.ItemIndex].Attachments = append(next.Items[a.ItemIndex].Attachments, a.Ref())
+        } else {
+            next.Attachments = append(next.Attachments, a.Ref())
+        }
+        next.UpdatedAt = now
+        next.Version++
+        err = s.repo.UpdateVersioned(next, o.Version)
+        if errors.Is(err, models.ErrVersionConflict) && attempt < 2 {
+            s.log.Debug("attachment: order changed, retrying", "order_id", o.ID, "attempt", attempt+1)
+            continue
+        }
+        if err != nil {
+            return nil, err
+        }
+        s.log.Info("attachment stored", "attachment_id", a.ID, "order_id", a.OrderID, "kind", a.Kind, "size", a.Size, "uploaded_by", a.UploadedBy)
+        return next, nil
+    }
+}
+
+// dropBlob removes a rejected upload; a failure only leaves an orphan file behind
+func (s *Service) dropBlob(key string) {
+    if err := s.blobs.Delete(key); err != nil {
+        s.log.Warn("attachment: delete blob", "key", key, "error", err)
+    }
+}
+
+// viewableAttachment lets anyone see item photos, which collectors browse in the pool;
+// completion proofs are limited to the order's participants and admins
+func (s *Service) viewableAttachment(id, userID string, admin bool) (*models.Attachment, error) {
+    if s.blobs == nil {
+        return nil, ErrAttachmentsDisabled
+    }
+    a, err := s.attachments.GetAttachment(id)
+    if err != nil {
+        return nil, err
+    }
+    if a.UploadedAt == nil {
+        return nil, ErrAttachmentNotFound
+    }
+    if a.Kind == models.AttachmentCompletionProof && !admin {
+        o, err := s.repo.Get(a.OrderID)
+        if err != nil {
+            return nil, err
+        }
+        if userID == "" || (userID != o.CustomerID && userID != valueOrEmpty(o.AcceptedBy)) {
+            return nil, ErrNotParticipant
+        }
+    }
+    return a, nil
+}
+
+// AttachmentURL signs a short-lived download URL
+func (s *Service) AttachmentURL(id, userID string, admin bool) (string, time.Time, error) {
+    a, err := s.viewableAttachment(id, userID, admin)
+    if err != nil {
+        return "", time.Time{}, err
+    }
+    signer, ok := s.blobs.(BlobURLSigner)
+    if !ok {
+        return "", time.Time{}, ErrUploadURLUnsupported
+    }
+    url, err := signer.DownloadURL(a.Key, s.attachmentURLTTL)
+    if err != nil {
+        return "", time.Time{}, err
+    }
+    return url, time.Now().Add(s.attachmentURLTTL), nil
+}
+
+// OpenAttachment returns the file for streaming to the client; the caller closes it
+func (s *Service) OpenAttachment(id, userID string, admin bool) (io.ReadCloser, *models.Attachment, error) {
+    a, err := s.viewableAttachment(id, userID, admin)
+    if err != nil {
+        return nil, nil, err
+    }
+    rc, err := s.blobs.Open(a.Key)
+    if err != nil {
+        return nil, nil, err
+    }
+    return rc, a, nil
+}
+
+// checkContent compares the declared type with what the first bytes of the file look like
+func checkContent(a *models.Attachment, head []byte) error {
+    if sniffed := normalizeContentType(http.DetectContentType(head)); sniffed != a.ContentType {
+        return validation.Invalid("content_type", "declared %s but the file looks like %s", a.ContentType, sniffed)
+    }
+    return nil
+}
+
+func normalizeContentType(ct string) string {
+    if mt, _, err := mime.ParseMediaType(ct); err == nil {
+        return mt
+    }
+    return strings.ToLower(strings.TrimSpace(ct))
+}
+
+// sizedReader fails when the stream is shorter or longer than the declared size
+type sizedReader struct {
+    r    io.Reader
+    left int64
+    err  error
+}
+
+func (s *sizedReader) Read(p []byte) (int, error) {
+    if s.err != nil {
+        return 0, s.err
+    }
+    if s.left == 0 {
+        var b [1]byte
+        if n, _ := s.r.Read(b[:]); n > 0 {
+            s.err = validation.Invalid("size", "the file is larger than the declared size")
+            return 0, s.err
+        }
+        return 0, io.EOF
+    }
+    if int64(len(p)) > s.left {
+        p = p[:s.left]
+    }
+    n, err := s.r.Read(p)
+    s.left -= int64(n)
+    if errors.Is(err, io.EOF) && s.left > 0 {
+        s.err = validation.Invalid("size", "the file is smaller than the declared size")
+        return n, s.err
+    }
+    if errors.Is(err, io.EOF) {
+        err = nil
+    }
+    return n, err
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// CancelPolicy prices a customer cancellation after a collector accepted. Fees are in points.
+type CancelPolicy struct {
+    FreeWindow  time.Duration `yaml:"free_window"`  // free while accepted for at most this long
+    AcceptedFee float64       `yaml:"accepted_fee"`
+    OnWayFee    float64       `yaml:"on_way_fee"`   // the collector is already driving; never free
+}
+
+func DefaultCancelPolicy() CancelPolicy {
+    return CancelPolicy{FreeWindow: 5 * time.Minute, AcceptedFee: 10, OnWayFee: 20}
+}
+
+func WithCancelPolicy(p CancelPolicy) Option {
+    return func(s *Service) { s.cancel = p }
+}
+
+// Fee returns what the customer owes for cancelling o at now
+func (p CancelPolicy) Fee(o *models.Order, now time.Time) float64 {
+    switch o.Status {
+    case models.StatusOnWay:
+        return p.OnWayFee
+    case models.StatusAccepted:
+        if o.AcceptedAt != nil && now.Sub(*o.AcceptedAt) <= p.FreeWindow {
+            return 0
+        }
+        return p.AcceptedFee
+    }
+    return 0
+}
+
+// bookCancelFee debits the customer and compensates the collector. The entry ids derive from
+// the order id, so booking again after a failure never charges twice.
+func (s *Service) bookCancelFee(o *models.Order, collectorID string, now time.Time) error {
+    if o.CancelFee <= 0 {
+        return nil
+    }
+    if err := s.ledger.AddLedgerEntry(models.LedgerEntry{
+        ID: o.ID + ":" + string(models.LedgerCancelFee), UserID: o.CustomerID, OrderID: o.ID,
+        Kind: models.LedgerCancelFee, Points: -o.CancelFee, At: now,
+    }); err != nil {
+        return err
+    }
+    return s.ledger.AddLedgerEntry(models.LedgerEntry{
+        ID: o.ID + ":" + string(models.LedgerCancelCompensation), UserID: collectorID, OrderID: o.ID,
+        Kind: models.LedgerCancelCompensation, Points: o.CancelFee, At: now,
+    })
+}
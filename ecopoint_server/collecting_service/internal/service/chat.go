@@ -0,0 +1,276 @@
+package service
+
+import (
+    "errors"
+    "sync"
+    "time"
+
+    "github.com/google/uuid"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrChatNotOpen = errors.New("chat opens once a collector accepts the order")
+    ErrChatClosed  = errors.New("chat is read-only after the order is complete or cancelled")
+)
+
+// ChatStore persists order conversations
+type ChatStore interface {
+    AddChatMessage(m *models.ChatMessage) error
+    GetChatMessage(orderID, id string) (*models.ChatMessage, error)
+    // ListChatMessages returns up to limit messages sent after `after` (zero = from the start), oldest first
+    ListChatMessages(orderID string, after time.Time, limit int) ([]*models.ChatMessage, error)
+    // MarkChatRead sets ReadAt on unread messages of the other participant sent at or before upTo
+    MarkChatRead(orderID, readerID string, upTo, at time.Time) (int, error)
+}
+
+type InMemoryChatStore struct {
+    mu   sync.Mutex
+    msgs map[string][]*models.ChatMessage
+}
+
+func NewInMemoryChatStore() *InMemoryChatStore {
+    return &InMemoryChatStore{msgs: map[string][]*models.ChatMessage{}}
+}
+
+func (m *InMemoryChatStore) AddChatMessage(msg *models.ChatMessage) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    cp := *msg
+    m.msgs[msg.OrderID] = append(m.msgs[msg.OrderID], &cp)
+    return nil
+}
+
+func (m *InMemoryChatStore) GetChatMessage(orderID, id string) (*models.ChatMessage, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, msg := range m.msgs[orderID] {
+        if msg.ID == id {
+            cp := *msg
+            return &cp, nil
+        }
+    }
+    return nil, errors.New("message not found")
+}
+
+func (m *InMemoryChatStore) ListChatMessages(orderID string, after time.Time, limit int) ([]*models.ChatMessage, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    res := make([]*models.ChatMessage, 0)
+    for _, msg := range m.msgs[orderID] {
+        if msg.SentAt.After(after) {
+            cp := *msg
+            res = append(res, &cp)
+            if len(res) >= limit {
+                break
+            }
+        }
+    }
+    return res, nil
+}
+
+func (m *InMemoryChatStore) MarkChatRead(orderID, readerID string, upTo, at time.Time) (int, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    n := 0
+    for _, msg := range m.msgs[orderID] {
+        if msg.SenderID != readerID && msg.ReadAt == nil && !msg.SentAt.After(upTo) {
+            t := at
+            msg.ReadAt = &t
+            n++
+        }
+    }
+    return n, nil
+}
+
+func WithChatStore(store ChatStore) Option {
+    return func(s *Service) { s.chat = store }
+}
+
+// ChatEvent is pushed to live chat subscribers; exactly one field is set
+type ChatEvent struct {
+    Message *models.ChatMessage
+    Receipt *models.ChatReceipt
+    Closed  bool // the order finished; no more messages will arrive
+}
+
+// ChatSubscription receives the events of one order until Close or until the order finishes
+type ChatSubscription struct {
+    Events <-chan ChatEvent
+    ch     chan ChatEvent
+    hub    *chatHub
+    order  string
+    once   sync.Once
+}
+
+func (sub *ChatSubscription) Close() {
+    sub.hub.remove(sub)
+}
+
+// chatHub fans chat events out to the streams connected to this process only. A message sent
+// through another replica is stored but never pushed here, so chat needs a single instance (or
+// every participant of an order routed to the same one) until events go through change streams.
+type chatHub struct {
+    mu   sync.Mutex
+    subs map[string]map[*ChatSubscription]struct{}
+}
+
+func newChatHub() *chatHub {
+    return &chatHub{subs: map[string]map[*ChatSubscription]struct{}{}}
+}
+
+func (h *chatHub) subscribe(orderID string) *ChatSubscription {
+    ch := make(chan ChatEvent, 64)
+    sub := &ChatSubscription{Events: ch, ch: ch, hub: h, order: orderID}
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    if h.subs[orderID] == nil {
+        h.subs[orderID] = map[*ChatSubscription]struct{}{}
+    }
+    h.subs[orderID][sub] = struct{}{}
+    return sub
+}
+
+func (h *chatHub) remove(sub *ChatSubscription) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    h.removeLocked(sub)
+}
+
+func (h *chatHub) removeLocked(sub *ChatSubscription) {
+    delete(h.subs[sub.order], sub)
+    if len(h.subs[sub.order]) == 0 {
+        delete(h.subs, sub.order)
+    }
+    sub.once.Do(func() { close(sub.ch) })
+}
+
+// broadcast drops subscribers that fall behind; clients reload history when their stream ends
+func (h *chatHub) broadcast(orderID string, ev ChatEvent) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    for sub := range h.subs[orderID] {
+        select {
+        case sub.ch <- ev:
+        default:
+            h.removeLocked(sub)
+        }
+    }
+}
+
+func (h *chatHub) closeOrder(orderID string) {
+    h.mu.Lock()
+    defer h.mu.Unlock()
+    for sub := range h.subs[orderID] {
+        select {
+        case sub.ch <- ChatEvent{Closed: true}:
+        default:
+        }
+        h.removeLocked(sub)
+    }
+}
+
+// chatRole returns the role of userID in the order's conversation
+func chatRole(o *models.Order, userID string) (models.Role, error) {
+    switch {
+    case userID != "" && userID == o.CustomerID:
+        return models.RoleCustomer, nil
+    case o.AcceptedBy != nil && userID == *o.AcceptedBy:
+        return models.RoleCollector, nil
+    }
+    return "", ErrNotParticipant
+}
+
+func chatOpen(o *models.Order) error {
+    switch o.Status {
+    case models.StatusAccepted, models.StatusOnWay:
+        return nil
+    case models.StatusCreated:
+        return ErrChatNotOpen
+    }
+    return ErrChatClosed
+}
+
+// SendChatMessage posts text as userID; only participants of an active order may post
+func (s *Service) SendChatMessage(orderID, userID, text string) (*models.ChatMessage, error) {
+    o, err := s.repo.Get(orderID)
+    if err != nil {
+        return nil, err
+    }
+    role, err := chatRole(o, userID)
+    if err != nil {
+        return nil, err
+    }
+    if err := chatOpen(o); err != nil {
+        return nil, err
+    }
+    msg := &models.ChatMessage{
+        ID:         uuid.NewString(),
+        OrderID:    orderID,
+        SenderID:   userID,
+        SenderRole: role,
+        Text:       text,
+        SentAt:     time.Now().Truncate(time.Millisecond), // Mongo keeps milliseconds
+    }
+    if err := s.validator.ChatMessage(msg); err != nil {
+        return nil, err
+    }
+    if err := s.chat.AddChatMessage(msg); err != nil {
+        return nil, err
+    }
+    s.chatHub.broadcast(orderID, ChatEvent{Message: msg})
+    return msg, nil
+}
+
+// MarkChatRead records that userID read everything up to and including messageID
+func (s *Service) MarkChatRead(orderID, userID, messageID string) (*models.ChatReceipt, error) {
+    o, err := s.repo.Get(orderID)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := chatRole(o, userID); err != nil {
+        return nil, err
+    }
+    last, err := s.chat.GetChatMessage(orderID, messageID)
+    if err != nil {
+        return nil, err
+    }
+    now := time.Now()
+    n, err := s.chat.MarkChatRead(orderID, userID, last.SentAt, now)
+    if err != nil {
+        return nil, err
+    }
+    r := &models.ChatReceipt{OrderID: orderID, ReaderID: userID, UpTo: last.SentAt, At: now}
+    if n > 0 {
+        s.chatHub.broadcast(orderID, ChatEvent{Receipt: r})
+    }
+    return r, nil
+}
+
+// ListChatMessages pages through the conversation oldest first; history stays readable after the order ends
+func (s *Service) ListChatMessages(orderID, userID string, after time.Time, size int) ([]*models.ChatMessage, error) {
+    o, err := s.repo.Get(orderID)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := chatRole(o, userID); err != nil {
+        return nil, err
+    }
+    return s.chat.ListChatMessages(orderID, after, s.pages.clamp(size))
+}
+
+// SubscribeChat streams new messages and receipts of an active order to a participant
+func (s *Service) SubscribeChat(orderID, userID string) (*ChatSubscription, error) {
+    o, err := s.repo.Get(orderID)
+    if err != nil {
+        return nil, err
+    }
+    if _, err := chatRole(o, userID); err != nil {
+        return nil, err
+    }
+    if err := chatOpen(o); err != nil {
+        return nil, err
+    }
+    return s.chatHub.subscribe(orderID), nil
+}
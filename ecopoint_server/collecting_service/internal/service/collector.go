@@ -0,0 +1,168 @@
+package service
+
+import (
+    "errors"
+    "sort"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrInvalidRange   = errors.New("invalid time range")
+    ErrInvalidGroupBy = errors.New("group_by must be day, week or month")
+)
+
+const (
+    DefaultEarningsRange = 30 * 24 * time.Hour
+    MaxEarningsRange     = 366 * 24 * time.Hour
+)
+
+// OrderFilter narrows a collector's history; zero values mean "any"
+type OrderFilter struct {
+    Statuses []models.OrderStatus
+    From     time.Time // created_at >= From
+    To       time.Time // created_at < To
+}
+
+func (f OrderFilter) Match(o *models.Order) bool {
+    if len(f.Statuses) > 0 {
+        ok := false
+        for _, st := range f.Statuses {
+            if o.Status == st {
+                ok = true
+                break
+            }
+        }
+        if !ok {
+            return false
+        }
+    }
+    if !f.From.IsZero() && o.CreatedAt.Before(f.From) {
+        return false
+    }
+    if !f.To.IsZero() && !o.CreatedAt.Before(f.To) {
+        return false
+    }
+    return true
+}
+
+type EarningsGroup string
+
+const (
+    GroupByDay   EarningsGroup = "day"
+    GroupByWeek  EarningsGroup = "week" // weeks start on Monday
+    GroupByMonth EarningsGroup = "month"
+)
+
+// EarningsQuery covers completed orders with From <= completed_at < To
+type EarningsQuery struct {
+    From     time.Time
+    To       time.Time
+    GroupBy  EarningsGroup
+    Location *time.Location // buckets start at local midnight in this zone
+}
+
+// EarningsBucket sums completed orders of one period. Amount is the order's estimated price.
+type EarningsBucket struct {
+    PeriodStart  time.Time
+    Orders       int
+    WeightByType map[string]float64
+    TotalWeight  float64
+    Amount       float64
+}
+
+type EarningsSummary struct {
+    Buckets []EarningsBucket // oldest period first
+    Total   EarningsBucket   // PeriodStart = query From
+}
+
+// ListCollectorOrders pages through every order a collector accepted, including finished ones
+func (s *Service) ListCollectorOrders(collectorID string, f OrderFilter, pageToken string, size int) ([]*models.Order, string, error) {
+    if !f.From.IsZero() && !f.To.IsZero() && !f.From.Before(f.To) {
+        return nil, "", ErrInvalidRange
+    }
+    return s.listPage(pageToken, size, func(after *Cursor, limit int) ([]*models.Order, error) {
+        return s.repo.ListByCollector(collectorID, f, after, limit)
+    })
+}
+
+// GetCollectorEarnings defaults to the last 30 days grouped by day in q.Location, which the
+// server sets to the configured time zone; UTC only when no location is given
+func (s *Service) GetCollectorEarnings(collectorID string, q EarningsQuery) (*EarningsSummary, error) {
+    if q.To.IsZero() {
+        q.To = time.Now()
+    }
+    if q.From.IsZero() {
+        q.From = q.To.Add(-DefaultEarningsRange)
+    }
+    if !q.From.Before(q.To) || q.To.Sub(q.From) > MaxEarningsRange {
+        return nil, ErrInvalidRange
+    }
+    switch q.GroupBy {
+    case "":
+        q.GroupBy = GroupByDay
+    case GroupByDay, GroupByWeek, GroupByMonth:
+    default:
+        return nil, ErrInvalidGroupBy
+    }
+    if q.Location == nil {
+        q.Location = time.UTC
+    }
+    buckets, err := s.repo.CollectorEarnings(collectorID, q)
+    if err != nil {
+        return nil, err
+    }
+    sum := &EarningsSummary{Buckets: buckets, Total: EarningsBucket{PeriodStart: q.From, WeightByType: map[string]float64{}}}
+    for _, b := range buckets {
+        sum.Total.Orders += b.Orders
+        sum.Total.TotalWeight += b.TotalWeight
+        sum.Total.Amount += b.Amount
+        for t, kg := range b.WeightByType {
+            sum.Total.WeightByType[t] += kg
+        }
+    }
+    return sum, nil
+}
+
+// PeriodStart truncates t to the start of its day, Monday-based week or month in loc
+func PeriodStart(t time.Time, g EarningsGroup, loc *time.Location) time.Time {
+    t = t.In(loc)
+    day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+    switch g {
+    case GroupByWeek:
+        return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+    case GroupByMonth:
+        return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, loc)
+    default:
+        return day
+    }
+}
+
+// groupEarnings is the in-memory counterpart of the Mongo aggregation
+func groupEarnings(orders []*models.Order, q EarningsQuery) []EarningsBucket {
+    byStart := map[int64]*EarningsBucket{}
+    for _, o := range orders {
+        if o.CompletedAt == nil {
+            continue
+        }
+        start := PeriodStart(*o.CompletedAt, q.GroupBy, q.Location)
+        b, ok := byStart[start.Unix()]
+        if !ok {
+            b = &EarningsBucket{PeriodStart: start, WeightByType: map[string]float64{}}
+            byStart[start.Unix()] = b
+        }
+        b.Orders++
+        b.TotalWeight += o.TotalWeight
+        b.Amount += o.EstimatedPrice
+        for _, it := range o.Items {
+            b.WeightByType[it.Type] += it.Weight
+        }
+    }
+    res := make([]EarningsBucket, 0, len(byStart))
+    for _, b := range byStart {
+        res = append(res, *b)
+    }
+    sort.Slice(res, func(i, j int) bool { return res[i].PeriodStart.Before(res[j].PeriodStart) })
+    return res
+}
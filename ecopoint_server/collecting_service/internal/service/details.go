@@ -0,0 +1,82 @@
+package service
+
+import (
+    "errors"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var ErrOrderNotEditable = errors.New("order can only be edited before it is accepted")
+
+// UpdateOrderDetailsInput replaces the given fields; nil pointers and empty Items keep the current value.
+// Version must match the order's current version.
+type UpdateOrderDetailsInput struct {
+    OrderID        string
+    CustomerID     string
+    Version        int64
+    Address        *models.Address
+    Items          []models.WasteItem
+    EstimatedPrice *float64 // nil: quote again when weight or address change, as CreateOrder does
+    Note           *string
+}
+
+// UpdateOrderDetails lets the owner fix an order still in the pool. The edited order is validated
+// like a new one; nobody else follows a pool order yet, so no event is published
+func (s *Service) UpdateOrderDetails(in UpdateOrderDetailsInput) (*models.Order, error) {
+    o, err := s.repo.Get(in.OrderID)
+    if err != nil {
+        return nil, err
+    }
+    if o.CustomerID != in.CustomerID {
+        return nil, ErrNotParticipant
+    }
+    if o.Status != models.StatusCreated {
+        return nil, ErrOrderNotEditable
+    }
+    if o.Version != in.Version {
+        return nil, models.ErrVersionConflict
+    }
+    next := o.Clone()
+    if in.Address != nil {
+        next.PickAddressSnapshot = *in.Address
+        // distance and ETA were quoted for the old address
+        next.DistanceKm = 0
+        next.EtaMinutes = 0
+    }
+    if len(in.Items) > 0 {
+        next.Items = keepItemPhotos(o.Items, in.Items)
+        next.TotalWeight = sumWeight(in.Items)
+    }
+    if in.Note != nil {
+        next.Note = *in.Note
+    }
+    switch {
+    case in.EstimatedPrice != nil:
+        next.EstimatedPrice = *in.EstimatedPrice
+    case next.TotalWeight != o.TotalWeight || in.Address != nil:
+        s.quote(next)
+    }
+    if err := s.validator.Order(next); err != nil {
+        return nil, err
+    }
+    next.UpdatedAt = time.Now()
+    next.Version++
+    if err := s.repo.UpdateVersioned(next, o.Version); err != nil {
+        return nil, err
+    }
+    s.log.Info("order details updated", "order_id", next.ID, "version", next.Version)
+    return next, nil
+}
+
+// keepItemPhotos carries photos over to the new item list where the item at the same
+// position still has the same type
+func keepItemPhotos(old, items []models.WasteItem) []models.WasteItem {
+    res := append([]models.WasteItem(nil), items...)
+    for i := range res {
+        if i < len(old) && old[i].Type == res[i].Type {
+            res[i].Attachments = append([]models.AttachmentRef(nil), old[i].Attachments...)
+        }
+    }
+    return res
+}
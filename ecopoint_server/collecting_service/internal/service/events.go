@@ -0,0 +1,76 @@
+package service
+
+import (
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// EventPublisher receives order events after they are persisted. Implementations must not block.
+type EventPublisher interface {
+    Publish(e models.OrderEvent)
+}
+
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(models.OrderEvent) {}
+
+func WithEventPublisher(p EventPublisher) Option {
+    return func(s *Service) { s.events = p }
+}
+
+// OrderObserver is told about the changes that publish no event: creation and expiry.
+// Implementations must not block.
+type OrderObserver interface {
+    OrderCreated(o *models.Order)
+    OrderExpired(o *models.Order)
+}
+
+type noopObserver struct{}
+
+func (noopObserver) OrderCreated(*models.Order) {}
+func (noopObserver) OrderExpired(*models.Order) {}
+
+func WithOrderObserver(o OrderObserver) Option {
+    return func(s *Service) { s.observer = o }
+}
+
+type multiPublisher []EventPublisher
+
+func (m multiPublisher) Publish(e models.OrderEvent) {
+    for _, p := range m {
+        p.Publish(e)
+    }
+}
+
+// MultiPublisher hands every event to each publisher in order
+func MultiPublisher(ps ...EventPublisher) EventPublisher {
+    return multiPublisher(ps)
+}
+
+// statusEvents are published when a collector moves an order to the key status
+var statusEvents = map[models.OrderStatus]models.EventType{
+    models.StatusOnWay:    models.EventOrderOnWay,
+    models.StatusComplete: models.EventOrderCompleted,
+}
+
+// publish notifies the customer and, when assigned, the collector
+func (s *Service) publish(t models.EventType, o *models.Order, collectorID, actor, reason string) {
+    // finished or released orders end their live chat streams
+    if chatOpen(o) != nil {
+        s.chatHub.closeOrder(o.ID)
+    }
+    s.log.Info("order event", "event", t, "order", o, "actor", actor, "reason", reason)
+    recipients := []string{o.CustomerID}
+    if collectorID != "" {
+        recipients = append(recipients, collectorID)
+    }
+    s.events.Publish(models.OrderEvent{
+        Type:       t,
+        Order:      *o.Clone(),
+        Actor:      actor,
+        Reason:     reason,
+        Recipients: recipients,
+        At:         time.Now(),
+    })
+}
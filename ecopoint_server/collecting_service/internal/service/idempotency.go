@@ -0,0 +1,141 @@
+package service
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "strings"
+    "sync"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrIdempotencyKeyExists  = errors.New("idempotency key already used")
+    ErrIdempotencyKeyReused  = errors.New("idempotency key was used for a different request")
+    ErrIdempotencyInProgress = errors.New("request with this idempotency key is still in progress")
+)
+
+const DefaultIdempotencyTTL = 24 * time.Hour
+
+// Operation names recorded with idempotency keys
+const (
+    OpCreateOrder  = "create"
+    OpAcceptOrder  = "accept"
+    OpUpdateStatus = "update_status"
+    OpCancelOrder  = "cancel"
+)
+
+// IdempotencyRecord remembers which order a client key produced.
+// Scope is the owner of the key: the calling user (customer for create, collector for
+// accept/update, either party for cancel).
+type IdempotencyRecord struct {
+    Scope       string
+    Key         string
+    Operation   string
+    OrderID     string
+    Fingerprint string // of the request fields besides the order; a replay must match it
+    CreatedAt time.Time
+    ExpiresAt time.Time
+}
+
+// IdempotencyStore persists client request keys; (Scope, Key) is unique among unexpired records
+type IdempotencyStore interface {
+    // ReserveIdempotencyKey stores rec unless a live record exists for (Scope, Key),
+    // in which case it returns that record together with ErrIdempotencyKeyExists
+    ReserveIdempotencyKey(rec IdempotencyRecord) (*IdempotencyRecord, error)
+    ReleaseIdempotencyKey(scope, key string) error
+}
+
+// InMemoryIdempotencyStore is the default store used by tests and NewService
+type InMemoryIdempotencyStore struct {
+    mu   sync.Mutex
+    recs map[[2]string]IdempotencyRecord
+}
+
+func NewInMemoryIdempotencyStore() *InMemoryIdempotencyStore {
+    return &InMemoryIdempotencyStore{recs: map[[2]string]IdempotencyRecord{}}
+}
+
+func (m *InMemoryIdempotencyStore) ReserveIdempotencyKey(rec IdempotencyRecord) (*IdempotencyRecord, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    k := [2]string{rec.Scope, rec.Key}
+    if old, ok := m.recs[k]; ok && old.ExpiresAt.After(time.Now()) {
+        return &old, ErrIdempotencyKeyExists
+    }
+    m.recs[k] = rec
+    return &rec, nil
+}
+
+func (m *InMemoryIdempotencyStore) ReleaseIdempotencyKey(scope, key string) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    delete(m.recs, [2]string{scope, key})
+    return nil
+}
+
+// WithIdempotency sets the key store and how long keys are remembered
+func WithIdempotency(store IdempotencyStore, ttl time.Duration) Option {
+    return func(s *Service) {
+        s.idem = store
+        if ttl > 0 {
+            s.idemTTL = ttl
+        }
+    }
+}
+
+// Idempotent runs fn at most once per (scope, key). A replay returns the current state of the
+// order the first call produced. An empty key disables the check.
+func (s *Service) Idempotent(scope, key, operation, orderID string, fn func() (*models.Order, error)) (*models.Order, error) {
+    return s.IdempotentRequest(scope, key, operation, orderID, "", fn)
+}
+
+// IdempotentRequest is Idempotent for requests with more fields than the order, summed up in
+// fingerprint (see RequestFingerprint): a key replayed with other fields is rejected with
+// ErrIdempotencyKeyReused instead of returning the first result.
+func (s *Service) IdempotentRequest(scope, key, operation, orderID, fingerprint string, fn func() (*models.Order, error)) (*models.Order, error) {
+    if key == "" {
+        return fn()
+    }
+    now := time.Now()
+    existing, err := s.idem.ReserveIdempotencyKey(IdempotencyRecord{
+        Scope:       scope,
+        Key:         key,
+        Operation:   operation,
+        OrderID:     orderID,
+        Fingerprint: fingerprint,
+        CreatedAt:   now,
+        ExpiresAt:   now.Add(s.idemTTL),
+    })
+    if errors.Is(err, ErrIdempotencyKeyExists) {
+        if existing.Operation != operation || (operation != OpCreateOrder && existing.OrderID != orderID) || existing.Fingerprint != fingerprint {
+            return nil, ErrIdempotencyKeyReused
+        }
+        o, err := s.repo.Get(existing.OrderID)
+        if err != nil {
+            return nil, ErrIdempotencyInProgress
+        }
+        s.log.Debug("idempotent replay", "scope", scope, "operation", operation, "order_id", o.ID)
+        return o, nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    o, err := fn()
+    if err != nil {
+        // let the client retry the same key after a failure
+        if rerr := s.idem.ReleaseIdempotencyKey(scope, key); rerr != nil {
+            s.log.Warn("release idempotency key", "scope", scope, "operation", operation, "error", rerr)
+        }
+        return nil, err
+    }
+    return o, nil
+}
+
+// RequestFingerprint hashes the fields of a request, in order
+func RequestFingerprint(fields ...string) string {
+    sum := sha256.Sum256([]byte(strings.Join(fields, "\x00")))
+    return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,51 @@
+package service
+
+import (
+    "sync"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// LedgerStore books points movements. AddLedgerEntry ignores an entry whose ID already exists.
+type LedgerStore interface {
+    AddLedgerEntry(e models.LedgerEntry) error
+    // ListLedgerEntries returns a user's entries, newest first
+    ListLedgerEntries(userID string) ([]models.LedgerEntry, error)
+}
+
+type InMemoryLedgerStore struct {
+    mu      sync.Mutex
+    entries []models.LedgerEntry
+}
+
+func NewInMemoryLedgerStore() *InMemoryLedgerStore {
+    return &InMemoryLedgerStore{}
+}
+
+func (m *InMemoryLedgerStore) AddLedgerEntry(e models.LedgerEntry) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    for _, old := range m.entries {
+        if old.ID == e.ID {
+            return nil
+        }
+    }
+    m.entries = append(m.entries, e)
+    return nil
+}
+
+func (m *InMemoryLedgerStore) ListLedgerEntries(userID string) ([]models.LedgerEntry, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    res := make([]models.LedgerEntry, 0)
+    for i := len(m.entries) - 1; i >= 0; i-- {
+        if m.entries[i].UserID == userID {
+            res = append(res, m.entries[i])
+        }
+    }
+    return res, nil
+}
+
+func WithLedgerStore(store LedgerStore) Option {
+    return func(s *Service) { s.ledger = store }
+}
@@ -0,0 +1,133 @@
+package service
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "sort"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+const (
+    DefaultPageSize = 20
+    MaxPageSize     = 100
+)
+
+// PageSizes bounds the page size of every list; a request for 0 gets Default
+type PageSizes struct {
+    Default int `yaml:"default"`
+    Max     int `yaml:"max"`
+}
+
+func DefaultPageSizes() PageSizes {
+    return PageSizes{Default: DefaultPageSize, Max: MaxPageSize}
+}
+
+// WithPageSizes replaces DefaultPageSizes
+func WithPageSizes(p PageSizes) Option {
+    return func(s *Service) { s.pages = p }
+}
+
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// Cursor is the position of the last order returned. Lists are ordered by
+// created_at desc, then id desc, so the next page holds orders strictly after it.
+type Cursor struct {
+    CreatedAt time.Time
+    ID        string
+}
+
+type pageToken struct {
+    CreatedAt int64  `json:"c"` // unix nanos
+    ID        string `json:"i"`
+}
+
+// EncodePageToken turns a cursor into the opaque token handed to clients
+func EncodePageToken(c Cursor) string {
+    b, _ := json.Marshal(pageToken{CreatedAt: c.CreatedAt.UnixNano(), ID: c.ID})
+    return base64.RawURLEncoding.EncodeToString(b)
+}
+
+// DecodePageToken returns nil for an empty token (first page)
+func DecodePageToken(token string) (*Cursor, error) {
+    if token == "" {
+        return nil, nil
+    }
+    b, err := base64.RawURLEncoding.DecodeString(token)
+    if err != nil {
+        return nil, ErrInvalidPageToken
+    }
+    var t pageToken
+    if err := json.Unmarshal(b, &t); err != nil || t.ID == "" {
+        return nil, ErrInvalidPageToken
+    }
+    return &Cursor{CreatedAt: time.Unix(0, t.CreatedAt), ID: t.ID}, nil
+}
+
+// Before reports whether o sorts before c, i.e. belongs to a later page
+func (c *Cursor) Before(o *models.Order) bool {
+    if c == nil {
+        return true
+    }
+    if o.CreatedAt.Equal(c.CreatedAt) {
+        return o.ID < c.ID
+    }
+    return o.CreatedAt.Before(c.CreatedAt)
+}
+
+// SortNewestFirst applies the list ordering (created_at desc, id desc)
+func SortNewestFirst(list []*models.Order) {
+    sort.Slice(list, func(i, j int) bool {
+        if list[i].CreatedAt.Equal(list[j].CreatedAt) {
+            return list[i].ID > list[j].ID
+        }
+        return list[i].CreatedAt.After(list[j].CreatedAt)
+    })
+}
+
+// paginate is the in-memory counterpart of a keyset query
+func paginate(all []*models.Order, after *Cursor, limit int) []*models.Order {
+    SortNewestFirst(all)
+    res := make([]*models.Order, 0, limit)
+    for _, o := range all {
+        if !after.Before(o) {
+            continue
+        }
+        res = append(res, o)
+        if len(res) >= limit {
+            break
+        }
+    }
+    return res
+}
+
+func (p PageSizes) clamp(size int) int {
+    if size <= 0 {
+        size = p.Default
+    }
+    if size > p.Max {
+        return p.Max
+    }
+    return size
+}
+
+// listPage decodes the token, asks fetch for one extra row and derives the next token from it
+func (s *Service) listPage(token string, size int, fetch func(after *Cursor, limit int) ([]*models.Order, error)) ([]*models.Order, string, error) {
+    after, err := DecodePageToken(token)
+    if err != nil {
+        return nil, "", err
+    }
+    size = s.pages.clamp(size)
+    list, err := fetch(after, size+1)
+    if err != nil {
+        return nil, "", err
+    }
+    if len(list) <= size {
+        return list, "", nil
+    }
+    list = list[:size]
+    last := list[size-1]
+    return list, EncodePageToken(Cursor{CreatedAt: last.CreatedAt, ID: last.ID}), nil
+}
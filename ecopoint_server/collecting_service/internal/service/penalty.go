@@ -0,0 +1,191 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var ErrCollectorSuspended = errors.New("collector is in cooldown")
+
+// CooldownError tells the collector why and until when AcceptOrder is refused
+type CooldownError struct {
+    CollectorID string
+    Until       time.Time
+    Reason      string
+}
+
+func (e *CooldownError) Error() string {
+    return fmt.Sprintf("collector is suspended until %s: %s", e.Until.UTC().Format(time.RFC3339), e.Reason)
+}
+
+func (e *CooldownError) Unwrap() error { return ErrCollectorSuspended }
+
+// PenaltyTier applies Cooldown once the weighted strikes in the window reach Points
+type PenaltyTier struct {
+    Points   int           `yaml:"points"`
+    Cooldown time.Duration `yaml:"cooldown"`
+}
+
+type PenaltyPolicy struct {
+    Window    time.Duration             `yaml:"window"`
+    Weights   map[models.StrikeKind]int `yaml:"weights"`
+    Tiers     []PenaltyTier             `yaml:"tiers"`
+    LateGrace time.Duration             `yaml:"late_grace"` // completion this long after on_way + ETA counts as late
+}
+
+func DefaultPenaltyPolicy() PenaltyPolicy {
+    return PenaltyPolicy{
+        Window: 7 * 24 * time.Hour,
+        Weights: map[models.StrikeKind]int{
+            models.StrikeCancelled: 1,
+            models.StrikeLate:      1,
+            models.StrikeNoShow:    2,
+            models.StrikeAbandoned: 2,
+        },
+        Tiers: []PenaltyTier{
+            {Points: 3, Cooldown: time.Hour},
+            {Points: 5, Cooldown: 24 * time.Hour},
+            {Points: 8, Cooldown: 7 * 24 * time.Hour},
+        },
+        LateGrace: 15 * time.Minute,
+    }
+}
+
+func WithPenaltyPolicy(p PenaltyPolicy) Option {
+    return func(s *Service) { s.penalties = p }
+}
+
+// PenaltyStatus is the admin view of a collector's standing
+type PenaltyStatus struct {
+    Penalty models.CollectorPenalty
+    Strikes []models.Strike // counted strikes in the window, newest first
+    Points  int
+}
+
+// addStrike records a strike and escalates the cooldown if a tier is reached
+func (s *Service) addStrike(collectorID, orderID string, kind models.StrikeKind, now time.Time) error {
+    if err := s.strikes.AddStrike(models.Strike{CollectorID: collectorID, OrderID: orderID, Kind: kind, At: now}); err != nil {
+        return err
+    }
+    s.log.Info("collector strike", "collector_id", collectorID, "order_id", orderID, "kind", kind)
+    st, err := s.penaltyStatus(collectorID, now)
+    if err != nil {
+        return err
+    }
+    tier, ok := s.tierFor(st.Points)
+    if !ok {
+        return nil
+    }
+    p := st.Penalty
+    until := now.Add(tier.Cooldown)
+    if !until.After(p.CooldownUntil) {
+        return nil
+    }
+    p.CooldownUntil = until
+    p.Points = st.Points
+    p.Reason = describeStrikes(st.Strikes, s.penalties.Window)
+    p.UpdatedAt = now
+    if err := s.strikes.SavePenalty(&p); err != nil {
+        return err
+    }
+    s.log.Warn("collector cooldown", "collector_id", collectorID, "points", p.Points, "until", until, "reason", p.Reason)
+    return nil
+}
+
+func (s *Service) tierFor(points int) (PenaltyTier, bool) {
+    var best PenaltyTier
+    found := false
+    for _, t := range s.penalties.Tiers {
+        if points >= t.Points && (!found || t.Points > best.Points) {
+            best, found = t, true
+        }
+    }
+    return best, found
+}
+
+func (s *Service) penaltyStatus(collectorID string, now time.Time) (*PenaltyStatus, error) {
+    p, err := s.strikes.GetPenalty(collectorID)
+    if err != nil {
+        return nil, err
+    }
+    since := now.Add(-s.penalties.Window)
+    if p.ClearedAt.After(since) {
+        since = p.ClearedAt.Add(time.Nanosecond)
+    }
+    strikes, err := s.strikes.ListStrikes(collectorID, since)
+    if err != nil {
+        return nil, err
+    }
+    st := &PenaltyStatus{Penalty: *p, Strikes: strikes}
+    for _, sk := range strikes {
+        st.Points += s.penalties.Weights[sk.Kind]
+    }
+    return st, nil
+}
+
+// checkCooldown returns a *CooldownError while the collector is suspended
+func (s *Service) checkCooldown(collectorID string, now time.Time) error {
+    p, err := s.strikes.GetPenalty(collectorID)
+    if err != nil {
+        return err
+    }
+    if p.Active(now) {
+        return &CooldownError{CollectorID: collectorID, Until: p.CooldownUntil, Reason: p.Reason}
+    }
+    return nil
+}
+
+// GetCollectorPenalty is the admin view: current cooldown plus the strikes still counting
+func (s *Service) GetCollectorPenalty(collectorID string) (*PenaltyStatus, error) {
+    return s.penaltyStatus(collectorID, time.Now())
+}
+
+// ClearCollectorPenalty lifts the cooldown and forgives every strike recorded so far
+func (s *Service) ClearCollectorPenalty(collectorID, adminID string) (*PenaltyStatus, error) {
+    now := time.Now()
+    p, err := s.strikes.GetPenalty(collectorID)
+    if err != nil {
+        return nil, err
+    }
+    p.CooldownUntil = time.Time{}
+    p.Reason = ""
+    p.Points = 0
+    p.ClearedAt = now
+    p.ClearedBy = adminID
+    p.UpdatedAt = now
+    if err := s.strikes.SavePenalty(p); err != nil {
+        return nil, err
+    }
+    s.log.Info("collector penalty cleared", "collector_id", collectorID, "admin_id", adminID)
+    return s.penaltyStatus(collectorID, now)
+}
+
+// describeStrikes renders e.g. "2 cancelled, 1 no_show in the last 7 days"
+func describeStrikes(strikes []models.Strike, window time.Duration) string {
+    counts := map[models.StrikeKind]int{}
+    for _, st := range strikes {
+        counts[st.Kind]++
+    }
+    kinds := make([]string, 0, len(counts))
+    for k := range counts {
+        kinds = append(kinds, string(k))
+    }
+    sort.Strings(kinds)
+    parts := make([]string, 0, len(kinds))
+    for _, k := range kinds {
+        parts = append(parts, fmt.Sprintf("%d %s", counts[models.StrikeKind(k)], k))
+    }
+    return fmt.Sprintf("%s in the last %s", strings.Join(parts, ", "), humanDuration(window))
+}
+
+func humanDuration(d time.Duration) string {
+    if d >= 24*time.Hour && d%(24*time.Hour) == 0 {
+        return fmt.Sprintf("%d days", int(d/(24*time.Hour)))
+    }
+    return d.String()
+}
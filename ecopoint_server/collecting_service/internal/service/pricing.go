@@ -0,0 +1,38 @@
+package service
+
+import "ecopoint/collecting_service/internal/models"
+
+// Pricing quotes base + per_kg*kg + per_km*km, with the ETA at the average speed
+type Pricing struct {
+    Base        float64 `yaml:"base"`
+    PerKg       float64 `yaml:"per_kg"`
+    PerKm       float64 `yaml:"per_km"`
+    AvgSpeedKmH float64 `yaml:"avg_speed_kmh"` // <= 0: no ETA
+}
+
+func DefaultPricing() Pricing {
+    return Pricing{Base: 10000, PerKg: 2000, PerKm: 3000, AvgSpeedKmH: 30}
+}
+
+// WithPricing replaces DefaultPricing, used for orders created or edited without an estimated price
+func WithPricing(p Pricing) Option {
+    return func(s *Service) { s.pricing = p }
+}
+
+// quote prices o from its weight and distance. Orders in the pool have no distance yet: it is
+// unknown until a collector accepts, so only weight is priced.
+func (s *Service) quote(o *models.Order) {
+    o.EstimatedPrice, o.EtaMinutes = s.pricing.Quote(o.TotalWeight, o.DistanceKm)
+}
+
+// Quote never returns a negative price
+func (p Pricing) Quote(weightKg, distanceKm float64) (price float64, etaMinutes int) {
+    price = p.Base + p.PerKg*weightKg + p.PerKm*distanceKm
+    if p.AvgSpeedKmH > 0 {
+        etaMinutes = int((distanceKm/p.AvgSpeedKmH)*60 + 0.5)
+    }
+    if price < 0 {
+        price = 0
+    }
+    return
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+    "errors"
+    "fmt"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var ErrQuotaExceeded = errors.New("quota exceeded")
+
+// QuotaPolicy caps what a single user may have open at once; 0 = no cap. A Service has
+// no caps until WithQuotaPolicy.
+type QuotaPolicy struct {
+    MaxOpenOrders int `yaml:"max_open_orders"` // orders of one customer still waiting in the pool
+}
+
+func DefaultQuotaPolicy() QuotaPolicy {
+    return QuotaPolicy{MaxOpenOrders: 5}
+}
+
+func WithQuotaPolicy(p QuotaPolicy) Option {
+    return func(s *Service) { s.quotas = p }
+}
+
+// QuotaError tells the user which business limit they hit
+type QuotaError struct {
+    Subject string // the user
+    Quota   string // e.g. open_orders
+    Limit   int
+}
+
+func (e *QuotaError) Error() string {
+    return fmt.Sprintf("%s limit of %d reached", e.Quota, e.Limit)
+}
+
+func (e *QuotaError) Unwrap() error { return ErrQuotaExceeded }
+
+// checkOpenOrders refuses a new order while the customer already has MaxOpenOrders in the pool.
+// Concurrent creations may pass the count together; the per-user rate limit keeps that small.
+func (s *Service) checkOpenOrders(customerID string) error {
+    max := s.quotas.MaxOpenOrders
+    if max <= 0 {
+        return nil
+    }
+    n, err := s.repo.CountByCustomer(customerID, models.StatusCreated)
+    if err != nil {
+        return err
+    }
+    if n >= max {
+        return &QuotaError{Subject: customerID, Quota: "open_orders", Limit: max}
+    }
+    return nil
+}
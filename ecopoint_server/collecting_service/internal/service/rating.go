@@ -0,0 +1,233 @@
+package service
+
+import (
+    "encoding/base64"
+    "encoding/json"
+    "errors"
+    "sort"
+    "strings"
+    "sync"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrAlreadyRated   = errors.New("order already rated by this side")
+    ErrNotRateable    = errors.New("only completed orders can be rated")
+    ErrNotParticipant = errors.New("rater is not a participant of this order")
+)
+
+// RatingStore keeps individual ratings (unique per order and side) and per-user aggregates
+type RatingStore interface {
+    // CreateRating returns ErrAlreadyRated when the side already rated the order
+    CreateRating(r *models.Rating) error
+    // ApplyRating recomputes the aggregate of r's ratee from the stored ratings and returns
+    // it; applying twice is harmless, so a failed apply can simply be retried
+    ApplyRating(r *models.Rating) (*models.UserRating, error)
+    GetUserRating(userID string, role models.Role) (*models.UserRating, error)
+    // SearchUserRatings returns at most limit aggregates of role matching f, lowest average
+    // first, then by user id, starting strictly after the cursor (nil = first page)
+    SearchUserRatings(role models.Role, f RatingFilter, after *RatingCursor, limit int) ([]*models.UserRating, error)
+}
+
+// RatingFilter narrows an admin search; zero fields match everything
+type RatingFilter struct {
+    MaxAverage float64
+    MinCount   int64
+}
+
+func (f RatingFilter) Match(u *models.UserRating) bool {
+    return (f.MaxAverage <= 0 || u.Average <= f.MaxAverage) && u.Count >= f.MinCount
+}
+
+// RatingCursor is the last aggregate of a search page
+type RatingCursor struct {
+    Average float64 `json:"a"`
+    UserID  string  `json:"u"`
+}
+
+// Before reports whether u sorts after c, i.e. belongs to a later page
+func (c *RatingCursor) Before(u *models.UserRating) bool {
+    if c == nil {
+        return true
+    }
+    if u.Average == c.Average {
+        return u.UserID > c.UserID
+    }
+    return u.Average > c.Average
+}
+
+type InMemoryRatingStore struct {
+    mu      sync.Mutex
+    ratings map[[2]string]*models.Rating
+    users   map[[2]string]*models.UserRating
+}
+
+func NewInMemoryRatingStore() *InMemoryRatingStore {
+    return &InMemoryRatingStore{ratings: map[[2]string]*models.Rating{}, users: map[[2]string]*models.UserRating{}}
+}
+
+func (m *InMemoryRatingStore) CreateRating(r *models.Rating) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    k := [2]string{r.OrderID, string(r.Side)}
+    if _, ok := m.ratings[k]; ok {
+        return ErrAlreadyRated
+    }
+    m.ratings[k] = r
+    return nil
+}
+
+func (m *InMemoryRatingStore) ApplyRating(r *models.Rating) (*models.UserRating, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    role := r.RateeRole()
+    u := &models.UserRating{UserID: r.RateeID, Role: role}
+    for _, stored := range m.ratings {
+        if stored.RateeID == r.RateeID && stored.Side == r.Side {
+            u.Add(stored)
+        }
+    }
+    m.users[[2]string{r.RateeID, string(role)}] = u
+    cp := *u
+    return &cp, nil
+}
+
+func (m *InMemoryRatingStore) GetUserRating(userID string, role models.Role) (*models.UserRating, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    if u, ok := m.users[[2]string{userID, string(role)}]; ok {
+        cp := *u
+        return &cp, nil
+    }
+    return &models.UserRating{UserID: userID, Role: role}, nil
+}
+
+func (m *InMemoryRatingStore) SearchUserRatings(role models.Role, f RatingFilter, after *RatingCursor, limit int) ([]*models.UserRating, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    var all []*models.UserRating
+    for _, u := range m.users {
+        if u.Role == role && f.Match(u) && after.Before(u) {
+            cp := *u
+            all = append(all, &cp)
+        }
+    }
+    sort.Slice(all, func(i, j int) bool {
+        if all[i].Average == all[j].Average {
+            return all[i].UserID < all[j].UserID
+        }
+        return all[i].Average < all[j].Average
+    })
+    return all[:min(len(all), limit)], nil
+}
+
+func WithRatingStore(store RatingStore) Option {
+    return func(s *Service) { s.ratings = store }
+}
+
+type RateOrderInput struct {
+    OrderID string
+    RaterID string
+    Side    models.Role // role of the rater
+    Stars   int
+    Tags    []string
+    Comment string
+}
+
+// RateOrder records one rating per side on a completed order and updates the ratee's aggregate
+func (s *Service) RateOrder(in RateOrderInput) (*models.Rating, error) {
+    o, err := s.repo.Get(in.OrderID)
+    if err != nil {
+        return nil, err
+    }
+    if o.Status != models.StatusComplete {
+        return nil, ErrNotRateable
+    }
+    r := &models.Rating{
+        OrderID:   o.ID,
+        Side:      in.Side,
+        RaterID:   in.RaterID,
+        Stars:     in.Stars,
+        Tags:      normalizeTags(in.Tags),
+        Comment:   strings.TrimSpace(in.Comment),
+        CreatedAt: time.Now(),
+    }
+    switch in.Side {
+    case models.RoleCustomer:
+        if in.RaterID != o.CustomerID || o.AcceptedBy == nil {
+            return nil, ErrNotParticipant
+        }
+        r.RateeID = *o.AcceptedBy
+    case models.RoleCollector:
+        if o.AcceptedBy == nil || in.RaterID != *o.AcceptedBy {
+            return nil, ErrNotParticipant
+        }
+        r.RateeID = o.CustomerID
+    default:
+        return nil, ErrNotParticipant
+    }
+    if err := s.validator.Rating(r); err != nil {
+        return nil, err
+    }
+    err = s.ratings.CreateRating(r)
+    if err != nil && !errors.Is(err, ErrAlreadyRated) {
+        return nil, err
+    }
+    // also on ErrAlreadyRated: a retry after a failed apply repairs the aggregate
+    if _, aerr := s.ratings.ApplyRating(r); aerr != nil {
+        return nil, aerr
+    }
+    if err != nil {
+        return nil, err
+    }
+    return r, nil
+}
+
+// GetUserRating returns a zero aggregate for users without ratings
+func (s *Service) GetUserRating(userID string, role models.Role) (*models.UserRating, error) {
+    return s.ratings.GetUserRating(userID, role)
+}
+
+// SearchUserRatings pages through the aggregates of role for admins, lowest average first.
+// An empty next token means the last page.
+func (s *Service) SearchUserRatings(role models.Role, f RatingFilter, pageToken string, size int) ([]*models.UserRating, string, error) {
+    var after *RatingCursor
+    if pageToken != "" {
+        b, err := base64.RawURLEncoding.DecodeString(pageToken)
+        if err != nil {
+            return nil, "", ErrInvalidPageToken
+        }
+        after = &RatingCursor{}
+        if err := json.Unmarshal(b, after); err != nil || after.UserID == "" {
+            return nil, "", ErrInvalidPageToken
+        }
+    }
+    size = s.pages.clamp(size)
+    list, err := s.ratings.SearchUserRatings(role, f, after, size+1)
+    if err != nil {
+        return nil, "", err
+    }
+    if len(list) <= size {
+        return list, "", nil
+    }
+    list = list[:size]
+    last := list[size-1]
+    b, _ := json.Marshal(RatingCursor{Average: last.Average, UserID: last.UserID})
+    return list, base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func normalizeTags(tags []string) []string {
+    seen := map[string]bool{}
+    res := make([]string, 0, len(tags))
+    for _, t := range tags {
+        t = strings.ToLower(strings.TrimSpace(t))
+        if t == "" || seen[t] {
+            continue
+        }
+        seen[t] = true
+        res = append(res, t)
+    }
+    return res
+}
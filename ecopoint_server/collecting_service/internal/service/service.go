@@ -3,25 +3,44 @@ package service
 import (
     "errors"
     "fmt"
+    "log/slog"
     "math"
     "sort"
     "time"
 
+    "go.opentelemetry.io/otel/trace"
+
     "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/validation"
 )
 
+// ErrOrderNotFound is returned by Repository.Get for an unknown order id
+var ErrOrderNotFound = errors.New("order not found")
+
 // Repository abstracts order storage (in-memory for now)
 type Repository interface {
     Create(order *models.Order) error
     Get(id string) (*models.Order, error)
-    ListAvailable(limit int) ([]*models.Order, error)
+    // List methods return at most limit orders sorted by created_at desc, id desc,
+    // starting strictly after the cursor (nil = first page)
+    ListAvailable(after *Cursor, limit int) ([]*models.Order, error)
     AtomicAccept(id string, collectorID string) (*models.Order, error)
     FindActiveOrderByCollector(collectorID string) (*models.Order, error)
     Update(order *models.Order) error
+    // UpdateVersioned stores order only if the stored version still equals expectedVersion,
+    // otherwise it returns models.ErrVersionConflict
+    UpdateVersioned(order *models.Order, expectedVersion int64) error
+    // ListStalled returns orders in status that entered it before cutoff; created orders
+    // count from creation, so the watchdog can expire them
+    ListStalled(status models.OrderStatus, cutoff time.Time, limit int) ([]*models.Order, error)
     ListAll() ([]*models.Order, error)
     // Optional optimized queries for convenience
-    ListByCustomer(customerID string, page, size int) ([]*models.Order, error)
-    ListActiveByCollector(collectorID string) ([]*models.Order, error)
+    ListByCustomer(customerID string, after *Cursor, limit int) ([]*models.Order, error)
+    CountByCustomer(customerID string, status models.OrderStatus) (int, error)
+    ListActiveByCollector(collectorID string, after *Cursor, limit int) ([]*models.Order, error)
+    // Collector history and earnings
+    ListByCollector(collectorID string, f OrderFilter, after *Cursor, limit int) ([]*models.Order, error)
+    CollectorEarnings(collectorID string, q EarningsQuery) ([]EarningsBucket, error)
 }
 
 // InMemoryRepo is a simple in-memory implementation for tests
@@ -44,22 +63,19 @@ func (r *InMemoryRepo) Create(order *models.Order) error {
 func (r *InMemoryRepo) Get(id string) (*models.Order, error) {
     o, ok := r.store[id]
     if !ok {
-        return nil, fmt.Errorf("not found")
+        return nil, ErrOrderNotFound
     }
     return o, nil
 }
 
-func (r *InMemoryRepo) ListAvailable(limit int) ([]*models.Order, error) {
-    res := make([]*models.Order, 0, limit)
+func (r *InMemoryRepo) ListAvailable(after *Cursor, limit int) ([]*models.Order, error) {
+    all := make([]*models.Order, 0)
     for _, o := range r.store {
         if o.Status == models.StatusCreated {
-            res = append(res, o)
-            if len(res) >= limit {
-                break
-            }
+            all = append(all, o)
         }
     }
-    return res, nil
+    return paginate(all, after, limit), nil
 }
 
 func (r *InMemoryRepo) AtomicAccept(id string, collectorID string) (*models.Order, error) {
@@ -71,6 +87,7 @@ func (r *InMemoryRepo) AtomicAccept(id string, collectorID string) (*models.Orde
         return nil, fmt.Errorf("already taken")
     }
     now := time.Now()
+    o.Record(models.StatusAccepted, now, collectorID, "")
     o.Status = models.StatusAccepted
     o.AcceptedBy = &collectorID
     o.AcceptedAt = &now
@@ -87,6 +104,57 @@ func (r *InMemoryRepo) Update(order *models.Order) error {
     return nil
 }
 
+func (r *InMemoryRepo) UpdateVersioned(order *models.Order, expectedVersion int64) error {
+    cur, ok := r.store[order.ID]
+    if !ok {
+        return fmt.Errorf("not found")
+    }
+    if cur.Version != expectedVersion {
+        return models.ErrVersionConflict
+    }
+    r.store[order.ID] = order
+    return nil
+}
+
+func (r *InMemoryRepo) ListStalled(status models.OrderStatus, cutoff time.Time, limit int) ([]*models.Order, error) {
+    res := make([]*models.Order, 0)
+    for _, o := range r.store {
+        if o.Status != status {
+            continue
+        }
+        since := o.UpdatedAt
+        if status == models.StatusCreated {
+            // like the Mongo TTL, expiry only applies to orders never accepted
+            if wasAccepted(o) {
+                continue
+            }
+            since = o.CreatedAt
+        }
+        if status == models.StatusAccepted && o.AcceptedAt != nil {
+            since = *o.AcceptedAt
+        }
+        if status == models.StatusOnWay && o.OnWayAt != nil {
+            since = *o.OnWayAt
+        }
+        if since.Before(cutoff) {
+            res = append(res, o.Clone())
+            if len(res) >= limit {
+                break
+            }
+        }
+    }
+    return res, nil
+}
+
+func wasAccepted(o *models.Order) bool {
+    for _, h := range o.History {
+        if h.To == models.StatusAccepted {
+            return true
+        }
+    }
+    return false
+}
+
 func (r *InMemoryRepo) FindActiveOrderByCollector(collectorID string) (*models.Order, error) {
     for _, o := range r.store {
         if o.AcceptedBy != nil && *o.AcceptedBy == collectorID && (o.Status == models.StatusAccepted || o.Status == models.StatusOnWay) {
@@ -104,10 +172,7 @@ func (r *InMemoryRepo) ListAll() ([]*models.Order, error) {
     return res, nil
 }
 
-func (r *InMemoryRepo) ListByCustomer(customerID string, page, size int) ([]*models.Order, error) {
-    if page < 1 { page = 1 }
-    if size <= 0 { size = 20 }
-    start := (page-1) * size
+func (r *InMemoryRepo) ListByCustomer(customerID string, after *Cursor, limit int) ([]*models.Order, error) {
     // naive: collect all then paginate
     all := make([]*models.Order, 0)
     for _, o := range r.store {
@@ -115,45 +180,139 @@ func (r *InMemoryRepo) ListByCustomer(customerID string, page, size int) ([]*mod
             all = append(all, o)
         }
     }
-    // sort by CreatedAt desc
-    sort.Slice(all, func(i, j int) bool { return all[i].CreatedAt.After(all[j].CreatedAt) })
-    end := start + size
-    if start >= len(all) { return []*models.Order{}, nil }
-    if end > len(all) { end = len(all) }
-    return all[start:end], nil
+    return paginate(all, after, limit), nil
 }
 
-func (r *InMemoryRepo) ListActiveByCollector(collectorID string) ([]*models.Order, error) {
-    res := make([]*models.Order, 0)
+func (r *InMemoryRepo) CountByCustomer(customerID string, status models.OrderStatus) (int, error) {
+    n := 0
+    for _, o := range r.store {
+        if o.CustomerID == customerID && o.Status == status {
+            n++
+        }
+    }
+    return n, nil
+}
+
+func (r *InMemoryRepo) ListActiveByCollector(collectorID string, after *Cursor, limit int) ([]*models.Order, error) {
+    all := make([]*models.Order, 0)
     for _, o := range r.store {
         if o.AcceptedBy != nil && *o.AcceptedBy == collectorID && (o.Status == models.StatusAccepted || o.Status == models.StatusOnWay) {
-            res = append(res, o)
+            all = append(all, o)
         }
     }
-    return res, nil
+    return paginate(all, after, limit), nil
+}
+
+func (r *InMemoryRepo) ListByCollector(collectorID string, f OrderFilter, after *Cursor, limit int) ([]*models.Order, error) {
+    all := make([]*models.Order, 0)
+    for _, o := range r.store {
+        if o.AcceptedBy != nil && *o.AcceptedBy == collectorID && f.Match(o) {
+            all = append(all, o)
+        }
+    }
+    return paginate(all, after, limit), nil
+}
+
+func (r *InMemoryRepo) CollectorEarnings(collectorID string, q EarningsQuery) ([]EarningsBucket, error) {
+    done := make([]*models.Order, 0)
+    for _, o := range r.store {
+        if o.AcceptedBy == nil || *o.AcceptedBy != collectorID || o.Status != models.StatusComplete || o.CompletedAt == nil {
+            continue
+        }
+        if o.CompletedAt.Before(q.From) || !o.CompletedAt.Before(q.To) {
+            continue
+        }
+        done = append(done, o)
+    }
+    return groupEarnings(done, q), nil
 }
 
 // Service contains business logic
 type Service struct {
-    repo Repository
+    repo      Repository
+    validator *validation.Validator
+    idem      IdempotencyStore
+    idemTTL   time.Duration
+    ratings   RatingStore
+    events    EventPublisher
+    strikes   StrikeStore
+    watchdog  WatchdogPolicy
+    orderTTL  time.Duration
+    penalties PenaltyPolicy
+    quotas    QuotaPolicy // zero: no caps
+    ledger    LedgerStore
+    cancel    CancelPolicy
+    snapshots SnapshotSource // nil: trust client snapshots
+    chat      ChatStore
+    chatHub   *chatHub
+    blobs       BlobStore // nil: attachments disabled
+    attachments AttachmentStore
+    attachmentURLTTL time.Duration
+    pricing     Pricing
+    pages       PageSizes
+    log         *slog.Logger
+    observer    OrderObserver
+    tracer      trace.Tracer // nil: Op starts no spans
+}
+
+// Option customises a Service at construction time
+type Option func(*Service)
+
+// WithValidator replaces the default order validator (validation.DefaultLimits)
+func WithValidator(v *validation.Validator) Option {
+    return func(s *Service) { s.validator = v }
+}
+
+// WithLogger replaces slog.Default
+func WithLogger(l *slog.Logger) Option {
+    return func(s *Service) { s.log = l }
 }
 
-func NewService(repo Repository) *Service {
-    return &Service{repo: repo}
+func NewService(repo Repository, opts ...Option) *Service {
+    s := &Service{
+        repo:      repo,
+        validator: validation.New(validation.DefaultLimits()),
+        idem:      NewInMemoryIdempotencyStore(),
+        idemTTL:   DefaultIdempotencyTTL,
+        ratings:   NewInMemoryRatingStore(),
+        events:    noopPublisher{},
+        strikes:   NewInMemoryStrikeStore(),
+        watchdog:  DefaultWatchdogPolicy(),
+        penalties: DefaultPenaltyPolicy(),
+        ledger:    NewInMemoryLedgerStore(),
+        cancel:    DefaultCancelPolicy(),
+        chat:      NewInMemoryChatStore(),
+        chatHub:   newChatHub(),
+        attachments: NewInMemoryAttachmentStore(),
+        attachmentURLTTL: DefaultAttachmentURLTTL,
+        pricing:     DefaultPricing(),
+        pages:       DefaultPageSizes(),
+        log:         slog.Default(),
+        observer:    noopObserver{},
+    }
+    for _, opt := range opts {
+        opt(s)
+    }
+    return s
 }
 
 type CreateOrderInput struct {
     ID               string
     CustomerID       string
     Address          models.Address
+    AddressID        uint64 // saved address in the account service; replaces Address when set
     CustomerSnapshot models.CustomerSnapshot
     Items            []models.WasteItem
     TotalWeight      float64
     EstimatedPrice   float64
     Note             string
+    IdempotencyKey   string // optional; a replay returns the order created by the first call
 }
 
 func (s *Service) CreateOrder(in CreateOrderInput) (*models.Order, error) {
+    if err := s.enrich(&in); err != nil {
+        return nil, err
+    }
     now := time.Now()
     order := &models.Order{
         ID:                  in.ID,
@@ -169,22 +328,74 @@ func (s *Service) CreateOrder(in CreateOrderInput) (*models.Order, error) {
         UpdatedAt:           now,
         Version:             1,
     }
-    if err := s.repo.Create(order); err != nil {
+    // clients may omit total_weight; derive it so only a disagreeing value is rejected
+    if order.TotalWeight == 0 {
+        order.TotalWeight = sumWeight(order.Items)
+    }
+    if order.EstimatedPrice == 0 {
+        s.quote(order)
+    }
+    if err := s.validator.Order(order); err != nil {
         return nil, err
     }
-    return order, nil
+    return s.Idempotent(in.CustomerID, in.IdempotencyKey, OpCreateOrder, order.ID, func() (*models.Order, error) {
+        // inside the replay guard so retrying a created order still returns it
+        if err := s.checkOpenOrders(in.CustomerID); err != nil {
+            return nil, err
+        }
+        if err := s.repo.Create(order); err != nil {
+            return nil, err
+        }
+        s.log.Info("order created", "order", order, "items", len(order.Items), "weight_kg", order.TotalWeight, "price", order.EstimatedPrice)
+        s.observer.OrderCreated(order)
+        return order, nil
+    })
+}
+
+// poolScanBatch is the page size used when walking the whole pool
+const poolScanBatch = 500
+
+// PoolByArea counts the open pool per address area (see models.Address.Area); orders whose
+// address has no recognisable area are counted under ""
+func (s *Service) PoolByArea() (map[string]int, error) {
+    counts := map[string]int{}
+    var after *Cursor
+    for {
+        list, err := s.repo.ListAvailable(after, poolScanBatch)
+        if err != nil {
+            return nil, err
+        }
+        for _, o := range list {
+            counts[o.PickAddressSnapshot.Area()]++
+        }
+        if len(list) < poolScanBatch {
+            return counts, nil
+        }
+        last := list[len(list)-1]
+        after = &Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+    }
 }
 
-func (s *Service) ListAvailable(limit int) ([]*models.Order, error) {
-    return s.repo.ListAvailable(limit)
+// ListAvailable pages through the open pool, newest first. An empty next token means the last page.
+func (s *Service) ListAvailable(pageToken string, size int) ([]*models.Order, string, error) {
+    return s.listPage(pageToken, size, s.repo.ListAvailable)
 }
 
 func (s *Service) AcceptOrder(orderID string, collectorID string) (*models.Order, error) {
+    // Rule: no new work during a penalty cooldown
+    if err := s.checkCooldown(collectorID, time.Now()); err != nil {
+        return nil, err
+    }
     // Rule: one active order per collector
     if _, err := s.repo.FindActiveOrderByCollector(collectorID); err == nil {
         return nil, errors.New("collector already has an active order")
     }
-    return s.repo.AtomicAccept(orderID, collectorID)
+    o, err := s.repo.AtomicAccept(orderID, collectorID)
+    if err != nil {
+        return nil, err
+    }
+    s.publish(models.EventOrderAccepted, o, collectorID, collectorID, "")
+    return o, nil
 }
 
 func (s *Service) UpdateStatus(orderID string, next models.OrderStatus, collectorID string) (*models.Order, error) {
@@ -199,7 +410,11 @@ func (s *Service) UpdateStatus(orderID string, next models.OrderStatus, collecto
         return nil, models.ErrInvalidStatusTransition
     }
     now := time.Now()
+    o.Record(next, now, collectorID, "")
     o.Status = next
+    if next == models.StatusOnWay {
+        o.OnWayAt = &now
+    }
     if next == models.StatusComplete {
         o.CompletedAt = &now
     }
@@ -208,6 +423,15 @@ func (s *Service) UpdateStatus(orderID string, next models.OrderStatus, collecto
     if err := s.repo.Update(o); err != nil {
         return nil, err
     }
+    // the completion is stored: failing the call now would only make the client retry it
+    if next == models.StatusComplete && s.isLate(o, now) {
+        if err := s.addStrike(collectorID, o.ID, models.StrikeLate, now); err != nil {
+            s.log.Error("record late strike", "order", o, "collector_id", collectorID, "error", err)
+        }
+    }
+    if t, ok := statusEvents[next]; ok {
+        s.publish(t, o, collectorID, collectorID, "")
+    }
     return o, nil
 }
 
@@ -216,33 +440,56 @@ func (s *Service) GetOrder(orderID string) (*models.Order, error) {
     return s.repo.Get(orderID)
 }
 
-func (s *Service) ListMyActiveOrders(collectorID string) ([]*models.Order, error) {
-    return s.repo.ListActiveByCollector(collectorID)
+func (s *Service) ListMyActiveOrders(collectorID string, pageToken string, size int) ([]*models.Order, string, error) {
+    return s.listPage(pageToken, size, func(after *Cursor, limit int) ([]*models.Order, error) {
+        return s.repo.ListActiveByCollector(collectorID, after, limit)
+    })
 }
 
-func (s *Service) ListMyOrders(customerID string, page, size int) ([]*models.Order, error) {
-    return s.repo.ListByCustomer(customerID, page, size)
+func (s *Service) ListMyOrders(customerID string, pageToken string, size int) ([]*models.Order, string, error) {
+    return s.listPage(pageToken, size, func(after *Cursor, limit int) ([]*models.Order, error) {
+        return s.repo.ListByCustomer(customerID, after, limit)
+    })
 }
 
-// 1) Cancel by customer: only when not yet accepted
-func (s *Service) CancelOrderByCustomer(orderID string, reason string) (*models.Order, error) {
+// 1) Cancel by customer: free while created; after acceptance the cancel policy may charge a fee.
+// The collector's active slot is freed at once and the collector is notified.
+func (s *Service) CancelOrderByCustomer(orderID, customerID, reason string) (*models.Order, error) {
     o, err := s.repo.Get(orderID)
     if err != nil {
         return nil, err
     }
-    if o.Status != models.StatusCreated {
-        return nil, errors.New("cannot cancel after accepted")
+    if o.CustomerID != customerID {
+        return nil, ErrNotParticipant
+    }
+    if !(o.Status == models.StatusCreated || o.Status == models.StatusAccepted || o.Status == models.StatusOnWay) {
+        return nil, errors.New("cannot cancel at this status")
     }
     now := time.Now()
-    o.Status = models.StatusCancelled
-    o.CancelSide = models.CancelByCustomer
-    o.CancelReason = reason
-    o.UpdatedAt = now
-    o.Version++
-    if err := s.repo.Update(o); err != nil {
+    next := o.Clone()
+    next.Record(models.StatusCancelled, now, o.CustomerID, reason)
+    next.Status = models.StatusCancelled
+    next.CancelSide = models.CancelByCustomer
+    next.CancelReason = reason
+    next.CancelFee = s.cancel.Fee(o, now)
+    next.UpdatedAt = now
+    next.Version++
+    if err := s.repo.UpdateVersioned(next, o.Version); err != nil {
         return nil, err
     }
-    return o, nil
+    collectorID := valueOrEmpty(o.AcceptedBy)
+    if collectorID == "" {
+        // nobody to notify (the actor is skipped), but observers still see the cancellation
+        s.publish(models.EventOrderCancelled, next, "", o.CustomerID, reason)
+        return next, nil
+    }
+    // the cancel is stored, so failing the call would only make a retry be refused and the
+    // collector never hear of it; the logged fee can be booked later without charging twice
+    if err := s.bookCancelFee(next, collectorID, now); err != nil {
+        s.log.Error("book cancel fee", "order", next, "collector_id", collectorID, "fee", next.CancelFee, "error", err)
+    }
+    s.publish(models.EventOrderCancelled, next, collectorID, o.CustomerID, reason)
+    return next, nil
 }
 
 // 2) Cancel by collector: allowed when accepted/on_way
@@ -258,6 +505,7 @@ func (s *Service) CancelOrderByCollector(orderID string, collectorID string, rea
         return nil, errors.New("cannot cancel at this status")
     }
     now := time.Now()
+    o.Record(models.StatusCancelled, now, collectorID, reason)
     o.Status = models.StatusCancelled
     o.CancelSide = models.CancelByCollector
     o.CancelReason = reason
@@ -266,21 +514,17 @@ func (s *Service) CancelOrderByCollector(orderID string, collectorID string, rea
     if err := s.repo.Update(o); err != nil {
         return nil, err
     }
+    // the cancel is stored: failing the call now would only make the client retry it
+    if err := s.addStrike(collectorID, o.ID, models.StrikeCancelled, now); err != nil {
+        s.log.Error("record cancel strike", "order", o, "collector_id", collectorID, "error", err)
+    }
+    s.publish(models.EventOrderCancelled, o, collectorID, collectorID, reason)
     return o, nil
 }
 
 // 3) Pricing + ETA (simple): base + weight_factor*kg + distance_factor*km; ETA = distance/avg_speed
 func (s *Service) ComputePriceAndETA(base, weightFactor, distanceFactor, avgSpeedKmH float64, weightKg, distanceKm float64) (price float64, etaMinutes int) {
-    price = base + weightFactor*weightKg + distanceFactor*distanceKm
-    if avgSpeedKmH <= 0 {
-        etaMinutes = 0
-    } else {
-        etaMinutes = int((distanceKm/avgSpeedKmH)*60 + 0.5)
-    }
-    if price < 0 {
-        price = 0
-    }
-    return
+    return Pricing{Base: base, PerKg: weightFactor, PerKm: distanceFactor, AvgSpeedKmH: avgSpeedKmH}.Quote(weightKg, distanceKm)
 }
 
 // 4) ListAvailableOrdersNear with Haversine filter (in-memory)
@@ -326,15 +570,11 @@ func (s *Service) AutoExpireCreatedOrders(ttlMinutes int) (expired int, err erro
     if err != nil {
         return 0, err
     }
-    cutoff := time.Now().Add(-time.Duration(ttlMinutes) * time.Minute)
+    now := time.Now()
+    cutoff := now.Add(-time.Duration(ttlMinutes) * time.Minute)
     for _, o := range all {
         if o.Status == models.StatusCreated && o.CreatedAt.Before(cutoff) {
-            o.Status = models.StatusCancelled
-            o.CancelSide = models.CancelBySystem
-            o.CancelReason = "expired"
-            o.UpdatedAt = time.Now()
-            o.Version++
-            if err := s.repo.Update(o); err != nil {
+            if err := s.expire(o, now); err != nil {
                 return expired, err
             }
             expired++
@@ -344,6 +584,23 @@ func (s *Service) AutoExpireCreatedOrders(ttlMinutes int) (expired int, err erro
 }
 
 // Helpers
+// isLate: completed more than LateGrace after on_way + promised ETA (orders without an ETA are never late)
+func (s *Service) isLate(o *models.Order, completedAt time.Time) bool {
+    if o.EtaMinutes <= 0 || o.OnWayAt == nil {
+        return false
+    }
+    due := o.OnWayAt.Add(time.Duration(o.EtaMinutes)*time.Minute + s.penalties.LateGrace)
+    return completedAt.After(due)
+}
+
+func sumWeight(items []models.WasteItem) float64 {
+    total := 0.0
+    for _, it := range items {
+        total += it.Weight
+    }
+    return total
+}
+
 func haversineKm(lat1, lon1, lat2, lon2 float64) float64 {
     const R = 6371.0
     toRad := func(d float64) float64 { return d * math.Pi / 180 }
@@ -1,18 +1,39 @@
 package service
 
 import (
+    "bytes"
+    "context"
+    "errors"
+    "io"
+    "strings"
     "testing"
     "time"
 
+    "go.opentelemetry.io/otel/codes"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+    "go.opentelemetry.io/otel/trace"
+
     "ecopoint/collecting_service/internal/models"
+    "ecopoint/collecting_service/internal/validation"
 )
 
+// validInput returns a minimal order input that passes the default validator
+func validInput(id, customerID string) CreateOrderInput {
+    return CreateOrderInput{
+        ID:         id,
+        CustomerID: customerID,
+        Address:    models.Address{FullText: "A", Lat: 1, Lng: 2},
+        Items:      []models.WasteItem{{Type: "paper", Weight: 1}},
+    }
+}
+
 func TestCreateAndList(t *testing.T) {
     repo := NewInMemoryRepo()
     svc := NewService(repo)
 
     // Initially empty
-    list, err := svc.ListAvailable(10)
+    list, _, err := svc.ListAvailable("", 10)
     if err != nil || len(list) != 0 {
         t.Fatalf("expected empty list, got %v err %v", len(list), err)
     }
@@ -35,7 +56,7 @@ func TestCreateAndList(t *testing.T) {
         t.Fatalf("status expected created, got %s", order.Status)
     }
 
-    list, err = svc.ListAvailable(10)
+    list, _, err = svc.ListAvailable("", 10)
     if err != nil || len(list) != 1 {
         t.Fatalf("expected 1 available order, got %v err %v", len(list), err)
     }
@@ -91,31 +112,122 @@ func TestCancelRules(t *testing.T) {
     repo := NewInMemoryRepo()
     svc := NewService(repo)
 
-    // Customer can cancel when created
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "oc1", CustomerID: "u1"})
-    if _, err := svc.CancelOrderByCustomer("oc1", "change of mind"); err != nil {
-        t.Fatalf("customer cancel failed: %v", err)
+    // Customer can cancel when created, but only their own order
+    _, _ = svc.CreateOrder(validInput("oc1", "u1"))
+    if _, err := svc.CancelOrderByCustomer("oc1", "u2", "not mine"); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("expected ErrNotParticipant for another customer, got %v", err)
     }
-
-    // After accepted, customer cannot cancel
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "oc2", CustomerID: "u1"})
-    _, _ = svc.AcceptOrder("oc2", "c1")
-    if _, err := svc.CancelOrderByCustomer("oc2", "late"); err == nil {
-        t.Fatalf("expected error: customer cancel after accepted")
+    if _, err := svc.CancelOrderByCustomer("oc1", "u1", "change of mind"); err != nil {
+        t.Fatalf("customer cancel failed: %v", err)
     }
 
     // Collector can cancel when accepted
+    _, _ = svc.CreateOrder(validInput("oc2", "u1"))
+    _, _ = svc.AcceptOrder("oc2", "c1")
     if _, err := svc.CancelOrderByCollector("oc2", "c1", "busy"); err != nil {
         t.Fatalf("collector cancel failed: %v", err)
     }
+
+    // Finished orders cannot be cancelled by the customer
+    if _, err := svc.CancelOrderByCustomer("oc2", "u1", "again"); err == nil {
+        t.Fatalf("expected error: customer cancel after cancelled")
+    }
+}
+
+func TestCustomerCancelAfterAcceptFees(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    ledger := NewInMemoryLedgerStore()
+    svc := NewService(repo, WithEventPublisher(pub), WithLedgerStore(ledger), WithCancelPolicy(CancelPolicy{FreeWindow: 5 * time.Minute, AcceptedFee: 10, OnWayFee: 20}))
+
+    // Within the free window: no fee, collector notified and free to take other work
+    _, _ = svc.CreateOrder(validInput("f1", "u1"))
+    _, _ = svc.AcceptOrder("f1", "c1")
+    o, err := svc.CancelOrderByCustomer("f1", "u1", "changed plans")
+    if err != nil || o.Status != models.StatusCancelled || o.CancelFee != 0 {
+        t.Fatalf("unexpected free cancel %+v err %v", o, err)
+    }
+    if ev := pub.ofType(models.EventOrderCancelled); len(ev) != 1 || len(ev[0].Recipients) != 2 || ev[0].Recipients[1] != "c1" {
+        t.Fatalf("expected cancel event to the collector, got %+v", pub.events)
+    }
+    if _, err := repo.FindActiveOrderByCollector("c1"); err == nil {
+        t.Fatalf("expected collector slot to be released")
+    }
+
+    // After the free window the accepted fee applies
+    _, _ = svc.CreateOrder(validInput("f2", "u1"))
+    _, _ = svc.AcceptOrder("f2", "c1")
+    acceptedAt := time.Now().Add(-10 * time.Minute)
+    repo.store["f2"].AcceptedAt = &acceptedAt
+    if o, err := svc.CancelOrderByCustomer("f2", "u1", "too slow"); err != nil || o.CancelFee != 10 {
+        t.Fatalf("expected accepted fee, got %+v err %v", o, err)
+    }
+
+    // On the way is always charged
+    _, _ = svc.CreateOrder(validInput("f3", "u1"))
+    _, _ = svc.AcceptOrder("f3", "c2")
+    _, _ = svc.UpdateStatus("f3", models.StatusOnWay, "c2")
+    if o, err := svc.CancelOrderByCustomer("f3", "u1", "not home"); err != nil || o.CancelFee != 20 {
+        t.Fatalf("expected on-way fee, got %+v err %v", o, err)
+    }
+
+    entries, _ := ledger.ListLedgerEntries("u1")
+    if len(entries) != 2 || entries[0].Points != -20 || entries[1].Points != -10 || entries[0].Kind != models.LedgerCancelFee {
+        t.Fatalf("unexpected customer ledger %+v", entries)
+    }
+    if entries, _ := ledger.ListLedgerEntries("c2"); len(entries) != 1 || entries[0].Points != 20 || entries[0].Kind != models.LedgerCancelCompensation {
+        t.Fatalf("unexpected collector ledger %+v", entries)
+    }
+}
+
+// flakyLedger fails while down is set
+type flakyLedger struct {
+    *InMemoryLedgerStore
+    down bool
+}
+
+func (l *flakyLedger) AddLedgerEntry(e models.LedgerEntry) error {
+    if l.down {
+        return errors.New("ledger unavailable")
+    }
+    return l.InMemoryLedgerStore.AddLedgerEntry(e)
+}
+
+func TestCustomerCancelSurvivesLedgerFailure(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    ledger := &flakyLedger{InMemoryLedgerStore: NewInMemoryLedgerStore(), down: true}
+    svc := NewService(repo, WithEventPublisher(pub), WithLedgerStore(ledger))
+    _, _ = svc.CreateOrder(validInput("lf1", "u1"))
+    _, _ = svc.AcceptOrder("lf1", "c1")
+    _, _ = svc.UpdateStatus("lf1", models.StatusOnWay, "c1")
+
+    o, err := svc.CancelOrderByCustomer("lf1", "u1", "not home")
+    if err != nil || o.Status != models.StatusCancelled || o.CancelFee <= 0 {
+        t.Fatalf("a stored cancel must not fail on the ledger, got %+v err %v", o, err)
+    }
+    if ev := pub.ofType(models.EventOrderCancelled); len(ev) != 1 || ev[0].Recipients[len(ev[0].Recipients)-1] != "c1" {
+        t.Fatalf("the collector must still hear of the cancel, got %+v", pub.events)
+    }
+
+    // booking again once the ledger is back charges exactly once
+    ledger.down = false
+    for i := 0; i < 2; i++ {
+        if err := svc.bookCancelFee(o, "c1", time.Now()); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if entries, _ := ledger.ListLedgerEntries("u1"); len(entries) != 1 || entries[0].Points != -o.CancelFee {
+        t.Fatalf("unexpected customer ledger %+v", entries)
+    }
 }
 
 func TestOneActiveOrderPerCollector(t *testing.T) {
     repo := NewInMemoryRepo()
     svc := NewService(repo)
 
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "oa1", CustomerID: "u1"})
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "oa2", CustomerID: "u2"})
+    _, _ = svc.CreateOrder(validInput("oa1", "u1"))
+    _, _ = svc.CreateOrder(validInput("oa2", "u2"))
 
     if _, err := svc.AcceptOrder("oa1", "collector-1"); err != nil {
         t.Fatalf("accept oa1 failed: %v", err)
@@ -132,6 +244,21 @@ func TestPricingAndETA(t *testing.T) {
     if price <= 0 || eta <= 0 {
         t.Fatalf("unexpected price/eta %v %v", price, eta)
     }
+
+    // an order without an estimate is quoted by weight from the configured pricing
+    svc = NewService(NewInMemoryRepo(), WithPricing(Pricing{Base: 5000, PerKg: 1000, PerKm: 3000}))
+    o, err := svc.CreateOrder(CreateOrderInput{
+        ID:         "p1",
+        CustomerID: "u1",
+        Address:    models.Address{FullText: "A", Lat: 1, Lng: 2},
+        Items:      []models.WasteItem{{Type: "paper", Weight: 2.5}},
+    })
+    if err != nil {
+        t.Fatal(err)
+    }
+    if o.EstimatedPrice != 7500 {
+        t.Fatalf("expected a quoted price of 7500, got %v", o.EstimatedPrice)
+    }
 }
 
 func TestListAvailableOrdersNearAndExpire(t *testing.T) {
@@ -139,14 +266,12 @@ func TestListAvailableOrdersNearAndExpire(t *testing.T) {
     svc := NewService(repo)
 
     // Create two orders with different locations
-    _, _ = svc.CreateOrder(CreateOrderInput{
-        ID: "n1", CustomerID: "u1",
-        Address: models.Address{FullText: "A", Lat: 10.76, Lng: 106.66},
-    })
-    _, _ = svc.CreateOrder(CreateOrderInput{
-        ID: "n2", CustomerID: "u2",
-        Address: models.Address{FullText: "B", Lat: 10.80, Lng: 106.70},
-    })
+    n1 := validInput("n1", "u1")
+    n1.Address = models.Address{FullText: "A", Lat: 10.76, Lng: 106.66}
+    _, _ = svc.CreateOrder(n1)
+    n2 := validInput("n2", "u2")
+    n2.Address = models.Address{FullText: "B", Lat: 10.80, Lng: 106.70}
+    _, _ = svc.CreateOrder(n2)
 
     // Near search around (10.77, 106.67) within 5km
     res, err := svc.ListAvailableOrdersNear(10.77, 106.67, 5, 10)
@@ -169,12 +294,12 @@ func TestNewAPIs(t *testing.T) {
     svc := NewService(repo)
 
     // Create 3 orders for customer u9
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "m1", CustomerID: "u9"})
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "m2", CustomerID: "u9"})
-    _, _ = svc.CreateOrder(CreateOrderInput{ID: "m3", CustomerID: "u9"})
+    _, _ = svc.CreateOrder(validInput("m1", "u9"))
+    _, _ = svc.CreateOrder(validInput("m2", "u9"))
+    _, _ = svc.CreateOrder(validInput("m3", "u9"))
 
-    // ListMyOrders page 1 size 2
-    list, err := svc.ListMyOrders("u9", 1, 2)
+    // ListMyOrders first page of size 2
+    list, _, err := svc.ListMyOrders("u9", "", 2)
     if err != nil || len(list) != 2 {
         t.Fatalf("ListMyOrders failed: %v len=%d", err, len(list))
     }
@@ -182,7 +307,7 @@ func TestNewAPIs(t *testing.T) {
     if _, err := svc.AcceptOrder("m1", "c7"); err != nil {
         t.Fatalf("accept m1 failed: %v", err)
     }
-    actives, err := svc.ListMyActiveOrders("c7")
+    actives, _, err := svc.ListMyActiveOrders("c7", "", 10)
     if err != nil || len(actives) != 1 {
         t.Fatalf("ListMyActiveOrders failed: %v len=%d", err, len(actives))
     }
@@ -193,4 +318,901 @@ func TestNewAPIs(t *testing.T) {
     }
 }
 
+func TestCreateOrderValidation(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+
+    in := validInput("v1", "")
+    in.Items = append(in.Items, models.WasteItem{Type: "plastic", Weight: -1})
+    _, err := svc.CreateOrder(in)
+    var verr *validation.Error
+    if !errors.As(err, &verr) {
+        t.Fatalf("expected validation error, got %v", err)
+    }
+    if len(verr.Violations) != 2 {
+        t.Fatalf("expected 2 violations, got %+v", verr.Violations)
+    }
+    if _, err := repo.Get("v1"); err == nil {
+        t.Fatalf("invalid order must not be stored")
+    }
+
+    // Omitted total_weight is derived from items
+    in = validInput("v2", "u1")
+    in.Items = []models.WasteItem{{Type: "paper", Weight: 1.5}, {Type: "metal", Weight: 2}}
+    o, err := svc.CreateOrder(in)
+    if err != nil || o.TotalWeight != 3.5 {
+        t.Fatalf("expected derived total 3.5, got %v err %v", o, err)
+    }
+
+    // Custom limits via option
+    strict := NewService(NewInMemoryRepo(), WithValidator(validation.New(validation.Limits{MaxItems: 1})))
+    in = validInput("v3", "u1")
+    in.Items = []models.WasteItem{{Type: "anything", Weight: 1}, {Type: "else", Weight: 1}}
+    if _, err := strict.CreateOrder(in); !errors.As(err, &verr) {
+        t.Fatalf("expected max items violation, got %v", err)
+    }
+}
+
+func keyedInput(id, customerID, key string) CreateOrderInput {
+    in := validInput(id, customerID)
+    in.IdempotencyKey = key
+    return in
+}
+
+func TestIdempotentCreateAndAccept(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+
+    // Retries carry a fresh ID but the same key
+    first, err := svc.CreateOrder(keyedInput("i1", "u1", "k1"))
+    if err != nil {
+        t.Fatalf("create error: %v", err)
+    }
+    replay, err := svc.CreateOrder(keyedInput("i2", "u1", "k1"))
+    if err != nil || replay.ID != first.ID {
+        t.Fatalf("expected replay of %s, got %v err %v", first.ID, replay, err)
+    }
+    if list, _, _ := svc.ListMyOrders("u1", "", 10); len(list) != 1 {
+        t.Fatalf("expected a single stored order, got %d", len(list))
+    }
+
+    // Keys are unique per customer, not globally
+    other, err := svc.CreateOrder(keyedInput("i3", "u2", "k1"))
+    if err != nil || other.ID != "i3" {
+        t.Fatalf("expected new order for other customer, got %v err %v", other, err)
+    }
+
+    // Failed calls release the key so the client can retry
+    bad := validInput("i4", "u1")
+    bad.IdempotencyKey = "k2"
+    bad.Items = nil
+    if _, err := svc.CreateOrder(bad); err == nil {
+        t.Fatalf("expected validation error")
+    }
+    accept := func() (*models.Order, error) { return svc.AcceptOrder("i1", "c1") }
+    if _, err := svc.Idempotent("c1", "k2", OpAcceptOrder, "i1", func() (*models.Order, error) { return nil, errors.New("boom") }); err == nil {
+        t.Fatalf("expected fn error")
+    }
+    o, err := svc.Idempotent("c1", "k2", OpAcceptOrder, "i1", accept)
+    if err != nil || o.Status != models.StatusAccepted {
+        t.Fatalf("accept failed: %v", err)
+    }
+    // Replayed accept returns the order instead of "already has an active order"
+    o, err = svc.Idempotent("c1", "k2", OpAcceptOrder, "i1", accept)
+    if err != nil || o.ID != "i1" {
+        t.Fatalf("accept replay failed: %v", err)
+    }
+    // Same key for a different operation is rejected
+    if _, err := svc.Idempotent("c1", "k2", OpUpdateStatus, "i1", accept); !errors.Is(err, ErrIdempotencyKeyReused) {
+        t.Fatalf("expected ErrIdempotencyKeyReused, got %v", err)
+    }
+
+    // ... and so is the same key with other request fields
+    cancel := func() (*models.Order, error) { return svc.CancelOrderByCollector("i1", "c1", "busy") }
+    fp := RequestFingerprint("COLLECTOR", "c1", "busy")
+    if o, err := svc.IdempotentRequest("c1", "k3", OpCancelOrder, "i1", fp, cancel); err != nil || o.Status != models.StatusCancelled {
+        t.Fatalf("cancel failed: %v", err)
+    }
+    if o, err := svc.IdempotentRequest("c1", "k3", OpCancelOrder, "i1", fp, cancel); err != nil || o.ID != "i1" {
+        t.Fatalf("cancel replay failed: %v", err)
+    }
+    if _, err := svc.IdempotentRequest("c1", "k3", OpCancelOrder, "i1", RequestFingerprint("COLLECTOR", "c1", "flat tyre"), cancel); !errors.Is(err, ErrIdempotencyKeyReused) {
+        t.Fatalf("expected ErrIdempotencyKeyReused for another reason, got %v", err)
+    }
+}
 
+func TestIdempotencyKeyExpiry(t *testing.T) {
+    svc := NewService(NewInMemoryRepo(), WithIdempotency(NewInMemoryIdempotencyStore(), time.Nanosecond))
+    in := validInput("e1", "u1")
+    in.IdempotencyKey = "k"
+    if _, err := svc.CreateOrder(in); err != nil {
+        t.Fatalf("create error: %v", err)
+    }
+    time.Sleep(time.Millisecond)
+    in.ID = "e2"
+    o, err := svc.CreateOrder(in)
+    if err != nil || o.ID != "e2" {
+        t.Fatalf("expected expired key to allow a new order, got %v err %v", o, err)
+    }
+}
+
+func TestCursorPagination(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+
+    // Five orders sharing one timestamp: ties are broken by id desc
+    same := time.Now().Add(-time.Minute)
+    for _, id := range []string{"p1", "p2", "p3", "p4", "p5"} {
+        _, _ = svc.CreateOrder(validInput(id, "u1"))
+        o, _ := repo.Get(id)
+        o.CreatedAt = same
+    }
+
+    var got []string
+    token := ""
+    for page := 0; ; page++ {
+        list, next, err := svc.ListMyOrders("u1", token, 2)
+        if err != nil {
+            t.Fatalf("page %d: %v", page, err)
+        }
+        for _, o := range list {
+            got = append(got, o.ID)
+        }
+        if page == 0 {
+            // A new order arriving mid-walk must not shift later pages
+            _, _ = svc.CreateOrder(validInput("p6", "u1"))
+        }
+        if next == "" {
+            break
+        }
+        token = next
+    }
+    want := []string{"p5", "p4", "p3", "p2", "p1"}
+    if len(got) != len(want) {
+        t.Fatalf("expected %v, got %v", want, got)
+    }
+    for i := range want {
+        if got[i] != want[i] {
+            t.Fatalf("expected %v, got %v", want, got)
+        }
+    }
+
+    // The open pool uses the same ordering; the newest order comes first
+    list, next, err := svc.ListAvailable("", 1)
+    if err != nil || len(list) != 1 || list[0].ID != "p6" || next == "" {
+        t.Fatalf("unexpected first available page %v next=%q err=%v", list, next, err)
+    }
+
+    if _, _, err := svc.ListAvailable("not-a-token", 10); !errors.Is(err, ErrInvalidPageToken) {
+        t.Fatalf("expected ErrInvalidPageToken, got %v", err)
+    }
+}
+
+func TestCollectorHistoryAndEarnings(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+
+    // c1 completes h1 (Mon) and h2 (Wed same week), cancels h3, h4 belongs to c2
+    complete := func(id, collector string, items []models.WasteItem, price float64, at time.Time) {
+        in := validInput(id, "u1")
+        in.Items = items
+        in.EstimatedPrice = price
+        _, _ = svc.CreateOrder(in)
+        _, _ = svc.AcceptOrder(id, collector)
+        _, _ = svc.UpdateStatus(id, models.StatusOnWay, collector)
+        o, err := svc.UpdateStatus(id, models.StatusComplete, collector)
+        if err != nil {
+            t.Fatalf("complete %s: %v", id, err)
+        }
+        o.CompletedAt = &at
+    }
+    mon := time.Date(2025, 8, 11, 9, 0, 0, 0, time.UTC)
+    wed := time.Date(2025, 8, 13, 23, 30, 0, 0, time.UTC)
+    complete("h1", "c1", []models.WasteItem{{Type: "paper", Weight: 2}, {Type: "plastic", Weight: 1}}, 30000, mon)
+    complete("h2", "c1", []models.WasteItem{{Type: "paper", Weight: 1.5}}, 15000, wed)
+    complete("h4", "c2", []models.WasteItem{{Type: "metal", Weight: 3}}, 90000, wed)
+    _, _ = svc.CreateOrder(validInput("h3", "u2"))
+    _, _ = svc.AcceptOrder("h3", "c1")
+    _, _ = svc.CancelOrderByCollector("h3", "c1", "flat tyre")
+
+    all, _, err := svc.ListCollectorOrders("c1", OrderFilter{}, "", 10)
+    if err != nil || len(all) != 3 {
+        t.Fatalf("expected 3 orders in history, got %d err %v", len(all), err)
+    }
+    cancelled, _, err := svc.ListCollectorOrders("c1", OrderFilter{Statuses: []models.OrderStatus{models.StatusCancelled}}, "", 10)
+    if err != nil || len(cancelled) != 1 || cancelled[0].ID != "h3" {
+        t.Fatalf("expected only h3, got %v err %v", cancelled, err)
+    }
+    if _, _, err := svc.ListCollectorOrders("c1", OrderFilter{From: wed, To: mon}, "", 10); !errors.Is(err, ErrInvalidRange) {
+        t.Fatalf("expected ErrInvalidRange, got %v", err)
+    }
+
+    from := time.Date(2025, 8, 1, 0, 0, 0, 0, time.UTC)
+    to := time.Date(2025, 9, 1, 0, 0, 0, 0, time.UTC)
+    daily, err := svc.GetCollectorEarnings("c1", EarningsQuery{From: from, To: to})
+    if err != nil || len(daily.Buckets) != 2 {
+        t.Fatalf("expected 2 daily buckets, got %+v err %v", daily, err)
+    }
+    if daily.Total.Orders != 2 || daily.Total.Amount != 45000 || daily.Total.WeightByType["paper"] != 3.5 {
+        t.Fatalf("unexpected totals %+v", daily.Total)
+    }
+
+    weekly, _ := svc.GetCollectorEarnings("c1", EarningsQuery{From: from, To: to, GroupBy: GroupByWeek})
+    if len(weekly.Buckets) != 1 || !weekly.Buckets[0].PeriodStart.Equal(time.Date(2025, 8, 11, 0, 0, 0, 0, time.UTC)) {
+        t.Fatalf("expected one week starting Monday, got %+v", weekly.Buckets)
+    }
+
+    // 23:30 UTC Wednesday is already Thursday in Ho Chi Minh City (UTC+7)
+    hcm := time.FixedZone("ICT", 7*3600)
+    local, _ := svc.GetCollectorEarnings("c1", EarningsQuery{From: from, To: to, Location: hcm})
+    if got := local.Buckets[1].PeriodStart; got.Day() != 14 {
+        t.Fatalf("expected bucket on the 14th local time, got %v", got)
+    }
+
+    if _, err := svc.GetCollectorEarnings("c1", EarningsQuery{From: from, To: to, GroupBy: "year"}); !errors.Is(err, ErrInvalidGroupBy) {
+        t.Fatalf("expected ErrInvalidGroupBy, got %v", err)
+    }
+}
+
+func TestRateOrder(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+
+    _, _ = svc.CreateOrder(validInput("r1", "u1"))
+    rate := func(rater string, side models.Role, stars int, tags ...string) error {
+        _, err := svc.RateOrder(RateOrderInput{OrderID: "r1", RaterID: rater, Side: side, Stars: stars, Tags: tags})
+        return err
+    }
+    if err := rate("u1", models.RoleCustomer, 5); !errors.Is(err, ErrNotRateable) {
+        t.Fatalf("expected ErrNotRateable before completion, got %v", err)
+    }
+    _, _ = svc.AcceptOrder("r1", "c1")
+    _, _ = svc.UpdateStatus("r1", models.StatusOnWay, "c1")
+    _, _ = svc.UpdateStatus("r1", models.StatusComplete, "c1")
+
+    if err := rate("u2", models.RoleCustomer, 5); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("expected ErrNotParticipant, got %v", err)
+    }
+    var verr *validation.Error
+    if err := rate("u1", models.RoleCustomer, 6); !errors.As(err, &verr) {
+        t.Fatalf("expected validation error for 6 stars, got %v", err)
+    }
+    if err := rate("u1", models.RoleCustomer, 4, "On_Time", "on_time ", "friendly"); err != nil {
+        t.Fatalf("customer rating failed: %v", err)
+    }
+    if err := rate("u1", models.RoleCustomer, 1); !errors.Is(err, ErrAlreadyRated) {
+        t.Fatalf("expected ErrAlreadyRated, got %v", err)
+    }
+    if err := rate("c1", models.RoleCollector, 2); err != nil {
+        t.Fatalf("collector rating failed: %v", err)
+    }
+
+    // A second completed order for the same collector moves the average
+    _, _ = svc.CreateOrder(validInput("r2", "u3"))
+    _, _ = svc.AcceptOrder("r2", "c1")
+    _, _ = svc.UpdateStatus("r2", models.StatusOnWay, "c1")
+    _, _ = svc.UpdateStatus("r2", models.StatusComplete, "c1")
+    if _, err := svc.RateOrder(RateOrderInput{OrderID: "r2", RaterID: "u3", Side: models.RoleCustomer, Stars: 5, Tags: []string{"on_time"}}); err != nil {
+        t.Fatalf("second rating failed: %v", err)
+    }
+
+    c1, _ := svc.GetUserRating("c1", models.RoleCollector)
+    if c1.Count != 2 || c1.Average != 4.5 || c1.TagCounts["on_time"] != 2 || c1.TagCounts["friendly"] != 1 {
+        t.Fatalf("unexpected collector aggregate %+v", c1)
+    }
+    u1, _ := svc.GetUserRating("u1", models.RoleCustomer)
+    if u1.Count != 1 || u1.Average != 2 {
+        t.Fatalf("unexpected customer aggregate %+v", u1)
+    }
+    if none, _ := svc.GetUserRating("nobody", models.RoleCollector); none.Count != 0 {
+        t.Fatalf("expected empty aggregate, got %+v", none)
+    }
+
+    list, next, err := svc.SearchUserRatings(models.RoleCollector, RatingFilter{}, "", 0)
+    if err != nil || len(list) != 1 || list[0].UserID != "c1" || next != "" {
+        t.Fatalf("unexpected search %v next %q err %v", list, next, err)
+    }
+    if list, _, _ := svc.SearchUserRatings(models.RoleCollector, RatingFilter{MaxAverage: 4}, "", 0); len(list) != 0 {
+        t.Fatalf("expected no collector at or below 4 stars, got %v", list)
+    }
+}
+
+// flakyRatings fails the next ApplyRating
+type flakyRatings struct {
+    *InMemoryRatingStore
+    fail bool
+}
+
+func (f *flakyRatings) ApplyRating(r *models.Rating) (*models.UserRating, error) {
+    if f.fail {
+        f.fail = false
+        return nil, errors.New("write conflict")
+    }
+    return f.InMemoryRatingStore.ApplyRating(r)
+}
+
+func TestRateOrderRetryRepairsAggregate(t *testing.T) {
+    ratings := &flakyRatings{InMemoryRatingStore: NewInMemoryRatingStore(), fail: true}
+    svc := NewService(NewInMemoryRepo(), WithRatingStore(ratings))
+    _, _ = svc.CreateOrder(validInput("rr1", "u1"))
+    _, _ = svc.AcceptOrder("rr1", "c1")
+    _, _ = svc.UpdateStatus("rr1", models.StatusOnWay, "c1")
+    _, _ = svc.UpdateStatus("rr1", models.StatusComplete, "c1")
+
+    in := RateOrderInput{OrderID: "rr1", RaterID: "u1", Side: models.RoleCustomer, Stars: 4}
+    if _, err := svc.RateOrder(in); err == nil {
+        t.Fatal("expected the aggregate update to fail")
+    }
+    if _, err := svc.RateOrder(in); !errors.Is(err, ErrAlreadyRated) {
+        t.Fatalf("expected ErrAlreadyRated on retry, got %v", err)
+    }
+    if _, err := svc.RateOrder(in); !errors.Is(err, ErrAlreadyRated) {
+        t.Fatalf("expected ErrAlreadyRated on a second retry, got %v", err)
+    }
+    if c1, _ := svc.GetUserRating("c1", models.RoleCollector); c1.Count != 1 || c1.Average != 4 {
+        t.Fatalf("retries should repair the aggregate exactly once, got %+v", c1)
+    }
+}
+
+type recordingPublisher struct{ events []models.OrderEvent }
+
+func (p *recordingPublisher) Publish(e models.OrderEvent) { p.events = append(p.events, e) }
+
+func (p *recordingPublisher) ofType(t models.EventType) []models.OrderEvent {
+    var res []models.OrderEvent
+    for _, e := range p.events {
+        if e.Type == t {
+            res = append(res, e)
+        }
+    }
+    return res
+}
+
+func TestWatchdogReleasesAndCancelsStalledOrders(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    strikes := NewInMemoryStrikeStore()
+    svc := NewService(repo, WithEventPublisher(pub), WithStrikeStore(strikes))
+
+    _, _ = svc.CreateOrder(validInput("w1", "u1"))
+    _, _ = svc.AcceptOrder("w1", "c1")
+    now := time.Now()
+
+    // Fresh acceptance is left alone
+    if rep, err := svc.SweepStalledOrders(now); err != nil || rep.Released != 0 {
+        t.Fatalf("unexpected sweep %+v err %v", rep, err)
+    }
+
+    rep, err := svc.SweepStalledOrders(now.Add(31 * time.Minute))
+    if err != nil || rep.Released != 1 {
+        t.Fatalf("expected one release, got %+v err %v", rep, err)
+    }
+    o, _ := repo.Get("w1")
+    if o.Status != models.StatusCreated || o.AcceptedBy != nil || o.AcceptedAt != nil {
+        t.Fatalf("expected order back in the pool, got %+v", o)
+    }
+    last := o.History[len(o.History)-1]
+    if last.From != models.StatusAccepted || last.To != models.StatusCreated || last.Actor != models.ActorSystem || last.Reason != string(models.StrikeNoShow) {
+        t.Fatalf("unexpected history entry %+v", last)
+    }
+    if ev := pub.ofType(models.EventOrderReleased); len(ev) != 1 || len(ev[0].Recipients) != 2 {
+        t.Fatalf("expected release event to customer and collector, got %+v", pub.events)
+    }
+    if list, _ := strikes.ListStrikes("c1", now.Add(-time.Hour)); len(list) != 1 || list[0].Kind != models.StrikeNoShow {
+        t.Fatalf("expected one no-show strike, got %+v", list)
+    }
+    // The collector is no longer blocked and another collector can take the order
+    if _, err := svc.AcceptOrder("w1", "c2"); err != nil {
+        t.Fatalf("re-accept failed: %v", err)
+    }
+
+    // Cancel policy applies to orders stuck on the way
+    cancelSvc := NewService(repo, WithEventPublisher(pub), WithStrikeStore(strikes), WithWatchdogPolicy(WatchdogPolicy{OnWayTimeout: time.Hour, Action: WatchdogCancel, BatchSize: 10}))
+    _, _ = cancelSvc.UpdateStatus("w1", models.StatusOnWay, "c2")
+    rep, err = cancelSvc.SweepStalledOrders(time.Now().Add(2 * time.Hour))
+    if err != nil || rep.Cancelled != 1 {
+        t.Fatalf("expected one cancel, got %+v err %v", rep, err)
+    }
+    o, _ = repo.Get("w1")
+    if o.Status != models.StatusCancelled || o.CancelSide != models.CancelBySystem || o.CancelReason != string(models.StrikeAbandoned) {
+        t.Fatalf("unexpected cancelled order %+v", o)
+    }
+}
+
+// expiryObserver counts OrderExpired calls
+type expiryObserver struct{ expired []string }
+
+func (o *expiryObserver) OrderCreated(*models.Order)   {}
+func (o *expiryObserver) OrderExpired(e *models.Order) { o.expired = append(o.expired, e.ID) }
+
+func TestWatchdogExpiresCreatedOrders(t *testing.T) {
+    repo := NewInMemoryRepo()
+    obs := &expiryObserver{}
+    svc := NewService(repo, WithOrderObserver(obs), WithOrderTTL(time.Hour))
+    _, _ = svc.CreateOrder(validInput("e1", "u1"))
+    _, _ = svc.CreateOrder(validInput("e2", "u1"))
+    // e2 goes back to the pool at the accept timeout and, once accepted, never expires
+    _, _ = svc.AcceptOrder("e2", "c1")
+
+    if rep, err := svc.SweepStalledOrders(time.Now().Add(59 * time.Minute)); err != nil || rep.Expired != 0 {
+        t.Fatalf("nothing is due before the TTL, got %+v err %v", rep, err)
+    }
+    rep, err := svc.SweepStalledOrders(time.Now().Add(61 * time.Minute))
+    if err != nil || rep.Expired != 1 || len(obs.expired) != 1 || obs.expired[0] != "e1" {
+        t.Fatalf("expected e1 to expire once, got %+v observed %v err %v", rep, obs.expired, err)
+    }
+    o, _ := repo.Get("e1")
+    if o.Status != models.StatusCancelled || o.CancelSide != models.CancelBySystem || o.CancelReason != "expired" {
+        t.Fatalf("unexpected expired order %+v", o)
+    }
+    if rep, _ := svc.SweepStalledOrders(time.Now().Add(2 * time.Hour)); rep.Expired != 0 {
+        t.Fatalf("an order expires only once, got %+v", rep)
+    }
+}
+
+func TestUpdateVersionedConflict(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+    _, _ = svc.CreateOrder(validInput("vc1", "u1"))
+    stale, _ := repo.Get("vc1")
+    stale = stale.Clone()
+    _, _ = svc.AcceptOrder("vc1", "c1")
+    stale.Note = "late write"
+    if err := repo.UpdateVersioned(stale, stale.Version); !errors.Is(err, models.ErrVersionConflict) {
+        t.Fatalf("expected version conflict, got %v", err)
+    }
+}
+
+// brokenStrikes fails every write
+type brokenStrikes struct{ *InMemoryStrikeStore }
+
+func (brokenStrikes) AddStrike(models.Strike) error { return errors.New("strikes unavailable") }
+
+func TestCollectorCancelSurvivesStrikeFailure(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo, WithStrikeStore(brokenStrikes{NewInMemoryStrikeStore()}))
+    _, _ = svc.CreateOrder(validInput("sf1", "u1"))
+    _, _ = svc.AcceptOrder("sf1", "c1")
+    o, err := svc.CancelOrderByCollector("sf1", "c1", "busy")
+    if err != nil || o.Status != models.StatusCancelled {
+        t.Fatalf("a stored cancel must not fail on the strike, got %v err %v", o, err)
+    }
+}
+
+func TestLateCompletionSurvivesStrikeFailure(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    svc := NewService(repo, WithEventPublisher(pub), WithStrikeStore(brokenStrikes{NewInMemoryStrikeStore()}))
+    _, _ = svc.CreateOrder(validInput("sf2", "u1"))
+    _, _ = svc.AcceptOrder("sf2", "c1")
+    _, _ = svc.UpdateStatus("sf2", models.StatusOnWay, "c1")
+    o, _ := repo.Get("sf2")
+    onWay := time.Now().Add(-2 * time.Hour)
+    o.EtaMinutes = 30
+    o.OnWayAt = &onWay
+    _ = repo.Update(o)
+    o, err := svc.UpdateStatus("sf2", models.StatusComplete, "c1")
+    if err != nil || o.Status != models.StatusComplete {
+        t.Fatalf("a stored completion must not fail on the strike, got %v err %v", o, err)
+    }
+    if len(pub.ofType(models.EventOrderCompleted)) != 1 {
+        t.Fatalf("order.completed not published: %+v", pub.events)
+    }
+}
+
+func TestWatchdogSurvivesStrikeFailure(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    svc := NewService(repo, WithEventPublisher(pub), WithStrikeStore(brokenStrikes{NewInMemoryStrikeStore()}))
+    for _, id := range []string{"ws1", "ws2"} {
+        _, _ = svc.CreateOrder(validInput(id, "u1"))
+    }
+    _, _ = svc.AcceptOrder("ws1", "c1")
+    _, _ = svc.AcceptOrder("ws2", "c2")
+    rep, err := svc.SweepStalledOrders(time.Now().Add(time.Hour))
+    if err != nil || rep.Released != 2 {
+        t.Fatalf("every stalled order must be released, got %+v err %v", rep, err)
+    }
+    if ev := pub.ofType(models.EventOrderReleased); len(ev) != 2 {
+        t.Fatalf("expected two release events, got %+v", pub.events)
+    }
+}
+
+func TestCollectorCooldownAfterRepeatedCancels(t *testing.T) {
+    repo := NewInMemoryRepo()
+    strikes := NewInMemoryStrikeStore()
+    svc := NewService(repo, WithStrikeStore(strikes))
+
+    for i, id := range []string{"p1", "p2", "p3"} {
+        _, _ = svc.CreateOrder(validInput(id, "u1"))
+        if _, err := svc.AcceptOrder(id, "c1"); err != nil {
+            t.Fatalf("accept %d failed: %v", i, err)
+        }
+        if _, err := svc.CancelOrderByCollector(id, "c1", "busy"); err != nil {
+            t.Fatalf("cancel %d failed: %v", i, err)
+        }
+    }
+
+    _, _ = svc.CreateOrder(validInput("p4", "u1"))
+    _, err := svc.AcceptOrder("p4", "c1")
+    var cerr *CooldownError
+    if !errors.As(err, &cerr) || !errors.Is(err, ErrCollectorSuspended) {
+        t.Fatalf("expected cooldown error, got %v", err)
+    }
+    if d := time.Until(cerr.Until); d < 59*time.Minute || d > time.Hour {
+        t.Fatalf("expected first tier cooldown of one hour, got %v", d)
+    }
+    if cerr.Reason != "3 cancelled in the last 7 days" {
+        t.Fatalf("unexpected reason %q", cerr.Reason)
+    }
+    // Other collectors are unaffected
+    if _, err := svc.AcceptOrder("p4", "c2"); err != nil {
+        t.Fatalf("accept by c2 failed: %v", err)
+    }
+
+    st, err := svc.GetCollectorPenalty("c1")
+    if err != nil || st.Points != 3 || len(st.Strikes) != 3 || !st.Penalty.Active(time.Now()) {
+        t.Fatalf("unexpected penalty status %+v err %v", st, err)
+    }
+
+    // Clearing lifts the cooldown and forgives the counted strikes
+    st, err = svc.ClearCollectorPenalty("c1", "admin1")
+    if err != nil || st.Points != 0 || len(st.Strikes) != 0 || st.Penalty.Active(time.Now()) || st.Penalty.ClearedBy != "admin1" {
+        t.Fatalf("unexpected cleared status %+v err %v", st, err)
+    }
+    _, _ = svc.CreateOrder(validInput("p5", "u1"))
+    if _, err := svc.AcceptOrder("p5", "c1"); err != nil {
+        t.Fatalf("accept after clear failed: %v", err)
+    }
+}
+
+func TestLateCompletionStrike(t *testing.T) {
+    repo := NewInMemoryRepo()
+    strikes := NewInMemoryStrikeStore()
+    svc := NewService(repo, WithStrikeStore(strikes))
+
+    _, _ = svc.CreateOrder(validInput("l1", "u1"))
+    _, _ = svc.AcceptOrder("l1", "c1")
+    _, _ = svc.UpdateStatus("l1", models.StatusOnWay, "c1")
+    o, _ := repo.Get("l1")
+    onWay := time.Now().Add(-2 * time.Hour)
+    o.EtaMinutes = 30
+    o.OnWayAt = &onWay
+    _ = repo.Update(o)
+    if _, err := svc.UpdateStatus("l1", models.StatusComplete, "c1"); err != nil {
+        t.Fatalf("complete failed: %v", err)
+    }
+    list, _ := strikes.ListStrikes("c1", time.Now().Add(-time.Hour))
+    if len(list) != 1 || list[0].Kind != models.StrikeLate || list[0].OrderID != "l1" {
+        t.Fatalf("expected one late strike, got %+v", list)
+    }
+
+    // Within ETA plus grace is on time
+    _, _ = svc.CreateOrder(validInput("l2", "u1"))
+    _, _ = svc.AcceptOrder("l2", "c1")
+    _, _ = svc.UpdateStatus("l2", models.StatusOnWay, "c1")
+    o, _ = repo.Get("l2")
+    o.EtaMinutes = 30
+    _ = repo.Update(o)
+    _, _ = svc.UpdateStatus("l2", models.StatusComplete, "c1")
+    if list, _ := strikes.ListStrikes("c1", time.Now().Add(-time.Hour)); len(list) != 1 {
+        t.Fatalf("expected no new strike, got %+v", list)
+    }
+}
+
+func TestUpdateOrderDetails(t *testing.T) {
+    repo := NewInMemoryRepo()
+    pub := &recordingPublisher{}
+    svc := NewService(repo, WithEventPublisher(pub))
+    in := validInput("e1", "u1")
+    in.EstimatedPrice = 10
+    o, _ := svc.CreateOrder(in)
+
+    note := "gate code 42"
+    if _, err := svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "e1", CustomerID: "u2", Version: o.Version, Note: &note}); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("expected not participant, got %v", err)
+    }
+    if _, err := svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "e1", CustomerID: "u1", Version: o.Version + 1, Note: &note}); !errors.Is(err, models.ErrVersionConflict) {
+        t.Fatalf("expected version conflict, got %v", err)
+    }
+    bad := []models.WasteItem{{Type: "uranium", Weight: 1}}
+    var verr *validation.Error
+    if _, err := svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "e1", CustomerID: "u1", Version: o.Version, Items: bad}); !errors.As(err, &verr) {
+        t.Fatalf("expected validation error, got %v", err)
+    }
+
+    items := []models.WasteItem{{Type: "paper", Weight: 2}, {Type: "metal", Weight: 1}}
+    up, err := svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "e1", CustomerID: "u1", Version: o.Version, Items: items, Note: &note})
+    if err != nil {
+        t.Fatalf("update failed: %v", err)
+    }
+    // the new weight is quoted like a new order, not rescaled from the old estimate
+    if price, _ := DefaultPricing().Quote(3, 0); up.TotalWeight != 3 || up.EstimatedPrice != price || up.Note != note || up.Version != o.Version+1 {
+        t.Fatalf("unexpected updated order %+v", up)
+    }
+    if len(pub.events) != 0 {
+        t.Fatalf("expected no event for a pool edit, got %+v", pub.events)
+    }
+
+    // Editing stops once a collector took the order
+    _, _ = svc.AcceptOrder("e1", "c1")
+    cur, _ := repo.Get("e1")
+    if _, err := svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "e1", CustomerID: "u1", Version: cur.Version, Note: &note}); !errors.Is(err, ErrOrderNotEditable) {
+        t.Fatalf("expected not editable, got %v", err)
+    }
+}
+
+func TestOrderChat(t *testing.T) {
+    repo := NewInMemoryRepo()
+    svc := NewService(repo)
+    _, _ = svc.CreateOrder(validInput("ch1", "u1"))
+
+    if _, err := svc.SendChatMessage("ch1", "u1", "hello?"); !errors.Is(err, ErrChatNotOpen) {
+        t.Fatalf("expected chat not open before acceptance, got %v", err)
+    }
+    _, _ = svc.AcceptOrder("ch1", "c1")
+    sub, err := svc.SubscribeChat("ch1", "u1")
+    if err != nil {
+        t.Fatalf("subscribe failed: %v", err)
+    }
+    if _, err := svc.SubscribeChat("ch1", "c2"); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("expected not participant, got %v", err)
+    }
+    var verr *validation.Error
+    if _, err := svc.SendChatMessage("ch1", "c1", "   "); !errors.As(err, &verr) {
+        t.Fatalf("expected validation error for blank text, got %v", err)
+    }
+
+    m1, err := svc.SendChatMessage("ch1", "c1", "I am at the gate")
+    if err != nil || m1.SenderRole != models.RoleCollector {
+        t.Fatalf("send failed: %+v %v", m1, err)
+    }
+    if ev := <-sub.Events; ev.Message == nil || ev.Message.ID != m1.ID {
+        t.Fatalf("expected live message, got %+v", ev)
+    }
+    // a participant's own messages are not marked read by them
+    m2, _ := svc.SendChatMessage("ch1", "u1", "coming down")
+    <-sub.Events
+    if _, err := svc.MarkChatRead("ch1", "u1", m2.ID); err != nil {
+        t.Fatalf("mark read failed: %v", err)
+    }
+    if ev := <-sub.Events; ev.Receipt == nil || ev.Receipt.ReaderID != "u1" {
+        t.Fatalf("expected read receipt, got %+v", ev)
+    }
+    list, _ := svc.ListChatMessages("ch1", "c1", time.Time{}, 0)
+    if len(list) != 2 || list[0].ReadAt == nil || list[1].ReadAt != nil {
+        t.Fatalf("unexpected history %+v", list)
+    }
+
+    // Completing the order ends live streams and makes the chat read-only
+    _, _ = svc.UpdateStatus("ch1", models.StatusOnWay, "c1")
+    _, _ = svc.UpdateStatus("ch1", models.StatusComplete, "c1")
+    if ev := <-sub.Events; !ev.Closed {
+        t.Fatalf("expected closed event, got %+v", ev)
+    }
+    if _, ok := <-sub.Events; ok {
+        t.Fatalf("expected subscription channel to be closed")
+    }
+    if _, err := svc.SendChatMessage("ch1", "u1", "thanks"); !errors.Is(err, ErrChatClosed) {
+        t.Fatalf("expected chat closed, got %v", err)
+    }
+    if list, err := svc.ListChatMessages("ch1", "u1", time.Time{}, 0); err != nil || len(list) != 2 {
+        t.Fatalf("history must stay readable, got %d err %v", len(list), err)
+    }
+}
+
+// memBlobs is a BlobStore that also signs fake URLs
+type memBlobs struct {
+    data map[string][]byte
+}
+
+func (m *memBlobs) Put(key string, r io.Reader, size int64, contentType string) error {
+    b, err := io.ReadAll(r)
+    if err != nil {
+        return err
+    }
+    m.data[key] = b
+    return nil
+}
+
+func (m *memBlobs) Open(key string) (io.ReadCloser, error) {
+    b, ok := m.data[key]
+    if !ok {
+        return nil, ErrBlobNotFound
+    }
+    return io.NopCloser(bytes.NewReader(b)), nil
+}
+
+func (m *memBlobs) Stat(key string) (BlobInfo, error) {
+    b, ok := m.data[key]
+    if !ok {
+        return BlobInfo{}, ErrBlobNotFound
+    }
+    return BlobInfo{Size: int64(len(b))}, nil
+}
+
+func (m *memBlobs) Delete(key string) error { delete(m.data, key); return nil }
+
+func (m *memBlobs) UploadURL(key, contentType string, ttl time.Duration) (string, map[string]string, error) {
+    return "https://blobs.test/" + key, map[string]string{"Content-Type": contentType}, nil
+}
+
+func (m *memBlobs) DownloadURL(key string, ttl time.Duration) (string, error) {
+    return "https://blobs.test/" + key, nil
+}
+
+func TestAttachments(t *testing.T) {
+    png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 100)...)
+    blobs := &memBlobs{data: map[string][]byte{}}
+    svc := NewService(NewInMemoryRepo(), WithBlobStore(blobs))
+    in := validInput("at1", "u1")
+    in.Items = append(in.Items, models.WasteItem{Type: "plastic", Weight: 2})
+    _, _ = svc.CreateOrder(in)
+
+    photo := AttachmentInput{OrderID: "at1", UserID: "u1", Kind: models.AttachmentItemPhoto, ItemIndex: 1, ContentType: "image/png", Size: int64(len(png))}
+    o, err := svc.UploadAttachment(photo, bytes.NewReader(png))
+    if err != nil || len(o.Items[1].Attachments) != 1 || len(blobs.data) != 1 {
+        t.Fatalf("stream upload failed: %+v err %v", o, err)
+    }
+    var verr *validation.Error
+    jpeg := photo
+    jpeg.ContentType = "image/jpeg"
+    if _, err := svc.UploadAttachment(jpeg, bytes.NewReader(png)); !errors.As(err, &verr) {
+        t.Fatalf("expected a content type mismatch, got %v", err)
+    }
+    short := photo
+    short.Size = int64(len(png)) + 10
+    if _, err := svc.UploadAttachment(short, bytes.NewReader(png)); !errors.As(err, &verr) || len(blobs.data) != 1 {
+        t.Fatalf("expected a size mismatch without a stored blob, got %v (%d blobs)", err, len(blobs.data))
+    }
+    big := photo
+    big.Size = 11 << 20
+    if _, err := svc.CreateAttachmentUpload(big); !errors.As(err, &verr) {
+        t.Fatalf("expected the size limit, got %v", err)
+    }
+    other := photo
+    other.UserID = "u2"
+    if _, err := svc.UploadAttachment(other, bytes.NewReader(png)); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("only the customer adds item photos, got %v", err)
+    }
+
+    // Editing items keeps photos of items that did not change type
+    cur, _ := svc.GetOrder("at1")
+    o, err = svc.UpdateOrderDetails(UpdateOrderDetailsInput{OrderID: "at1", CustomerID: "u1", Version: cur.Version,
+        Items: []models.WasteItem{{Type: "paper", Weight: 1}, {Type: "plastic", Weight: 3}}})
+    if err != nil || len(o.Items[1].Attachments) != 1 {
+        t.Fatalf("expected the photo to survive the edit: %+v err %v", o, err)
+    }
+
+    proof := AttachmentInput{OrderID: "at1", UserID: "c1", Kind: models.AttachmentCompletionProof, ContentType: "image/png", Size: int64(len(png))}
+    if _, err := svc.CreateAttachmentUpload(proof); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("expected proofs to need the accepting collector, got %v", err)
+    }
+    _, _ = svc.AcceptOrder("at1", "c1")
+    if _, err := svc.UploadAttachment(photo, bytes.NewReader(png)); !errors.Is(err, ErrAttachmentClosed) {
+        t.Fatalf("expected item photos to close after acceptance, got %v", err)
+    }
+    ticket, err := svc.CreateAttachmentUpload(proof)
+    if err != nil || ticket.URL == "" {
+        t.Fatalf("create upload failed: %+v err %v", ticket, err)
+    }
+    if _, err := svc.CompleteAttachmentUpload(ticket.Attachment.ID, "c1"); !errors.Is(err, ErrUploadIncomplete) {
+        t.Fatalf("expected incomplete before the PUT, got %v", err)
+    }
+    blobs.data[ticket.Attachment.Key] = png // the client's PUT
+    o, err = svc.CompleteAttachmentUpload(ticket.Attachment.ID, "c1")
+    if err != nil || len(o.Attachments) != 1 {
+        t.Fatalf("complete failed: %+v err %v", o, err)
+    }
+    if o2, err := svc.CompleteAttachmentUpload(ticket.Attachment.ID, "c1"); err != nil || len(o2.Attachments) != 1 {
+        t.Fatalf("completing twice must not duplicate: %+v err %v", o2, err)
+    }
+
+    if _, _, err := svc.AttachmentURL(ticket.Attachment.ID, "c2", false); !errors.Is(err, ErrNotParticipant) {
+        t.Fatalf("proofs are private, got %v", err)
+    }
+    for _, viewer := range []string{"u1", "c1"} {
+        if url, _, err := svc.AttachmentURL(ticket.Attachment.ID, viewer, false); err != nil || url == "" {
+            t.Fatalf("%s should see the proof: %q %v", viewer, url, err)
+        }
+    }
+    if _, _, err := svc.AttachmentURL(ticket.Attachment.ID, "ops", true); err != nil {
+        t.Fatalf("admins see proofs: %v", err)
+    }
+    rc, a, err := svc.OpenAttachment(o.Items[1].Attachments[0].ID, "c2", false)
+    if err != nil || a.Kind != models.AttachmentItemPhoto {
+        t.Fatalf("item photos are public: %v", err)
+    }
+    rc.Close()
+}
+
+func TestOpTracesRepositoryCalls(t *testing.T) {
+    rec := tracetest.NewSpanRecorder()
+    tp := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(rec))
+    svc := NewService(NewInMemoryRepo(), WithTracerProvider(tp))
+    if _, err := svc.CreateOrder(validInput("tr1", "u1")); err != nil {
+        t.Fatal(err)
+    }
+    if len(rec.Ended()) != 0 {
+        t.Fatalf("calls outside Op must not be traced, got %d spans", len(rec.Ended()))
+    }
+
+    op, end := svc.Op(context.Background(), "AcceptOrder")
+    _, err := op.AcceptOrder("tr1", "c1")
+    end(err)
+    if err != nil {
+        t.Fatal(err)
+    }
+    op, end = svc.Op(context.Background(), "AcceptOrder")
+    _, err = op.AcceptOrder("missing", "c2")
+    end(err)
+    if err == nil {
+        t.Fatal("expected accepting a missing order to fail")
+    }
+
+    spans := rec.Ended()
+    var roots []sdktrace.ReadOnlySpan
+    children := map[trace.SpanID][]string{}
+    for _, sp := range spans {
+        if sp.Parent().IsValid() {
+            children[sp.Parent().SpanID()] = append(children[sp.Parent().SpanID()], sp.Name())
+        } else {
+            roots = append(roots, sp)
+        }
+    }
+    if len(roots) != 2 || roots[0].Name() != "Service.AcceptOrder" {
+        t.Fatalf("roots = %v", roots)
+    }
+    got := strings.Join(children[roots[0].SpanContext().SpanID()], ",")
+    if got != "Repository.FindActiveOrderByCollector,Repository.AtomicAccept" {
+        t.Errorf("children = %s", got)
+    }
+    if roots[0].Status().Code != codes.Unset {
+        t.Errorf("successful op status = %v", roots[0].Status())
+    }
+    if roots[1].Status().Code != codes.Error {
+        t.Errorf("failed op status = %v", roots[1].Status())
+    }
+
+    // without a tracer Op is free and returns the service itself
+    plain := NewService(NewInMemoryRepo())
+    if op, _ := plain.Op(context.Background(), "GetOrder"); op != plain {
+        t.Error("Op without a tracer must not copy the service")
+    }
+}
+
+func TestOpenOrderQuota(t *testing.T) {
+    svc := NewService(NewInMemoryRepo(), WithQuotaPolicy(QuotaPolicy{MaxOpenOrders: 2}))
+    for _, id := range []string{"q1", "q2"} {
+        if _, err := svc.CreateOrder(validInput(id, "u1")); err != nil {
+            t.Fatal(err)
+        }
+    }
+    var qerr *QuotaError
+    if _, err := svc.CreateOrder(validInput("q3", "u1")); !errors.As(err, &qerr) || !errors.Is(err, ErrQuotaExceeded) || qerr.Limit != 2 || qerr.Subject != "u1" {
+        t.Fatalf("expected the open_orders quota, got %v", err)
+    }
+    // other customers have their own quota
+    if _, err := svc.CreateOrder(validInput("q4", "u2")); err != nil {
+        t.Fatal(err)
+    }
+    // an accepted order leaves the pool and frees a slot
+    if _, err := svc.AcceptOrder("q1", "c1"); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := svc.CreateOrder(validInput("q3", "u1")); err != nil {
+        t.Fatalf("expected a free slot, got %v", err)
+    }
+
+    // replaying a created order is not a new order
+    in := validInput("q5", "u3")
+    in.IdempotencyKey = "k1"
+    svc = NewService(NewInMemoryRepo(), WithQuotaPolicy(QuotaPolicy{MaxOpenOrders: 1}))
+    first, err := svc.CreateOrder(in)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if again, err := svc.CreateOrder(in); err != nil || again.ID != first.ID {
+        t.Fatalf("replay = %v, %v", again, err)
+    }
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+    "errors"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var (
+    ErrAccountUnavailable = errors.New("account service unavailable")
+    ErrCustomerNotFound   = errors.New("customer not found")
+    ErrAddressNotFound    = errors.New("address not found for this customer")
+)
+
+// SnapshotSource resolves customer data owned by the account service.
+// Implementations return ErrAccountUnavailable when the data cannot be fetched right now.
+type SnapshotSource interface {
+    CustomerSnapshot(customerID string) (models.CustomerSnapshot, error)
+    // PickAddress returns one of the customer's saved addresses
+    PickAddress(customerID string, addressID uint64) (models.Address, error)
+}
+
+func WithSnapshotSource(src SnapshotSource) Option {
+    return func(s *Service) { s.snapshots = src }
+}
+
+// enrich fills snapshots server-side. While the account service is down the
+// client-supplied values are used; an address_id without a client address fails.
+func (s *Service) enrich(in *CreateOrderInput) error {
+    if s.snapshots == nil {
+        return nil
+    }
+    snap, err := s.snapshots.CustomerSnapshot(in.CustomerID)
+    switch {
+    case err == nil:
+        in.CustomerSnapshot = snap
+    case errors.Is(err, ErrAccountUnavailable):
+        // keep the client snapshot; it is display data only
+    default:
+        return err
+    }
+    if in.AddressID == 0 {
+        return nil
+    }
+    addr, err := s.snapshots.PickAddress(in.CustomerID, in.AddressID)
+    switch {
+    case err == nil:
+        in.Address = addr
+    case errors.Is(err, ErrAccountUnavailable) && in.Address.FullText != "":
+        // keep the client address
+    default:
+        return err
+    }
+    return nil
+}
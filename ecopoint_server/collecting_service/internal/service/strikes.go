@@ -0,0 +1,68 @@
+package service
+
+import (
+    "sync"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// StrikeStore records marks against collectors and the penalty state derived from them
+type StrikeStore interface {
+    AddStrike(st models.Strike) error
+    // ListStrikes returns strikes with At >= since, newest first
+    ListStrikes(collectorID string, since time.Time) ([]models.Strike, error)
+    // GetPenalty returns a zero penalty (no cooldown) for collectors without one
+    GetPenalty(collectorID string) (*models.CollectorPenalty, error)
+    SavePenalty(p *models.CollectorPenalty) error
+}
+
+type InMemoryStrikeStore struct {
+    mu        sync.Mutex
+    strikes   []models.Strike
+    penalties map[string]models.CollectorPenalty
+}
+
+func NewInMemoryStrikeStore() *InMemoryStrikeStore {
+    return &InMemoryStrikeStore{penalties: map[string]models.CollectorPenalty{}}
+}
+
+func (m *InMemoryStrikeStore) AddStrike(st models.Strike) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.strikes = append(m.strikes, st)
+    return nil
+}
+
+func (m *InMemoryStrikeStore) ListStrikes(collectorID string, since time.Time) ([]models.Strike, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    res := make([]models.Strike, 0)
+    for i := len(m.strikes) - 1; i >= 0; i-- {
+        if st := m.strikes[i]; st.CollectorID == collectorID && !st.At.Before(since) {
+            res = append(res, st)
+        }
+    }
+    return res, nil
+}
+
+func (m *InMemoryStrikeStore) GetPenalty(collectorID string) (*models.CollectorPenalty, error) {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    p, ok := m.penalties[collectorID]
+    if !ok {
+        p = models.CollectorPenalty{CollectorID: collectorID}
+    }
+    return &p, nil
+}
+
+func (m *InMemoryStrikeStore) SavePenalty(p *models.CollectorPenalty) error {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.penalties[p.CollectorID] = *p
+    return nil
+}
+
+func WithStrikeStore(store StrikeStore) Option {
+    return func(s *Service) { s.strikes = store }
+}
@@ -0,0 +1,169 @@
+package service
+
+import (
+    "context"
+    "time"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/trace"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// TracerName is the instrumentation scope of service and repository spans
+const TracerName = "ecopoint/collecting_service/internal/service"
+
+// WithTracerProvider enables spans for operations started with Op and for the Repository
+// calls they make
+func WithTracerProvider(tp trace.TracerProvider) Option {
+    return func(s *Service) { s.tracer = tp.Tracer(TracerName) }
+}
+
+// ContextBinder is implemented by repositories that can run their queries under a request
+// context, so database spans join the trace of the call
+type ContextBinder interface {
+    WithContext(ctx context.Context) Repository
+}
+
+// Op starts the span of one service operation and returns a copy of s whose Repository calls
+// are its children; end must be called with the operation's error. Without a tracer Op returns s.
+func (s *Service) Op(ctx context.Context, name string) (svc *Service, end func(error)) {
+    if s.tracer == nil {
+        return s, func(error) {}
+    }
+    ctx, span := s.tracer.Start(ctx, "Service."+name)
+    c := *s
+    c.repo = tracedRepo{ctx: ctx, tracer: s.tracer, next: s.repo}
+    return &c, func(err error) { endSpan(span, err) }
+}
+
+func endSpan(span trace.Span, err error) {
+    if err != nil {
+        span.RecordError(err)
+        span.SetStatus(codes.Error, err.Error())
+    }
+    span.End()
+}
+
+// tracedRepo wraps every Repository method in a span
+type tracedRepo struct {
+    ctx    context.Context
+    tracer trace.Tracer
+    next   Repository
+}
+
+func (r tracedRepo) start(method string, attrs ...attribute.KeyValue) (Repository, func(error)) {
+    ctx, span := r.tracer.Start(r.ctx, "Repository."+method, trace.WithAttributes(attrs...))
+    next := r.next
+    if b, ok := next.(ContextBinder); ok {
+        next = b.WithContext(ctx)
+    }
+    return next, func(err error) { endSpan(span, err) }
+}
+
+func orderAttr(id string) attribute.KeyValue { return attribute.String("order.id", id) }
+
+func collectorAttr(id string) attribute.KeyValue { return attribute.String("collector.id", id) }
+
+func (r tracedRepo) Create(order *models.Order) error {
+    next, end := r.start("Create", orderAttr(order.ID))
+    err := next.Create(order)
+    end(err)
+    return err
+}
+
+func (r tracedRepo) Get(id string) (*models.Order, error) {
+    next, end := r.start("Get", orderAttr(id))
+    o, err := next.Get(id)
+    end(err)
+    return o, err
+}
+
+func (r tracedRepo) ListAvailable(after *Cursor, limit int) ([]*models.Order, error) {
+    next, end := r.start("ListAvailable", attribute.Int("limit", limit))
+    list, err := next.ListAvailable(after, limit)
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) AtomicAccept(id string, collectorID string) (*models.Order, error) {
+    next, end := r.start("AtomicAccept", orderAttr(id), collectorAttr(collectorID))
+    o, err := next.AtomicAccept(id, collectorID)
+    end(err)
+    return o, err
+}
+
+// FindActiveOrderByCollector usually fails with "not found", which is the expected outcome
+// and is not marked as an error
+func (r tracedRepo) FindActiveOrderByCollector(collectorID string) (*models.Order, error) {
+    next, end := r.start("FindActiveOrderByCollector", collectorAttr(collectorID))
+    o, err := next.FindActiveOrderByCollector(collectorID)
+    end(nil)
+    return o, err
+}
+
+func (r tracedRepo) Update(order *models.Order) error {
+    next, end := r.start("Update", orderAttr(order.ID))
+    err := next.Update(order)
+    end(err)
+    return err
+}
+
+func (r tracedRepo) UpdateVersioned(order *models.Order, expectedVersion int64) error {
+    next, end := r.start("UpdateVersioned", orderAttr(order.ID), attribute.Int64("order.version", expectedVersion))
+    err := next.UpdateVersioned(order, expectedVersion)
+    end(err)
+    return err
+}
+
+func (r tracedRepo) ListStalled(status models.OrderStatus, cutoff time.Time, limit int) ([]*models.Order, error) {
+    next, end := r.start("ListStalled", attribute.String("order.status", string(status)), attribute.Int("limit", limit))
+    list, err := next.ListStalled(status, cutoff, limit)
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) ListAll() ([]*models.Order, error) {
+    next, end := r.start("ListAll")
+    list, err := next.ListAll()
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) ListByCustomer(customerID string, after *Cursor, limit int) ([]*models.Order, error) {
+    next, end := r.start("ListByCustomer", attribute.String("customer.id", customerID), attribute.Int("limit", limit))
+    list, err := next.ListByCustomer(customerID, after, limit)
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) CountByCustomer(customerID string, status models.OrderStatus) (int, error) {
+    next, end := r.start("CountByCustomer", attribute.String("customer.id", customerID), attribute.String("order.status", string(status)))
+    n, err := next.CountByCustomer(customerID, status)
+    end(err)
+    return n, err
+}
+
+func (r tracedRepo) ListActiveByCollector(collectorID string, after *Cursor, limit int) ([]*models.Order, error) {
+    next, end := r.start("ListActiveByCollector", collectorAttr(collectorID), attribute.Int("limit", limit))
+    list, err := next.ListActiveByCollector(collectorID, after, limit)
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) ListByCollector(collectorID string, f OrderFilter, after *Cursor, limit int) ([]*models.Order, error) {
+    next, end := r.start("ListByCollector", collectorAttr(collectorID), attribute.Int("limit", limit))
+    list, err := next.ListByCollector(collectorID, f, after, limit)
+    end(err)
+    return list, err
+}
+
+func (r tracedRepo) CollectorEarnings(collectorID string, q EarningsQuery) ([]EarningsBucket, error) {
+    next, end := r.start("CollectorEarnings", collectorAttr(collectorID))
+    b, err := next.CollectorEarnings(collectorID, q)
+    end(err)
+    return b, err
+}
+
+var _ Repository = tracedRepo{}
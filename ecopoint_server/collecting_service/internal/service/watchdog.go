@@ -0,0 +1,180 @@
+package service
+
+import (
+    "context"
+    "errors"
+    "time"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+type WatchdogAction string
+
+const (
+    WatchdogRelease WatchdogAction = "release" // back to created so another collector can take it
+    WatchdogCancel  WatchdogAction = "cancel"  // cancel with CancelBySystem
+)
+
+// WatchdogPolicy decides when an accepted order counts as abandoned
+type WatchdogPolicy struct {
+    AcceptTimeout time.Duration  `yaml:"accept_timeout"` // accepted, never moved to on_way
+    OnWayTimeout  time.Duration  `yaml:"on_way_timeout"` // on_way, never completed
+    Action        WatchdogAction `yaml:"action"`
+    BatchSize     int            `yaml:"batch_size"`     // orders handled per status per sweep
+}
+
+func DefaultWatchdogPolicy() WatchdogPolicy {
+    return WatchdogPolicy{
+        AcceptTimeout: 30 * time.Minute,
+        OnWayTimeout:  2 * time.Hour,
+        Action:        WatchdogRelease,
+        BatchSize:     100,
+    }
+}
+
+func WithWatchdogPolicy(p WatchdogPolicy) Option {
+    return func(s *Service) { s.watchdog = p }
+}
+
+// WithOrderTTL makes each sweep expire created orders nobody accepted within ttl, so every
+// expiry is recorded and observed; <= 0 leaves them to the repository's TTL backstop
+func WithOrderTTL(ttl time.Duration) Option {
+    return func(s *Service) { s.orderTTL = ttl }
+}
+
+type WatchdogReport struct {
+    Released  int
+    Cancelled int
+    Expired   int
+    Conflicts int // orders that progressed while being swept
+}
+
+// SweepStalledOrders expires created orders past the order TTL, then releases or cancels
+// orders whose collector made no progress in time, records a strike for each and notifies both parties
+func (s *Service) SweepStalledOrders(now time.Time) (WatchdogReport, error) {
+    var rep WatchdogReport
+    if s.orderTTL > 0 {
+        due, err := s.repo.ListStalled(models.StatusCreated, now.Add(-s.orderTTL), s.watchdog.BatchSize)
+        if err != nil {
+            return rep, err
+        }
+        for _, o := range due {
+            err := s.expire(o, now)
+            switch {
+            case errors.Is(err, models.ErrVersionConflict):
+                rep.Conflicts++
+            case err != nil:
+                return rep, err
+            default:
+                rep.Expired++
+            }
+        }
+    }
+    stages := []struct {
+        status  models.OrderStatus
+        timeout time.Duration
+        strike  models.StrikeKind
+    }{
+        {models.StatusAccepted, s.watchdog.AcceptTimeout, models.StrikeNoShow},
+        {models.StatusOnWay, s.watchdog.OnWayTimeout, models.StrikeAbandoned},
+    }
+    for _, st := range stages {
+        if st.timeout <= 0 {
+            continue
+        }
+        stalled, err := s.repo.ListStalled(st.status, now.Add(-st.timeout), s.watchdog.BatchSize)
+        if err != nil {
+            return rep, err
+        }
+        for _, o := range stalled {
+            err := s.releaseStalled(o, st.strike, now)
+            switch {
+            case errors.Is(err, models.ErrVersionConflict):
+                s.log.Debug("watchdog: order progressed while being swept", "order_id", o.ID)
+                rep.Conflicts++
+            case err != nil:
+                return rep, err
+            case s.watchdog.Action == WatchdogCancel:
+                rep.Cancelled++
+            default:
+                rep.Released++
+            }
+        }
+    }
+    return rep, nil
+}
+
+func (s *Service) releaseStalled(o *models.Order, kind models.StrikeKind, now time.Time) error {
+    collectorID := valueOrEmpty(o.AcceptedBy)
+    next := o.Clone()
+    next.UpdatedAt = now
+    next.Version++
+    event := models.EventOrderReleased
+    if s.watchdog.Action == WatchdogCancel {
+        next.Record(models.StatusCancelled, now, models.ActorSystem, string(kind))
+        next.Status = models.StatusCancelled
+        next.CancelSide = models.CancelBySystem
+        next.CancelReason = string(kind)
+        event = models.EventOrderCancelled
+    } else {
+        next.Record(models.StatusCreated, now, models.ActorSystem, string(kind))
+        next.Status = models.StatusCreated
+        next.AcceptedBy = nil
+        next.AcceptedAt = nil
+        next.OnWayAt = nil
+    }
+    if err := s.repo.UpdateVersioned(next, o.Version); err != nil {
+        return err
+    }
+    // the release is stored: both sides must hear of it and the sweep must go on
+    if collectorID != "" {
+        if err := s.addStrike(collectorID, o.ID, kind, now); err != nil {
+            s.log.Error("record watchdog strike", "order", next, "collector_id", collectorID, "error", err)
+        }
+    }
+    s.publish(event, next, collectorID, models.ActorSystem, string(kind))
+    return nil
+}
+
+// expire cancels a created order nobody accepted in time
+func (s *Service) expire(o *models.Order, now time.Time) error {
+    next := o.Clone()
+    next.Record(models.StatusCancelled, now, models.ActorSystem, "expired")
+    next.Status = models.StatusCancelled
+    next.CancelSide = models.CancelBySystem
+    next.CancelReason = "expired"
+    next.UpdatedAt = now
+    next.Version++
+    if err := s.repo.UpdateVersioned(next, o.Version); err != nil {
+        return err
+    }
+    s.observer.OrderExpired(next)
+    return nil
+}
+
+// RunWatchdog sweeps every interval until ctx is cancelled; a non-positive interval disables it
+func (s *Service) RunWatchdog(ctx context.Context, interval time.Duration, report func(WatchdogReport, error)) {
+    if interval <= 0 {
+        return
+    }
+    t := time.NewTicker(interval)
+    defer t.Stop()
+    for {
+        select {
+        case <-ctx.Done():
+            return
+        case now := <-t.C:
+            rep, err := s.SweepStalledOrders(now)
+            if report != nil {
+                report(rep, err)
+            }
+        }
+    }
+}
+
+func valueOrEmpty(p *string) string {
+    if p == nil {
+        return ""
+    }
+    return *p
+}
@@ -0,0 +1,91 @@
+// Package tracing builds the OpenTelemetry tracer provider of the service. Spans are exported
+// over OTLP/gRPC to a collector, or pretty-printed to stdout for local runs.
+package tracing
+
+import (
+    "context"
+    "fmt"
+    "io"
+    "os"
+    "strings"
+
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+    "go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/trace"
+    "go.opentelemetry.io/otel/trace/noop"
+)
+
+// Options selects the exporter and sampling of the tracer provider
+type Options struct {
+    Exporter    string  `yaml:"exporter"`     // otlp | stdout | none
+    Endpoint    string  `yaml:"endpoint"`     // OTLP gRPC collector, host:port or URL
+    Insecure    bool    `yaml:"insecure"`     // plaintext connection to the collector
+    SampleRatio float64 `yaml:"sample_ratio"` // share of new traces kept; sampled parents are always followed
+    ServiceName string  `yaml:"service_name"`
+}
+
+// Provider is a tracer provider that must be shut down to flush buffered spans
+type Provider interface {
+    trace.TracerProvider
+    Shutdown(ctx context.Context) error
+}
+
+type noopProvider struct{ noop.TracerProvider }
+
+func (noopProvider) Shutdown(context.Context) error { return nil }
+
+// New builds the provider and installs it, with W3C trace context propagation, as the
+// otel global. Exporter "none" returns a provider that records nothing.
+func New(ctx context.Context, o Options) (Provider, error) {
+    var (
+        exp sdktrace.SpanExporter
+        err error
+    )
+    switch o.Exporter {
+    case "otlp":
+        exp, err = otlptracegrpc.New(ctx, otlpOptions(o)...)
+    case "stdout":
+        exp, err = stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+    case "none", "":
+        return noopProvider{}, nil
+    default:
+        return nil, fmt.Errorf("unknown trace exporter %q", o.Exporter)
+    }
+    if err != nil {
+        return nil, fmt.Errorf("trace exporter %s: %w", o.Exporter, err)
+    }
+    res, err := resource.Merge(resource.Default(), resource.NewSchemaless(attribute.String("service.name", o.ServiceName)))
+    if err != nil {
+        return nil, err
+    }
+    tp := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exp),
+        sdktrace.WithResource(res),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(o.SampleRatio))),
+    )
+    otel.SetTracerProvider(tp)
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+    return tp, nil
+}
+
+// stdout is where the stdout exporter writes; tests replace it
+var stdout io.Writer = os.Stdout
+
+// otlpOptions accepts both a bare host:port and the http(s):// URL form of OTEL_EXPORTER_OTLP_ENDPOINT
+func otlpOptions(o Options) []otlptracegrpc.Option {
+    var opts []otlptracegrpc.Option
+    if strings.Contains(o.Endpoint, "://") {
+        opts = append(opts, otlptracegrpc.WithEndpointURL(o.Endpoint))
+    } else if o.Endpoint != "" {
+        opts = append(opts, otlptracegrpc.WithEndpoint(o.Endpoint))
+    }
+    if o.Insecure {
+        opts = append(opts, otlptracegrpc.WithInsecure())
+    }
+    return opts
+}
@@ -0,0 +1,41 @@
+package tracing
+
+import (
+    "bytes"
+    "context"
+    "strings"
+    "testing"
+)
+
+func TestStdoutExporter(t *testing.T) {
+    var buf bytes.Buffer
+    stdout = &buf
+    tp, err := New(context.Background(), Options{Exporter: "stdout", SampleRatio: 1, ServiceName: "collecting_test"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, span := tp.Tracer("test").Start(context.Background(), "Service.GetOrder")
+    span.End()
+    if err := tp.Shutdown(context.Background()); err != nil {
+        t.Fatal(err)
+    }
+    for _, want := range []string{`"Name": "Service.GetOrder"`, "collecting_test"} {
+        if !strings.Contains(buf.String(), want) {
+            t.Errorf("missing %s in %s", want, buf.String())
+        }
+    }
+}
+
+func TestNoneAndUnknownExporters(t *testing.T) {
+    tp, err := New(context.Background(), Options{Exporter: "none"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, span := tp.Tracer("test").Start(context.Background(), "x")
+    if span.SpanContext().IsValid() {
+        t.Error("none exporter must not record spans")
+    }
+    if _, err := New(context.Background(), Options{Exporter: "zipkin"}); err == nil {
+        t.Error("expected an error for an unknown exporter")
+    }
+}
@@ -0,0 +1,35 @@
+package validation
+
+import (
+    "strings"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// Attachment checks the declared type and size of a new upload; existing is how many
+// attachments the order already has
+func (v *Validator) Attachment(a *models.Attachment, existing int) error {
+    e := &Error{}
+    if len(v.attachmentTypes) > 0 && !v.attachmentTypes[a.ContentType] {
+        e.add("content_type", "must be one of %s", strings.Join(v.limits.AttachmentTypes, ", "))
+    }
+    if a.Size <= 0 {
+        e.add("size", "must be greater than 0")
+    } else if v.limits.MaxAttachmentSize > 0 && a.Size > v.limits.MaxAttachmentSize {
+        e.add("size", "must be at most %d bytes", v.limits.MaxAttachmentSize)
+    }
+    if v.limits.MaxAttachments > 0 && existing >= v.limits.MaxAttachments {
+        e.add("order_id", "already has the maximum of %d attachments", v.limits.MaxAttachments)
+    }
+    if len(e.Violations) > 0 {
+        return e
+    }
+    return nil
+}
+
+// Invalid builds an *Error for a single field, for checks made outside this package
+func Invalid(field, format string, args ...any) error {
+    e := &Error{}
+    e.add(field, format, args...)
+    return e
+}
@@ -0,0 +1,22 @@
+package validation
+
+import (
+    "strings"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// ChatMessage checks the text of a chat message
+func (v *Validator) ChatMessage(m *models.ChatMessage) error {
+    e := &Error{}
+    if strings.TrimSpace(m.Text) == "" {
+        e.add("text", "must not be empty")
+    }
+    if v.limits.MaxChatLength > 0 && len([]rune(m.Text)) > v.limits.MaxChatLength {
+        e.add("text", "must be at most %d characters", v.limits.MaxChatLength)
+    }
+    if len(e.Violations) > 0 {
+        return e
+    }
+    return nil
+}
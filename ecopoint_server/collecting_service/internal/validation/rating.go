@@ -0,0 +1,33 @@
+package validation
+
+import (
+    "fmt"
+    "regexp"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+var tagPattern = regexp.MustCompile(`^[a-z0-9_-]{1,32}$`)
+
+// Rating checks stars, tags and comment; callers lowercase and dedupe tags first
+func (v *Validator) Rating(r *models.Rating) error {
+    e := &Error{}
+    if r.Stars < 1 || r.Stars > 5 {
+        e.add("stars", "must be between 1 and 5")
+    }
+    if v.limits.MaxRatingTags > 0 && len(r.Tags) > v.limits.MaxRatingTags {
+        e.add("tags", "at most %d tags are allowed", v.limits.MaxRatingTags)
+    }
+    for i, t := range r.Tags {
+        if !tagPattern.MatchString(t) {
+            e.add(fmt.Sprintf("tags[%d]", i), "must be 1-32 characters of a-z, 0-9, _ or -")
+        }
+    }
+    if v.limits.MaxCommentLength > 0 && len([]rune(r.Comment)) > v.limits.MaxCommentLength {
+        e.add("comment", "must be at most %d characters", v.limits.MaxCommentLength)
+    }
+    if len(e.Violations) > 0 {
+        return e
+    }
+    return nil
+}
@@ -0,0 +1,163 @@
+package validation
+
+import (
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+// Violation describes one invalid field, using request field paths (e.g. items[0].weight)
+type Violation struct {
+    Field       string
+    Description string
+}
+
+// Error carries every violation found in a single request
+type Error struct {
+    Violations []Violation
+}
+
+func (e *Error) Error() string {
+    parts := make([]string, 0, len(e.Violations))
+    for _, v := range e.Violations {
+        parts = append(parts, v.Field+": "+v.Description)
+    }
+    return "invalid request: " + strings.Join(parts, "; ")
+}
+
+func (e *Error) add(field, format string, args ...any) {
+    e.Violations = append(e.Violations, Violation{Field: field, Description: fmt.Sprintf(format, args...)})
+}
+
+// Limits are the configurable bounds applied to order input
+type Limits struct {
+    MaxItems          int                `yaml:"max_items"`
+    MaxWeightPerType  map[string]float64 `yaml:"max_weight_per_type"` // kg per waste type, summed over all items; missing type = no cap
+    WasteTypes        []string           `yaml:"waste_types"`         // accepted item types; empty = any non-empty type
+    WeightTolerance   float64            `yaml:"weight_tolerance"`    // allowed |total_weight - sum(items)| in kg
+    MaxNoteLength     int                `yaml:"max_note_length"`
+    MaxRatingTags     int                `yaml:"max_rating_tags"`
+    MaxCommentLength  int                `yaml:"max_comment_length"`
+    MaxChatLength     int                `yaml:"max_chat_length"`
+    MaxAttachmentSize int64              `yaml:"max_attachment_size"` // bytes per file
+    AttachmentTypes   []string           `yaml:"attachment_types"`    // accepted content types; empty = any
+    MaxAttachments    int                `yaml:"max_attachments"`     // per order, item photos and completion proofs together
+}
+
+func DefaultLimits() Limits {
+    return Limits{
+        MaxItems:         20,
+        MaxWeightPerType: map[string]float64{
+            "plastic":    100,
+            "paper":      200,
+            "metal":      200,
+            "glass":      100,
+            "electronic": 50,
+        },
+        WasteTypes:       []string{"plastic", "paper", "metal", "glass", "electronic"},
+        WeightTolerance:  0.01,
+        MaxNoteLength:    500,
+        MaxRatingTags:    5,
+        MaxCommentLength: 500,
+        MaxChatLength:    1000,
+        MaxAttachmentSize: 10 << 20,
+        AttachmentTypes:   []string{"image/jpeg", "image/png", "image/webp"},
+        MaxAttachments:    10,
+    }
+}
+
+// Validator checks orders against Limits before they are stored
+type Validator struct {
+    limits          Limits
+    types           map[string]bool
+    attachmentTypes map[string]bool
+}
+
+func New(limits Limits) *Validator {
+    types := make(map[string]bool, len(limits.WasteTypes))
+    for _, t := range limits.WasteTypes {
+        types[t] = true
+    }
+    attachmentTypes := make(map[string]bool, len(limits.AttachmentTypes))
+    for _, t := range limits.AttachmentTypes {
+        attachmentTypes[t] = true
+    }
+    return &Validator{limits: limits, types: types, attachmentTypes: attachmentTypes}
+}
+
+// Order validates the customer-supplied parts of an order. It returns *Error or nil.
+func (v *Validator) Order(o *models.Order) error {
+    e := &Error{}
+    if strings.TrimSpace(o.CustomerID) == "" {
+        e.add("customer_id", "is required")
+    }
+
+    a := o.PickAddressSnapshot
+    if strings.TrimSpace(a.FullText) == "" {
+        e.add("pick_address.full_text", "is required")
+    }
+    if math.IsNaN(a.Lat) || a.Lat < -90 || a.Lat > 90 {
+        e.add("pick_address.lat", "must be between -90 and 90")
+    }
+    if math.IsNaN(a.Lng) || a.Lng < -180 || a.Lng > 180 {
+        e.add("pick_address.lng", "must be between -180 and 180")
+    }
+
+    if len(o.Items) == 0 {
+        e.add("items", "at least one item is required")
+    }
+    if v.limits.MaxItems > 0 && len(o.Items) > v.limits.MaxItems {
+        e.add("items", "at most %d items are allowed, got %d", v.limits.MaxItems, len(o.Items))
+    }
+    sum := 0.0
+    badWeight := false
+    perType := map[string]float64{}
+    for i, it := range o.Items {
+        field := fmt.Sprintf("items[%d]", i)
+        switch {
+        case strings.TrimSpace(it.Type) == "":
+            e.add(field+".type", "is required")
+        case len(v.types) > 0 && !v.types[it.Type]:
+            e.add(field+".type", "unknown waste type %q", it.Type)
+        }
+        if !isFinite(it.Weight) || it.Weight <= 0 {
+            e.add(field+".weight", "must be greater than 0")
+            badWeight = true
+            continue
+        }
+        sum += it.Weight
+        perType[it.Type] += it.Weight
+    }
+    types := make([]string, 0, len(perType))
+    for t := range perType {
+        types = append(types, t)
+    }
+    sort.Strings(types)
+    for _, t := range types {
+        if max, ok := v.limits.MaxWeightPerType[t]; ok && perType[t] > max {
+            e.add("items", "total weight for %q is %.2f kg, limit is %.2f kg", t, perType[t], max)
+        }
+    }
+
+    if !isFinite(o.TotalWeight) || o.TotalWeight < 0 {
+        e.add("total_weight", "must not be negative")
+    } else if len(o.Items) > 0 && !badWeight && math.Abs(o.TotalWeight-sum) > v.limits.WeightTolerance {
+        e.add("total_weight", "is %.2f kg but items sum to %.2f kg", o.TotalWeight, sum)
+    }
+    if !isFinite(o.EstimatedPrice) || o.EstimatedPrice < 0 {
+        e.add("estimated_price", "must not be negative")
+    }
+    if v.limits.MaxNoteLength > 0 && len([]rune(o.Note)) > v.limits.MaxNoteLength {
+        e.add("note", "must be at most %d characters", v.limits.MaxNoteLength)
+    }
+
+    if len(e.Violations) > 0 {
+        return e
+    }
+    return nil
+}
+
+func isFinite(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) }
@@ -0,0 +1,129 @@
+package validation
+
+import (
+    "errors"
+    "math"
+    "testing"
+
+    "ecopoint/collecting_service/internal/models"
+)
+
+func validOrder() *models.Order {
+    return &models.Order{
+        CustomerID:          "u1",
+        PickAddressSnapshot: models.Address{FullText: "1 Demo St", Lat: 10.77, Lng: 106.70},
+        Items:               []models.WasteItem{{Type: "plastic", Weight: 1.2}, {Type: "paper", Weight: 0.8}},
+        TotalWeight:         2.0,
+        EstimatedPrice:      50000,
+    }
+}
+
+func TestValidatorOrder(t *testing.T) {
+    tests := []struct {
+        name   string
+        mutate func(o *models.Order)
+        fields []string // expected violation fields, in order; nil = valid
+    }{
+        {"valid", func(o *models.Order) {}, nil},
+        {"missing customer", func(o *models.Order) { o.CustomerID = " " }, []string{"customer_id"}},
+        {"missing address text", func(o *models.Order) { o.PickAddressSnapshot.FullText = "" }, []string{"pick_address.full_text"}},
+        {"lat out of range", func(o *models.Order) { o.PickAddressSnapshot.Lat = 91 }, []string{"pick_address.lat"}},
+        {"lng out of range", func(o *models.Order) { o.PickAddressSnapshot.Lng = -181 }, []string{"pick_address.lng"}},
+        {"lat NaN", func(o *models.Order) { o.PickAddressSnapshot.Lat = math.NaN() }, []string{"pick_address.lat"}},
+        {"no items", func(o *models.Order) { o.Items = nil; o.TotalWeight = 0 }, []string{"items"}},
+        {"too many items", func(o *models.Order) {
+            o.Items = make([]models.WasteItem, 4)
+            for i := range o.Items {
+                o.Items[i] = models.WasteItem{Type: "paper", Weight: 1}
+            }
+            o.TotalWeight = 4
+        }, []string{"items"}},
+        {"empty item type", func(o *models.Order) { o.Items[0].Type = "" }, []string{"items[0].type"}},
+        {"unknown item type", func(o *models.Order) { o.Items[1].Type = "uranium" }, []string{"items[1].type"}},
+        {"negative weight", func(o *models.Order) { o.Items[0].Weight = -1 }, []string{"items[0].weight"}},
+        {"zero weight", func(o *models.Order) { o.Items[0].Weight = 0 }, []string{"items[0].weight"}},
+        {"infinite weight", func(o *models.Order) { o.Items[0].Weight = math.Inf(1) }, []string{"items[0].weight"}},
+        {"per type cap", func(o *models.Order) {
+            o.Items = []models.WasteItem{{Type: "plastic", Weight: 6}, {Type: "plastic", Weight: 5}}
+            o.TotalWeight = 11
+        }, []string{"items"}},
+        {"total disagrees", func(o *models.Order) { o.TotalWeight = 5 }, []string{"total_weight"}},
+        {"total within tolerance", func(o *models.Order) { o.TotalWeight = 2.005 }, nil},
+        {"negative total", func(o *models.Order) { o.TotalWeight = -2 }, []string{"total_weight"}},
+        {"negative price", func(o *models.Order) { o.EstimatedPrice = -1 }, []string{"estimated_price"}},
+        {"note too long", func(o *models.Order) { o.Note = "abcdefghijk" }, []string{"note"}},
+        {"multiple", func(o *models.Order) { o.CustomerID = ""; o.PickAddressSnapshot.Lat = -100 }, []string{"customer_id", "pick_address.lat"}},
+    }
+
+    v := New(Limits{
+        MaxItems:         3,
+        MaxWeightPerType: map[string]float64{"plastic": 10},
+        WasteTypes:       []string{"plastic", "paper"},
+        WeightTolerance:  0.01,
+        MaxNoteLength:    10,
+    })
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            o := validOrder()
+            tc.mutate(o)
+            err := v.Order(o)
+            if tc.fields == nil {
+                if err != nil {
+                    t.Fatalf("expected valid, got %v", err)
+                }
+                return
+            }
+            var verr *Error
+            if !errors.As(err, &verr) {
+                t.Fatalf("expected *Error, got %v", err)
+            }
+            if len(verr.Violations) != len(tc.fields) {
+                t.Fatalf("expected fields %v, got %+v", tc.fields, verr.Violations)
+            }
+            for i, f := range tc.fields {
+                if verr.Violations[i].Field != f {
+                    t.Fatalf("violation %d: expected field %s, got %s", i, f, verr.Violations[i].Field)
+                }
+            }
+        })
+    }
+}
+
+func TestEmptyWasteTypesAcceptsAny(t *testing.T) {
+    v := New(Limits{WeightTolerance: 0.01})
+    o := validOrder()
+    o.Items[0].Type = "textile"
+    if err := v.Order(o); err != nil {
+        t.Fatalf("expected any type accepted, got %v", err)
+    }
+}
+
+func TestValidatorRating(t *testing.T) {
+    v := New(DefaultLimits())
+    tests := []struct {
+        name   string
+        r      models.Rating
+        fields []string
+    }{
+        {"valid", models.Rating{Stars: 5, Tags: []string{"on_time", "friendly-1"}, Comment: "thanks"}, nil},
+        {"zero stars", models.Rating{Stars: 0}, []string{"stars"}},
+        {"six stars", models.Rating{Stars: 6}, []string{"stars"}},
+        {"bad tag", models.Rating{Stars: 3, Tags: []string{"Nice!"}}, []string{"tags[0]"}},
+        {"too many tags", models.Rating{Stars: 3, Tags: []string{"a", "b", "c", "d", "e", "f"}}, []string{"tags"}},
+    }
+    for _, tc := range tests {
+        t.Run(tc.name, func(t *testing.T) {
+            err := v.Rating(&tc.r)
+            var verr *Error
+            if tc.fields == nil {
+                if err != nil {
+                    t.Fatalf("expected valid, got %v", err)
+                }
+                return
+            }
+            if !errors.As(err, &verr) || len(verr.Violations) != len(tc.fields) || verr.Violations[0].Field != tc.fields[0] {
+                t.Fatalf("expected %v, got %v", tc.fields, err)
+            }
+        })
+    }
+}
@@ -21,6 +21,266 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type OrderStatus int32
+
+const (
+	OrderStatus_ORDER_STATUS_UNSPECIFIED OrderStatus = 0
+	OrderStatus_ORDER_STATUS_CREATED     OrderStatus = 1
+	OrderStatus_ORDER_STATUS_ACCEPTED    OrderStatus = 2
+	OrderStatus_ORDER_STATUS_ON_WAY      OrderStatus = 3
+	OrderStatus_ORDER_STATUS_COMPLETE    OrderStatus = 4
+	OrderStatus_ORDER_STATUS_CANCELLED   OrderStatus = 5
+)
+
+// Enum value maps for OrderStatus.
+var (
+	OrderStatus_name = map[int32]string{
+		0: "ORDER_STATUS_UNSPECIFIED",
+		1: "ORDER_STATUS_CREATED",
+		2: "ORDER_STATUS_ACCEPTED",
+		3: "ORDER_STATUS_ON_WAY",
+		4: "ORDER_STATUS_COMPLETE",
+		5: "ORDER_STATUS_CANCELLED",
+	}
+	OrderStatus_value = map[string]int32{
+		"ORDER_STATUS_UNSPECIFIED": 0,
+		"ORDER_STATUS_CREATED":     1,
+		"ORDER_STATUS_ACCEPTED":    2,
+		"ORDER_STATUS_ON_WAY":      3,
+		"ORDER_STATUS_COMPLETE":    4,
+		"ORDER_STATUS_CANCELLED":   5,
+	}
+)
+
+func (x OrderStatus) Enum() *OrderStatus {
+	p := new(OrderStatus)
+	*p = x
+	return p
+}
+
+func (x OrderStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (OrderStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_collecting_proto_enumTypes[0].Descriptor()
+}
+
+func (OrderStatus) Type() protoreflect.EnumType {
+	return &file_collecting_proto_enumTypes[0]
+}
+
+func (x OrderStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use OrderStatus.Descriptor instead.
+func (OrderStatus) EnumDescriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{0}
+}
+
+type CancelSide int32
+
+const (
+	CancelSide_CANCEL_SIDE_UNSPECIFIED CancelSide = 0
+	CancelSide_CANCEL_SIDE_CUSTOMER    CancelSide = 1
+	CancelSide_CANCEL_SIDE_COLLECTOR   CancelSide = 2
+	CancelSide_CANCEL_SIDE_SYSTEM      CancelSide = 3
+)
+
+// Enum value maps for CancelSide.
+var (
+	CancelSide_name = map[int32]string{
+		0: "CANCEL_SIDE_UNSPECIFIED",
+		1: "CANCEL_SIDE_CUSTOMER",
+		2: "CANCEL_SIDE_COLLECTOR",
+		3: "CANCEL_SIDE_SYSTEM",
+	}
+	CancelSide_value = map[string]int32{
+		"CANCEL_SIDE_UNSPECIFIED": 0,
+		"CANCEL_SIDE_CUSTOMER":    1,
+		"CANCEL_SIDE_COLLECTOR":   2,
+		"CANCEL_SIDE_SYSTEM":      3,
+	}
+)
+
+func (x CancelSide) Enum() *CancelSide {
+	p := new(CancelSide)
+	*p = x
+	return p
+}
+
+func (x CancelSide) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CancelSide) Descriptor() protoreflect.EnumDescriptor {
+	return file_collecting_proto_enumTypes[1].Descriptor()
+}
+
+func (CancelSide) Type() protoreflect.EnumType {
+	return &file_collecting_proto_enumTypes[1]
+}
+
+func (x CancelSide) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CancelSide.Descriptor instead.
+func (CancelSide) EnumDescriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{1}
+}
+
+type EarningsGroupBy int32
+
+const (
+	EarningsGroupBy_EARNINGS_GROUP_BY_UNSPECIFIED EarningsGroupBy = 0 // day
+	EarningsGroupBy_EARNINGS_GROUP_BY_DAY         EarningsGroupBy = 1
+	EarningsGroupBy_EARNINGS_GROUP_BY_WEEK        EarningsGroupBy = 2 // weeks start on Monday
+	EarningsGroupBy_EARNINGS_GROUP_BY_MONTH       EarningsGroupBy = 3
+)
+
+// Enum value maps for EarningsGroupBy.
+var (
+	EarningsGroupBy_name = map[int32]string{
+		0: "EARNINGS_GROUP_BY_UNSPECIFIED",
+		1: "EARNINGS_GROUP_BY_DAY",
+		2: "EARNINGS_GROUP_BY_WEEK",
+		3: "EARNINGS_GROUP_BY_MONTH",
+	}
+	EarningsGroupBy_value = map[string]int32{
+		"EARNINGS_GROUP_BY_UNSPECIFIED": 0,
+		"EARNINGS_GROUP_BY_DAY":         1,
+		"EARNINGS_GROUP_BY_WEEK":        2,
+		"EARNINGS_GROUP_BY_MONTH":       3,
+	}
+)
+
+func (x EarningsGroupBy) Enum() *EarningsGroupBy {
+	p := new(EarningsGroupBy)
+	*p = x
+	return p
+}
+
+func (x EarningsGroupBy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (EarningsGroupBy) Descriptor() protoreflect.EnumDescriptor {
+	return file_collecting_proto_enumTypes[2].Descriptor()
+}
+
+func (EarningsGroupBy) Type() protoreflect.EnumType {
+	return &file_collecting_proto_enumTypes[2]
+}
+
+func (x EarningsGroupBy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use EarningsGroupBy.Descriptor instead.
+func (EarningsGroupBy) EnumDescriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{2}
+}
+
+type UserRole int32
+
+const (
+	UserRole_USER_ROLE_UNSPECIFIED UserRole = 0
+	UserRole_USER_ROLE_CUSTOMER    UserRole = 1
+	UserRole_USER_ROLE_COLLECTOR   UserRole = 2
+)
+
+// Enum value maps for UserRole.
+var (
+	UserRole_name = map[int32]string{
+		0: "USER_ROLE_UNSPECIFIED",
+		1: "USER_ROLE_CUSTOMER",
+		2: "USER_ROLE_COLLECTOR",
+	}
+	UserRole_value = map[string]int32{
+		"USER_ROLE_UNSPECIFIED": 0,
+		"USER_ROLE_CUSTOMER":    1,
+		"USER_ROLE_COLLECTOR":   2,
+	}
+)
+
+func (x UserRole) Enum() *UserRole {
+	p := new(UserRole)
+	*p = x
+	return p
+}
+
+func (x UserRole) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (UserRole) Descriptor() protoreflect.EnumDescriptor {
+	return file_collecting_proto_enumTypes[3].Descriptor()
+}
+
+func (UserRole) Type() protoreflect.EnumType {
+	return &file_collecting_proto_enumTypes[3]
+}
+
+func (x UserRole) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use UserRole.Descriptor instead.
+func (UserRole) EnumDescriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{3}
+}
+
+type AttachmentKind int32
+
+const (
+	AttachmentKind_ATTACHMENT_KIND_UNSPECIFIED      AttachmentKind = 0
+	AttachmentKind_ATTACHMENT_KIND_ITEM_PHOTO       AttachmentKind = 1 // customer, while the order is in the pool
+	AttachmentKind_ATTACHMENT_KIND_COMPLETION_PROOF AttachmentKind = 2 // accepting collector, from acceptance on
+)
+
+// Enum value maps for AttachmentKind.
+var (
+	AttachmentKind_name = map[int32]string{
+		0: "ATTACHMENT_KIND_UNSPECIFIED",
+		1: "ATTACHMENT_KIND_ITEM_PHOTO",
+		2: "ATTACHMENT_KIND_COMPLETION_PROOF",
+	}
+	AttachmentKind_value = map[string]int32{
+		"ATTACHMENT_KIND_UNSPECIFIED":      0,
+		"ATTACHMENT_KIND_ITEM_PHOTO":       1,
+		"ATTACHMENT_KIND_COMPLETION_PROOF": 2,
+	}
+)
+
+func (x AttachmentKind) Enum() *AttachmentKind {
+	p := new(AttachmentKind)
+	*p = x
+	return p
+}
+
+func (x AttachmentKind) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AttachmentKind) Descriptor() protoreflect.EnumDescriptor {
+	return file_collecting_proto_enumTypes[4].Descriptor()
+}
+
+func (AttachmentKind) Type() protoreflect.EnumType {
+	return &file_collecting_proto_enumTypes[4]
+}
+
+func (x AttachmentKind) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AttachmentKind.Descriptor instead.
+func (AttachmentKind) EnumDescriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{4}
+}
+
 type Empty struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	unknownFields protoimpl.UnknownFields
@@ -173,6 +433,7 @@ type WasteItem struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
 	Weight        float64                `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	Attachments   []*Attachment          `protobuf:"bytes,3,rep,name=attachments,proto3" json:"attachments,omitempty"` // photos; ignored on input
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -221,23 +482,40 @@ func (x *WasteItem) GetWeight() float64 {
 	return 0
 }
 
+func (x *WasteItem) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
 type Order struct {
-	state               protoimpl.MessageState `protogen:"open.v1"`
-	Id                  string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
-	CustomerId          string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
-	Status              string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"`
-	AcceptedBy          string                 `protobuf:"bytes,4,opt,name=accepted_by,json=acceptedBy,proto3" json:"accepted_by,omitempty"`
-	PickAddressSnapshot *Address               `protobuf:"bytes,5,opt,name=pick_address_snapshot,json=pickAddressSnapshot,proto3" json:"pick_address_snapshot,omitempty"`
-	CustomerSnapshot    *CustomerSnapshot      `protobuf:"bytes,6,opt,name=customer_snapshot,json=customerSnapshot,proto3" json:"customer_snapshot,omitempty"`
-	Items               []*WasteItem           `protobuf:"bytes,7,rep,name=items,proto3" json:"items,omitempty"`
-	TotalWeight         float64                `protobuf:"fixed64,8,opt,name=total_weight,json=totalWeight,proto3" json:"total_weight,omitempty"`
-	EstimatedPrice      float64                `protobuf:"fixed64,9,opt,name=estimated_price,json=estimatedPrice,proto3" json:"estimated_price,omitempty"`
-	DistanceKm          float64                `protobuf:"fixed64,10,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
-	EtaMinutes          int32                  `protobuf:"varint,11,opt,name=eta_minutes,json=etaMinutes,proto3" json:"eta_minutes,omitempty"`
-	Note                string                 `protobuf:"bytes,12,opt,name=note,proto3" json:"note,omitempty"`
-	Version             int64                  `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
-	unknownFields       protoimpl.UnknownFields
-	sizeCache           protoimpl.SizeCache
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	Id         string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	CustomerId string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	// Deprecated: Marked as deprecated in collecting.proto.
+	Status              string            `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // use order_status
+	AcceptedBy          string            `protobuf:"bytes,4,opt,name=accepted_by,json=acceptedBy,proto3" json:"accepted_by,omitempty"`
+	PickAddressSnapshot *Address          `protobuf:"bytes,5,opt,name=pick_address_snapshot,json=pickAddressSnapshot,proto3" json:"pick_address_snapshot,omitempty"`
+	CustomerSnapshot    *CustomerSnapshot `protobuf:"bytes,6,opt,name=customer_snapshot,json=customerSnapshot,proto3" json:"customer_snapshot,omitempty"`
+	Items               []*WasteItem      `protobuf:"bytes,7,rep,name=items,proto3" json:"items,omitempty"`
+	TotalWeight         float64           `protobuf:"fixed64,8,opt,name=total_weight,json=totalWeight,proto3" json:"total_weight,omitempty"`
+	EstimatedPrice      float64           `protobuf:"fixed64,9,opt,name=estimated_price,json=estimatedPrice,proto3" json:"estimated_price,omitempty"`
+	DistanceKm          float64           `protobuf:"fixed64,10,opt,name=distance_km,json=distanceKm,proto3" json:"distance_km,omitempty"`
+	EtaMinutes          int32             `protobuf:"varint,11,opt,name=eta_minutes,json=etaMinutes,proto3" json:"eta_minutes,omitempty"`
+	Note                string            `protobuf:"bytes,12,opt,name=note,proto3" json:"note,omitempty"`
+	Version             int64             `protobuf:"varint,13,opt,name=version,proto3" json:"version,omitempty"`
+	OrderStatus         OrderStatus       `protobuf:"varint,14,opt,name=order_status,json=orderStatus,proto3,enum=ecopoint.collecting.v1.OrderStatus" json:"order_status,omitempty"`
+	CancelSide          CancelSide        `protobuf:"varint,15,opt,name=cancel_side,json=cancelSide,proto3,enum=ecopoint.collecting.v1.CancelSide" json:"cancel_side,omitempty"`
+	CancelReason        string            `protobuf:"bytes,16,opt,name=cancel_reason,json=cancelReason,proto3" json:"cancel_reason,omitempty"`
+	// unix seconds; 0 = not set
+	CreatedAtUnix   int64         `protobuf:"varint,17,opt,name=created_at_unix,json=createdAtUnix,proto3" json:"created_at_unix,omitempty"`
+	AcceptedAtUnix  int64         `protobuf:"varint,18,opt,name=accepted_at_unix,json=acceptedAtUnix,proto3" json:"accepted_at_unix,omitempty"`
+	CompletedAtUnix int64         `protobuf:"varint,19,opt,name=completed_at_unix,json=completedAtUnix,proto3" json:"completed_at_unix,omitempty"`
+	CancelFee       float64       `protobuf:"fixed64,20,opt,name=cancel_fee,json=cancelFee,proto3" json:"cancel_fee,omitempty"` // points charged for a customer cancel after acceptance
+	Attachments     []*Attachment `protobuf:"bytes,21,rep,name=attachments,proto3" json:"attachments,omitempty"`                // completion proofs
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
 }
 
 func (x *Order) Reset() {
@@ -284,6 +562,7 @@ func (x *Order) GetCustomerId() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in collecting.proto.
 func (x *Order) GetStatus() string {
 	if x != nil {
 		return x.Status
@@ -361,17 +640,79 @@ func (x *Order) GetVersion() int64 {
 	return 0
 }
 
+func (x *Order) GetOrderStatus() OrderStatus {
+	if x != nil {
+		return x.OrderStatus
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
+}
+
+func (x *Order) GetCancelSide() CancelSide {
+	if x != nil {
+		return x.CancelSide
+	}
+	return CancelSide_CANCEL_SIDE_UNSPECIFIED
+}
+
+func (x *Order) GetCancelReason() string {
+	if x != nil {
+		return x.CancelReason
+	}
+	return ""
+}
+
+func (x *Order) GetCreatedAtUnix() int64 {
+	if x != nil {
+		return x.CreatedAtUnix
+	}
+	return 0
+}
+
+func (x *Order) GetAcceptedAtUnix() int64 {
+	if x != nil {
+		return x.AcceptedAtUnix
+	}
+	return 0
+}
+
+func (x *Order) GetCompletedAtUnix() int64 {
+	if x != nil {
+		return x.CompletedAtUnix
+	}
+	return 0
+}
+
+func (x *Order) GetCancelFee() float64 {
+	if x != nil {
+		return x.CancelFee
+	}
+	return 0
+}
+
+func (x *Order) GetAttachments() []*Attachment {
+	if x != nil {
+		return x.Attachments
+	}
+	return nil
+}
+
 type CreateOrderRequest struct {
 	state            protoimpl.MessageState `protogen:"open.v1"`
-	CustomerId       string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	CustomerId       string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"` // optional; must be the caller when set
 	PickAddress      *Address               `protobuf:"bytes,2,opt,name=pick_address,json=pickAddress,proto3" json:"pick_address,omitempty"`
 	CustomerSnapshot *CustomerSnapshot      `protobuf:"bytes,3,opt,name=customer_snapshot,json=customerSnapshot,proto3" json:"customer_snapshot,omitempty"`
 	Items            []*WasteItem           `protobuf:"bytes,4,rep,name=items,proto3" json:"items,omitempty"`
 	TotalWeight      float64                `protobuf:"fixed64,5,opt,name=total_weight,json=totalWeight,proto3" json:"total_weight,omitempty"`
 	EstimatedPrice   float64                `protobuf:"fixed64,6,opt,name=estimated_price,json=estimatedPrice,proto3" json:"estimated_price,omitempty"`
 	Note             string                 `protobuf:"bytes,7,opt,name=note,proto3" json:"note,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+	// Optional client-generated key (e.g. a UUID per tap). Retries with the same key
+	// return the order created by the first request instead of creating a duplicate.
+	IdempotencyKey string `protobuf:"bytes,8,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	// Saved address from AccountService.ListAddresses. When set the server fills
+	// pick_address; customer_snapshot is always filled from the account service.
+	AddressId     uint64 `protobuf:"varint,9,opt,name=address_id,json=addressId,proto3" json:"address_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
 func (x *CreateOrderRequest) Reset() {
@@ -453,9 +794,29 @@ func (x *CreateOrderRequest) GetNote() string {
 	return ""
 }
 
+func (x *CreateOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+func (x *CreateOrderRequest) GetAddressId() uint64 {
+	if x != nil {
+		return x.AddressId
+	}
+	return 0
+}
+
+// List RPCs return orders newest first (created_at desc, then id desc) and page with
+// opaque tokens: pass next_page_token back as page_token; an empty next_page_token
+// means there are no more results. Orders created while paging never shift pages.
 type ListAvailableOrdersRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Limit         int32                  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Deprecated: Marked as deprecated in collecting.proto.
+	Limit         int32  `protobuf:"varint,1,opt,name=limit,proto3" json:"limit,omitempty"`                       // use page_size
+	PageSize      int32  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"` // default 20, max 100
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -490,6 +851,7 @@ func (*ListAvailableOrdersRequest) Descriptor() ([]byte, []int) {
 	return file_collecting_proto_rawDescGZIP(), []int{6}
 }
 
+// Deprecated: Marked as deprecated in collecting.proto.
 func (x *ListAvailableOrdersRequest) GetLimit() int32 {
 	if x != nil {
 		return x.Limit
@@ -497,9 +859,24 @@ func (x *ListAvailableOrdersRequest) GetLimit() int32 {
 	return 0
 }
 
+func (x *ListAvailableOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListAvailableOrdersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
 type ListAvailableOrdersResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -541,12 +918,20 @@ func (x *ListAvailableOrdersResponse) GetOrders() []*Order {
 	return nil
 }
 
+func (x *ListAvailableOrdersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
 type AcceptOrderRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	CollectorId   string                 `protobuf:"bytes,2,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CollectorId    string                 `protobuf:"bytes,2,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`          // optional; must be the caller when set
+	IdempotencyKey string                 `protobuf:"bytes,3,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // scoped to the caller
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *AcceptOrderRequest) Reset() {
@@ -593,13 +978,23 @@ func (x *AcceptOrderRequest) GetCollectorId() string {
 	return ""
 }
 
+func (x *AcceptOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
 type UpdateOrderStatusRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	Status        string                 `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`
-	CollectorId   string                 `protobuf:"bytes,3,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	OrderId string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	// Deprecated: Marked as deprecated in collecting.proto.
+	Status         string      `protobuf:"bytes,2,opt,name=status,proto3" json:"status,omitempty"`                              // legacy form ("on_way"), used only when next_status is unset
+	CollectorId    string      `protobuf:"bytes,3,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"` // optional; must be the caller when set
+	NextStatus     OrderStatus `protobuf:"varint,4,opt,name=next_status,json=nextStatus,proto3,enum=ecopoint.collecting.v1.OrderStatus" json:"next_status,omitempty"`
+	IdempotencyKey string      `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"` // scoped to the caller
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *UpdateOrderStatusRequest) Reset() {
@@ -639,6 +1034,7 @@ func (x *UpdateOrderStatusRequest) GetOrderId() string {
 	return ""
 }
 
+// Deprecated: Marked as deprecated in collecting.proto.
 func (x *UpdateOrderStatusRequest) GetStatus() string {
 	if x != nil {
 		return x.Status
@@ -653,27 +1049,46 @@ func (x *UpdateOrderStatusRequest) GetCollectorId() string {
 	return ""
 }
 
-type GetOrderRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+func (x *UpdateOrderStatusRequest) GetNextStatus() OrderStatus {
+	if x != nil {
+		return x.NextStatus
+	}
+	return OrderStatus_ORDER_STATUS_UNSPECIFIED
 }
 
-func (x *GetOrderRequest) Reset() {
-	*x = GetOrderRequest{}
+func (x *UpdateOrderStatusRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+// The caller cancels as the order's customer or as its collector
+type CancelOrderRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Side           CancelSide             `protobuf:"varint,2,opt,name=side,proto3,enum=ecopoint.collecting.v1.CancelSide" json:"side,omitempty"` // CUSTOMER or COLLECTOR
+	CollectorId    string                 `protobuf:"bytes,3,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`        // optional; must be the caller when set
+	Reason         string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	IdempotencyKey string                 `protobuf:"bytes,5,opt,name=idempotency_key,json=idempotencyKey,proto3" json:"idempotency_key,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CancelOrderRequest) Reset() {
+	*x = CancelOrderRequest{}
 	mi := &file_collecting_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetOrderRequest) String() string {
+func (x *CancelOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetOrderRequest) ProtoMessage() {}
+func (*CancelOrderRequest) ProtoMessage() {}
 
-func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
+func (x *CancelOrderRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_collecting_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -685,39 +1100,67 @@ func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
-func (*GetOrderRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use CancelOrderRequest.ProtoReflect.Descriptor instead.
+func (*CancelOrderRequest) Descriptor() ([]byte, []int) {
 	return file_collecting_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *GetOrderRequest) GetOrderId() string {
+func (x *CancelOrderRequest) GetOrderId() string {
 	if x != nil {
 		return x.OrderId
 	}
 	return ""
 }
 
-type ListMyActiveOrdersRequest struct {
+func (x *CancelOrderRequest) GetSide() CancelSide {
+	if x != nil {
+		return x.Side
+	}
+	return CancelSide_CANCEL_SIDE_UNSPECIFIED
+}
+
+func (x *CancelOrderRequest) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+func (x *CancelOrderRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CancelOrderRequest) GetIdempotencyKey() string {
+	if x != nil {
+		return x.IdempotencyKey
+	}
+	return ""
+}
+
+type GetOrderRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListMyActiveOrdersRequest) Reset() {
-	*x = ListMyActiveOrdersRequest{}
+func (x *GetOrderRequest) Reset() {
+	*x = GetOrderRequest{}
 	mi := &file_collecting_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListMyActiveOrdersRequest) String() string {
+func (x *GetOrderRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListMyActiveOrdersRequest) ProtoMessage() {}
+func (*GetOrderRequest) ProtoMessage() {}
 
-func (x *ListMyActiveOrdersRequest) ProtoReflect() protoreflect.Message {
+func (x *GetOrderRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_collecting_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -729,42 +1172,2350 @@ func (x *ListMyActiveOrdersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListMyActiveOrdersRequest.ProtoReflect.Descriptor instead.
-func (*ListMyActiveOrdersRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use GetOrderRequest.ProtoReflect.Descriptor instead.
+func (*GetOrderRequest) Descriptor() ([]byte, []int) {
 	return file_collecting_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *ListMyActiveOrdersRequest) GetCollectorId() string {
+func (x *GetOrderRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+type ListMyActiveOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"` // optional; the caller unless an admin names someone else
+	PageSize      int32                  `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyActiveOrdersRequest) Reset() {
+	*x = ListMyActiveOrdersRequest{}
+	mi := &file_collecting_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyActiveOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyActiveOrdersRequest) ProtoMessage() {}
+
+func (x *ListMyActiveOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyActiveOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListMyActiveOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListMyActiveOrdersRequest) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+func (x *ListMyActiveOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListMyActiveOrdersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type ListMyOrdersRequest struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"` // optional; the caller unless an admin names someone else
+	// Deprecated: Marked as deprecated in collecting.proto.
+	Page int32 `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"` // page numbers are no longer supported; only 0/1 is accepted
+	// Deprecated: Marked as deprecated in collecting.proto.
+	Size          int32  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"` // use page_size
+	PageToken     string `protobuf:"bytes,4,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	PageSize      int32  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListMyOrdersRequest) Reset() {
+	*x = ListMyOrdersRequest{}
+	mi := &file_collecting_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListMyOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListMyOrdersRequest) ProtoMessage() {}
+
+func (x *ListMyOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListMyOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListMyOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListMyOrdersRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+// Deprecated: Marked as deprecated in collecting.proto.
+func (x *ListMyOrdersRequest) GetPage() int32 {
+	if x != nil {
+		return x.Page
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in collecting.proto.
+func (x *ListMyOrdersRequest) GetSize() int32 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *ListMyOrdersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListMyOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListOrdersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListOrdersResponse) Reset() {
+	*x = ListOrdersResponse{}
+	mi := &file_collecting_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListOrdersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListOrdersResponse) ProtoMessage() {}
+
+func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
+func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ListOrdersResponse) GetOrders() []*Order {
+	if x != nil {
+		return x.Orders
+	}
+	return nil
+}
+
+func (x *ListOrdersResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+// Collector history: every order the collector accepted, filtered by status and
+// created_at in [from_unix, to_unix). Same ordering and paging as the other lists.
+type ListCollectorOrdersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`                        // optional; the caller unless an admin names someone else
+	Statuses      []OrderStatus          `protobuf:"varint,2,rep,packed,name=statuses,proto3,enum=ecopoint.collecting.v1.OrderStatus" json:"statuses,omitempty"` // empty = all
+	FromUnix      int64                  `protobuf:"varint,3,opt,name=from_unix,json=fromUnix,proto3" json:"from_unix,omitempty"`                                // 0 = open
+	ToUnix        int64                  `protobuf:"varint,4,opt,name=to_unix,json=toUnix,proto3" json:"to_unix,omitempty"`                                      // 0 = open
+	PageSize      int32                  `protobuf:"varint,5,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,6,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollectorOrdersRequest) Reset() {
+	*x = ListCollectorOrdersRequest{}
+	mi := &file_collecting_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollectorOrdersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollectorOrdersRequest) ProtoMessage() {}
+
+func (x *ListCollectorOrdersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollectorOrdersRequest.ProtoReflect.Descriptor instead.
+func (*ListCollectorOrdersRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ListCollectorOrdersRequest) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+func (x *ListCollectorOrdersRequest) GetStatuses() []OrderStatus {
+	if x != nil {
+		return x.Statuses
+	}
+	return nil
+}
+
+func (x *ListCollectorOrdersRequest) GetFromUnix() int64 {
+	if x != nil {
+		return x.FromUnix
+	}
+	return 0
+}
+
+func (x *ListCollectorOrdersRequest) GetToUnix() int64 {
+	if x != nil {
+		return x.ToUnix
+	}
+	return 0
+}
+
+func (x *ListCollectorOrdersRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *ListCollectorOrdersRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+// Earnings over completed orders with completed_at in [from_unix, to_unix).
+// Defaults: the last 30 days, grouped by day in the server time zone. Max range 366 days.
+type GetCollectorEarningsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"` // optional; the caller unless an admin names someone else
+	FromUnix      int64                  `protobuf:"varint,2,opt,name=from_unix,json=fromUnix,proto3" json:"from_unix,omitempty"`
+	ToUnix        int64                  `protobuf:"varint,3,opt,name=to_unix,json=toUnix,proto3" json:"to_unix,omitempty"`
+	GroupBy       EarningsGroupBy        `protobuf:"varint,4,opt,name=group_by,json=groupBy,proto3,enum=ecopoint.collecting.v1.EarningsGroupBy" json:"group_by,omitempty"`
+	TimeZone      string                 `protobuf:"bytes,5,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"` // IANA name, e.g. "Asia/Ho_Chi_Minh"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCollectorEarningsRequest) Reset() {
+	*x = GetCollectorEarningsRequest{}
+	mi := &file_collecting_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCollectorEarningsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCollectorEarningsRequest) ProtoMessage() {}
+
+func (x *GetCollectorEarningsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCollectorEarningsRequest.ProtoReflect.Descriptor instead.
+func (*GetCollectorEarningsRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *GetCollectorEarningsRequest) GetCollectorId() string {
 	if x != nil {
 		return x.CollectorId
 	}
-	return ""
+	return ""
+}
+
+func (x *GetCollectorEarningsRequest) GetFromUnix() int64 {
+	if x != nil {
+		return x.FromUnix
+	}
+	return 0
+}
+
+func (x *GetCollectorEarningsRequest) GetToUnix() int64 {
+	if x != nil {
+		return x.ToUnix
+	}
+	return 0
+}
+
+func (x *GetCollectorEarningsRequest) GetGroupBy() EarningsGroupBy {
+	if x != nil {
+		return x.GroupBy
+	}
+	return EarningsGroupBy_EARNINGS_GROUP_BY_UNSPECIFIED
+}
+
+func (x *GetCollectorEarningsRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+type WasteWeight struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Type          string                 `protobuf:"bytes,1,opt,name=type,proto3" json:"type,omitempty"`
+	Weight        float64                `protobuf:"fixed64,2,opt,name=weight,proto3" json:"weight,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WasteWeight) Reset() {
+	*x = WasteWeight{}
+	mi := &file_collecting_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WasteWeight) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WasteWeight) ProtoMessage() {}
+
+func (x *WasteWeight) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WasteWeight.ProtoReflect.Descriptor instead.
+func (*WasteWeight) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *WasteWeight) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *WasteWeight) GetWeight() float64 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+type EarningsBucket struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	PeriodStartUnix int64                  `protobuf:"varint,1,opt,name=period_start_unix,json=periodStartUnix,proto3" json:"period_start_unix,omitempty"`
+	Orders          int32                  `protobuf:"varint,2,opt,name=orders,proto3" json:"orders,omitempty"`
+	Weights         []*WasteWeight         `protobuf:"bytes,3,rep,name=weights,proto3" json:"weights,omitempty"` // sorted by type
+	TotalWeight     float64                `protobuf:"fixed64,4,opt,name=total_weight,json=totalWeight,proto3" json:"total_weight,omitempty"`
+	Amount          float64                `protobuf:"fixed64,5,opt,name=amount,proto3" json:"amount,omitempty"` // sum of estimated_price
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *EarningsBucket) Reset() {
+	*x = EarningsBucket{}
+	mi := &file_collecting_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EarningsBucket) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EarningsBucket) ProtoMessage() {}
+
+func (x *EarningsBucket) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EarningsBucket.ProtoReflect.Descriptor instead.
+func (*EarningsBucket) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *EarningsBucket) GetPeriodStartUnix() int64 {
+	if x != nil {
+		return x.PeriodStartUnix
+	}
+	return 0
+}
+
+func (x *EarningsBucket) GetOrders() int32 {
+	if x != nil {
+		return x.Orders
+	}
+	return 0
+}
+
+func (x *EarningsBucket) GetWeights() []*WasteWeight {
+	if x != nil {
+		return x.Weights
+	}
+	return nil
+}
+
+func (x *EarningsBucket) GetTotalWeight() float64 {
+	if x != nil {
+		return x.TotalWeight
+	}
+	return 0
+}
+
+func (x *EarningsBucket) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type CollectorEarnings struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Buckets       []*EarningsBucket      `protobuf:"bytes,1,rep,name=buckets,proto3" json:"buckets,omitempty"` // oldest first; periods without orders are omitted
+	Total         *EarningsBucket        `protobuf:"bytes,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectorEarnings) Reset() {
+	*x = CollectorEarnings{}
+	mi := &file_collecting_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectorEarnings) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectorEarnings) ProtoMessage() {}
+
+func (x *CollectorEarnings) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectorEarnings.ProtoReflect.Descriptor instead.
+func (*CollectorEarnings) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *CollectorEarnings) GetBuckets() []*EarningsBucket {
+	if x != nil {
+		return x.Buckets
+	}
+	return nil
+}
+
+func (x *CollectorEarnings) GetTotal() *EarningsBucket {
+	if x != nil {
+		return x.Total
+	}
+	return nil
+}
+
+// Each side may rate a completed order once: the customer rates the collector
+// (side = CUSTOMER) and the collector rates the customer (side = COLLECTOR).
+type RateOrderRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	RaterId       string                 `protobuf:"bytes,2,opt,name=rater_id,json=raterId,proto3" json:"rater_id,omitempty"`                  // optional; the caller is the rater
+	Side          UserRole               `protobuf:"varint,3,opt,name=side,proto3,enum=ecopoint.collecting.v1.UserRole" json:"side,omitempty"` // role of the rater
+	Stars         int32                  `protobuf:"varint,4,opt,name=stars,proto3" json:"stars,omitempty"`                                    // 1..5
+	Tags          []string               `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`                                       // e.g. "on_time", "friendly"; lowercased, max 5
+	Comment       string                 `protobuf:"bytes,6,opt,name=comment,proto3" json:"comment,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateOrderRequest) Reset() {
+	*x = RateOrderRequest{}
+	mi := &file_collecting_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateOrderRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateOrderRequest) ProtoMessage() {}
+
+func (x *RateOrderRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateOrderRequest.ProtoReflect.Descriptor instead.
+func (*RateOrderRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *RateOrderRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *RateOrderRequest) GetRaterId() string {
+	if x != nil {
+		return x.RaterId
+	}
+	return ""
+}
+
+func (x *RateOrderRequest) GetSide() UserRole {
+	if x != nil {
+		return x.Side
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *RateOrderRequest) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *RateOrderRequest) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *RateOrderRequest) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+type Rating struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Side          UserRole               `protobuf:"varint,2,opt,name=side,proto3,enum=ecopoint.collecting.v1.UserRole" json:"side,omitempty"`
+	RaterId       string                 `protobuf:"bytes,3,opt,name=rater_id,json=raterId,proto3" json:"rater_id,omitempty"`
+	RateeId       string                 `protobuf:"bytes,4,opt,name=ratee_id,json=rateeId,proto3" json:"ratee_id,omitempty"`
+	Stars         int32                  `protobuf:"varint,5,opt,name=stars,proto3" json:"stars,omitempty"`
+	Tags          []string               `protobuf:"bytes,6,rep,name=tags,proto3" json:"tags,omitempty"`
+	Comment       string                 `protobuf:"bytes,7,opt,name=comment,proto3" json:"comment,omitempty"`
+	CreatedAtUnix int64                  `protobuf:"varint,8,opt,name=created_at_unix,json=createdAtUnix,proto3" json:"created_at_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Rating) Reset() {
+	*x = Rating{}
+	mi := &file_collecting_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Rating) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Rating) ProtoMessage() {}
+
+func (x *Rating) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Rating.ProtoReflect.Descriptor instead.
+func (*Rating) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *Rating) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Rating) GetSide() UserRole {
+	if x != nil {
+		return x.Side
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *Rating) GetRaterId() string {
+	if x != nil {
+		return x.RaterId
+	}
+	return ""
+}
+
+func (x *Rating) GetRateeId() string {
+	if x != nil {
+		return x.RateeId
+	}
+	return ""
+}
+
+func (x *Rating) GetStars() int32 {
+	if x != nil {
+		return x.Stars
+	}
+	return 0
+}
+
+func (x *Rating) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+func (x *Rating) GetComment() string {
+	if x != nil {
+		return x.Comment
+	}
+	return ""
+}
+
+func (x *Rating) GetCreatedAtUnix() int64 {
+	if x != nil {
+		return x.CreatedAtUnix
+	}
+	return 0
+}
+
+type GetUserRatingRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          UserRole               `protobuf:"varint,2,opt,name=role,proto3,enum=ecopoint.collecting.v1.UserRole" json:"role,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetUserRatingRequest) Reset() {
+	*x = GetUserRatingRequest{}
+	mi := &file_collecting_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetUserRatingRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetUserRatingRequest) ProtoMessage() {}
+
+func (x *GetUserRatingRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetUserRatingRequest.ProtoReflect.Descriptor instead.
+func (*GetUserRatingRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *GetUserRatingRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *GetUserRatingRequest) GetRole() UserRole {
+	if x != nil {
+		return x.Role
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+type TagCount struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	Count         int64                  `protobuf:"varint,2,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagCount) Reset() {
+	*x = TagCount{}
+	mi := &file_collecting_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagCount) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagCount) ProtoMessage() {}
+
+func (x *TagCount) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagCount.ProtoReflect.Descriptor instead.
+func (*TagCount) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *TagCount) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+func (x *TagCount) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+// Running aggregate of the ratings a user received in one role
+type UserRating struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	Role          UserRole               `protobuf:"varint,2,opt,name=role,proto3,enum=ecopoint.collecting.v1.UserRole" json:"role,omitempty"`
+	Count         int64                  `protobuf:"varint,3,opt,name=count,proto3" json:"count,omitempty"`
+	Average       float64                `protobuf:"fixed64,4,opt,name=average,proto3" json:"average,omitempty"` // 0 when count is 0
+	Tags          []*TagCount            `protobuf:"bytes,5,rep,name=tags,proto3" json:"tags,omitempty"`         // most frequent first
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UserRating) Reset() {
+	*x = UserRating{}
+	mi := &file_collecting_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UserRating) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UserRating) ProtoMessage() {}
+
+func (x *UserRating) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UserRating.ProtoReflect.Descriptor instead.
+func (*UserRating) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *UserRating) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *UserRating) GetRole() UserRole {
+	if x != nil {
+		return x.Role
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *UserRating) GetCount() int64 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+func (x *UserRating) GetAverage() float64 {
+	if x != nil {
+		return x.Average
+	}
+	return 0
+}
+
+func (x *UserRating) GetTags() []*TagCount {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type SearchUserRatingsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Role          UserRole               `protobuf:"varint,1,opt,name=role,proto3,enum=ecopoint.collecting.v1.UserRole" json:"role,omitempty"` // required
+	MaxAverage    float64                `protobuf:"fixed64,2,opt,name=max_average,json=maxAverage,proto3" json:"max_average,omitempty"`       // 0 = any
+	MinCount      int64                  `protobuf:"varint,3,opt,name=min_count,json=minCount,proto3" json:"min_count,omitempty"`              // skip users with fewer ratings
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	PageToken     string                 `protobuf:"bytes,5,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchUserRatingsRequest) Reset() {
+	*x = SearchUserRatingsRequest{}
+	mi := &file_collecting_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchUserRatingsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchUserRatingsRequest) ProtoMessage() {}
+
+func (x *SearchUserRatingsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchUserRatingsRequest.ProtoReflect.Descriptor instead.
+func (*SearchUserRatingsRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SearchUserRatingsRequest) GetRole() UserRole {
+	if x != nil {
+		return x.Role
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *SearchUserRatingsRequest) GetMaxAverage() float64 {
+	if x != nil {
+		return x.MaxAverage
+	}
+	return 0
+}
+
+func (x *SearchUserRatingsRequest) GetMinCount() int64 {
+	if x != nil {
+		return x.MinCount
+	}
+	return 0
+}
+
+func (x *SearchUserRatingsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *SearchUserRatingsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type SearchUserRatingsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Ratings       []*UserRating          `protobuf:"bytes,1,rep,name=ratings,proto3" json:"ratings,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchUserRatingsResponse) Reset() {
+	*x = SearchUserRatingsResponse{}
+	mi := &file_collecting_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchUserRatingsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchUserRatingsResponse) ProtoMessage() {}
+
+func (x *SearchUserRatingsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchUserRatingsResponse.ProtoReflect.Descriptor instead.
+func (*SearchUserRatingsResponse) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *SearchUserRatingsResponse) GetRatings() []*UserRating {
+	if x != nil {
+		return x.Ratings
+	}
+	return nil
+}
+
+func (x *SearchUserRatingsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetCollectorPenaltyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCollectorPenaltyRequest) Reset() {
+	*x = GetCollectorPenaltyRequest{}
+	mi := &file_collecting_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCollectorPenaltyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCollectorPenaltyRequest) ProtoMessage() {}
+
+func (x *GetCollectorPenaltyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCollectorPenaltyRequest.ProtoReflect.Descriptor instead.
+func (*GetCollectorPenaltyRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *GetCollectorPenaltyRequest) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+// Admins only; the calling admin is recorded as cleared_by
+type ClearCollectorPenaltyRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId   string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
+	AdminId       string                 `protobuf:"bytes,2,opt,name=admin_id,json=adminId,proto3" json:"admin_id,omitempty"` // optional; must be the caller when set
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClearCollectorPenaltyRequest) Reset() {
+	*x = ClearCollectorPenaltyRequest{}
+	mi := &file_collecting_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClearCollectorPenaltyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClearCollectorPenaltyRequest) ProtoMessage() {}
+
+func (x *ClearCollectorPenaltyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClearCollectorPenaltyRequest.ProtoReflect.Descriptor instead.
+func (*ClearCollectorPenaltyRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *ClearCollectorPenaltyRequest) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+func (x *ClearCollectorPenaltyRequest) GetAdminId() string {
+	if x != nil {
+		return x.AdminId
+	}
+	return ""
+}
+
+type Strike struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	Kind          string                 `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"` // no_show, abandoned, cancelled, late
+	AtUnix        int64                  `protobuf:"varint,3,opt,name=at_unix,json=atUnix,proto3" json:"at_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Strike) Reset() {
+	*x = Strike{}
+	mi := &file_collecting_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Strike) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Strike) ProtoMessage() {}
+
+func (x *Strike) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Strike.ProtoReflect.Descriptor instead.
+func (*Strike) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *Strike) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *Strike) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *Strike) GetAtUnix() int64 {
+	if x != nil {
+		return x.AtUnix
+	}
+	return 0
+}
+
+type CollectorPenalty struct {
+	state             protoimpl.MessageState `protogen:"open.v1"`
+	CollectorId       string                 `protobuf:"bytes,1,opt,name=collector_id,json=collectorId,proto3" json:"collector_id,omitempty"`
+	Suspended         bool                   `protobuf:"varint,2,opt,name=suspended,proto3" json:"suspended,omitempty"`
+	CooldownUntilUnix int64                  `protobuf:"varint,3,opt,name=cooldown_until_unix,json=cooldownUntilUnix,proto3" json:"cooldown_until_unix,omitempty"` // 0 when not suspended
+	Reason            string                 `protobuf:"bytes,4,opt,name=reason,proto3" json:"reason,omitempty"`
+	Points            int32                  `protobuf:"varint,5,opt,name=points,proto3" json:"points,omitempty"`  // weighted strikes in the current window
+	Strikes           []*Strike              `protobuf:"bytes,6,rep,name=strikes,proto3" json:"strikes,omitempty"` // strikes still counting, newest first
+	ClearedAtUnix     int64                  `protobuf:"varint,7,opt,name=cleared_at_unix,json=clearedAtUnix,proto3" json:"cleared_at_unix,omitempty"`
+	ClearedBy         string                 `protobuf:"bytes,8,opt,name=cleared_by,json=clearedBy,proto3" json:"cleared_by,omitempty"`
+	unknownFields     protoimpl.UnknownFields
+	sizeCache         protoimpl.SizeCache
+}
+
+func (x *CollectorPenalty) Reset() {
+	*x = CollectorPenalty{}
+	mi := &file_collecting_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectorPenalty) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectorPenalty) ProtoMessage() {}
+
+func (x *CollectorPenalty) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectorPenalty.ProtoReflect.Descriptor instead.
+func (*CollectorPenalty) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *CollectorPenalty) GetCollectorId() string {
+	if x != nil {
+		return x.CollectorId
+	}
+	return ""
+}
+
+func (x *CollectorPenalty) GetSuspended() bool {
+	if x != nil {
+		return x.Suspended
+	}
+	return false
+}
+
+func (x *CollectorPenalty) GetCooldownUntilUnix() int64 {
+	if x != nil {
+		return x.CooldownUntilUnix
+	}
+	return 0
+}
+
+func (x *CollectorPenalty) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *CollectorPenalty) GetPoints() int32 {
+	if x != nil {
+		return x.Points
+	}
+	return 0
+}
+
+func (x *CollectorPenalty) GetStrikes() []*Strike {
+	if x != nil {
+		return x.Strikes
+	}
+	return nil
+}
+
+func (x *CollectorPenalty) GetClearedAtUnix() int64 {
+	if x != nil {
+		return x.ClearedAtUnix
+	}
+	return 0
+}
+
+func (x *CollectorPenalty) GetClearedBy() string {
+	if x != nil {
+		return x.ClearedBy
+	}
+	return ""
+}
+
+// Edits an order that is still CREATED. Unset fields keep their value; an empty
+// items list keeps the current items. Fails with ABORTED when version is stale.
+type UpdateOrderDetailsRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	OrderId        string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	CustomerId     string                 `protobuf:"bytes,2,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"` // optional; the caller must own the order
+	Version        int64                  `protobuf:"varint,3,opt,name=version,proto3" json:"version,omitempty"`
+	PickAddress    *Address               `protobuf:"bytes,4,opt,name=pick_address,json=pickAddress,proto3" json:"pick_address,omitempty"`
+	Items          []*WasteItem           `protobuf:"bytes,5,rep,name=items,proto3" json:"items,omitempty"`
+	EstimatedPrice *float64               `protobuf:"fixed64,6,opt,name=estimated_price,json=estimatedPrice,proto3,oneof" json:"estimated_price,omitempty"` // unset: quoted again, as for a new order
+	Note           *string                `protobuf:"bytes,7,opt,name=note,proto3,oneof" json:"note,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *UpdateOrderDetailsRequest) Reset() {
+	*x = UpdateOrderDetailsRequest{}
+	mi := &file_collecting_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateOrderDetailsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateOrderDetailsRequest) ProtoMessage() {}
+
+func (x *UpdateOrderDetailsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateOrderDetailsRequest.ProtoReflect.Descriptor instead.
+func (*UpdateOrderDetailsRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *UpdateOrderDetailsRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *UpdateOrderDetailsRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *UpdateOrderDetailsRequest) GetVersion() int64 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *UpdateOrderDetailsRequest) GetPickAddress() *Address {
+	if x != nil {
+		return x.PickAddress
+	}
+	return nil
+}
+
+func (x *UpdateOrderDetailsRequest) GetItems() []*WasteItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+func (x *UpdateOrderDetailsRequest) GetEstimatedPrice() float64 {
+	if x != nil && x.EstimatedPrice != nil {
+		return *x.EstimatedPrice
+	}
+	return 0
+}
+
+func (x *UpdateOrderDetailsRequest) GetNote() string {
+	if x != nil && x.Note != nil {
+		return *x.Note
+	}
+	return ""
+}
+
+// user_id defaults to the caller; only admins may name another user
+type GetNotificationPreferencesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNotificationPreferencesRequest) Reset() {
+	*x = GetNotificationPreferencesRequest{}
+	mi := &file_collecting_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNotificationPreferencesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNotificationPreferencesRequest) ProtoMessage() {}
+
+func (x *GetNotificationPreferencesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNotificationPreferencesRequest.ProtoReflect.Descriptor instead.
+func (*GetNotificationPreferencesRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *GetNotificationPreferencesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type NotificationPreferences struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	UserId        string                 `protobuf:"bytes,1,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                // the caller when empty; only admins may set another user
+	Locale        string                 `protobuf:"bytes,2,opt,name=locale,proto3" json:"locale,omitempty"`                              // "vi" or "en"; empty uses the server default
+	Disabled      bool                   `protobuf:"varint,3,opt,name=disabled,proto3" json:"disabled,omitempty"`                         // mute all push notifications
+	MutedEvents   []string               `protobuf:"bytes,4,rep,name=muted_events,json=mutedEvents,proto3" json:"muted_events,omitempty"` // e.g. "order.accepted", "order.on_way"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NotificationPreferences) Reset() {
+	*x = NotificationPreferences{}
+	mi := &file_collecting_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NotificationPreferences) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NotificationPreferences) ProtoMessage() {}
+
+func (x *NotificationPreferences) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NotificationPreferences.ProtoReflect.Descriptor instead.
+func (*NotificationPreferences) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *NotificationPreferences) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *NotificationPreferences) GetLocale() string {
+	if x != nil {
+		return x.Locale
+	}
+	return ""
+}
+
+func (x *NotificationPreferences) GetDisabled() bool {
+	if x != nil {
+		return x.Disabled
+	}
+	return false
+}
+
+func (x *NotificationPreferences) GetMutedEvents() []string {
+	if x != nil {
+		return x.MutedEvents
+	}
+	return nil
+}
+
+type ChatMessage struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	OrderId       string                 `protobuf:"bytes,2,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	SenderId      string                 `protobuf:"bytes,3,opt,name=sender_id,json=senderId,proto3" json:"sender_id,omitempty"`
+	SenderRole    UserRole               `protobuf:"varint,4,opt,name=sender_role,json=senderRole,proto3,enum=ecopoint.collecting.v1.UserRole" json:"sender_role,omitempty"`
+	Text          string                 `protobuf:"bytes,5,opt,name=text,proto3" json:"text,omitempty"`
+	SentAtUnixMs  int64                  `protobuf:"varint,6,opt,name=sent_at_unix_ms,json=sentAtUnixMs,proto3" json:"sent_at_unix_ms,omitempty"`
+	ReadAtUnixMs  int64                  `protobuf:"varint,7,opt,name=read_at_unix_ms,json=readAtUnixMs,proto3" json:"read_at_unix_ms,omitempty"` // 0 = not read by the other participant yet
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatMessage) Reset() {
+	*x = ChatMessage{}
+	mi := &file_collecting_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatMessage) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatMessage) ProtoMessage() {}
+
+func (x *ChatMessage) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatMessage.ProtoReflect.Descriptor instead.
+func (*ChatMessage) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *ChatMessage) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSenderId() string {
+	if x != nil {
+		return x.SenderId
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSenderRole() UserRole {
+	if x != nil {
+		return x.SenderRole
+	}
+	return UserRole_USER_ROLE_UNSPECIFIED
+}
+
+func (x *ChatMessage) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+func (x *ChatMessage) GetSentAtUnixMs() int64 {
+	if x != nil {
+		return x.SentAtUnixMs
+	}
+	return 0
+}
+
+func (x *ChatMessage) GetReadAtUnixMs() int64 {
+	if x != nil {
+		return x.ReadAtUnixMs
+	}
+	return 0
+}
+
+type ChatJoin struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatJoin) Reset() {
+	*x = ChatJoin{}
+	mi := &file_collecting_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatJoin) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatJoin) ProtoMessage() {}
+
+func (x *ChatJoin) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatJoin.ProtoReflect.Descriptor instead.
+func (*ChatJoin) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ChatJoin) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *ChatJoin) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type ChatSend struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Text          string                 `protobuf:"bytes,1,opt,name=text,proto3" json:"text,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatSend) Reset() {
+	*x = ChatSend{}
+	mi := &file_collecting_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatSend) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatSend) ProtoMessage() {}
+
+func (x *ChatSend) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatSend.ProtoReflect.Descriptor instead.
+func (*ChatSend) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *ChatSend) GetText() string {
+	if x != nil {
+		return x.Text
+	}
+	return ""
+}
+
+type ChatRead struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	LastMessageId string                 `protobuf:"bytes,1,opt,name=last_message_id,json=lastMessageId,proto3" json:"last_message_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatRead) Reset() {
+	*x = ChatRead{}
+	mi := &file_collecting_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatRead) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatRead) ProtoMessage() {}
+
+func (x *ChatRead) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatRead.ProtoReflect.Descriptor instead.
+func (*ChatRead) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *ChatRead) GetLastMessageId() string {
+	if x != nil {
+		return x.LastMessageId
+	}
+	return ""
+}
+
+type ChatClientFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Frame:
+	//
+	//	*ChatClientFrame_Join
+	//	*ChatClientFrame_Send
+	//	*ChatClientFrame_Read
+	Frame         isChatClientFrame_Frame `protobuf_oneof:"frame"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatClientFrame) Reset() {
+	*x = ChatClientFrame{}
+	mi := &file_collecting_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatClientFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatClientFrame) ProtoMessage() {}
+
+func (x *ChatClientFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatClientFrame.ProtoReflect.Descriptor instead.
+func (*ChatClientFrame) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *ChatClientFrame) GetFrame() isChatClientFrame_Frame {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+func (x *ChatClientFrame) GetJoin() *ChatJoin {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatClientFrame_Join); ok {
+			return x.Join
+		}
+	}
+	return nil
+}
+
+func (x *ChatClientFrame) GetSend() *ChatSend {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatClientFrame_Send); ok {
+			return x.Send
+		}
+	}
+	return nil
+}
+
+func (x *ChatClientFrame) GetRead() *ChatRead {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatClientFrame_Read); ok {
+			return x.Read
+		}
+	}
+	return nil
+}
+
+type isChatClientFrame_Frame interface {
+	isChatClientFrame_Frame()
+}
+
+type ChatClientFrame_Join struct {
+	Join *ChatJoin `protobuf:"bytes,1,opt,name=join,proto3,oneof"`
+}
+
+type ChatClientFrame_Send struct {
+	Send *ChatSend `protobuf:"bytes,2,opt,name=send,proto3,oneof"`
+}
+
+type ChatClientFrame_Read struct {
+	Read *ChatRead `protobuf:"bytes,3,opt,name=read,proto3,oneof"`
+}
+
+func (*ChatClientFrame_Join) isChatClientFrame_Frame() {}
+
+func (*ChatClientFrame_Send) isChatClientFrame_Frame() {}
+
+func (*ChatClientFrame_Read) isChatClientFrame_Frame() {}
+
+type ChatReceipt struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ReaderId      string                 `protobuf:"bytes,1,opt,name=reader_id,json=readerId,proto3" json:"reader_id,omitempty"`
+	UpToUnixMs    int64                  `protobuf:"varint,2,opt,name=up_to_unix_ms,json=upToUnixMs,proto3" json:"up_to_unix_ms,omitempty"`
+	ReadAtUnixMs  int64                  `protobuf:"varint,3,opt,name=read_at_unix_ms,json=readAtUnixMs,proto3" json:"read_at_unix_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatReceipt) Reset() {
+	*x = ChatReceipt{}
+	mi := &file_collecting_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatReceipt) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatReceipt) ProtoMessage() {}
+
+func (x *ChatReceipt) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatReceipt.ProtoReflect.Descriptor instead.
+func (*ChatReceipt) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *ChatReceipt) GetReaderId() string {
+	if x != nil {
+		return x.ReaderId
+	}
+	return ""
+}
+
+func (x *ChatReceipt) GetUpToUnixMs() int64 {
+	if x != nil {
+		return x.UpToUnixMs
+	}
+	return 0
+}
+
+func (x *ChatReceipt) GetReadAtUnixMs() int64 {
+	if x != nil {
+		return x.ReadAtUnixMs
+	}
+	return 0
+}
+
+type ChatClosed struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatClosed) Reset() {
+	*x = ChatClosed{}
+	mi := &file_collecting_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatClosed) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatClosed) ProtoMessage() {}
+
+func (x *ChatClosed) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatClosed.ProtoReflect.Descriptor instead.
+func (*ChatClosed) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ChatClosed) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ChatServerFrame struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Frame:
+	//
+	//	*ChatServerFrame_Message
+	//	*ChatServerFrame_Receipt
+	//	*ChatServerFrame_Closed
+	Frame         isChatServerFrame_Frame `protobuf_oneof:"frame"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChatServerFrame) Reset() {
+	*x = ChatServerFrame{}
+	mi := &file_collecting_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChatServerFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChatServerFrame) ProtoMessage() {}
+
+func (x *ChatServerFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChatServerFrame.ProtoReflect.Descriptor instead.
+func (*ChatServerFrame) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *ChatServerFrame) GetFrame() isChatServerFrame_Frame {
+	if x != nil {
+		return x.Frame
+	}
+	return nil
+}
+
+func (x *ChatServerFrame) GetMessage() *ChatMessage {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatServerFrame_Message); ok {
+			return x.Message
+		}
+	}
+	return nil
+}
+
+func (x *ChatServerFrame) GetReceipt() *ChatReceipt {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatServerFrame_Receipt); ok {
+			return x.Receipt
+		}
+	}
+	return nil
+}
+
+func (x *ChatServerFrame) GetClosed() *ChatClosed {
+	if x != nil {
+		if x, ok := x.Frame.(*ChatServerFrame_Closed); ok {
+			return x.Closed
+		}
+	}
+	return nil
+}
+
+type isChatServerFrame_Frame interface {
+	isChatServerFrame_Frame()
+}
+
+type ChatServerFrame_Message struct {
+	Message *ChatMessage `protobuf:"bytes,1,opt,name=message,proto3,oneof"` // includes the sender's own messages
+}
+
+type ChatServerFrame_Receipt struct {
+	Receipt *ChatReceipt `protobuf:"bytes,2,opt,name=receipt,proto3,oneof"`
+}
+
+type ChatServerFrame_Closed struct {
+	Closed *ChatClosed `protobuf:"bytes,3,opt,name=closed,proto3,oneof"`
+}
+
+func (*ChatServerFrame_Message) isChatServerFrame_Frame() {}
+
+func (*ChatServerFrame_Receipt) isChatServerFrame_Frame() {}
+
+func (*ChatServerFrame_Closed) isChatServerFrame_Frame() {}
+
+type ListChatMessagesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`                   // optional; must be the caller when set
+	AfterUnixMs   int64                  `protobuf:"varint,3,opt,name=after_unix_ms,json=afterUnixMs,proto3" json:"after_unix_ms,omitempty"` // 0 = from the first message
+	PageSize      int32                  `protobuf:"varint,4,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChatMessagesRequest) Reset() {
+	*x = ListChatMessagesRequest{}
+	mi := &file_collecting_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChatMessagesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChatMessagesRequest) ProtoMessage() {}
+
+func (x *ListChatMessagesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChatMessagesRequest.ProtoReflect.Descriptor instead.
+func (*ListChatMessagesRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *ListChatMessagesRequest) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *ListChatMessagesRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *ListChatMessagesRequest) GetAfterUnixMs() int64 {
+	if x != nil {
+		return x.AfterUnixMs
+	}
+	return 0
+}
+
+func (x *ListChatMessagesRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type ListChatMessagesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Messages      []*ChatMessage         `protobuf:"bytes,1,rep,name=messages,proto3" json:"messages,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListChatMessagesResponse) Reset() {
+	*x = ListChatMessagesResponse{}
+	mi := &file_collecting_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListChatMessagesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListChatMessagesResponse) ProtoMessage() {}
+
+func (x *ListChatMessagesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListChatMessagesResponse.ProtoReflect.Descriptor instead.
+func (*ListChatMessagesResponse) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *ListChatMessagesResponse) GetMessages() []*ChatMessage {
+	if x != nil {
+		return x.Messages
+	}
+	return nil
+}
+
+type Attachment struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Kind           AttachmentKind         `protobuf:"varint,2,opt,name=kind,proto3,enum=ecopoint.collecting.v1.AttachmentKind" json:"kind,omitempty"`
+	ContentType    string                 `protobuf:"bytes,3,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size           int64                  `protobuf:"varint,4,opt,name=size,proto3" json:"size,omitempty"`
+	UploadedBy     string                 `protobuf:"bytes,5,opt,name=uploaded_by,json=uploadedBy,proto3" json:"uploaded_by,omitempty"`
+	UploadedAtUnix int64                  `protobuf:"varint,6,opt,name=uploaded_at_unix,json=uploadedAtUnix,proto3" json:"uploaded_at_unix,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Attachment) Reset() {
+	*x = Attachment{}
+	mi := &file_collecting_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Attachment) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Attachment) ProtoMessage() {}
+
+func (x *Attachment) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Attachment.ProtoReflect.Descriptor instead.
+func (*Attachment) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Attachment) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Attachment) GetKind() AttachmentKind {
+	if x != nil {
+		return x.Kind
+	}
+	return AttachmentKind_ATTACHMENT_KIND_UNSPECIFIED
+}
+
+func (x *Attachment) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Attachment) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *Attachment) GetUploadedBy() string {
+	if x != nil {
+		return x.UploadedBy
+	}
+	return ""
+}
+
+func (x *Attachment) GetUploadedAtUnix() int64 {
+	if x != nil {
+		return x.UploadedAtUnix
+	}
+	return 0
+}
+
+type AttachmentInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	OrderId       string                 `protobuf:"bytes,1,opt,name=order_id,json=orderId,proto3" json:"order_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"` // optional; must be the caller when set
+	Kind          AttachmentKind         `protobuf:"varint,3,opt,name=kind,proto3,enum=ecopoint.collecting.v1.AttachmentKind" json:"kind,omitempty"`
+	ItemIndex     int32                  `protobuf:"varint,4,opt,name=item_index,json=itemIndex,proto3" json:"item_index,omitempty"` // item photos: index into order.items
+	ContentType   string                 `protobuf:"bytes,5,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"` // bytes; must match the uploaded file
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentInfo) Reset() {
+	*x = AttachmentInfo{}
+	mi := &file_collecting_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentInfo) ProtoMessage() {}
+
+func (x *AttachmentInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentInfo.ProtoReflect.Descriptor instead.
+func (*AttachmentInfo) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *AttachmentInfo) GetOrderId() string {
+	if x != nil {
+		return x.OrderId
+	}
+	return ""
+}
+
+func (x *AttachmentInfo) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+func (x *AttachmentInfo) GetKind() AttachmentKind {
+	if x != nil {
+		return x.Kind
+	}
+	return AttachmentKind_ATTACHMENT_KIND_UNSPECIFIED
+}
+
+func (x *AttachmentInfo) GetItemIndex() int32 {
+	if x != nil {
+		return x.ItemIndex
+	}
+	return 0
+}
+
+func (x *AttachmentInfo) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *AttachmentInfo) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
 }
 
-type ListMyOrdersRequest struct {
+type AttachmentUpload struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
-	Page          int32                  `protobuf:"varint,2,opt,name=page,proto3" json:"page,omitempty"`
-	Size          int32                  `protobuf:"varint,3,opt,name=size,proto3" json:"size,omitempty"`
+	AttachmentId  string                 `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	UploadUrl     string                 `protobuf:"bytes,2,opt,name=upload_url,json=uploadUrl,proto3" json:"upload_url,omitempty"`
+	Headers       map[string]string      `protobuf:"bytes,3,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // send these with the PUT
+	ExpiresAtUnix int64                  `protobuf:"varint,4,opt,name=expires_at_unix,json=expiresAtUnix,proto3" json:"expires_at_unix,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListMyOrdersRequest) Reset() {
-	*x = ListMyOrdersRequest{}
-	mi := &file_collecting_proto_msgTypes[12]
+func (x *AttachmentUpload) Reset() {
+	*x = AttachmentUpload{}
+	mi := &file_collecting_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListMyOrdersRequest) String() string {
+func (x *AttachmentUpload) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListMyOrdersRequest) ProtoMessage() {}
+func (*AttachmentUpload) ProtoMessage() {}
 
-func (x *ListMyOrdersRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_collecting_proto_msgTypes[12]
+func (x *AttachmentUpload) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -775,54 +3526,303 @@ func (x *ListMyOrdersRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListMyOrdersRequest.ProtoReflect.Descriptor instead.
-func (*ListMyOrdersRequest) Descriptor() ([]byte, []int) {
-	return file_collecting_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use AttachmentUpload.ProtoReflect.Descriptor instead.
+func (*AttachmentUpload) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{46}
 }
 
-func (x *ListMyOrdersRequest) GetCustomerId() string {
+func (x *AttachmentUpload) GetAttachmentId() string {
 	if x != nil {
-		return x.CustomerId
+		return x.AttachmentId
 	}
 	return ""
 }
 
-func (x *ListMyOrdersRequest) GetPage() int32 {
+func (x *AttachmentUpload) GetUploadUrl() string {
 	if x != nil {
-		return x.Page
+		return x.UploadUrl
+	}
+	return ""
+}
+
+func (x *AttachmentUpload) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *AttachmentUpload) GetExpiresAtUnix() int64 {
+	if x != nil {
+		return x.ExpiresAtUnix
 	}
 	return 0
 }
 
-func (x *ListMyOrdersRequest) GetSize() int32 {
+// user_id: optional; must be the caller when set
+type CompleteAttachmentUploadRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttachmentId  string                 `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompleteAttachmentUploadRequest) Reset() {
+	*x = CompleteAttachmentUploadRequest{}
+	mi := &file_collecting_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompleteAttachmentUploadRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompleteAttachmentUploadRequest) ProtoMessage() {}
+
+func (x *CompleteAttachmentUploadRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[47]
 	if x != nil {
-		return x.Size
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompleteAttachmentUploadRequest.ProtoReflect.Descriptor instead.
+func (*CompleteAttachmentUploadRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *CompleteAttachmentUploadRequest) GetAttachmentId() string {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return ""
+}
+
+func (x *CompleteAttachmentUploadRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type UploadAttachmentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Part:
+	//
+	//	*UploadAttachmentRequest_Info
+	//	*UploadAttachmentRequest_Chunk
+	Part          isUploadAttachmentRequest_Part `protobuf_oneof:"part"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UploadAttachmentRequest) Reset() {
+	*x = UploadAttachmentRequest{}
+	mi := &file_collecting_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UploadAttachmentRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UploadAttachmentRequest) ProtoMessage() {}
+
+func (x *UploadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UploadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*UploadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *UploadAttachmentRequest) GetPart() isUploadAttachmentRequest_Part {
+	if x != nil {
+		return x.Part
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetInfo() *AttachmentInfo {
+	if x != nil {
+		if x, ok := x.Part.(*UploadAttachmentRequest_Info); ok {
+			return x.Info
+		}
+	}
+	return nil
+}
+
+func (x *UploadAttachmentRequest) GetChunk() []byte {
+	if x != nil {
+		if x, ok := x.Part.(*UploadAttachmentRequest_Chunk); ok {
+			return x.Chunk
+		}
+	}
+	return nil
+}
+
+type isUploadAttachmentRequest_Part interface {
+	isUploadAttachmentRequest_Part()
+}
+
+type UploadAttachmentRequest_Info struct {
+	Info *AttachmentInfo `protobuf:"bytes,1,opt,name=info,proto3,oneof"` // first frame
+}
+
+type UploadAttachmentRequest_Chunk struct {
+	Chunk []byte `protobuf:"bytes,2,opt,name=chunk,proto3,oneof"`
+}
+
+func (*UploadAttachmentRequest_Info) isUploadAttachmentRequest_Part() {}
+
+func (*UploadAttachmentRequest_Chunk) isUploadAttachmentRequest_Part() {}
+
+// user_id defaults to the caller; only admins may name another user
+type GetAttachmentURLRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AttachmentId  string                 `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetAttachmentURLRequest) Reset() {
+	*x = GetAttachmentURLRequest{}
+	mi := &file_collecting_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetAttachmentURLRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetAttachmentURLRequest) ProtoMessage() {}
+
+func (x *GetAttachmentURLRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetAttachmentURLRequest.ProtoReflect.Descriptor instead.
+func (*GetAttachmentURLRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *GetAttachmentURLRequest) GetAttachmentId() string {
+	if x != nil {
+		return x.AttachmentId
+	}
+	return ""
+}
+
+func (x *GetAttachmentURLRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type AttachmentURL struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Url           string                 `protobuf:"bytes,1,opt,name=url,proto3" json:"url,omitempty"`
+	ExpiresAtUnix int64                  `protobuf:"varint,2,opt,name=expires_at_unix,json=expiresAtUnix,proto3" json:"expires_at_unix,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentURL) Reset() {
+	*x = AttachmentURL{}
+	mi := &file_collecting_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentURL) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentURL) ProtoMessage() {}
+
+func (x *AttachmentURL) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentURL.ProtoReflect.Descriptor instead.
+func (*AttachmentURL) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *AttachmentURL) GetUrl() string {
+	if x != nil {
+		return x.Url
+	}
+	return ""
+}
+
+func (x *AttachmentURL) GetExpiresAtUnix() int64 {
+	if x != nil {
+		return x.ExpiresAtUnix
 	}
 	return 0
 }
 
-type ListOrdersResponse struct {
+// user_id defaults to the caller; only admins may name another user
+type DownloadAttachmentRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Orders        []*Order               `protobuf:"bytes,1,rep,name=orders,proto3" json:"orders,omitempty"`
+	AttachmentId  string                 `protobuf:"bytes,1,opt,name=attachment_id,json=attachmentId,proto3" json:"attachment_id,omitempty"`
+	UserId        string                 `protobuf:"bytes,2,opt,name=user_id,json=userId,proto3" json:"user_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ListOrdersResponse) Reset() {
-	*x = ListOrdersResponse{}
-	mi := &file_collecting_proto_msgTypes[13]
+func (x *DownloadAttachmentRequest) Reset() {
+	*x = DownloadAttachmentRequest{}
+	mi := &file_collecting_proto_msgTypes[51]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ListOrdersResponse) String() string {
+func (x *DownloadAttachmentRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ListOrdersResponse) ProtoMessage() {}
+func (*DownloadAttachmentRequest) ProtoMessage() {}
 
-func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_collecting_proto_msgTypes[13]
+func (x *DownloadAttachmentRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[51]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -833,18 +3833,77 @@ func (x *ListOrdersResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ListOrdersResponse.ProtoReflect.Descriptor instead.
-func (*ListOrdersResponse) Descriptor() ([]byte, []int) {
-	return file_collecting_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use DownloadAttachmentRequest.ProtoReflect.Descriptor instead.
+func (*DownloadAttachmentRequest) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{51}
 }
 
-func (x *ListOrdersResponse) GetOrders() []*Order {
+func (x *DownloadAttachmentRequest) GetAttachmentId() string {
 	if x != nil {
-		return x.Orders
+		return x.AttachmentId
+	}
+	return ""
+}
+
+func (x *DownloadAttachmentRequest) GetUserId() string {
+	if x != nil {
+		return x.UserId
+	}
+	return ""
+}
+
+type AttachmentChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // first chunk only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AttachmentChunk) Reset() {
+	*x = AttachmentChunk{}
+	mi := &file_collecting_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AttachmentChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AttachmentChunk) ProtoMessage() {}
+
+func (x *AttachmentChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_collecting_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AttachmentChunk.ProtoReflect.Descriptor instead.
+func (*AttachmentChunk) Descriptor() ([]byte, []int) {
+	return file_collecting_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *AttachmentChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
 	}
 	return nil
 }
 
+func (x *AttachmentChunk) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
 var File_collecting_proto protoreflect.FileDescriptor
 
 const file_collecting_proto_rawDesc = "" +
@@ -857,15 +3916,16 @@ const file_collecting_proto_rawDesc = "" +
 	"\x03lng\x18\x03 \x01(\x01R\x03lng\"K\n" +
 	"\x10CustomerSnapshot\x12!\n" +
 	"\fdisplay_name\x18\x01 \x01(\tR\vdisplayName\x12\x14\n" +
-	"\x05phone\x18\x02 \x01(\tR\x05phone\"7\n" +
+	"\x05phone\x18\x02 \x01(\tR\x05phone\"}\n" +
 	"\tWasteItem\x12\x12\n" +
 	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
-	"\x06weight\x18\x02 \x01(\x01R\x06weight\"\x92\x04\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\x12D\n" +
+	"\vattachments\x18\x03 \x03(\v2\".ecopoint.collecting.v1.AttachmentR\vattachments\"\xab\a\n" +
 	"\x05Order\x12\x0e\n" +
 	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1f\n" +
 	"\vcustomer_id\x18\x02 \x01(\tR\n" +
-	"customerId\x12\x16\n" +
-	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1f\n" +
+	"customerId\x12\x1a\n" +
+	"\x06status\x18\x03 \x01(\tB\x02\x18\x01R\x06status\x12\x1f\n" +
 	"\vaccepted_by\x18\x04 \x01(\tR\n" +
 	"acceptedBy\x12S\n" +
 	"\x15pick_address_snapshot\x18\x05 \x01(\v2\x1f.ecopoint.collecting.v1.AddressR\x13pickAddressSnapshot\x12U\n" +
@@ -879,7 +3939,17 @@ const file_collecting_proto_rawDesc = "" +
 	"\veta_minutes\x18\v \x01(\x05R\n" +
 	"etaMinutes\x12\x12\n" +
 	"\x04note\x18\f \x01(\tR\x04note\x12\x18\n" +
-	"\aversion\x18\r \x01(\x03R\aversion\"\xe9\x02\n" +
+	"\aversion\x18\r \x01(\x03R\aversion\x12F\n" +
+	"\forder_status\x18\x0e \x01(\x0e2#.ecopoint.collecting.v1.OrderStatusR\vorderStatus\x12C\n" +
+	"\vcancel_side\x18\x0f \x01(\x0e2\".ecopoint.collecting.v1.CancelSideR\n" +
+	"cancelSide\x12#\n" +
+	"\rcancel_reason\x18\x10 \x01(\tR\fcancelReason\x12&\n" +
+	"\x0fcreated_at_unix\x18\x11 \x01(\x03R\rcreatedAtUnix\x12(\n" +
+	"\x10accepted_at_unix\x18\x12 \x01(\x03R\x0eacceptedAtUnix\x12*\n" +
+	"\x11completed_at_unix\x18\x13 \x01(\x03R\x0fcompletedAtUnix\x12\x1d\n" +
+	"\n" +
+	"cancel_fee\x18\x14 \x01(\x01R\tcancelFee\x12D\n" +
+	"\vattachments\x18\x15 \x03(\v2\".ecopoint.collecting.v1.AttachmentR\vattachments\"\xb1\x03\n" +
 	"\x12CreateOrderRequest\x12\x1f\n" +
 	"\vcustomer_id\x18\x01 \x01(\tR\n" +
 	"customerId\x12B\n" +
@@ -888,29 +3958,268 @@ const file_collecting_proto_rawDesc = "" +
 	"\x05items\x18\x04 \x03(\v2!.ecopoint.collecting.v1.WasteItemR\x05items\x12!\n" +
 	"\ftotal_weight\x18\x05 \x01(\x01R\vtotalWeight\x12'\n" +
 	"\x0festimated_price\x18\x06 \x01(\x01R\x0eestimatedPrice\x12\x12\n" +
-	"\x04note\x18\a \x01(\tR\x04note\"2\n" +
-	"\x1aListAvailableOrdersRequest\x12\x14\n" +
-	"\x05limit\x18\x01 \x01(\x05R\x05limit\"T\n" +
+	"\x04note\x18\a \x01(\tR\x04note\x12'\n" +
+	"\x0fidempotency_key\x18\b \x01(\tR\x0eidempotencyKey\x12\x1d\n" +
+	"\n" +
+	"address_id\x18\t \x01(\x04R\taddressId\"r\n" +
+	"\x1aListAvailableOrdersRequest\x12\x18\n" +
+	"\x05limit\x18\x01 \x01(\x05B\x02\x18\x01R\x05limit\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"|\n" +
 	"\x1bListAvailableOrdersResponse\x125\n" +
-	"\x06orders\x18\x01 \x03(\v2\x1d.ecopoint.collecting.v1.OrderR\x06orders\"R\n" +
+	"\x06orders\x18\x01 \x03(\v2\x1d.ecopoint.collecting.v1.OrderR\x06orders\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"{\n" +
 	"\x12AcceptOrderRequest\x12\x19\n" +
 	"\border_id\x18\x01 \x01(\tR\aorderId\x12!\n" +
-	"\fcollector_id\x18\x02 \x01(\tR\vcollectorId\"p\n" +
+	"\fcollector_id\x18\x02 \x01(\tR\vcollectorId\x12'\n" +
+	"\x0fidempotency_key\x18\x03 \x01(\tR\x0eidempotencyKey\"\xe3\x01\n" +
 	"\x18UpdateOrderStatusRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x16\n" +
-	"\x06status\x18\x02 \x01(\tR\x06status\x12!\n" +
-	"\fcollector_id\x18\x03 \x01(\tR\vcollectorId\",\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1a\n" +
+	"\x06status\x18\x02 \x01(\tB\x02\x18\x01R\x06status\x12!\n" +
+	"\fcollector_id\x18\x03 \x01(\tR\vcollectorId\x12D\n" +
+	"\vnext_status\x18\x04 \x01(\x0e2#.ecopoint.collecting.v1.OrderStatusR\n" +
+	"nextStatus\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\"\xcb\x01\n" +
+	"\x12CancelOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x126\n" +
+	"\x04side\x18\x02 \x01(\x0e2\".ecopoint.collecting.v1.CancelSideR\x04side\x12!\n" +
+	"\fcollector_id\x18\x03 \x01(\tR\vcollectorId\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12'\n" +
+	"\x0fidempotency_key\x18\x05 \x01(\tR\x0eidempotencyKey\",\n" +
 	"\x0fGetOrderRequest\x12\x19\n" +
-	"\border_id\x18\x01 \x01(\tR\aorderId\">\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\"z\n" +
 	"\x19ListMyActiveOrdersRequest\x12!\n" +
-	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\"^\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\xa2\x01\n" +
 	"\x13ListMyOrdersRequest\x12\x1f\n" +
 	"\vcustomer_id\x18\x01 \x01(\tR\n" +
-	"customerId\x12\x12\n" +
-	"\x04page\x18\x02 \x01(\x05R\x04page\x12\x12\n" +
-	"\x04size\x18\x03 \x01(\x05R\x04size\"K\n" +
+	"customerId\x12\x16\n" +
+	"\x04page\x18\x02 \x01(\x05B\x02\x18\x01R\x04page\x12\x16\n" +
+	"\x04size\x18\x03 \x01(\x05B\x02\x18\x01R\x04size\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x04 \x01(\tR\tpageToken\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\"s\n" +
 	"\x12ListOrdersResponse\x125\n" +
-	"\x06orders\x18\x01 \x03(\v2\x1d.ecopoint.collecting.v1.OrderR\x06orders2\xdf\x05\n" +
+	"\x06orders\x18\x01 \x03(\v2\x1d.ecopoint.collecting.v1.OrderR\x06orders\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\xf2\x01\n" +
+	"\x1aListCollectorOrdersRequest\x12!\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\x12?\n" +
+	"\bstatuses\x18\x02 \x03(\x0e2#.ecopoint.collecting.v1.OrderStatusR\bstatuses\x12\x1b\n" +
+	"\tfrom_unix\x18\x03 \x01(\x03R\bfromUnix\x12\x17\n" +
+	"\ato_unix\x18\x04 \x01(\x03R\x06toUnix\x12\x1b\n" +
+	"\tpage_size\x18\x05 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x06 \x01(\tR\tpageToken\"\xd7\x01\n" +
+	"\x1bGetCollectorEarningsRequest\x12!\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\x12\x1b\n" +
+	"\tfrom_unix\x18\x02 \x01(\x03R\bfromUnix\x12\x17\n" +
+	"\ato_unix\x18\x03 \x01(\x03R\x06toUnix\x12B\n" +
+	"\bgroup_by\x18\x04 \x01(\x0e2'.ecopoint.collecting.v1.EarningsGroupByR\agroupBy\x12\x1b\n" +
+	"\ttime_zone\x18\x05 \x01(\tR\btimeZone\"9\n" +
+	"\vWasteWeight\x12\x12\n" +
+	"\x04type\x18\x01 \x01(\tR\x04type\x12\x16\n" +
+	"\x06weight\x18\x02 \x01(\x01R\x06weight\"\xce\x01\n" +
+	"\x0eEarningsBucket\x12*\n" +
+	"\x11period_start_unix\x18\x01 \x01(\x03R\x0fperiodStartUnix\x12\x16\n" +
+	"\x06orders\x18\x02 \x01(\x05R\x06orders\x12=\n" +
+	"\aweights\x18\x03 \x03(\v2#.ecopoint.collecting.v1.WasteWeightR\aweights\x12!\n" +
+	"\ftotal_weight\x18\x04 \x01(\x01R\vtotalWeight\x12\x16\n" +
+	"\x06amount\x18\x05 \x01(\x01R\x06amount\"\x93\x01\n" +
+	"\x11CollectorEarnings\x12@\n" +
+	"\abuckets\x18\x01 \x03(\v2&.ecopoint.collecting.v1.EarningsBucketR\abuckets\x12<\n" +
+	"\x05total\x18\x02 \x01(\v2&.ecopoint.collecting.v1.EarningsBucketR\x05total\"\xc2\x01\n" +
+	"\x10RateOrderRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x19\n" +
+	"\brater_id\x18\x02 \x01(\tR\araterId\x124\n" +
+	"\x04side\x18\x03 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\x04side\x12\x14\n" +
+	"\x05stars\x18\x04 \x01(\x05R\x05stars\x12\x12\n" +
+	"\x04tags\x18\x05 \x03(\tR\x04tags\x12\x18\n" +
+	"\acomment\x18\x06 \x01(\tR\acomment\"\xfb\x01\n" +
+	"\x06Rating\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x124\n" +
+	"\x04side\x18\x02 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\x04side\x12\x19\n" +
+	"\brater_id\x18\x03 \x01(\tR\araterId\x12\x19\n" +
+	"\bratee_id\x18\x04 \x01(\tR\arateeId\x12\x14\n" +
+	"\x05stars\x18\x05 \x01(\x05R\x05stars\x12\x12\n" +
+	"\x04tags\x18\x06 \x03(\tR\x04tags\x12\x18\n" +
+	"\acomment\x18\a \x01(\tR\acomment\x12&\n" +
+	"\x0fcreated_at_unix\x18\b \x01(\x03R\rcreatedAtUnix\"e\n" +
+	"\x14GetUserRatingRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x124\n" +
+	"\x04role\x18\x02 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\x04role\"2\n" +
+	"\bTagCount\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x03R\x05count\"\xc1\x01\n" +
+	"\n" +
+	"UserRating\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x124\n" +
+	"\x04role\x18\x02 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\x04role\x12\x14\n" +
+	"\x05count\x18\x03 \x01(\x03R\x05count\x12\x18\n" +
+	"\aaverage\x18\x04 \x01(\x01R\aaverage\x124\n" +
+	"\x04tags\x18\x05 \x03(\v2 .ecopoint.collecting.v1.TagCountR\x04tags\"\xca\x01\n" +
+	"\x18SearchUserRatingsRequest\x124\n" +
+	"\x04role\x18\x01 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\x04role\x12\x1f\n" +
+	"\vmax_average\x18\x02 \x01(\x01R\n" +
+	"maxAverage\x12\x1b\n" +
+	"\tmin_count\x18\x03 \x01(\x03R\bminCount\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x05 \x01(\tR\tpageToken\"\x81\x01\n" +
+	"\x19SearchUserRatingsResponse\x12<\n" +
+	"\aratings\x18\x01 \x03(\v2\".ecopoint.collecting.v1.UserRatingR\aratings\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"?\n" +
+	"\x1aGetCollectorPenaltyRequest\x12!\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\"\\\n" +
+	"\x1cClearCollectorPenaltyRequest\x12!\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\x12\x19\n" +
+	"\badmin_id\x18\x02 \x01(\tR\aadminId\"P\n" +
+	"\x06Strike\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x17\n" +
+	"\aat_unix\x18\x03 \x01(\x03R\x06atUnix\"\xb4\x02\n" +
+	"\x10CollectorPenalty\x12!\n" +
+	"\fcollector_id\x18\x01 \x01(\tR\vcollectorId\x12\x1c\n" +
+	"\tsuspended\x18\x02 \x01(\bR\tsuspended\x12.\n" +
+	"\x13cooldown_until_unix\x18\x03 \x01(\x03R\x11cooldownUntilUnix\x12\x16\n" +
+	"\x06reason\x18\x04 \x01(\tR\x06reason\x12\x16\n" +
+	"\x06points\x18\x05 \x01(\x05R\x06points\x128\n" +
+	"\astrikes\x18\x06 \x03(\v2\x1e.ecopoint.collecting.v1.StrikeR\astrikes\x12&\n" +
+	"\x0fcleared_at_unix\x18\a \x01(\x03R\rclearedAtUnix\x12\x1d\n" +
+	"\n" +
+	"cleared_by\x18\b \x01(\tR\tclearedBy\"\xd2\x02\n" +
+	"\x19UpdateOrderDetailsRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x1f\n" +
+	"\vcustomer_id\x18\x02 \x01(\tR\n" +
+	"customerId\x12\x18\n" +
+	"\aversion\x18\x03 \x01(\x03R\aversion\x12B\n" +
+	"\fpick_address\x18\x04 \x01(\v2\x1f.ecopoint.collecting.v1.AddressR\vpickAddress\x127\n" +
+	"\x05items\x18\x05 \x03(\v2!.ecopoint.collecting.v1.WasteItemR\x05items\x12,\n" +
+	"\x0festimated_price\x18\x06 \x01(\x01H\x00R\x0eestimatedPrice\x88\x01\x01\x12\x17\n" +
+	"\x04note\x18\a \x01(\tH\x01R\x04note\x88\x01\x01B\x12\n" +
+	"\x10_estimated_priceB\a\n" +
+	"\x05_note\"<\n" +
+	"!GetNotificationPreferencesRequest\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\"\x89\x01\n" +
+	"\x17NotificationPreferences\x12\x17\n" +
+	"\auser_id\x18\x01 \x01(\tR\x06userId\x12\x16\n" +
+	"\x06locale\x18\x02 \x01(\tR\x06locale\x12\x1a\n" +
+	"\bdisabled\x18\x03 \x01(\bR\bdisabled\x12!\n" +
+	"\fmuted_events\x18\x04 \x03(\tR\vmutedEvents\"\xfa\x01\n" +
+	"\vChatMessage\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\border_id\x18\x02 \x01(\tR\aorderId\x12\x1b\n" +
+	"\tsender_id\x18\x03 \x01(\tR\bsenderId\x12A\n" +
+	"\vsender_role\x18\x04 \x01(\x0e2 .ecopoint.collecting.v1.UserRoleR\n" +
+	"senderRole\x12\x12\n" +
+	"\x04text\x18\x05 \x01(\tR\x04text\x12%\n" +
+	"\x0fsent_at_unix_ms\x18\x06 \x01(\x03R\fsentAtUnixMs\x12%\n" +
+	"\x0fread_at_unix_ms\x18\a \x01(\x03R\freadAtUnixMs\">\n" +
+	"\bChatJoin\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"\x1e\n" +
+	"\bChatSend\x12\x12\n" +
+	"\x04text\x18\x01 \x01(\tR\x04text\"2\n" +
+	"\bChatRead\x12&\n" +
+	"\x0flast_message_id\x18\x01 \x01(\tR\rlastMessageId\"\xc2\x01\n" +
+	"\x0fChatClientFrame\x126\n" +
+	"\x04join\x18\x01 \x01(\v2 .ecopoint.collecting.v1.ChatJoinH\x00R\x04join\x126\n" +
+	"\x04send\x18\x02 \x01(\v2 .ecopoint.collecting.v1.ChatSendH\x00R\x04send\x126\n" +
+	"\x04read\x18\x03 \x01(\v2 .ecopoint.collecting.v1.ChatReadH\x00R\x04readB\a\n" +
+	"\x05frame\"t\n" +
+	"\vChatReceipt\x12\x1b\n" +
+	"\treader_id\x18\x01 \x01(\tR\breaderId\x12!\n" +
+	"\rup_to_unix_ms\x18\x02 \x01(\x03R\n" +
+	"upToUnixMs\x12%\n" +
+	"\x0fread_at_unix_ms\x18\x03 \x01(\x03R\freadAtUnixMs\"$\n" +
+	"\n" +
+	"ChatClosed\x12\x16\n" +
+	"\x06reason\x18\x01 \x01(\tR\x06reason\"\xda\x01\n" +
+	"\x0fChatServerFrame\x12?\n" +
+	"\amessage\x18\x01 \x01(\v2#.ecopoint.collecting.v1.ChatMessageH\x00R\amessage\x12?\n" +
+	"\areceipt\x18\x02 \x01(\v2#.ecopoint.collecting.v1.ChatReceiptH\x00R\areceipt\x12<\n" +
+	"\x06closed\x18\x03 \x01(\v2\".ecopoint.collecting.v1.ChatClosedH\x00R\x06closedB\a\n" +
+	"\x05frame\"\x8e\x01\n" +
+	"\x17ListChatMessagesRequest\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12\"\n" +
+	"\rafter_unix_ms\x18\x03 \x01(\x03R\vafterUnixMs\x12\x1b\n" +
+	"\tpage_size\x18\x04 \x01(\x05R\bpageSize\"[\n" +
+	"\x18ListChatMessagesResponse\x12?\n" +
+	"\bmessages\x18\x01 \x03(\v2#.ecopoint.collecting.v1.ChatMessageR\bmessages\"\xda\x01\n" +
+	"\n" +
+	"Attachment\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12:\n" +
+	"\x04kind\x18\x02 \x01(\x0e2&.ecopoint.collecting.v1.AttachmentKindR\x04kind\x12!\n" +
+	"\fcontent_type\x18\x03 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x04 \x01(\x03R\x04size\x12\x1f\n" +
+	"\vuploaded_by\x18\x05 \x01(\tR\n" +
+	"uploadedBy\x12(\n" +
+	"\x10uploaded_at_unix\x18\x06 \x01(\x03R\x0euploadedAtUnix\"\xd6\x01\n" +
+	"\x0eAttachmentInfo\x12\x19\n" +
+	"\border_id\x18\x01 \x01(\tR\aorderId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\x12:\n" +
+	"\x04kind\x18\x03 \x01(\x0e2&.ecopoint.collecting.v1.AttachmentKindR\x04kind\x12\x1d\n" +
+	"\n" +
+	"item_index\x18\x04 \x01(\x05R\titemIndex\x12!\n" +
+	"\fcontent_type\x18\x05 \x01(\tR\vcontentType\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\"\x8b\x02\n" +
+	"\x10AttachmentUpload\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\x12\x1d\n" +
+	"\n" +
+	"upload_url\x18\x02 \x01(\tR\tuploadUrl\x12O\n" +
+	"\aheaders\x18\x03 \x03(\v25.ecopoint.collecting.v1.AttachmentUpload.HeadersEntryR\aheaders\x12&\n" +
+	"\x0fexpires_at_unix\x18\x04 \x01(\x03R\rexpiresAtUnix\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"_\n" +
+	"\x1fCompleteAttachmentUploadRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"w\n" +
+	"\x17UploadAttachmentRequest\x12<\n" +
+	"\x04info\x18\x01 \x01(\v2&.ecopoint.collecting.v1.AttachmentInfoH\x00R\x04info\x12\x16\n" +
+	"\x05chunk\x18\x02 \x01(\fH\x00R\x05chunkB\x06\n" +
+	"\x04part\"W\n" +
+	"\x17GetAttachmentURLRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"I\n" +
+	"\rAttachmentURL\x12\x10\n" +
+	"\x03url\x18\x01 \x01(\tR\x03url\x12&\n" +
+	"\x0fexpires_at_unix\x18\x02 \x01(\x03R\rexpiresAtUnix\"Y\n" +
+	"\x19DownloadAttachmentRequest\x12#\n" +
+	"\rattachment_id\x18\x01 \x01(\tR\fattachmentId\x12\x17\n" +
+	"\auser_id\x18\x02 \x01(\tR\x06userId\"H\n" +
+	"\x0fAttachmentChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType*\xb0\x01\n" +
+	"\vOrderStatus\x12\x1c\n" +
+	"\x18ORDER_STATUS_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14ORDER_STATUS_CREATED\x10\x01\x12\x19\n" +
+	"\x15ORDER_STATUS_ACCEPTED\x10\x02\x12\x17\n" +
+	"\x13ORDER_STATUS_ON_WAY\x10\x03\x12\x19\n" +
+	"\x15ORDER_STATUS_COMPLETE\x10\x04\x12\x1a\n" +
+	"\x16ORDER_STATUS_CANCELLED\x10\x05*v\n" +
+	"\n" +
+	"CancelSide\x12\x1b\n" +
+	"\x17CANCEL_SIDE_UNSPECIFIED\x10\x00\x12\x18\n" +
+	"\x14CANCEL_SIDE_CUSTOMER\x10\x01\x12\x19\n" +
+	"\x15CANCEL_SIDE_COLLECTOR\x10\x02\x12\x16\n" +
+	"\x12CANCEL_SIDE_SYSTEM\x10\x03*\x88\x01\n" +
+	"\x0fEarningsGroupBy\x12!\n" +
+	"\x1dEARNINGS_GROUP_BY_UNSPECIFIED\x10\x00\x12\x19\n" +
+	"\x15EARNINGS_GROUP_BY_DAY\x10\x01\x12\x1a\n" +
+	"\x16EARNINGS_GROUP_BY_WEEK\x10\x02\x12\x1b\n" +
+	"\x17EARNINGS_GROUP_BY_MONTH\x10\x03*V\n" +
+	"\bUserRole\x12\x19\n" +
+	"\x15USER_ROLE_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12USER_ROLE_CUSTOMER\x10\x01\x12\x17\n" +
+	"\x13USER_ROLE_COLLECTOR\x10\x02*w\n" +
+	"\x0eAttachmentKind\x12\x1f\n" +
+	"\x1bATTACHMENT_KIND_UNSPECIFIED\x10\x00\x12\x1e\n" +
+	"\x1aATTACHMENT_KIND_ITEM_PHOTO\x10\x01\x12$\n" +
+	" ATTACHMENT_KIND_COMPLETION_PROOF\x10\x022\xb8\x15\n" +
 	"\x11CollectingService\x12X\n" +
 	"\vCreateOrder\x12*.ecopoint.collecting.v1.CreateOrderRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12~\n" +
 	"\x13ListAvailableOrders\x122.ecopoint.collecting.v1.ListAvailableOrdersRequest\x1a3.ecopoint.collecting.v1.ListAvailableOrdersResponse\x12X\n" +
@@ -918,7 +4227,25 @@ const file_collecting_proto_rawDesc = "" +
 	"\x11UpdateOrderStatus\x120.ecopoint.collecting.v1.UpdateOrderStatusRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12R\n" +
 	"\bGetOrder\x12'.ecopoint.collecting.v1.GetOrderRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12s\n" +
 	"\x12ListMyActiveOrders\x121.ecopoint.collecting.v1.ListMyActiveOrdersRequest\x1a*.ecopoint.collecting.v1.ListOrdersResponse\x12g\n" +
-	"\fListMyOrders\x12+.ecopoint.collecting.v1.ListMyOrdersRequest\x1a*.ecopoint.collecting.v1.ListOrdersResponseB#Z!ecopoint/collecting_service/pb;pbb\x06proto3"
+	"\fListMyOrders\x12+.ecopoint.collecting.v1.ListMyOrdersRequest\x1a*.ecopoint.collecting.v1.ListOrdersResponse\x12X\n" +
+	"\vCancelOrder\x12*.ecopoint.collecting.v1.CancelOrderRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12f\n" +
+	"\x12UpdateOrderDetails\x121.ecopoint.collecting.v1.UpdateOrderDetailsRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12u\n" +
+	"\x13ListCollectorOrders\x122.ecopoint.collecting.v1.ListCollectorOrdersRequest\x1a*.ecopoint.collecting.v1.ListOrdersResponse\x12v\n" +
+	"\x14GetCollectorEarnings\x123.ecopoint.collecting.v1.GetCollectorEarningsRequest\x1a).ecopoint.collecting.v1.CollectorEarnings\x12U\n" +
+	"\tRateOrder\x12(.ecopoint.collecting.v1.RateOrderRequest\x1a\x1e.ecopoint.collecting.v1.Rating\x12a\n" +
+	"\rGetUserRating\x12,.ecopoint.collecting.v1.GetUserRatingRequest\x1a\".ecopoint.collecting.v1.UserRating\x12x\n" +
+	"\x11SearchUserRatings\x120.ecopoint.collecting.v1.SearchUserRatingsRequest\x1a1.ecopoint.collecting.v1.SearchUserRatingsResponse\x12s\n" +
+	"\x13GetCollectorPenalty\x122.ecopoint.collecting.v1.GetCollectorPenaltyRequest\x1a(.ecopoint.collecting.v1.CollectorPenalty\x12w\n" +
+	"\x15ClearCollectorPenalty\x124.ecopoint.collecting.v1.ClearCollectorPenaltyRequest\x1a(.ecopoint.collecting.v1.CollectorPenalty\x12\x88\x01\n" +
+	"\x1aGetNotificationPreferences\x129.ecopoint.collecting.v1.GetNotificationPreferencesRequest\x1a/.ecopoint.collecting.v1.NotificationPreferences\x12~\n" +
+	"\x1aSetNotificationPreferences\x12/.ecopoint.collecting.v1.NotificationPreferences\x1a/.ecopoint.collecting.v1.NotificationPreferences\x12\\\n" +
+	"\x04Chat\x12'.ecopoint.collecting.v1.ChatClientFrame\x1a'.ecopoint.collecting.v1.ChatServerFrame(\x010\x01\x12u\n" +
+	"\x10ListChatMessages\x12/.ecopoint.collecting.v1.ListChatMessagesRequest\x1a0.ecopoint.collecting.v1.ListChatMessagesResponse\x12j\n" +
+	"\x16CreateAttachmentUpload\x12&.ecopoint.collecting.v1.AttachmentInfo\x1a(.ecopoint.collecting.v1.AttachmentUpload\x12r\n" +
+	"\x18CompleteAttachmentUpload\x127.ecopoint.collecting.v1.CompleteAttachmentUploadRequest\x1a\x1d.ecopoint.collecting.v1.Order\x12d\n" +
+	"\x10UploadAttachment\x12/.ecopoint.collecting.v1.UploadAttachmentRequest\x1a\x1d.ecopoint.collecting.v1.Order(\x01\x12j\n" +
+	"\x10GetAttachmentURL\x12/.ecopoint.collecting.v1.GetAttachmentURLRequest\x1a%.ecopoint.collecting.v1.AttachmentURL\x12r\n" +
+	"\x12DownloadAttachment\x121.ecopoint.collecting.v1.DownloadAttachmentRequest\x1a'.ecopoint.collecting.v1.AttachmentChunk0\x01B#Z!ecopoint/collecting_service/pb;pbb\x06proto3"
 
 var (
 	file_collecting_proto_rawDescOnce sync.Once
@@ -932,51 +4259,166 @@ func file_collecting_proto_rawDescGZIP() []byte {
 	return file_collecting_proto_rawDescData
 }
 
-var file_collecting_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_collecting_proto_enumTypes = make([]protoimpl.EnumInfo, 5)
+var file_collecting_proto_msgTypes = make([]protoimpl.MessageInfo, 54)
 var file_collecting_proto_goTypes = []any{
-	(*Empty)(nil),                       // 0: ecopoint.collecting.v1.Empty
-	(*Address)(nil),                     // 1: ecopoint.collecting.v1.Address
-	(*CustomerSnapshot)(nil),            // 2: ecopoint.collecting.v1.CustomerSnapshot
-	(*WasteItem)(nil),                   // 3: ecopoint.collecting.v1.WasteItem
-	(*Order)(nil),                       // 4: ecopoint.collecting.v1.Order
-	(*CreateOrderRequest)(nil),          // 5: ecopoint.collecting.v1.CreateOrderRequest
-	(*ListAvailableOrdersRequest)(nil),  // 6: ecopoint.collecting.v1.ListAvailableOrdersRequest
-	(*ListAvailableOrdersResponse)(nil), // 7: ecopoint.collecting.v1.ListAvailableOrdersResponse
-	(*AcceptOrderRequest)(nil),          // 8: ecopoint.collecting.v1.AcceptOrderRequest
-	(*UpdateOrderStatusRequest)(nil),    // 9: ecopoint.collecting.v1.UpdateOrderStatusRequest
-	(*GetOrderRequest)(nil),             // 10: ecopoint.collecting.v1.GetOrderRequest
-	(*ListMyActiveOrdersRequest)(nil),   // 11: ecopoint.collecting.v1.ListMyActiveOrdersRequest
-	(*ListMyOrdersRequest)(nil),         // 12: ecopoint.collecting.v1.ListMyOrdersRequest
-	(*ListOrdersResponse)(nil),          // 13: ecopoint.collecting.v1.ListOrdersResponse
+	(OrderStatus)(0),                          // 0: ecopoint.collecting.v1.OrderStatus
+	(CancelSide)(0),                           // 1: ecopoint.collecting.v1.CancelSide
+	(EarningsGroupBy)(0),                      // 2: ecopoint.collecting.v1.EarningsGroupBy
+	(UserRole)(0),                             // 3: ecopoint.collecting.v1.UserRole
+	(AttachmentKind)(0),                       // 4: ecopoint.collecting.v1.AttachmentKind
+	(*Empty)(nil),                             // 5: ecopoint.collecting.v1.Empty
+	(*Address)(nil),                           // 6: ecopoint.collecting.v1.Address
+	(*CustomerSnapshot)(nil),                  // 7: ecopoint.collecting.v1.CustomerSnapshot
+	(*WasteItem)(nil),                         // 8: ecopoint.collecting.v1.WasteItem
+	(*Order)(nil),                             // 9: ecopoint.collecting.v1.Order
+	(*CreateOrderRequest)(nil),                // 10: ecopoint.collecting.v1.CreateOrderRequest
+	(*ListAvailableOrdersRequest)(nil),        // 11: ecopoint.collecting.v1.ListAvailableOrdersRequest
+	(*ListAvailableOrdersResponse)(nil),       // 12: ecopoint.collecting.v1.ListAvailableOrdersResponse
+	(*AcceptOrderRequest)(nil),                // 13: ecopoint.collecting.v1.AcceptOrderRequest
+	(*UpdateOrderStatusRequest)(nil),          // 14: ecopoint.collecting.v1.UpdateOrderStatusRequest
+	(*CancelOrderRequest)(nil),                // 15: ecopoint.collecting.v1.CancelOrderRequest
+	(*GetOrderRequest)(nil),                   // 16: ecopoint.collecting.v1.GetOrderRequest
+	(*ListMyActiveOrdersRequest)(nil),         // 17: ecopoint.collecting.v1.ListMyActiveOrdersRequest
+	(*ListMyOrdersRequest)(nil),               // 18: ecopoint.collecting.v1.ListMyOrdersRequest
+	(*ListOrdersResponse)(nil),                // 19: ecopoint.collecting.v1.ListOrdersResponse
+	(*ListCollectorOrdersRequest)(nil),        // 20: ecopoint.collecting.v1.ListCollectorOrdersRequest
+	(*GetCollectorEarningsRequest)(nil),       // 21: ecopoint.collecting.v1.GetCollectorEarningsRequest
+	(*WasteWeight)(nil),                       // 22: ecopoint.collecting.v1.WasteWeight
+	(*EarningsBucket)(nil),                    // 23: ecopoint.collecting.v1.EarningsBucket
+	(*CollectorEarnings)(nil),                 // 24: ecopoint.collecting.v1.CollectorEarnings
+	(*RateOrderRequest)(nil),                  // 25: ecopoint.collecting.v1.RateOrderRequest
+	(*Rating)(nil),                            // 26: ecopoint.collecting.v1.Rating
+	(*GetUserRatingRequest)(nil),              // 27: ecopoint.collecting.v1.GetUserRatingRequest
+	(*TagCount)(nil),                          // 28: ecopoint.collecting.v1.TagCount
+	(*UserRating)(nil),                        // 29: ecopoint.collecting.v1.UserRating
+	(*SearchUserRatingsRequest)(nil),          // 30: ecopoint.collecting.v1.SearchUserRatingsRequest
+	(*SearchUserRatingsResponse)(nil),         // 31: ecopoint.collecting.v1.SearchUserRatingsResponse
+	(*GetCollectorPenaltyRequest)(nil),        // 32: ecopoint.collecting.v1.GetCollectorPenaltyRequest
+	(*ClearCollectorPenaltyRequest)(nil),      // 33: ecopoint.collecting.v1.ClearCollectorPenaltyRequest
+	(*Strike)(nil),                            // 34: ecopoint.collecting.v1.Strike
+	(*CollectorPenalty)(nil),                  // 35: ecopoint.collecting.v1.CollectorPenalty
+	(*UpdateOrderDetailsRequest)(nil),         // 36: ecopoint.collecting.v1.UpdateOrderDetailsRequest
+	(*GetNotificationPreferencesRequest)(nil), // 37: ecopoint.collecting.v1.GetNotificationPreferencesRequest
+	(*NotificationPreferences)(nil),           // 38: ecopoint.collecting.v1.NotificationPreferences
+	(*ChatMessage)(nil),                       // 39: ecopoint.collecting.v1.ChatMessage
+	(*ChatJoin)(nil),                          // 40: ecopoint.collecting.v1.ChatJoin
+	(*ChatSend)(nil),                          // 41: ecopoint.collecting.v1.ChatSend
+	(*ChatRead)(nil),                          // 42: ecopoint.collecting.v1.ChatRead
+	(*ChatClientFrame)(nil),                   // 43: ecopoint.collecting.v1.ChatClientFrame
+	(*ChatReceipt)(nil),                       // 44: ecopoint.collecting.v1.ChatReceipt
+	(*ChatClosed)(nil),                        // 45: ecopoint.collecting.v1.ChatClosed
+	(*ChatServerFrame)(nil),                   // 46: ecopoint.collecting.v1.ChatServerFrame
+	(*ListChatMessagesRequest)(nil),           // 47: ecopoint.collecting.v1.ListChatMessagesRequest
+	(*ListChatMessagesResponse)(nil),          // 48: ecopoint.collecting.v1.ListChatMessagesResponse
+	(*Attachment)(nil),                        // 49: ecopoint.collecting.v1.Attachment
+	(*AttachmentInfo)(nil),                    // 50: ecopoint.collecting.v1.AttachmentInfo
+	(*AttachmentUpload)(nil),                  // 51: ecopoint.collecting.v1.AttachmentUpload
+	(*CompleteAttachmentUploadRequest)(nil),   // 52: ecopoint.collecting.v1.CompleteAttachmentUploadRequest
+	(*UploadAttachmentRequest)(nil),           // 53: ecopoint.collecting.v1.UploadAttachmentRequest
+	(*GetAttachmentURLRequest)(nil),           // 54: ecopoint.collecting.v1.GetAttachmentURLRequest
+	(*AttachmentURL)(nil),                     // 55: ecopoint.collecting.v1.AttachmentURL
+	(*DownloadAttachmentRequest)(nil),         // 56: ecopoint.collecting.v1.DownloadAttachmentRequest
+	(*AttachmentChunk)(nil),                   // 57: ecopoint.collecting.v1.AttachmentChunk
+	nil,                                       // 58: ecopoint.collecting.v1.AttachmentUpload.HeadersEntry
 }
 var file_collecting_proto_depIdxs = []int32{
-	1,  // 0: ecopoint.collecting.v1.Order.pick_address_snapshot:type_name -> ecopoint.collecting.v1.Address
-	2,  // 1: ecopoint.collecting.v1.Order.customer_snapshot:type_name -> ecopoint.collecting.v1.CustomerSnapshot
-	3,  // 2: ecopoint.collecting.v1.Order.items:type_name -> ecopoint.collecting.v1.WasteItem
-	1,  // 3: ecopoint.collecting.v1.CreateOrderRequest.pick_address:type_name -> ecopoint.collecting.v1.Address
-	2,  // 4: ecopoint.collecting.v1.CreateOrderRequest.customer_snapshot:type_name -> ecopoint.collecting.v1.CustomerSnapshot
-	3,  // 5: ecopoint.collecting.v1.CreateOrderRequest.items:type_name -> ecopoint.collecting.v1.WasteItem
-	4,  // 6: ecopoint.collecting.v1.ListAvailableOrdersResponse.orders:type_name -> ecopoint.collecting.v1.Order
-	4,  // 7: ecopoint.collecting.v1.ListOrdersResponse.orders:type_name -> ecopoint.collecting.v1.Order
-	5,  // 8: ecopoint.collecting.v1.CollectingService.CreateOrder:input_type -> ecopoint.collecting.v1.CreateOrderRequest
-	6,  // 9: ecopoint.collecting.v1.CollectingService.ListAvailableOrders:input_type -> ecopoint.collecting.v1.ListAvailableOrdersRequest
-	8,  // 10: ecopoint.collecting.v1.CollectingService.AcceptOrder:input_type -> ecopoint.collecting.v1.AcceptOrderRequest
-	9,  // 11: ecopoint.collecting.v1.CollectingService.UpdateOrderStatus:input_type -> ecopoint.collecting.v1.UpdateOrderStatusRequest
-	10, // 12: ecopoint.collecting.v1.CollectingService.GetOrder:input_type -> ecopoint.collecting.v1.GetOrderRequest
-	11, // 13: ecopoint.collecting.v1.CollectingService.ListMyActiveOrders:input_type -> ecopoint.collecting.v1.ListMyActiveOrdersRequest
-	12, // 14: ecopoint.collecting.v1.CollectingService.ListMyOrders:input_type -> ecopoint.collecting.v1.ListMyOrdersRequest
-	4,  // 15: ecopoint.collecting.v1.CollectingService.CreateOrder:output_type -> ecopoint.collecting.v1.Order
-	7,  // 16: ecopoint.collecting.v1.CollectingService.ListAvailableOrders:output_type -> ecopoint.collecting.v1.ListAvailableOrdersResponse
-	4,  // 17: ecopoint.collecting.v1.CollectingService.AcceptOrder:output_type -> ecopoint.collecting.v1.Order
-	4,  // 18: ecopoint.collecting.v1.CollectingService.UpdateOrderStatus:output_type -> ecopoint.collecting.v1.Order
-	4,  // 19: ecopoint.collecting.v1.CollectingService.GetOrder:output_type -> ecopoint.collecting.v1.Order
-	13, // 20: ecopoint.collecting.v1.CollectingService.ListMyActiveOrders:output_type -> ecopoint.collecting.v1.ListOrdersResponse
-	13, // 21: ecopoint.collecting.v1.CollectingService.ListMyOrders:output_type -> ecopoint.collecting.v1.ListOrdersResponse
-	15, // [15:22] is the sub-list for method output_type
-	8,  // [8:15] is the sub-list for method input_type
-	8,  // [8:8] is the sub-list for extension type_name
-	8,  // [8:8] is the sub-list for extension extendee
-	0,  // [0:8] is the sub-list for field type_name
+	49, // 0: ecopoint.collecting.v1.WasteItem.attachments:type_name -> ecopoint.collecting.v1.Attachment
+	6,  // 1: ecopoint.collecting.v1.Order.pick_address_snapshot:type_name -> ecopoint.collecting.v1.Address
+	7,  // 2: ecopoint.collecting.v1.Order.customer_snapshot:type_name -> ecopoint.collecting.v1.CustomerSnapshot
+	8,  // 3: ecopoint.collecting.v1.Order.items:type_name -> ecopoint.collecting.v1.WasteItem
+	0,  // 4: ecopoint.collecting.v1.Order.order_status:type_name -> ecopoint.collecting.v1.OrderStatus
+	1,  // 5: ecopoint.collecting.v1.Order.cancel_side:type_name -> ecopoint.collecting.v1.CancelSide
+	49, // 6: ecopoint.collecting.v1.Order.attachments:type_name -> ecopoint.collecting.v1.Attachment
+	6,  // 7: ecopoint.collecting.v1.CreateOrderRequest.pick_address:type_name -> ecopoint.collecting.v1.Address
+	7,  // 8: ecopoint.collecting.v1.CreateOrderRequest.customer_snapshot:type_name -> ecopoint.collecting.v1.CustomerSnapshot
+	8,  // 9: ecopoint.collecting.v1.CreateOrderRequest.items:type_name -> ecopoint.collecting.v1.WasteItem
+	9,  // 10: ecopoint.collecting.v1.ListAvailableOrdersResponse.orders:type_name -> ecopoint.collecting.v1.Order
+	0,  // 11: ecopoint.collecting.v1.UpdateOrderStatusRequest.next_status:type_name -> ecopoint.collecting.v1.OrderStatus
+	1,  // 12: ecopoint.collecting.v1.CancelOrderRequest.side:type_name -> ecopoint.collecting.v1.CancelSide
+	9,  // 13: ecopoint.collecting.v1.ListOrdersResponse.orders:type_name -> ecopoint.collecting.v1.Order
+	0,  // 14: ecopoint.collecting.v1.ListCollectorOrdersRequest.statuses:type_name -> ecopoint.collecting.v1.OrderStatus
+	2,  // 15: ecopoint.collecting.v1.GetCollectorEarningsRequest.group_by:type_name -> ecopoint.collecting.v1.EarningsGroupBy
+	22, // 16: ecopoint.collecting.v1.EarningsBucket.weights:type_name -> ecopoint.collecting.v1.WasteWeight
+	23, // 17: ecopoint.collecting.v1.CollectorEarnings.buckets:type_name -> ecopoint.collecting.v1.EarningsBucket
+	23, // 18: ecopoint.collecting.v1.CollectorEarnings.total:type_name -> ecopoint.collecting.v1.EarningsBucket
+	3,  // 19: ecopoint.collecting.v1.RateOrderRequest.side:type_name -> ecopoint.collecting.v1.UserRole
+	3,  // 20: ecopoint.collecting.v1.Rating.side:type_name -> ecopoint.collecting.v1.UserRole
+	3,  // 21: ecopoint.collecting.v1.GetUserRatingRequest.role:type_name -> ecopoint.collecting.v1.UserRole
+	3,  // 22: ecopoint.collecting.v1.UserRating.role:type_name -> ecopoint.collecting.v1.UserRole
+	28, // 23: ecopoint.collecting.v1.UserRating.tags:type_name -> ecopoint.collecting.v1.TagCount
+	3,  // 24: ecopoint.collecting.v1.SearchUserRatingsRequest.role:type_name -> ecopoint.collecting.v1.UserRole
+	29, // 25: ecopoint.collecting.v1.SearchUserRatingsResponse.ratings:type_name -> ecopoint.collecting.v1.UserRating
+	34, // 26: ecopoint.collecting.v1.CollectorPenalty.strikes:type_name -> ecopoint.collecting.v1.Strike
+	6,  // 27: ecopoint.collecting.v1.UpdateOrderDetailsRequest.pick_address:type_name -> ecopoint.collecting.v1.Address
+	8,  // 28: ecopoint.collecting.v1.UpdateOrderDetailsRequest.items:type_name -> ecopoint.collecting.v1.WasteItem
+	3,  // 29: ecopoint.collecting.v1.ChatMessage.sender_role:type_name -> ecopoint.collecting.v1.UserRole
+	40, // 30: ecopoint.collecting.v1.ChatClientFrame.join:type_name -> ecopoint.collecting.v1.ChatJoin
+	41, // 31: ecopoint.collecting.v1.ChatClientFrame.send:type_name -> ecopoint.collecting.v1.ChatSend
+	42, // 32: ecopoint.collecting.v1.ChatClientFrame.read:type_name -> ecopoint.collecting.v1.ChatRead
+	39, // 33: ecopoint.collecting.v1.ChatServerFrame.message:type_name -> ecopoint.collecting.v1.ChatMessage
+	44, // 34: ecopoint.collecting.v1.ChatServerFrame.receipt:type_name -> ecopoint.collecting.v1.ChatReceipt
+	45, // 35: ecopoint.collecting.v1.ChatServerFrame.closed:type_name -> ecopoint.collecting.v1.ChatClosed
+	39, // 36: ecopoint.collecting.v1.ListChatMessagesResponse.messages:type_name -> ecopoint.collecting.v1.ChatMessage
+	4,  // 37: ecopoint.collecting.v1.Attachment.kind:type_name -> ecopoint.collecting.v1.AttachmentKind
+	4,  // 38: ecopoint.collecting.v1.AttachmentInfo.kind:type_name -> ecopoint.collecting.v1.AttachmentKind
+	58, // 39: ecopoint.collecting.v1.AttachmentUpload.headers:type_name -> ecopoint.collecting.v1.AttachmentUpload.HeadersEntry
+	50, // 40: ecopoint.collecting.v1.UploadAttachmentRequest.info:type_name -> ecopoint.collecting.v1.AttachmentInfo
+	10, // 41: ecopoint.collecting.v1.CollectingService.CreateOrder:input_type -> ecopoint.collecting.v1.CreateOrderRequest
+	11, // 42: ecopoint.collecting.v1.CollectingService.ListAvailableOrders:input_type -> ecopoint.collecting.v1.ListAvailableOrdersRequest
+	13, // 43: ecopoint.collecting.v1.CollectingService.AcceptOrder:input_type -> ecopoint.collecting.v1.AcceptOrderRequest
+	14, // 44: ecopoint.collecting.v1.CollectingService.UpdateOrderStatus:input_type -> ecopoint.collecting.v1.UpdateOrderStatusRequest
+	16, // 45: ecopoint.collecting.v1.CollectingService.GetOrder:input_type -> ecopoint.collecting.v1.GetOrderRequest
+	17, // 46: ecopoint.collecting.v1.CollectingService.ListMyActiveOrders:input_type -> ecopoint.collecting.v1.ListMyActiveOrdersRequest
+	18, // 47: ecopoint.collecting.v1.CollectingService.ListMyOrders:input_type -> ecopoint.collecting.v1.ListMyOrdersRequest
+	15, // 48: ecopoint.collecting.v1.CollectingService.CancelOrder:input_type -> ecopoint.collecting.v1.CancelOrderRequest
+	36, // 49: ecopoint.collecting.v1.CollectingService.UpdateOrderDetails:input_type -> ecopoint.collecting.v1.UpdateOrderDetailsRequest
+	20, // 50: ecopoint.collecting.v1.CollectingService.ListCollectorOrders:input_type -> ecopoint.collecting.v1.ListCollectorOrdersRequest
+	21, // 51: ecopoint.collecting.v1.CollectingService.GetCollectorEarnings:input_type -> ecopoint.collecting.v1.GetCollectorEarningsRequest
+	25, // 52: ecopoint.collecting.v1.CollectingService.RateOrder:input_type -> ecopoint.collecting.v1.RateOrderRequest
+	27, // 53: ecopoint.collecting.v1.CollectingService.GetUserRating:input_type -> ecopoint.collecting.v1.GetUserRatingRequest
+	30, // 54: ecopoint.collecting.v1.CollectingService.SearchUserRatings:input_type -> ecopoint.collecting.v1.SearchUserRatingsRequest
+	32, // 55: ecopoint.collecting.v1.CollectingService.GetCollectorPenalty:input_type -> ecopoint.collecting.v1.GetCollectorPenaltyRequest
+	33, // 56: ecopoint.collecting.v1.CollectingService.ClearCollectorPenalty:input_type -> ecopoint.collecting.v1.ClearCollectorPenaltyRequest
+	37, // 57: ecopoint.collecting.v1.CollectingService.GetNotificationPreferences:input_type -> ecopoint.collecting.v1.GetNotificationPreferencesRequest
+	38, // 58: ecopoint.collecting.v1.CollectingService.SetNotificationPreferences:input_type -> ecopoint.collecting.v1.NotificationPreferences
+	43, // 59: ecopoint.collecting.v1.CollectingService.Chat:input_type -> ecopoint.collecting.v1.ChatClientFrame
+	47, // 60: ecopoint.collecting.v1.CollectingService.ListChatMessages:input_type -> ecopoint.collecting.v1.ListChatMessagesRequest
+	50, // 61: ecopoint.collecting.v1.CollectingService.CreateAttachmentUpload:input_type -> ecopoint.collecting.v1.AttachmentInfo
+	52, // 62: ecopoint.collecting.v1.CollectingService.CompleteAttachmentUpload:input_type -> ecopoint.collecting.v1.CompleteAttachmentUploadRequest
+	53, // 63: ecopoint.collecting.v1.CollectingService.UploadAttachment:input_type -> ecopoint.collecting.v1.UploadAttachmentRequest
+	54, // 64: ecopoint.collecting.v1.CollectingService.GetAttachmentURL:input_type -> ecopoint.collecting.v1.GetAttachmentURLRequest
+	56, // 65: ecopoint.collecting.v1.CollectingService.DownloadAttachment:input_type -> ecopoint.collecting.v1.DownloadAttachmentRequest
+	9,  // 66: ecopoint.collecting.v1.CollectingService.CreateOrder:output_type -> ecopoint.collecting.v1.Order
+	12, // 67: ecopoint.collecting.v1.CollectingService.ListAvailableOrders:output_type -> ecopoint.collecting.v1.ListAvailableOrdersResponse
+	9,  // 68: ecopoint.collecting.v1.CollectingService.AcceptOrder:output_type -> ecopoint.collecting.v1.Order
+	9,  // 69: ecopoint.collecting.v1.CollectingService.UpdateOrderStatus:output_type -> ecopoint.collecting.v1.Order
+	9,  // 70: ecopoint.collecting.v1.CollectingService.GetOrder:output_type -> ecopoint.collecting.v1.Order
+	19, // 71: ecopoint.collecting.v1.CollectingService.ListMyActiveOrders:output_type -> ecopoint.collecting.v1.ListOrdersResponse
+	19, // 72: ecopoint.collecting.v1.CollectingService.ListMyOrders:output_type -> ecopoint.collecting.v1.ListOrdersResponse
+	9,  // 73: ecopoint.collecting.v1.CollectingService.CancelOrder:output_type -> ecopoint.collecting.v1.Order
+	9,  // 74: ecopoint.collecting.v1.CollectingService.UpdateOrderDetails:output_type -> ecopoint.collecting.v1.Order
+	19, // 75: ecopoint.collecting.v1.CollectingService.ListCollectorOrders:output_type -> ecopoint.collecting.v1.ListOrdersResponse
+	24, // 76: ecopoint.collecting.v1.CollectingService.GetCollectorEarnings:output_type -> ecopoint.collecting.v1.CollectorEarnings
+	26, // 77: ecopoint.collecting.v1.CollectingService.RateOrder:output_type -> ecopoint.collecting.v1.Rating
+	29, // 78: ecopoint.collecting.v1.CollectingService.GetUserRating:output_type -> ecopoint.collecting.v1.UserRating
+	31, // 79: ecopoint.collecting.v1.CollectingService.SearchUserRatings:output_type -> ecopoint.collecting.v1.SearchUserRatingsResponse
+	35, // 80: ecopoint.collecting.v1.CollectingService.GetCollectorPenalty:output_type -> ecopoint.collecting.v1.CollectorPenalty
+	35, // 81: ecopoint.collecting.v1.CollectingService.ClearCollectorPenalty:output_type -> ecopoint.collecting.v1.CollectorPenalty
+	38, // 82: ecopoint.collecting.v1.CollectingService.GetNotificationPreferences:output_type -> ecopoint.collecting.v1.NotificationPreferences
+	38, // 83: ecopoint.collecting.v1.CollectingService.SetNotificationPreferences:output_type -> ecopoint.collecting.v1.NotificationPreferences
+	46, // 84: ecopoint.collecting.v1.CollectingService.Chat:output_type -> ecopoint.collecting.v1.ChatServerFrame
+	48, // 85: ecopoint.collecting.v1.CollectingService.ListChatMessages:output_type -> ecopoint.collecting.v1.ListChatMessagesResponse
+	51, // 86: ecopoint.collecting.v1.CollectingService.CreateAttachmentUpload:output_type -> ecopoint.collecting.v1.AttachmentUpload
+	9,  // 87: ecopoint.collecting.v1.CollectingService.CompleteAttachmentUpload:output_type -> ecopoint.collecting.v1.Order
+	9,  // 88: ecopoint.collecting.v1.CollectingService.UploadAttachment:output_type -> ecopoint.collecting.v1.Order
+	55, // 89: ecopoint.collecting.v1.CollectingService.GetAttachmentURL:output_type -> ecopoint.collecting.v1.AttachmentURL
+	57, // 90: ecopoint.collecting.v1.CollectingService.DownloadAttachment:output_type -> ecopoint.collecting.v1.AttachmentChunk
+	66, // [66:91] is the sub-list for method output_type
+	41, // [41:66] is the sub-list for method input_type
+	41, // [41:41] is the sub-list for extension type_name
+	41, // [41:41] is the sub-list for extension extendee
+	0,  // [0:41] is the sub-list for field type_name
 }
 
 func init() { file_collecting_proto_init() }
@@ -984,18 +4426,34 @@ func file_collecting_proto_init() {
 	if File_collecting_proto != nil {
 		return
 	}
+	file_collecting_proto_msgTypes[31].OneofWrappers = []any{}
+	file_collecting_proto_msgTypes[38].OneofWrappers = []any{
+		(*ChatClientFrame_Join)(nil),
+		(*ChatClientFrame_Send)(nil),
+		(*ChatClientFrame_Read)(nil),
+	}
+	file_collecting_proto_msgTypes[41].OneofWrappers = []any{
+		(*ChatServerFrame_Message)(nil),
+		(*ChatServerFrame_Receipt)(nil),
+		(*ChatServerFrame_Closed)(nil),
+	}
+	file_collecting_proto_msgTypes[48].OneofWrappers = []any{
+		(*UploadAttachmentRequest_Info)(nil),
+		(*UploadAttachmentRequest_Chunk)(nil),
+	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_collecting_proto_rawDesc), len(file_collecting_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   14,
+			NumEnums:      5,
+			NumMessages:   54,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_collecting_proto_goTypes,
 		DependencyIndexes: file_collecting_proto_depIdxs,
+		EnumInfos:         file_collecting_proto_enumTypes,
 		MessageInfos:      file_collecting_proto_msgTypes,
 	}.Build()
 	File_collecting_proto = out.File
@@ -19,13 +19,31 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	CollectingService_CreateOrder_FullMethodName         = "/ecopoint.collecting.v1.CollectingService/CreateOrder"
-	CollectingService_ListAvailableOrders_FullMethodName = "/ecopoint.collecting.v1.CollectingService/ListAvailableOrders"
-	CollectingService_AcceptOrder_FullMethodName         = "/ecopoint.collecting.v1.CollectingService/AcceptOrder"
-	CollectingService_UpdateOrderStatus_FullMethodName   = "/ecopoint.collecting.v1.CollectingService/UpdateOrderStatus"
-	CollectingService_GetOrder_FullMethodName            = "/ecopoint.collecting.v1.CollectingService/GetOrder"
-	CollectingService_ListMyActiveOrders_FullMethodName  = "/ecopoint.collecting.v1.CollectingService/ListMyActiveOrders"
-	CollectingService_ListMyOrders_FullMethodName        = "/ecopoint.collecting.v1.CollectingService/ListMyOrders"
+	CollectingService_CreateOrder_FullMethodName                = "/ecopoint.collecting.v1.CollectingService/CreateOrder"
+	CollectingService_ListAvailableOrders_FullMethodName        = "/ecopoint.collecting.v1.CollectingService/ListAvailableOrders"
+	CollectingService_AcceptOrder_FullMethodName                = "/ecopoint.collecting.v1.CollectingService/AcceptOrder"
+	CollectingService_UpdateOrderStatus_FullMethodName          = "/ecopoint.collecting.v1.CollectingService/UpdateOrderStatus"
+	CollectingService_GetOrder_FullMethodName                   = "/ecopoint.collecting.v1.CollectingService/GetOrder"
+	CollectingService_ListMyActiveOrders_FullMethodName         = "/ecopoint.collecting.v1.CollectingService/ListMyActiveOrders"
+	CollectingService_ListMyOrders_FullMethodName               = "/ecopoint.collecting.v1.CollectingService/ListMyOrders"
+	CollectingService_CancelOrder_FullMethodName                = "/ecopoint.collecting.v1.CollectingService/CancelOrder"
+	CollectingService_UpdateOrderDetails_FullMethodName         = "/ecopoint.collecting.v1.CollectingService/UpdateOrderDetails"
+	CollectingService_ListCollectorOrders_FullMethodName        = "/ecopoint.collecting.v1.CollectingService/ListCollectorOrders"
+	CollectingService_GetCollectorEarnings_FullMethodName       = "/ecopoint.collecting.v1.CollectingService/GetCollectorEarnings"
+	CollectingService_RateOrder_FullMethodName                  = "/ecopoint.collecting.v1.CollectingService/RateOrder"
+	CollectingService_GetUserRating_FullMethodName              = "/ecopoint.collecting.v1.CollectingService/GetUserRating"
+	CollectingService_SearchUserRatings_FullMethodName          = "/ecopoint.collecting.v1.CollectingService/SearchUserRatings"
+	CollectingService_GetCollectorPenalty_FullMethodName        = "/ecopoint.collecting.v1.CollectingService/GetCollectorPenalty"
+	CollectingService_ClearCollectorPenalty_FullMethodName      = "/ecopoint.collecting.v1.CollectingService/ClearCollectorPenalty"
+	CollectingService_GetNotificationPreferences_FullMethodName = "/ecopoint.collecting.v1.CollectingService/GetNotificationPreferences"
+	CollectingService_SetNotificationPreferences_FullMethodName = "/ecopoint.collecting.v1.CollectingService/SetNotificationPreferences"
+	CollectingService_Chat_FullMethodName                       = "/ecopoint.collecting.v1.CollectingService/Chat"
+	CollectingService_ListChatMessages_FullMethodName           = "/ecopoint.collecting.v1.CollectingService/ListChatMessages"
+	CollectingService_CreateAttachmentUpload_FullMethodName     = "/ecopoint.collecting.v1.CollectingService/CreateAttachmentUpload"
+	CollectingService_CompleteAttachmentUpload_FullMethodName   = "/ecopoint.collecting.v1.CollectingService/CompleteAttachmentUpload"
+	CollectingService_UploadAttachment_FullMethodName           = "/ecopoint.collecting.v1.CollectingService/UploadAttachment"
+	CollectingService_GetAttachmentURL_FullMethodName           = "/ecopoint.collecting.v1.CollectingService/GetAttachmentURL"
+	CollectingService_DownloadAttachment_FullMethodName         = "/ecopoint.collecting.v1.CollectingService/DownloadAttachment"
 )
 
 // CollectingServiceClient is the client API for CollectingService service.
@@ -39,6 +57,33 @@ type CollectingServiceClient interface {
 	GetOrder(ctx context.Context, in *GetOrderRequest, opts ...grpc.CallOption) (*Order, error)
 	ListMyActiveOrders(ctx context.Context, in *ListMyActiveOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
 	ListMyOrders(ctx context.Context, in *ListMyOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
+	CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error)
+	UpdateOrderDetails(ctx context.Context, in *UpdateOrderDetailsRequest, opts ...grpc.CallOption) (*Order, error)
+	ListCollectorOrders(ctx context.Context, in *ListCollectorOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error)
+	GetCollectorEarnings(ctx context.Context, in *GetCollectorEarningsRequest, opts ...grpc.CallOption) (*CollectorEarnings, error)
+	RateOrder(ctx context.Context, in *RateOrderRequest, opts ...grpc.CallOption) (*Rating, error)
+	GetUserRating(ctx context.Context, in *GetUserRatingRequest, opts ...grpc.CallOption) (*UserRating, error)
+	// Admin: find users by their rating, lowest average first
+	SearchUserRatings(ctx context.Context, in *SearchUserRatingsRequest, opts ...grpc.CallOption) (*SearchUserRatingsResponse, error)
+	// Admin: inspect and lift collector cooldowns
+	GetCollectorPenalty(ctx context.Context, in *GetCollectorPenaltyRequest, opts ...grpc.CallOption) (*CollectorPenalty, error)
+	ClearCollectorPenalty(ctx context.Context, in *ClearCollectorPenaltyRequest, opts ...grpc.CallOption) (*CollectorPenalty, error)
+	GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*NotificationPreferences, error)
+	SetNotificationPreferences(ctx context.Context, in *NotificationPreferences, opts ...grpc.CallOption) (*NotificationPreferences, error)
+	// Order chat: the first client frame must be a join; the server then streams new
+	// messages and read receipts until the order is complete or cancelled. Both participants
+	// must be connected to the same server instance: live events are not shared across replicas.
+	Chat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ChatClientFrame, ChatServerFrame], error)
+	ListChatMessages(ctx context.Context, in *ListChatMessagesRequest, opts ...grpc.CallOption) (*ListChatMessagesResponse, error)
+	// Attachments: either PUT the file to the URL from CreateAttachmentUpload and then call
+	// CompleteAttachmentUpload, or send it through UploadAttachment (info frame, then chunks).
+	// Direct URLs need an S3-compatible store; otherwise CreateAttachmentUpload fails with
+	// FAILED_PRECONDITION and clients fall back to the stream.
+	CreateAttachmentUpload(ctx context.Context, in *AttachmentInfo, opts ...grpc.CallOption) (*AttachmentUpload, error)
+	CompleteAttachmentUpload(ctx context.Context, in *CompleteAttachmentUploadRequest, opts ...grpc.CallOption) (*Order, error)
+	UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, Order], error)
+	GetAttachmentURL(ctx context.Context, in *GetAttachmentURLRequest, opts ...grpc.CallOption) (*AttachmentURL, error)
+	DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AttachmentChunk], error)
 }
 
 type collectingServiceClient struct {
@@ -119,6 +164,201 @@ func (c *collectingServiceClient) ListMyOrders(ctx context.Context, in *ListMyOr
 	return out, nil
 }
 
+func (c *collectingServiceClient) CancelOrder(ctx context.Context, in *CancelOrderRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, CollectingService_CancelOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) UpdateOrderDetails(ctx context.Context, in *UpdateOrderDetailsRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, CollectingService_UpdateOrderDetails_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) ListCollectorOrders(ctx context.Context, in *ListCollectorOrdersRequest, opts ...grpc.CallOption) (*ListOrdersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListOrdersResponse)
+	err := c.cc.Invoke(ctx, CollectingService_ListCollectorOrders_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) GetCollectorEarnings(ctx context.Context, in *GetCollectorEarningsRequest, opts ...grpc.CallOption) (*CollectorEarnings, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectorEarnings)
+	err := c.cc.Invoke(ctx, CollectingService_GetCollectorEarnings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) RateOrder(ctx context.Context, in *RateOrderRequest, opts ...grpc.CallOption) (*Rating, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rating)
+	err := c.cc.Invoke(ctx, CollectingService_RateOrder_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) GetUserRating(ctx context.Context, in *GetUserRatingRequest, opts ...grpc.CallOption) (*UserRating, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(UserRating)
+	err := c.cc.Invoke(ctx, CollectingService_GetUserRating_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) SearchUserRatings(ctx context.Context, in *SearchUserRatingsRequest, opts ...grpc.CallOption) (*SearchUserRatingsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchUserRatingsResponse)
+	err := c.cc.Invoke(ctx, CollectingService_SearchUserRatings_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) GetCollectorPenalty(ctx context.Context, in *GetCollectorPenaltyRequest, opts ...grpc.CallOption) (*CollectorPenalty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectorPenalty)
+	err := c.cc.Invoke(ctx, CollectingService_GetCollectorPenalty_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) ClearCollectorPenalty(ctx context.Context, in *ClearCollectorPenaltyRequest, opts ...grpc.CallOption) (*CollectorPenalty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectorPenalty)
+	err := c.cc.Invoke(ctx, CollectingService_ClearCollectorPenalty_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) GetNotificationPreferences(ctx context.Context, in *GetNotificationPreferencesRequest, opts ...grpc.CallOption) (*NotificationPreferences, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationPreferences)
+	err := c.cc.Invoke(ctx, CollectingService_GetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) SetNotificationPreferences(ctx context.Context, in *NotificationPreferences, opts ...grpc.CallOption) (*NotificationPreferences, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationPreferences)
+	err := c.cc.Invoke(ctx, CollectingService_SetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) Chat(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ChatClientFrame, ChatServerFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectingService_ServiceDesc.Streams[0], CollectingService_Chat_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ChatClientFrame, ChatServerFrame]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_ChatClient = grpc.BidiStreamingClient[ChatClientFrame, ChatServerFrame]
+
+func (c *collectingServiceClient) ListChatMessages(ctx context.Context, in *ListChatMessagesRequest, opts ...grpc.CallOption) (*ListChatMessagesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListChatMessagesResponse)
+	err := c.cc.Invoke(ctx, CollectingService_ListChatMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) CreateAttachmentUpload(ctx context.Context, in *AttachmentInfo, opts ...grpc.CallOption) (*AttachmentUpload, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentUpload)
+	err := c.cc.Invoke(ctx, CollectingService_CreateAttachmentUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) CompleteAttachmentUpload(ctx context.Context, in *CompleteAttachmentUploadRequest, opts ...grpc.CallOption) (*Order, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Order)
+	err := c.cc.Invoke(ctx, CollectingService_CompleteAttachmentUpload_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) UploadAttachment(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[UploadAttachmentRequest, Order], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectingService_ServiceDesc.Streams[1], CollectingService_UploadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[UploadAttachmentRequest, Order]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_UploadAttachmentClient = grpc.ClientStreamingClient[UploadAttachmentRequest, Order]
+
+func (c *collectingServiceClient) GetAttachmentURL(ctx context.Context, in *GetAttachmentURLRequest, opts ...grpc.CallOption) (*AttachmentURL, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttachmentURL)
+	err := c.cc.Invoke(ctx, CollectingService_GetAttachmentURL_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *collectingServiceClient) DownloadAttachment(ctx context.Context, in *DownloadAttachmentRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AttachmentChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &CollectingService_ServiceDesc.Streams[2], CollectingService_DownloadAttachment_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DownloadAttachmentRequest, AttachmentChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_DownloadAttachmentClient = grpc.ServerStreamingClient[AttachmentChunk]
+
 // CollectingServiceServer is the server API for CollectingService service.
 // All implementations must embed UnimplementedCollectingServiceServer
 // for forward compatibility.
@@ -130,6 +370,33 @@ type CollectingServiceServer interface {
 	GetOrder(context.Context, *GetOrderRequest) (*Order, error)
 	ListMyActiveOrders(context.Context, *ListMyActiveOrdersRequest) (*ListOrdersResponse, error)
 	ListMyOrders(context.Context, *ListMyOrdersRequest) (*ListOrdersResponse, error)
+	CancelOrder(context.Context, *CancelOrderRequest) (*Order, error)
+	UpdateOrderDetails(context.Context, *UpdateOrderDetailsRequest) (*Order, error)
+	ListCollectorOrders(context.Context, *ListCollectorOrdersRequest) (*ListOrdersResponse, error)
+	GetCollectorEarnings(context.Context, *GetCollectorEarningsRequest) (*CollectorEarnings, error)
+	RateOrder(context.Context, *RateOrderRequest) (*Rating, error)
+	GetUserRating(context.Context, *GetUserRatingRequest) (*UserRating, error)
+	// Admin: find users by their rating, lowest average first
+	SearchUserRatings(context.Context, *SearchUserRatingsRequest) (*SearchUserRatingsResponse, error)
+	// Admin: inspect and lift collector cooldowns
+	GetCollectorPenalty(context.Context, *GetCollectorPenaltyRequest) (*CollectorPenalty, error)
+	ClearCollectorPenalty(context.Context, *ClearCollectorPenaltyRequest) (*CollectorPenalty, error)
+	GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*NotificationPreferences, error)
+	SetNotificationPreferences(context.Context, *NotificationPreferences) (*NotificationPreferences, error)
+	// Order chat: the first client frame must be a join; the server then streams new
+	// messages and read receipts until the order is complete or cancelled. Both participants
+	// must be connected to the same server instance: live events are not shared across replicas.
+	Chat(grpc.BidiStreamingServer[ChatClientFrame, ChatServerFrame]) error
+	ListChatMessages(context.Context, *ListChatMessagesRequest) (*ListChatMessagesResponse, error)
+	// Attachments: either PUT the file to the URL from CreateAttachmentUpload and then call
+	// CompleteAttachmentUpload, or send it through UploadAttachment (info frame, then chunks).
+	// Direct URLs need an S3-compatible store; otherwise CreateAttachmentUpload fails with
+	// FAILED_PRECONDITION and clients fall back to the stream.
+	CreateAttachmentUpload(context.Context, *AttachmentInfo) (*AttachmentUpload, error)
+	CompleteAttachmentUpload(context.Context, *CompleteAttachmentUploadRequest) (*Order, error)
+	UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, Order]) error
+	GetAttachmentURL(context.Context, *GetAttachmentURLRequest) (*AttachmentURL, error)
+	DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[AttachmentChunk]) error
 	mustEmbedUnimplementedCollectingServiceServer()
 }
 
@@ -161,6 +428,60 @@ func (UnimplementedCollectingServiceServer) ListMyActiveOrders(context.Context,
 func (UnimplementedCollectingServiceServer) ListMyOrders(context.Context, *ListMyOrdersRequest) (*ListOrdersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method ListMyOrders not implemented")
 }
+func (UnimplementedCollectingServiceServer) CancelOrder(context.Context, *CancelOrderRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CancelOrder not implemented")
+}
+func (UnimplementedCollectingServiceServer) UpdateOrderDetails(context.Context, *UpdateOrderDetailsRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateOrderDetails not implemented")
+}
+func (UnimplementedCollectingServiceServer) ListCollectorOrders(context.Context, *ListCollectorOrdersRequest) (*ListOrdersResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollectorOrders not implemented")
+}
+func (UnimplementedCollectingServiceServer) GetCollectorEarnings(context.Context, *GetCollectorEarningsRequest) (*CollectorEarnings, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollectorEarnings not implemented")
+}
+func (UnimplementedCollectingServiceServer) RateOrder(context.Context, *RateOrderRequest) (*Rating, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RateOrder not implemented")
+}
+func (UnimplementedCollectingServiceServer) GetUserRating(context.Context, *GetUserRatingRequest) (*UserRating, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetUserRating not implemented")
+}
+func (UnimplementedCollectingServiceServer) SearchUserRatings(context.Context, *SearchUserRatingsRequest) (*SearchUserRatingsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchUserRatings not implemented")
+}
+func (UnimplementedCollectingServiceServer) GetCollectorPenalty(context.Context, *GetCollectorPenaltyRequest) (*CollectorPenalty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetCollectorPenalty not implemented")
+}
+func (UnimplementedCollectingServiceServer) ClearCollectorPenalty(context.Context, *ClearCollectorPenaltyRequest) (*CollectorPenalty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ClearCollectorPenalty not implemented")
+}
+func (UnimplementedCollectingServiceServer) GetNotificationPreferences(context.Context, *GetNotificationPreferencesRequest) (*NotificationPreferences, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedCollectingServiceServer) SetNotificationPreferences(context.Context, *NotificationPreferences) (*NotificationPreferences, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNotificationPreferences not implemented")
+}
+func (UnimplementedCollectingServiceServer) Chat(grpc.BidiStreamingServer[ChatClientFrame, ChatServerFrame]) error {
+	return status.Errorf(codes.Unimplemented, "method Chat not implemented")
+}
+func (UnimplementedCollectingServiceServer) ListChatMessages(context.Context, *ListChatMessagesRequest) (*ListChatMessagesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListChatMessages not implemented")
+}
+func (UnimplementedCollectingServiceServer) CreateAttachmentUpload(context.Context, *AttachmentInfo) (*AttachmentUpload, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAttachmentUpload not implemented")
+}
+func (UnimplementedCollectingServiceServer) CompleteAttachmentUpload(context.Context, *CompleteAttachmentUploadRequest) (*Order, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CompleteAttachmentUpload not implemented")
+}
+func (UnimplementedCollectingServiceServer) UploadAttachment(grpc.ClientStreamingServer[UploadAttachmentRequest, Order]) error {
+	return status.Errorf(codes.Unimplemented, "method UploadAttachment not implemented")
+}
+func (UnimplementedCollectingServiceServer) GetAttachmentURL(context.Context, *GetAttachmentURLRequest) (*AttachmentURL, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetAttachmentURL not implemented")
+}
+func (UnimplementedCollectingServiceServer) DownloadAttachment(*DownloadAttachmentRequest, grpc.ServerStreamingServer[AttachmentChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method DownloadAttachment not implemented")
+}
 func (UnimplementedCollectingServiceServer) mustEmbedUnimplementedCollectingServiceServer() {}
 func (UnimplementedCollectingServiceServer) testEmbeddedByValue()                           {}
 
@@ -308,6 +629,301 @@ func _CollectingService_ListMyOrders_Handler(srv interface{}, ctx context.Contex
 	return interceptor(ctx, in, info, handler)
 }
 
+func _CollectingService_CancelOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).CancelOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_CancelOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).CancelOrder(ctx, req.(*CancelOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_UpdateOrderDetails_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateOrderDetailsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).UpdateOrderDetails(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_UpdateOrderDetails_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).UpdateOrderDetails(ctx, req.(*UpdateOrderDetailsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_ListCollectorOrders_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectorOrdersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).ListCollectorOrders(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_ListCollectorOrders_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).ListCollectorOrders(ctx, req.(*ListCollectorOrdersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_GetCollectorEarnings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCollectorEarningsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).GetCollectorEarnings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_GetCollectorEarnings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).GetCollectorEarnings(ctx, req.(*GetCollectorEarningsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_RateOrder_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RateOrderRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).RateOrder(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_RateOrder_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).RateOrder(ctx, req.(*RateOrderRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_GetUserRating_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetUserRatingRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).GetUserRating(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_GetUserRating_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).GetUserRating(ctx, req.(*GetUserRatingRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_SearchUserRatings_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchUserRatingsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).SearchUserRatings(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_SearchUserRatings_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).SearchUserRatings(ctx, req.(*SearchUserRatingsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_GetCollectorPenalty_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCollectorPenaltyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).GetCollectorPenalty(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_GetCollectorPenalty_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).GetCollectorPenalty(ctx, req.(*GetCollectorPenaltyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_ClearCollectorPenalty_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClearCollectorPenaltyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).ClearCollectorPenalty(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_ClearCollectorPenalty_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).ClearCollectorPenalty(ctx, req.(*ClearCollectorPenaltyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNotificationPreferencesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).GetNotificationPreferences(ctx, req.(*GetNotificationPreferencesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_SetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationPreferences)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).SetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_SetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).SetNotificationPreferences(ctx, req.(*NotificationPreferences))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_Chat_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CollectingServiceServer).Chat(&grpc.GenericServerStream[ChatClientFrame, ChatServerFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_ChatServer = grpc.BidiStreamingServer[ChatClientFrame, ChatServerFrame]
+
+func _CollectingService_ListChatMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListChatMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).ListChatMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_ListChatMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).ListChatMessages(ctx, req.(*ListChatMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_CreateAttachmentUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttachmentInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).CreateAttachmentUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_CreateAttachmentUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).CreateAttachmentUpload(ctx, req.(*AttachmentInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_CompleteAttachmentUpload_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompleteAttachmentUploadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).CompleteAttachmentUpload(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_CompleteAttachmentUpload_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).CompleteAttachmentUpload(ctx, req.(*CompleteAttachmentUploadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_UploadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(CollectingServiceServer).UploadAttachment(&grpc.GenericServerStream[UploadAttachmentRequest, Order]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_UploadAttachmentServer = grpc.ClientStreamingServer[UploadAttachmentRequest, Order]
+
+func _CollectingService_GetAttachmentURL_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetAttachmentURLRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CollectingServiceServer).GetAttachmentURL(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: CollectingService_GetAttachmentURL_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CollectingServiceServer).GetAttachmentURL(ctx, req.(*GetAttachmentURLRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _CollectingService_DownloadAttachment_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DownloadAttachmentRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(CollectingServiceServer).DownloadAttachment(m, &grpc.GenericServerStream[DownloadAttachmentRequest, AttachmentChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type CollectingService_DownloadAttachmentServer = grpc.ServerStreamingServer[AttachmentChunk]
+
 // CollectingService_ServiceDesc is the grpc.ServiceDesc for CollectingService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -343,7 +959,84 @@ var CollectingService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "ListMyOrders",
 			Handler:    _CollectingService_ListMyOrders_Handler,
 		},
+		{
+			MethodName: "CancelOrder",
+			Handler:    _CollectingService_CancelOrder_Handler,
+		},
+		{
+			MethodName: "UpdateOrderDetails",
+			Handler:    _CollectingService_UpdateOrderDetails_Handler,
+		},
+		{
+			MethodName: "ListCollectorOrders",
+			Handler:    _CollectingService_ListCollectorOrders_Handler,
+		},
+		{
+			MethodName: "GetCollectorEarnings",
+			Handler:    _CollectingService_GetCollectorEarnings_Handler,
+		},
+		{
+			MethodName: "RateOrder",
+			Handler:    _CollectingService_RateOrder_Handler,
+		},
+		{
+			MethodName: "GetUserRating",
+			Handler:    _CollectingService_GetUserRating_Handler,
+		},
+		{
+			MethodName: "SearchUserRatings",
+			Handler:    _CollectingService_SearchUserRatings_Handler,
+		},
+		{
+			MethodName: "GetCollectorPenalty",
+			Handler:    _CollectingService_GetCollectorPenalty_Handler,
+		},
+		{
+			MethodName: "ClearCollectorPenalty",
+			Handler:    _CollectingService_ClearCollectorPenalty_Handler,
+		},
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _CollectingService_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "SetNotificationPreferences",
+			Handler:    _CollectingService_SetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "ListChatMessages",
+			Handler:    _CollectingService_ListChatMessages_Handler,
+		},
+		{
+			MethodName: "CreateAttachmentUpload",
+			Handler:    _CollectingService_CreateAttachmentUpload_Handler,
+		},
+		{
+			MethodName: "CompleteAttachmentUpload",
+			Handler:    _CollectingService_CompleteAttachmentUpload_Handler,
+		},
+		{
+			MethodName: "GetAttachmentURL",
+			Handler:    _CollectingService_GetAttachmentURL_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Chat",
+			Handler:       _CollectingService_Chat_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "UploadAttachment",
+			Handler:       _CollectingService_UploadAttachment_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "DownloadAttachment",
+			Handler:       _CollectingService_DownloadAttachment_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "collecting.proto",
 }